@@ -0,0 +1,62 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { isWorkerRequest, verifyAuth, unauthorizedResponse } from '@/lib/auth';
+import { appendLiveEvents, getLiveEvents } from '@/lib/live-events';
+import { parseBody, postEventsSchema } from '@/lib/validation';
+import { errorResponse, badRequestResponse } from '@/lib/api-response';
+import type { GameEvent } from '@/lib/types';
+
+interface RouteParams {
+  params: Promise<{ id: string }>;
+}
+
+/**
+ * GET /api/jobs/[id]/events?since=N — Events streamed so far for a running
+ * job, after cursor `since`. Returns { events, next }.
+ */
+export async function GET(request: NextRequest, { params }: RouteParams) {
+  try {
+    await verifyAuth(request);
+  } catch {
+    return unauthorizedResponse();
+  }
+
+  const { id } = await params;
+  const sinceParam = request.nextUrl.searchParams.get('since');
+  const since = sinceParam === null ? 0 : Number(sinceParam);
+  if (!Number.isInteger(since) || since < 0) {
+    return badRequestResponse('since must be a non-negative integer');
+  }
+  return NextResponse.json(getLiveEvents(id, since), {
+    headers: { 'Cache-Control': 'no-store' },
+  });
+}
+
+/**
+ * POST /api/jobs/[id]/events — Append a batch of condensed events.
+ * Called by the worker while it condenses a simulation's log.
+ * Body: { events: GameEvent[] }
+ */
+export async function POST(request: NextRequest, { params }: RouteParams) {
+  if (!isWorkerRequest(request)) {
+    return unauthorizedResponse('Worker authentication required');
+  }
+
+  try {
+    const { id } = await params;
+    let body: unknown;
+    try {
+      body = await request.json();
+    } catch {
+      return badRequestResponse('Invalid JSON body');
+    }
+    const parsed = parseBody(postEventsSchema, body);
+    if (!parsed.success) {
+      return badRequestResponse(parsed.error);
+    }
+    const total = appendLiveEvents(id, parsed.data.events as GameEvent[]);
+    return NextResponse.json({ accepted: parsed.data.events.length, total }, { status: 202 });
+  } catch (error) {
+    console.error('POST /api/jobs/[id]/events error:', error);
+    return errorResponse(error instanceof Error ? error.message : 'Failed to store events', 500);
+  }
+}
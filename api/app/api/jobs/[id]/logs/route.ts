@@ -26,10 +26,10 @@ export async function POST(request: NextRequest, { params }: RouteParams) {
       return badRequestResponse('gameLogs array is required and must not be empty');
     }
 
-    const { gameCount } = await ingestLogs(id, gameLogs, deckNames, deckLists);
+    const { gameCount, deadLetterCount, duplicateCount } = await ingestLogs(id, gameLogs, deckNames, deckLists);
 
     return NextResponse.json(
-      { message: 'Logs ingested successfully', jobId: id, gameCount },
+      { message: 'Logs ingested successfully', jobId: id, gameCount, deadLetterCount, duplicateCount },
       { status: 201 }
     );
   } catch (error) {
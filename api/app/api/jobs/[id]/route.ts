@@ -170,7 +170,7 @@ export async function DELETE(request: NextRequest, { params }: RouteParams) {
 
     if (isGcpMode()) {
       try {
-        await deleteJobArtifacts(id);
+        await deleteJobArtifacts(id, job.createdAt);
       } catch (err) {
         console.warn('Failed to delete GCS artifacts:', err);
       }
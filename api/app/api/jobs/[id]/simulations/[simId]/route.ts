@@ -16,7 +16,7 @@ interface RouteParams {
 /**
  * PATCH /api/jobs/[id]/simulations/[simId] — Update a single simulation's status.
  * Called by the worker to report per-simulation progress.
- * Body: Partial<SimulationStatus> (state, workerId, durationMs, errorMessage, winner, winningTurn)
+ * Body: Partial<SimulationStatus> (state, workerId, durationMs, stageDurationsMs, errorMessage, winner, winningTurn)
  */
 export async function PATCH(request: NextRequest, { params }: RouteParams) {
   if (!isWorkerRequest(request)) {
@@ -34,7 +34,7 @@ export async function PATCH(request: NextRequest, { params }: RouteParams) {
     if (!parsed.success) {
       return NextResponse.json({ error: parsed.error }, { status: 400 });
     }
-    const { state, workerId, workerName, durationMs, errorMessage, winner, winningTurn, winners, winningTurns } = parsed.data;
+    const { state, workerId, workerName, durationMs, errorMessage, winner, winningTurn, winners, winningTurns, stageDurationsMs } = parsed.data;
 
     // Build update object, only including defined fields
     const update: Record<string, unknown> = {};
@@ -47,6 +47,7 @@ export async function PATCH(request: NextRequest, { params }: RouteParams) {
     if (winningTurn !== undefined) update.winningTurn = winningTurn;
     if (winners !== undefined) update.winners = winners;
     if (winningTurns !== undefined) update.winningTurns = winningTurns;
+    if (stageDurationsMs !== undefined) update.stageDurationsMs = stageDurationsMs;
 
     // Guard: validate state transitions using the simulation state machine.
     // Rejects invalid transitions (e.g., COMPLETED→RUNNING from stale Pub/Sub redeliveries).
@@ -53,7 +53,7 @@ export async function POST(request: NextRequest) {
 
           if (isGcpMode()) {
             try {
-              await deleteJobArtifacts(id);
+              await deleteJobArtifacts(id, job.createdAt);
             } catch (err) {
               console.warn(`Failed to delete GCS artifacts for ${id}:`, err);
             }
@@ -16,6 +16,8 @@ import { OVERRIDE_HEADER_NAME, encodeOverrideHeader } from '@/lib/override-heade
  * Query params:
  *   workerId   — stable identifier for reclaim heuristics and audit
  *   workerName — display name surfaced on the job detail UI
+ *   excludeJobs — optional comma-separated job IDs to skip (jobs already at
+ *                 their parallelism on this worker)
  *
  * Returns 200 { jobId, simId, simIndex } on success, 204 when no work is
  * available. Auth: X-Worker-Secret (shared with the other worker endpoints).
@@ -38,10 +40,14 @@ export async function GET(request: NextRequest) {
   if (!workerId || !workerName) {
     return badRequestResponse('workerId and workerName query params are required');
   }
+  const excludeJobIds = (url.searchParams.get('excludeJobs') ?? '')
+    .split(',')
+    .map((id) => id.trim())
+    .filter((id) => id.length > 0);
 
   try {
     const [claimed, overrideResult] = await Promise.all([
-      claimNextSim(workerId, workerName),
+      claimNextSim(workerId, workerName, excludeJobIds),
       // Best-effort: if this fails we omit the header and the worker just
       // keeps its last-known override. Don't let it orphan a successful claim.
       workerStore.getMaxConcurrentOverride(workerId).catch(() => undefined),
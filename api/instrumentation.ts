@@ -3,6 +3,30 @@ import * as Sentry from '@sentry/nextjs';
 export async function register() {
   if (process.env.NEXT_RUNTIME === 'nodejs') {
     await import('./sentry.server.config');
+    // Fail fast on a bad WIN_LINE_PATTERN, HIGHLIGHT_KINDS, LOW_SIGNAL_THRESHOLD,
+    // PAYLOAD_TRANSFORM, ARTIFACT_STORAGE_CLASSES, PATH_LAYOUT, SIMULTANEOUS_WIN_SCORING,
+    // WINNER_CAPTURE, WINNER_ALIASES, AGGREGATORS or CONFIDENCE_THRESHOLDS instead of
+    // on the first log ingest
+    const { getWinLinePattern, getSimultaneousWinScoring, getWinnerCapture } = await import('./lib/condenser/turns');
+    getWinLinePattern();
+    getSimultaneousWinScoring();
+    getWinnerCapture();
+    const { getWinnerAliases } = await import('./lib/condenser/deck-match');
+    getWinnerAliases();
+    const { getLowSignalThreshold } = await import('./lib/condenser/low-signal');
+    getLowSignalThreshold();
+    const { getConfidenceThresholds } = await import('./lib/condenser/confidence');
+    getConfidenceThresholds();
+    const { getHighlightKinds } = await import('./lib/condenser/highlights');
+    getHighlightKinds();
+    const { resolvePayloadTransform } = await import('./lib/payload-transform');
+    resolvePayloadTransform();
+    const { resolveStorageClassPolicy } = await import('./lib/artifact-write');
+    resolveStorageClassPolicy();
+    const { resolvePathLayout } = await import('./lib/artifact-path');
+    resolvePathLayout();
+    const { resolveAggregators } = await import('./lib/aggregators');
+    resolveAggregators();
     // Previously spawned a long-lived setTimeout/setInterval here to sync
     // precons from Archidekt every 24 hours. That's the wrong shape for a
     // scale-to-zero serverless container: the sync re-runs on every cold
@@ -0,0 +1,188 @@
+/**
+ * Tests for the post-condense aggregator registry and the built-in
+ * aggregators.
+ *
+ * Run with: npx tsx lib/aggregators.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { CondensedGame } from './types';
+import { condenseGames, splitConcatenatedGames } from './condenser/index';
+import { tallyWins } from './condenser/win-tally';
+import {
+  ELO_START,
+  parseAggregators,
+  registerAggregator,
+  registeredAggregators,
+  resolveAggregators,
+  runAggregators,
+} from './aggregators';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser/condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const DECK_NAMES = ['Explorers of the Deep', 'Doran Big Butts', 'Enduring Enchantments', 'Veloci-RAMP-Tor'];
+
+const rawLog = fs.readFileSync(path.join(__dirname, 'condenser', 'fixtures', 'real-4game-log.txt'), 'utf-8');
+const games = condenseGames(splitConcatenatedGames(rawLog));
+
+/** Runs one registered aggregator by name and parses its output. */
+function aggregate<T>(name: string, input: CondensedGame[] = games): T {
+  const [artifact] = runAggregators(parseAggregators(name), input, DECK_NAMES);
+  return JSON.parse(artifact.content) as T;
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running aggregator tests...\n');
+
+  await test('built-ins are registered and nothing runs by default', () => {
+    assertEqual(registeredAggregators().join(','), 'win-rates,elo,matchup', 'registered');
+    assertEqual(resolveAggregators({}).length, 0, 'none when AGGREGATORS is unset');
+    assertEqual(resolveAggregators({ AGGREGATORS: 'all' }).length, 3, 'all');
+    assertEqual(
+      resolveAggregators({ AGGREGATORS: ' Matchup, elo, matchup ' }).map((a) => a.name).join(','),
+      'matchup,elo',
+      'subset in order, repeats dropped'
+    );
+  });
+
+  await test('win-rates: wins and games per deck from the real fixture', () => {
+    const rates = aggregate<Record<string, { games: number; wins: number; winRate: number }>>('win-rates');
+    assertEqual(rates['Enduring Enchantments'].wins, 2, 'Enduring wins');
+    assertEqual(rates['Explorers of the Deep'].wins, 1, 'Explorers wins');
+    assertEqual(rates['Doran Big Butts'].wins, 1, 'Doran wins');
+    assertEqual(rates['Veloci-RAMP-Tor'].wins, 0, 'Veloci-RAMP-Tor wins');
+    assertEqual(rates['Enduring Enchantments'].games, 4, 'every deck played every game');
+    assertEqual(rates['Enduring Enchantments'].winRate, 0.5, 'win rate');
+  });
+
+  await test('win-rates: agrees with the job results tally', () => {
+    const rates = aggregate<Record<string, { games: number; wins: number }>>('win-rates');
+    const tally = tallyWins(games, DECK_NAMES);
+    for (const name of DECK_NAMES) {
+      assertEqual(rates[name].wins, tally.wins[name], `${name} wins`);
+      assertEqual(rates[name].games, tally.gamesPlayed, `${name} games`);
+    }
+  });
+
+  await test('elo: winners gain what losers lose', () => {
+    const ratings = aggregate<Record<string, number>>('elo');
+    assert(ratings['Enduring Enchantments'] > ELO_START, 'two-time winner above the start');
+    assert(ratings['Veloci-RAMP-Tor'] < ELO_START, 'winless deck below the start');
+    const total = Object.values(ratings).reduce((sum, rating) => sum + rating, 0);
+    assert(Math.abs(total - ELO_START * DECK_NAMES.length) <= DECK_NAMES.length, `zero-sum up to rounding, got ${total}`);
+    const noWinner = games.map(({ winner: _winner, ...game }) => game);
+    assert(Object.values(aggregate<Record<string, number>>('elo', noWinner)).every((r) => r === ELO_START), 'draws move nothing');
+  });
+
+  await test('matchup: games together are symmetric, wins are the deck\'s', () => {
+    const matrix = aggregate<Record<string, Record<string, { games: number; wins: number }>>>('matchup');
+    assertEqual(matrix['Enduring Enchantments']['Veloci-RAMP-Tor'].games, 4, 'games together');
+    assertEqual(matrix['Veloci-RAMP-Tor']['Enduring Enchantments'].games, 4, 'symmetric');
+    assertEqual(matrix['Enduring Enchantments']['Veloci-RAMP-Tor'].wins, 2, 'Enduring won twice');
+    assertEqual(matrix['Veloci-RAMP-Tor']['Enduring Enchantments'].wins, 0, 'Veloci-RAMP-Tor never won');
+    assertEqual(matrix['Enduring Enchantments']['Enduring Enchantments'], undefined, 'no self matchup');
+  });
+
+  await test('registerAggregator: a custom aggregator can be enabled and produces agg-<name>.json', () => {
+    registerAggregator({ name: 'game-count', aggregate: (input) => ({ games: input.length }) });
+    const aggregators = resolveAggregators({ AGGREGATORS: 'win-rates,game-count' });
+    const artifacts = runAggregators(aggregators, games, DECK_NAMES);
+    assertEqual(artifacts.map((a) => a.name).join(','), 'agg-win-rates.json,agg-game-count.json', 'artifact names');
+    assertEqual(JSON.parse(artifacts[1].content).games, 4, 'custom output');
+  });
+
+  await test('runAggregators: a failing aggregator is skipped with a warning', () => {
+    registerAggregator({ name: 'broken', aggregate: () => { throw new Error('boom'); } });
+    const warnings: string[] = [];
+    const originalWarn = console.warn;
+    console.warn = (line: string) => warnings.push(line);
+    let artifacts;
+    try {
+      artifacts = runAggregators(parseAggregators('broken,elo'), games, DECK_NAMES);
+    } finally {
+      console.warn = originalWarn;
+    }
+    assertEqual(artifacts.map((a) => a.name).join(','), 'agg-elo.json', 'others still run');
+    assert(warnings.some((w) => w.includes('broken') && w.includes('boom')), 'failure logged');
+  });
+
+  await test('registry and AGGREGATORS reject bad names', () => {
+    const messageOf = (fn: () => unknown) => {
+      try { fn(); } catch (err) { return (err as Error).message; }
+      return '';
+    };
+    assert(messageOf(() => registerAggregator({ name: 'elo', aggregate: () => 0 })).includes('already registered'), 'duplicate');
+    assert(messageOf(() => registerAggregator({ name: 'Bad Name', aggregate: () => 0 })).startsWith('Invalid aggregator name'), 'bad name');
+    assert(messageOf(() => parseAggregators('elo,trueskill')).includes('unknown aggregator "trueskill"'), 'unknown');
+    assert(messageOf(() => resolveAggregators({ AGGREGATORS: 'nope' })).startsWith('Invalid AGGREGATORS'), 'from env');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
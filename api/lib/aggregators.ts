@@ -0,0 +1,198 @@
+/**
+ * Post-condense aggregators: named job-level statistics, each uploaded as
+ * its own `agg-<name>.json` artifact next to `condensed.json`.
+ *
+ * Deployments want different derived stats without editing ingestLogs.
+ * An aggregator is a name and a function of the job's condensed games;
+ * register one with registerAggregator and enable it by name with
+ * AGGREGATORS (comma-separated, or "all"). Nothing runs when AGGREGATORS
+ * is unset. Built in:
+ *
+ *   - win-rates: per deck, games played, wins and win rate, from the same
+ *                tally as JobResults.wins and gamesPlayed (condenser/win-tally.ts)
+ *   - elo:       per deck, an Elo rating replayed over the job's games in
+ *                order (each winner beats each other player in the game)
+ *   - matchup:   per deck and opponent, games played together and games
+ *                the deck won
+ *
+ * Aggregators see the counted games (low-signal games left out), every
+ * game rather than the LOG_SAMPLE_RATE sample. A shared simultaneous win
+ * (SIMULTANEOUS_WIN_SCORING=shared) counts for every winner. An aggregator
+ * that throws is skipped with a warning; the others still run.
+ */
+
+import type { CondensedGame } from './types';
+import { resolveWinnerName } from './condenser/deck-match';
+import { tallyWins } from './condenser/win-tally';
+
+/** A named job-level statistic. */
+export interface Aggregator {
+  /** Lowercase name; the artifact is `agg-<name>.json` */
+  name: string;
+  /** Builds the statistic; the result is stored as JSON. May throw. */
+  aggregate: (games: CondensedGame[], deckNames: string[]) => unknown;
+}
+
+/** Environment variable naming the aggregators to run. */
+export const AGGREGATORS_ENV = 'AGGREGATORS';
+
+/** Rating every deck starts the job with. */
+export const ELO_START = 1500;
+
+/** How far one game moves a rating. */
+export const ELO_K = 32;
+
+const AGGREGATOR_NAME = /^[a-z0-9][a-z0-9-]*$/;
+
+const registry = new Map<string, Aggregator>();
+
+/**
+ * Adds an aggregator to the registry.
+ *
+ * @throws If the name isn't lowercase letters, digits and dashes, or is
+ *   already registered
+ */
+export function registerAggregator(aggregator: Aggregator): void {
+  if (!AGGREGATOR_NAME.test(aggregator.name)) {
+    throw new Error(`Invalid aggregator name "${aggregator.name}" (expected lowercase letters, digits and dashes)`);
+  }
+  if (registry.has(aggregator.name)) {
+    throw new Error(`Aggregator "${aggregator.name}" is already registered`);
+  }
+  registry.set(aggregator.name, aggregator);
+}
+
+/** Registered aggregator names, in registration order. */
+export function registeredAggregators(): string[] {
+  return [...registry.keys()];
+}
+
+/** The artifact name for an aggregator's output. */
+export function aggregatorFilename(name: string): string {
+  return `agg-${name}.json`;
+}
+
+/** The decks (resolved against deckNames) that played a game. */
+function decksIn(game: CondensedGame, deckNames: string[]): string[] {
+  const players = Object.keys(game.perDeckTurns ?? {});
+  if (game.winner) players.push(...winnersOf(game));
+  return [...new Set(players.map((player) => resolveWinnerName(player, deckNames)))];
+}
+
+/** The players credited with a game's win. */
+function winnersOf(game: CondensedGame): string[] {
+  if (!game.winner) return [];
+  return game.simultaneousWinners ?? [game.winner];
+}
+
+function winRates(games: CondensedGame[], deckNames: string[]) {
+  const { gamesPlayed, wins } = tallyWins(games, deckNames);
+  return Object.fromEntries(
+    Object.entries(wins).map(([deck, won]) => [
+      deck,
+      { games: gamesPlayed, wins: won, winRate: gamesPlayed > 0 ? Math.round((won / gamesPlayed) * 1000) / 1000 : 0 },
+    ])
+  );
+}
+
+function elo(games: CondensedGame[], deckNames: string[]) {
+  const ratings: Record<string, number> = Object.fromEntries(deckNames.map((name) => [name, ELO_START]));
+  for (const game of games) {
+    const winners = new Set(winnersOf(game).map((winner) => resolveWinnerName(winner, deckNames)));
+    if (winners.size === 0) continue;
+    const decks = decksIn(game, deckNames);
+    for (const deck of decks) ratings[deck] ??= ELO_START;
+    // Every pairing in the game is scored against the ratings it started with
+    const before = { ...ratings };
+    for (const winner of winners) {
+      for (const loser of decks.filter((deck) => !winners.has(deck))) {
+        const expected = 1 / (1 + 10 ** ((before[loser] - before[winner]) / 400));
+        const delta = ELO_K * (1 - expected);
+        ratings[winner] += delta;
+        ratings[loser] -= delta;
+      }
+    }
+  }
+  return Object.fromEntries(Object.entries(ratings).map(([deck, rating]) => [deck, Math.round(rating)]));
+}
+
+function matchup(games: CondensedGame[], deckNames: string[]) {
+  const result: Record<string, Record<string, { games: number; wins: number }>> = {};
+  for (const game of games) {
+    const winners = new Set(winnersOf(game).map((winner) => resolveWinnerName(winner, deckNames)));
+    const decks = decksIn(game, deckNames);
+    for (const deck of decks) {
+      for (const opponent of decks) {
+        if (opponent === deck) continue;
+        result[deck] ??= {};
+        result[deck][opponent] ??= { games: 0, wins: 0 };
+        result[deck][opponent].games++;
+        if (winners.has(deck)) result[deck][opponent].wins++;
+      }
+    }
+  }
+  return result;
+}
+
+registerAggregator({ name: 'win-rates', aggregate: winRates });
+registerAggregator({ name: 'elo', aggregate: elo });
+registerAggregator({ name: 'matchup', aggregate: matchup });
+
+/**
+ * Looks up the aggregators named in an AGGREGATORS value.
+ *
+ * @param source - Comma-separated names, or "all"
+ * @throws Error when a name isn't registered
+ */
+export function parseAggregators(source: string): Aggregator[] {
+  const names = source
+    .split(',')
+    .map((part) => part.trim().toLowerCase())
+    .filter((name) => name.length > 0);
+  if (names.includes('all')) return [...registry.values()];
+  return [...new Set(names)].map((name) => {
+    const aggregator = registry.get(name);
+    if (!aggregator) {
+      throw new Error(
+        `Invalid ${AGGREGATORS_ENV}: unknown aggregator "${name}" (expected all, ${registeredAggregators().join(', ')})`
+      );
+    }
+    return aggregator;
+  });
+}
+
+/**
+ * Reads the enabled aggregators from the environment.
+ *
+ * @returns The aggregators; none when AGGREGATORS is unset
+ * @throws If AGGREGATORS names an unknown aggregator
+ */
+export function resolveAggregators(env: NodeJS.ProcessEnv = process.env): Aggregator[] {
+  const source = env[AGGREGATORS_ENV]?.trim();
+  return source ? parseAggregators(source) : [];
+}
+
+/**
+ * Runs aggregators over a job's games.
+ *
+ * @returns One artifact (name and JSON contents) per aggregator that
+ *          succeeded, in order
+ */
+export function runAggregators(
+  aggregators: Aggregator[],
+  games: CondensedGame[],
+  deckNames: string[]
+): Array<{ name: string; content: string }> {
+  const artifacts: Array<{ name: string; content: string }> = [];
+  for (const aggregator of aggregators) {
+    try {
+      const output = aggregator.aggregate(games, deckNames);
+      artifacts.push({ name: aggregatorFilename(aggregator.name), content: JSON.stringify(output ?? null, null, 2) });
+    } catch (err) {
+      console.warn(
+        `Aggregator ${aggregator.name} failed, skipping: ${err instanceof Error ? err.message : String(err)}`
+      );
+    }
+  }
+  return artifacts;
+}
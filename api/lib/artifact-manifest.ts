@@ -0,0 +1,89 @@
+/**
+ * Artifact manifest — a `manifest.json` written after all of a job's log
+ * artifacts, listing exactly what was stored.
+ *
+ * Consumers read the manifest instead of guessing which artifacts exist.
+ * It is written LAST, so its presence means the job's artifacts are fully
+ * written.
+ */
+
+import { createHash } from 'crypto';
+
+export const ARTIFACT_MANIFEST_FILENAME = 'manifest.json';
+
+/** Bump when the manifest shape changes. */
+export const ARTIFACT_MANIFEST_SCHEMA_VERSION = 1;
+
+/** A single stored artifact, as returned by the upload/write call. */
+export interface UploadedArtifact {
+  /** Path relative to the job's artifact root (e.g. 'raw/game_001.txt') */
+  name: string;
+  /** Where the object lives (gs://... in GCP mode, file://... locally) */
+  uri: string;
+  contentType: string;
+  /** Size in bytes */
+  size: number;
+  /** Hex-encoded SHA-256 of the contents */
+  sha256: string;
+}
+
+export interface ArtifactManifest {
+  schemaVersion: number;
+  jobId: string;
+  createdAt: string;
+  artifacts: UploadedArtifact[];
+}
+
+/**
+ * Content type for an artifact, by file extension.
+ */
+export function artifactContentType(filename: string): string {
+  return filename.endsWith('.json')
+    ? 'application/json'
+    : filename.endsWith('.txt')
+    ? 'text/plain'
+    : filename.endsWith('.md')
+    ? 'text/markdown'
+    : 'application/octet-stream';
+}
+
+/**
+ * Describes stored artifact contents for the manifest.
+ */
+export function describeArtifact(name: string, uri: string, data: string | Buffer): UploadedArtifact {
+  const buffer = typeof data === 'string' ? Buffer.from(data, 'utf-8') : data;
+  return {
+    name,
+    uri,
+    contentType: artifactContentType(name),
+    size: buffer.length,
+    sha256: createHash('sha256').update(buffer).digest('hex'),
+  };
+}
+
+/**
+ * Builds the manifest from the upload results, in upload order.
+ */
+export function buildArtifactManifest(jobId: string, artifacts: UploadedArtifact[]): ArtifactManifest {
+  return {
+    schemaVersion: ARTIFACT_MANIFEST_SCHEMA_VERSION,
+    jobId,
+    createdAt: new Date().toISOString(),
+    artifacts,
+  };
+}
+
+/**
+ * Returns the manifest with one artifact's entry replaced after it was
+ * rewritten, keeping its position. An artifact the manifest didn't list is
+ * appended.
+ */
+export function replaceManifestArtifact(manifest: ArtifactManifest, artifact: UploadedArtifact): ArtifactManifest {
+  const listed = manifest.artifacts.some((a) => a.name === artifact.name);
+  return {
+    ...manifest,
+    artifacts: listed
+      ? manifest.artifacts.map((a) => (a.name === artifact.name ? artifact : a))
+      : [...manifest.artifacts, artifact],
+  };
+}
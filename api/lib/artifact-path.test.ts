@@ -0,0 +1,143 @@
+/**
+ * Tests for artifact-path.ts — flat and date-partitioned artifact paths.
+ *
+ * Run with: npx tsx lib/artifact-path.test.ts
+ */
+
+import {
+  createJobPrefixResolver,
+  jobArtifactPrefix,
+  resolvePathLayout,
+  type JobPrefixResolver,
+} from './artifact-path';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+const CREATED_AT = new Date('2025-03-07T23:30:00Z');
+
+/** A fake bucket: writes and reads go through the same prefix resolver, as in gcs-storage.ts. */
+function fakeBucket(jobPrefix: JobPrefixResolver) {
+  const objects = new Map<string, string>();
+  return {
+    objects,
+    async upload(jobId: string, filename: string, data: string) {
+      objects.set(`${await jobPrefix(jobId)}${filename}`, data);
+    },
+    async read(jobId: string, filename: string) {
+      return objects.get(`${await jobPrefix(jobId)}${filename}`);
+    },
+  };
+}
+
+async function runTests() {
+  await test('jobArtifactPrefix: flat and dated layouts', async () => {
+    assertEqual(jobArtifactPrefix('job-1', 'flat'), 'jobs/job-1/', 'flat');
+    assertEqual(jobArtifactPrefix('job-1', 'flat', CREATED_AT), 'jobs/job-1/', 'flat ignores the date');
+    assertEqual(jobArtifactPrefix('job-1', 'dated', CREATED_AT), 'jobs/2025/03/07/job-1/', 'dated (UTC)');
+  });
+
+  await test('dated layout: uploads and reads use the job creation date', async () => {
+    const lookups: string[] = [];
+    const bucket = fakeBucket(
+      createJobPrefixResolver('dated', async (jobId) => {
+        lookups.push(jobId);
+        return CREATED_AT;
+      })
+    );
+    await bucket.upload('job-1', 'condensed.json', '[]');
+    assertEqual([...bucket.objects.keys()][0], 'jobs/2025/03/07/job-1/condensed.json', 'upload path');
+    assertEqual(await bucket.read('job-1', 'condensed.json'), '[]', 'read resolves the same path');
+    assertEqual(lookups.length, 1, 'job date looked up once');
+
+    // A new process (fresh resolver) finds the artifact from the job's date again
+    const restarted = fakeBucket(createJobPrefixResolver('dated', async () => CREATED_AT));
+    for (const [key, value] of bucket.objects) restarted.objects.set(key, value);
+    assertEqual(await restarted.read('job-1', 'condensed.json'), '[]', 'read after restart');
+  });
+
+  await test('dated layout: a job that can\'t be found uses the current date, consistently', async () => {
+    let clock = new Date('2025-06-01T10:00:00Z');
+    const jobPrefix = createJobPrefixResolver('dated', async () => undefined, () => clock);
+    assertEqual(await jobPrefix('job-2'), 'jobs/2025/06/01/job-2/', 'now');
+    clock = new Date('2025-06-02T10:00:00Z');
+    assertEqual(await jobPrefix('job-2'), 'jobs/2025/06/01/job-2/', 'cached across midnight');
+    assertEqual(await jobPrefix('job-3', CREATED_AT), 'jobs/2025/03/07/job-3/', 'known date skips the lookup');
+  });
+
+  await test('flat layout: never looks up the job', async () => {
+    const bucket = fakeBucket(
+      createJobPrefixResolver('flat', async () => {
+        throw new Error('lookup called');
+      })
+    );
+    await bucket.upload('job-1', 'raw/game_001.txt', 'log');
+    assertEqual([...bucket.objects.keys()][0], 'jobs/job-1/raw/game_001.txt', 'upload path');
+    assertEqual(await bucket.read('job-1', 'raw/game_001.txt'), 'log', 'read');
+  });
+
+  await test('resolvePathLayout: flat by default, rejects unknown layouts', async () => {
+    assertEqual(resolvePathLayout({}), 'flat', 'unset');
+    assertEqual(resolvePathLayout({ PATH_LAYOUT: ' Dated ' }), 'dated', 'trimmed and lower-cased');
+    let message = '';
+    try {
+      resolvePathLayout({ PATH_LAYOUT: 'hourly' });
+    } catch (error) {
+      message = error instanceof Error ? error.message : String(error);
+    }
+    assert(message.startsWith('Invalid PATH_LAYOUT'), 'unknown layout rejected');
+  });
+
+  // ---------------------------------------------------------------------------
+  // Summary
+  // ---------------------------------------------------------------------------
+
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log('\n--- Test Summary ---');
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
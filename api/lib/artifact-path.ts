@@ -0,0 +1,95 @@
+/**
+ * Object path layout for job artifacts.
+ *
+ * PATH_LAYOUT picks where a job's artifacts live in the bucket:
+ *
+ *   - flat (default): jobs/<id>/<file>
+ *   - dated:          jobs/YYYY/MM/DD/<id>/<file>
+ *
+ * The dated layout partitions the bucket by the job's creation date (UTC),
+ * so lifecycle rules and queries can work on whole days ("delete
+ * jobs/2025/01/ after 90 days"). The date comes from the job's `createdAt`,
+ * or the current date when the job can't be found; either way it's cached
+ * per job, so a job's uploads and later reads agree within a process.
+ *
+ * Switching layouts doesn't move existing artifacts: jobs written under the
+ * other layout are no longer found.
+ *
+ * Lives in its own module so it can be unit-tested without a real
+ * @google-cloud/storage client.
+ */
+import { lruEvictIfFull, lruTouch } from './lru';
+
+/** Environment variable selecting the layout. */
+export const PATH_LAYOUT_ENV = 'PATH_LAYOUT';
+
+export const PATH_LAYOUTS = ['flat', 'dated'] as const;
+
+export type PathLayout = (typeof PATH_LAYOUTS)[number];
+
+/** Job dates kept in memory by a prefix resolver. */
+export const JOB_DATE_CACHE_SIZE = 1000;
+
+/**
+ * Reads the path layout from the environment.
+ *
+ * @returns The layout; 'flat' when unset
+ * @throws If PATH_LAYOUT isn't a known layout
+ */
+export function resolvePathLayout(env: NodeJS.ProcessEnv = process.env): PathLayout {
+  const source = env[PATH_LAYOUT_ENV]?.trim().toLowerCase();
+  if (!source) return 'flat';
+  if (!(PATH_LAYOUTS as readonly string[]).includes(source)) {
+    throw new Error(`Invalid ${PATH_LAYOUT_ENV}: unknown layout "${source}" (expected ${PATH_LAYOUTS.join(', ')})`);
+  }
+  return source as PathLayout;
+}
+
+/**
+ * Object path prefix for a job's artifacts, ending in "/".
+ *
+ * @param jobId - The job ID
+ * @param layout - The path layout
+ * @param date - The job's date; required for the dated layout
+ *
+ * @example
+ * jobArtifactPrefix('abc', 'dated', new Date('2025-03-07T12:00:00Z')) // "jobs/2025/03/07/abc/"
+ */
+export function jobArtifactPrefix(jobId: string, layout: PathLayout, date?: Date): string {
+  if (layout === 'flat' || !date) return `jobs/${jobId}/`;
+  const yyyy = String(date.getUTCFullYear());
+  const mm = String(date.getUTCMonth() + 1).padStart(2, '0');
+  const dd = String(date.getUTCDate()).padStart(2, '0');
+  return `jobs/${yyyy}/${mm}/${dd}/${jobId}/`;
+}
+
+/** Resolves a job's artifact prefix; `knownDate` skips the lookup. */
+export type JobPrefixResolver = (jobId: string, knownDate?: Date) => Promise<string>;
+
+/**
+ * Builds a resolver for a layout.
+ *
+ * @param layout - The path layout
+ * @param lookupDate - The job's creation date, or undefined when the job
+ *   can't be found. Not called for the flat layout.
+ * @param now - Clock for jobs without a date
+ */
+export function createJobPrefixResolver(
+  layout: PathLayout,
+  lookupDate: (jobId: string) => Promise<Date | undefined>,
+  now: () => Date = () => new Date()
+): JobPrefixResolver {
+  const dates = new Map<string, Date>();
+  return async (jobId, knownDate) => {
+    if (layout === 'flat') return jobArtifactPrefix(jobId, layout);
+    let date = knownDate ?? lruTouch(dates, jobId);
+    if (!date) {
+      date = (await lookupDate(jobId)) ?? now();
+    }
+    if (!dates.has(jobId)) {
+      lruEvictIfFull(dates, JOB_DATE_CACHE_SIZE);
+      dates.set(jobId, date);
+    }
+    return jobArtifactPrefix(jobId, layout, date);
+  };
+}
@@ -0,0 +1,127 @@
+/**
+ * Tests for artifact-read.ts — retrying artifact reads and the not-found error.
+ *
+ * Run with: npx tsx lib/artifact-read.test.ts
+ */
+
+import { readArtifact, ArtifactNotFoundError, isArtifactNotFound, type DownloadableFile } from './artifact-read';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function codeError(message: string, code: number): Error {
+  return Object.assign(new Error(message), { code });
+}
+
+/** A fake file that fails with each queued error in turn, then returns `contents`. */
+function fakeFile(contents: string, errors: Error[] = []): DownloadableFile & { calls: number } {
+  const file = {
+    calls: 0,
+    async download(): Promise<[Buffer]> {
+      file.calls++;
+      const error = errors.shift();
+      if (error) throw error;
+      return [Buffer.from(contents, 'utf-8')];
+    },
+  };
+  return file;
+}
+
+const NO_DELAY = { maxAttempts: 3, delayMs: 0 };
+
+async function expectRejection(promise: Promise<unknown>): Promise<unknown> {
+  try {
+    await promise;
+  } catch (error) {
+    return error;
+  }
+  throw new Error('expected a rejection');
+}
+
+async function runTests() {
+  await test('reads contents on first try', async () => {
+    const file = fakeFile('{"ok":true}');
+    assert((await readArtifact(file, 'jobs/j1/condensed.json', NO_DELAY)) === '{"ok":true}', 'contents');
+    assert(file.calls === 1, 'one download');
+  });
+
+  await test('empty artifact is returned as an empty string, not not-found', async () => {
+    assert((await readArtifact(fakeFile(''), 'jobs/j1/summary.md', NO_DELAY)) === '', 'empty contents');
+  });
+
+  await test('404 throws ArtifactNotFoundError without retrying', async () => {
+    const file = fakeFile('', [codeError('No such object', 404)]);
+    const error = await expectRejection(readArtifact(file, 'jobs/j1/missing.json', NO_DELAY));
+    assert(isArtifactNotFound(error), 'should be ArtifactNotFoundError');
+    assert((error as ArtifactNotFoundError).objectPath === 'jobs/j1/missing.json', 'objectPath');
+    assert(file.calls === 1, 'no retry on 404');
+  });
+
+  await test('transient error then success returns contents', async () => {
+    const file = fakeFile('raw log', [codeError('Service Unavailable', 503), new Error('socket hang up')]);
+    assert((await readArtifact(file, 'jobs/j1/raw/game_001.txt', NO_DELAY)) === 'raw log', 'contents');
+    assert(file.calls === 3, 'two retries');
+  });
+
+  await test('non-transient error is rethrown without retrying', async () => {
+    const file = fakeFile('', [codeError('Forbidden', 403)]);
+    const error = await expectRejection(readArtifact(file, 'jobs/j1/condensed.json', NO_DELAY));
+    assert(!isArtifactNotFound(error), 'not a not-found error');
+    assert((error as Error).message === 'Forbidden', 'original error');
+    assert(file.calls === 1, 'no retry on 403');
+  });
+
+  await test('transient errors past maxAttempts rethrow the last error', async () => {
+    const file = fakeFile('', [codeError('a', 500), codeError('b', 502), codeError('c', 503)]);
+    const error = await expectRejection(readArtifact(file, 'jobs/j1/condensed.json', NO_DELAY));
+    assert((error as Error).message === 'c', 'last error');
+    assert(file.calls === 3, 'three attempts');
+  });
+
+  // ---------------------------------------------------------------------------
+  // Summary
+  // ---------------------------------------------------------------------------
+
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log('\n--- Test Summary ---');
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
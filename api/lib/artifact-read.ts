@@ -0,0 +1,62 @@
+/**
+ * Retry-aware artifact reads with an explicit not-found error.
+ *
+ * Lives in its own module so it can be unit-tested with a fake file instead
+ * of a real @google-cloud/storage client.
+ */
+import { isRetryableGcsError } from './gcs-retry';
+import { withRetry, type RetryOptions } from './retry';
+
+/**
+ * Thrown when an artifact object does not exist, so callers can tell an
+ * absent artifact from an empty one.
+ */
+export class ArtifactNotFoundError extends Error {
+  readonly objectPath: string;
+
+  constructor(objectPath: string) {
+    super(`Artifact not found: ${objectPath}`);
+    this.objectPath = objectPath;
+    this.name = 'ArtifactNotFoundError';
+  }
+}
+
+export function isArtifactNotFound(error: unknown): error is ArtifactNotFoundError {
+  return error instanceof ArtifactNotFoundError;
+}
+
+/** The part of a GCS File that readArtifact needs. */
+export interface DownloadableFile {
+  download(): Promise<[Buffer]>;
+}
+
+export const ARTIFACT_READ_RETRY: RetryOptions = { maxAttempts: 3, delayMs: 500, backoffMultiplier: 2 };
+
+function isNotFoundError(error: unknown): boolean {
+  return (error as { code?: number } | null)?.code === 404;
+}
+
+/**
+ * Downloads an artifact as UTF-8, retrying transient (5xx/network) errors.
+ *
+ * @throws ArtifactNotFoundError if the object does not exist
+ * @throws the last error if a non-transient error occurs or retries run out
+ */
+export async function readArtifact(
+  file: DownloadableFile,
+  objectPath: string,
+  options: RetryOptions = ARTIFACT_READ_RETRY
+): Promise<string> {
+  try {
+    const [contents] = await withRetry(
+      () => file.download(),
+      options,
+      `GCS read ${objectPath}`,
+      isRetryableGcsError
+    );
+    return contents.toString('utf-8');
+  } catch (error) {
+    if (isNotFoundError(error)) throw new ArtifactNotFoundError(objectPath);
+    throw error;
+  }
+}
@@ -0,0 +1,174 @@
+/**
+ * Tests for artifact-schema.ts — condensed and results payload validation.
+ *
+ * Run with: npx tsx lib/artifact-schema.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { condenseGames, splitConcatenatedGames } from './condenser/index';
+import { ArtifactSchemaError, validateCondensed, validateJobResults } from './artifact-schema';
+import type { JobResults } from './types';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+function test(name: string, fn: () => void) {
+  try {
+    fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+/** Runs fn and returns the ArtifactSchemaError it throws. */
+function expectSchemaError(fn: () => unknown): ArtifactSchemaError {
+  try {
+    fn();
+  } catch (error) {
+    assert(error instanceof ArtifactSchemaError, `expected ArtifactSchemaError, got ${String(error)}`);
+    return error as ArtifactSchemaError;
+  }
+  throw new Error('expected a schema violation');
+}
+
+const rawLog = fs.readFileSync(path.join(__dirname, 'condenser', 'fixtures', 'real-4game-log.txt'), 'utf-8');
+const condensed = condenseGames(splitConcatenatedGames(rawLog));
+
+function validResults(): JobResults {
+  return {
+    wins: { Alpha: 3, Beta: 1 },
+    avgWinTurn: { Alpha: 8.3, Beta: 11 },
+    gamesPlayed: 4,
+    confidence: 'low (<20 games)',
+    explosiveness: { Alpha: 62, Beta: 30 },
+    turnCountPercentiles: { p50: 9, p90: 11, p99: 11, min: 7, max: 11 },
+    representativeGames: { medianWin: 1, fastestWin: 0 },
+    comebackWins: { Alpha: 0, Beta: 1 },
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Condensed
+// ---------------------------------------------------------------------------
+
+test('real condensed output passes, serialized or parsed', () => {
+  assert(condensed.length > 0, 'fixture should condense to games');
+  assertEqual(validateCondensed(JSON.stringify(condensed)).length, condensed.length, 'serialized');
+  assertEqual(validateCondensed(condensed), condensed, 'parsed input is returned unchanged');
+});
+
+test('unknown extra keys are allowed', () => {
+  const games = JSON.parse(JSON.stringify(condensed));
+  games[0].someFutureField = { nested: true };
+  validateCondensed(games);
+});
+
+test('invalid JSON is a schema error', () => {
+  const err = expectSchemaError(() => validateCondensed('[{"keptEvents": '));
+  assertEqual(err.artifact, 'condensed.json', 'artifact');
+  assert(err.issues[0].startsWith('invalid JSON'), `issue: ${err.issues[0]}`);
+});
+
+test('non-array payload is rejected', () => {
+  expectSchemaError(() => validateCondensed({ games: condensed }));
+});
+
+test('missing required field is reported with its path', () => {
+  const games = JSON.parse(JSON.stringify(condensed));
+  delete games[1].turnCount;
+  const err = expectSchemaError(() => validateCondensed(games));
+  assert(err.issues.some((i) => i.startsWith('1.turnCount:')), `issues: ${err.issues.join(' | ')}`);
+});
+
+test('wrong field types are rejected', () => {
+  const games = JSON.parse(JSON.stringify(condensed));
+  games[0].turnCount = '12';
+  games[0].winner = 42;
+  games[0].lockStallDetected = 'yes';
+  const err = expectSchemaError(() => validateCondensed(games));
+  assertEqual(err.issues.length, 3, 'one issue per bad field');
+  assert(err.message.includes('0.turnCount'), err.message);
+});
+
+test('unknown event type is rejected', () => {
+  const games = JSON.parse(JSON.stringify(condensed));
+  games[0].keptEvents.push({ type: 'teleport', line: 'x' });
+  const err = expectSchemaError(() => validateCondensed(games));
+  assert(err.issues.some((i) => i.includes('Unknown event type')), `issues: ${err.issues.join(' | ')}`);
+});
+
+test('negative counts are rejected', () => {
+  const games = JSON.parse(JSON.stringify(condensed));
+  games[0].millCount = -1;
+  expectSchemaError(() => validateCondensed(games));
+});
+
+// ---------------------------------------------------------------------------
+// Results
+// ---------------------------------------------------------------------------
+
+test('valid results pass, serialized or parsed', () => {
+  validateJobResults(validResults());
+  validateJobResults(JSON.stringify(validResults()));
+  validateJobResults({ wins: {}, avgWinTurn: {}, gamesPlayed: 0 });
+});
+
+test('results missing gamesPlayed are rejected', () => {
+  const bad: Partial<JobResults> = validResults();
+  delete bad.gamesPlayed;
+  const err = expectSchemaError(() => validateJobResults(bad));
+  assertEqual(err.artifact, 'results', 'artifact');
+  assert(err.issues.some((i) => i.startsWith('gamesPlayed:')), `issues: ${err.issues.join(' | ')}`);
+});
+
+test('corrupted results fields are rejected', () => {
+  const bad = { ...validResults(), wins: { Alpha: 1.5 }, explosiveness: { Alpha: 140 } };
+  const err = expectSchemaError(() => validateJobResults(bad));
+  assertEqual(err.issues.length, 2, 'both violations reported');
+});
+
+test('non-integer representative game index is rejected', () => {
+  const bad = { ...validResults(), representativeGames: { medianWin: 'first' } };
+  expectSchemaError(() => validateJobResults(bad));
+});
+
+// ---------------------------------------------------------------------------
+// Summary
+// ---------------------------------------------------------------------------
+
+const passed = results.filter((r) => r.passed).length;
+const failed = results.filter((r) => !r.passed).length;
+console.log('\n--- Test Summary ---');
+console.log(`Passed: ${passed}/${results.length}`);
+console.log(`Failed: ${failed}/${results.length}`);
+
+if (failed > 0) {
+  console.log('\nFailed tests:');
+  results
+    .filter((r) => !r.passed)
+    .forEach((r) => {
+      console.log(`  - ${r.name}: ${r.error}`);
+    });
+  process.exit(1);
+}
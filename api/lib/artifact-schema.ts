@@ -0,0 +1,196 @@
+/**
+ * Schema checks for the JSON artifacts a job produces.
+ *
+ * `condensed.json` (and `analyze-payload.json`, its reshaped copy) feeds the
+ * AI bracket analysis and `results` is what the frontend renders, so both
+ * are checked before they're stored: a condenser
+ * change that emits the wrong shape fails the job loudly instead of
+ * producing output that breaks consumers later.
+ *
+ * Unknown keys are allowed, so older readers keep working when fields are
+ * added; known keys must have the right type.
+ */
+import { z } from 'zod';
+import { DEFAULT_CLASSIFICATION_PRIORITY } from './condenser/classify';
+import type { CondensedGame, JobResults } from './types';
+
+const EVENT_TYPES: ReadonlySet<string> = new Set(DEFAULT_CLASSIFICATION_PRIORITY);
+
+const count = z.number().int().nonnegative();
+const round = z.number().int().positive();
+
+export const gameEventSchema = z.object({
+  type: z.string().refine((t) => EVENT_TYPES.has(t), { message: 'Unknown event type' }),
+  line: z.string(),
+  turn: round.optional(),
+  player: z.string().optional(),
+  repeat: z.number().int().min(2).optional(),
+  playerColors: z.array(z.string()).optional(),
+  lineNo: round.optional(),
+  goaded: z.boolean().optional(),
+});
+
+const killInfoSchema = z.object({
+  victim: z.string(),
+  source: z.string(),
+  type: z.enum(['combat', 'spell', 'ability']),
+  player: z.string().optional(),
+});
+
+export const condensedGameSchema = z.object({
+  gameId: z.string().optional(),
+  keptEvents: z.array(gameEventSchema),
+  omittedEvents: count.optional(),
+  manaPerTurn: z.record(z.string(), z.object({ manaEvents: count })),
+  cardsDrawnPerTurn: z.record(z.string(), count),
+  turnCount: count,
+  winner: z.string().optional(),
+  winReason: z.enum(['last_standing']).optional(),
+  simultaneousWin: z.boolean().optional(),
+  simultaneousWinners: z.array(z.string()).optional(),
+  winningTurn: round.optional(),
+  perDeckTurns: z.record(z.string(), z.object({ turnsTaken: count, lastSegment: count })).optional(),
+  aiProfiles: z.record(z.string(), z.string()).optional(),
+  mulliganDetails: z.record(z.string(), z.object({
+    mulligans: count,
+    handSizes: z.array(count),
+    keptHandSize: count,
+  })).optional(),
+  lockEffectDetected: z.boolean().optional(),
+  lockStallDetected: z.boolean().optional(),
+  lockStallStartRound: round.optional(),
+  millCount: count.optional(),
+  selfMillCount: count.optional(),
+  opponentMillCount: count.optional(),
+  libraryManipCount: count.optional(),
+  freeCastCount: count.optional(),
+  altCostCastCount: count.optional(),
+  ritualCount: count.optional(),
+  cloneCount: count.optional(),
+  goadCount: count.optional(),
+  protectionCount: count.optional(),
+  landDestructionCount: count.optional(),
+  massLandDestructionCount: count.optional(),
+  killingBlow: killInfoSchema.optional(),
+  lifeLossPerTurn: z.record(z.string(), z.number()).optional(),
+  fastClock: z.boolean().optional(),
+  selfLifePaymentsPerTurn: z.record(z.string(), count).optional(),
+  selfLifePaymentsByCategory: z.record(z.string(), count).optional(),
+  firstBloodTurn: count.optional(),
+  creatureDeathsPerTurn: z.record(z.string(), count).optional(),
+  castsPerTurn: z.record(z.string(), z.array(z.object({
+    player: z.string().optional(),
+    casts: count,
+    manaAdded: count,
+  }))).optional(),
+  castCmcHistogram: z.record(z.string(), count).optional(),
+  bigTurns: z.array(round).optional(),
+  offTurnActions: z.record(z.string(), count).optional(),
+  interactionReceived: z.record(z.string(), count).optional(),
+  attacksReceived: z.record(z.string(), count).optional(),
+  archenemy: z.string().optional(),
+  cardsDrawnByPlayer: z.record(z.string(), count).optional(),
+  symmetricDrawEvents: count.optional(),
+  earlyDamageDealt: z.record(z.string(), count).optional(),
+  extraTurns: z.record(z.string(), count).optional(),
+  extraTurnCombo: z.boolean().optional(),
+  protectionPerTurn: z.record(z.string(), count).optional(),
+  protectedCombo: z.boolean().optional(),
+  recurringEngines: z.array(z.string()).optional(),
+  cardTypeHints: z.record(z.string(), z.array(z.string())).optional(),
+  lowSignal: z.boolean().optional(),
+});
+
+export const condensedArtifactSchema = z.array(condensedGameSchema);
+
+const gameIndex = count.optional();
+
+export const jobResultsSchema = z.object({
+  wins: z.record(z.string(), count),
+  avgWinTurn: z.record(z.string(), z.number().nonnegative()),
+  gamesPlayed: count,
+  lowSignalGames: count.optional(),
+  confidence: z.string().optional(),
+  explosiveness: z.record(z.string(), z.number().min(0).max(100)).optional(),
+  ritualsPerGame: z.record(z.string(), z.number().nonnegative()).optional(),
+  winReasonCounts: z.record(z.string(), count).optional(),
+  avgFirstBloodTurn: z.number().nonnegative().optional(),
+  turnCountPercentiles: z.object({
+    p50: z.number(),
+    p90: z.number(),
+    p99: z.number(),
+    min: z.number(),
+    max: z.number(),
+  }).optional(),
+  tempoCurve: z.array(z.object({
+    round,
+    games: count,
+    mana: z.number().nonnegative(),
+    spells: z.number().nonnegative(),
+    cardsDrawn: z.number().nonnegative(),
+    tempo: z.number().min(0).max(1),
+  })).optional(),
+  representativeGames: z.object({
+    medianWin: gameIndex,
+    fastestWin: gameIndex,
+    stalled: gameIndex,
+    draw: gameIndex,
+  }).optional(),
+  comebackWins: z.record(z.string(), count).optional(),
+  aiProfiles: z.record(z.string(), z.array(z.string())).optional(),
+  archenemyCounts: z.record(z.string(), count).optional(),
+  aggressionIndex: z.record(z.string(), z.number().min(0).max(100)).optional(),
+  avgKeptHandSize: z.record(z.string(), z.number().nonnegative()).optional(),
+  deadLetterCount: count.optional(),
+  duplicateGames: count.optional(),
+});
+
+/** Thrown when an artifact doesn't match its schema. */
+export class ArtifactSchemaError extends Error {
+  readonly artifact: string;
+  readonly issues: string[];
+
+  constructor(artifact: string, issues: string[]) {
+    super(`${artifact} failed schema validation: ${issues.join('; ')}`);
+    this.name = 'ArtifactSchemaError';
+    this.artifact = artifact;
+    this.issues = issues;
+  }
+}
+
+function validate(artifact: string, schema: z.ZodTypeAny, data: string | unknown): unknown {
+  let value = data;
+  if (typeof data === 'string') {
+    try {
+      value = JSON.parse(data);
+    } catch (err) {
+      throw new ArtifactSchemaError(artifact, [`invalid JSON (${err instanceof Error ? err.message : String(err)})`]);
+    }
+  }
+  const result = schema.safeParse(value);
+  if (result.success) return value;
+  const issues = result.error.issues.map(
+    (i) => (i.path.length > 0 ? `${i.path.join('.')}: ` : '') + i.message
+  );
+  throw new ArtifactSchemaError(artifact, issues);
+}
+
+/**
+ * Checks a condensed artifact (serialized or parsed).
+ *
+ * @returns The games, unchanged
+ * @throws ArtifactSchemaError listing every violation
+ */
+export function validateCondensed(data: string | unknown): CondensedGame[] {
+  return validate('condensed.json', condensedArtifactSchema, data) as CondensedGame[];
+}
+
+/**
+ * Checks aggregated job results (serialized or parsed).
+ *
+ * @returns The results, unchanged
+ * @throws ArtifactSchemaError listing every violation
+ */
+export function validateJobResults(data: string | unknown): JobResults {
+  return validate('results', jobResultsSchema, data) as JobResults;
+}
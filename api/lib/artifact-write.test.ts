@@ -0,0 +1,175 @@
+/**
+ * Tests for artifact-write.ts — merged object metadata on written artifacts.
+ *
+ * Run with: npx tsx lib/artifact-write.test.ts
+ */
+
+import {
+  artifactKind,
+  artifactMetadataFromEnv,
+  buildArtifactMetadata,
+  parseStorageClassPolicy,
+  resolveStorageClassPolicy,
+  writeArtifact,
+  type SavableFile,
+} from './artifact-write';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+interface SavedObject {
+  data: string | Buffer;
+  contentType: string;
+  metadata: Record<string, string>;
+}
+
+/** A fake file that records what was saved, failing with each queued error first. */
+function fakeFile(errors: Error[] = []): SavableFile & { saved?: SavedObject; calls: number } {
+  const file: SavableFile & { saved?: SavedObject; calls: number } = {
+    calls: 0,
+    async save(data, options) {
+      file.calls++;
+      const error = errors.shift();
+      if (error) throw error;
+      file.saved = { data, ...options };
+    },
+  };
+  return file;
+}
+
+const NO_DELAY = { maxAttempts: 3, delayMs: 0 };
+
+async function runTests() {
+  await test('written object carries jobId merged with extra metadata', async () => {
+    const file = fakeFile();
+    await writeArtifact(file, 'condensed.json', '[]', 'job-1', { simVersion: '1.6.65', env: 'staging' }, NO_DELAY);
+    assertEqual(file.saved?.contentType, 'application/json', 'content type');
+    assertEqual(JSON.stringify(file.saved?.metadata), JSON.stringify({ simVersion: '1.6.65', env: 'staging', jobId: 'job-1' }), 'metadata');
+  });
+
+  await test('extra metadata cannot overwrite jobId', async () => {
+    const file = fakeFile();
+    await writeArtifact(file, 'raw/game_001.txt', 'log', 'job-1', { jobId: 'other-job', gitSha: 'abc123' }, NO_DELAY);
+    assertEqual(file.saved?.metadata.jobId, 'job-1', 'jobId kept');
+    assertEqual(file.saved?.metadata.gitSha, 'abc123', 'other keys kept');
+    assertEqual(buildArtifactMetadata('job-2').jobId, 'job-2', 'no extra metadata');
+  });
+
+  await test('retries a transient error and saves the same metadata', async () => {
+    const file = fakeFile([Object.assign(new Error('Service Unavailable'), { code: 503 })]);
+    await writeArtifact(file, 'summary.md', '# Summary', 'job-1', { env: 'prod' }, NO_DELAY);
+    assertEqual(file.calls, 2, 'one retry');
+    assertEqual(file.saved?.metadata.env, 'prod', 'metadata on the retried save');
+  });
+
+  await test('artifactMetadataFromEnv reads SIM_VERSION, ENV and GIT_SHA', async () => {
+    const metadata = artifactMetadataFromEnv({ SIM_VERSION: '1.6.65', ENV: 'prod', GIT_SHA: ' ', OTHER: 'x' });
+    assertEqual(JSON.stringify(metadata), JSON.stringify({ simVersion: '1.6.65', env: 'prod' }), 'env metadata');
+    assert(Object.keys(artifactMetadataFromEnv({})).length === 0, 'nothing set');
+  });
+
+  await test('sets the policy storage class on raw logs', async () => {
+    const policy = parseStorageClassPolicy('raw=COLDLINE,json=standard');
+    const raw = fakeFile();
+    await writeArtifact(raw, 'raw/game_001.txt', 'log', 'job-1', {}, NO_DELAY, policy);
+    assertEqual(raw.saved?.metadata.storageClass, 'COLDLINE', 'raw log class');
+    const json = fakeFile();
+    await writeArtifact(json, 'condensed.json', '[]', 'job-1', {}, NO_DELAY, policy);
+    assertEqual(json.saved?.metadata.storageClass, 'STANDARD', 'json class');
+  });
+
+  await test('kinds without a storage class use the bucket default', async () => {
+    const file = fakeFile();
+    await writeArtifact(file, 'summary.md', '# Summary', 'job-1', {}, NO_DELAY, { raw: 'COLDLINE' });
+    assertEqual(file.saved?.metadata.storageClass, undefined, 'no class for markdown');
+    const unset = fakeFile();
+    await writeArtifact(unset, 'raw/game_001.txt', 'log', 'job-1', {}, NO_DELAY);
+    assertEqual(unset.saved?.metadata.storageClass, undefined, 'no class without a policy');
+  });
+
+  await test('extra metadata cannot set storageClass', async () => {
+    const file = fakeFile();
+    await writeArtifact(file, 'raw/game_001.txt', 'log', 'job-1', { storageClass: 'ARCHIVE' }, NO_DELAY, { raw: 'NEARLINE' });
+    assertEqual(file.saved?.metadata.storageClass, 'NEARLINE', 'policy class kept');
+  });
+
+  await test('artifactKind classifies by path', async () => {
+    assertEqual(artifactKind('raw/game_001.txt'), 'raw', 'raw log');
+    assertEqual(artifactKind('job-1_game_1.txt'), 'raw', 'incremental simulation log');
+    assertEqual(artifactKind('deadletter/game_001.txt'), 'deadletter', 'dead letter');
+    assertEqual(artifactKind('highlights.json'), 'json', 'json');
+    assertEqual(artifactKind('summary.md'), 'markdown', 'markdown');
+    assertEqual(artifactKind('blob.bin'), undefined, 'unknown');
+  });
+
+  await test('ARTIFACT_STORAGE_CLASSES rejects unknown kinds, classes and malformed entries', async () => {
+    for (const source of ['logs=COLDLINE', 'raw=FROZEN', 'raw', 'raw=COLDLINE=x']) {
+      let message = '';
+      try {
+        parseStorageClassPolicy(source);
+      } catch (error) {
+        message = error instanceof Error ? error.message : String(error);
+      }
+      assert(message.startsWith('Invalid ARTIFACT_STORAGE_CLASSES'), `${source} rejected`);
+    }
+    assertEqual(JSON.stringify(resolveStorageClassPolicy({})), '{}', 'unset');
+    assertEqual(
+      JSON.stringify(resolveStorageClassPolicy({ ARTIFACT_STORAGE_CLASSES: ' raw=archive , ' })),
+      JSON.stringify({ raw: 'ARCHIVE' }),
+      'trimmed and upper-cased'
+    );
+  });
+
+  // ---------------------------------------------------------------------------
+  // Summary
+  // ---------------------------------------------------------------------------
+
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log('\n--- Test Summary ---');
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
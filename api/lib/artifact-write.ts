@@ -0,0 +1,166 @@
+/**
+ * Retry-aware artifact writes with self-describing object metadata.
+ *
+ * Every artifact carries its `jobId`. Callers can add extra tags (simulator
+ * version, environment, git SHA, ...) for lifecycle rules and debugging;
+ * `artifactMetadataFromEnv` supplies the deployment-wide ones.
+ *
+ * ARTIFACT_STORAGE_CLASSES picks a GCS storage class per artifact kind, e.g.
+ * "raw=COLDLINE,json=STANDARD": raw logs are kept for audit and rarely read,
+ * while the JSON artifacts are read on every log panel open. Kinds without
+ * a class use the bucket's default.
+ *
+ * Lives in its own module so it can be unit-tested with a fake file instead
+ * of a real @google-cloud/storage client.
+ */
+import { isRetryableGcsError } from './gcs-retry';
+import { withRetry, type RetryOptions } from './retry';
+import { artifactContentType } from './artifact-manifest';
+
+/** Metadata keys set by the storage layer that extra metadata can't replace. */
+export const RESERVED_METADATA_KEYS: ReadonlySet<string> = new Set(['jobId', 'storageClass']);
+
+/** Environment variable holding the per-kind storage class policy. */
+export const ARTIFACT_STORAGE_CLASSES_ENV = 'ARTIFACT_STORAGE_CLASSES';
+
+export const ARTIFACT_KINDS = ['raw', 'deadletter', 'json', 'markdown'] as const;
+
+export type ArtifactKind = (typeof ARTIFACT_KINDS)[number];
+
+export const STORAGE_CLASSES = ['STANDARD', 'NEARLINE', 'COLDLINE', 'ARCHIVE'] as const;
+
+export type StorageClass = (typeof STORAGE_CLASSES)[number];
+
+/** Storage class per artifact kind; missing kinds use the bucket default. */
+export type StorageClassPolicy = Partial<Record<ArtifactKind, StorageClass>>;
+
+/** Env var -> metadata key, for tags that apply to every artifact. */
+const ENV_METADATA: Record<string, string> = {
+  SIM_VERSION: 'simVersion',
+  ENV: 'env',
+  GIT_SHA: 'gitSha',
+};
+
+/**
+ * Kind of an artifact, by path: dead-lettered logs, raw logs (any other
+ * .txt), JSON and markdown. Undefined for anything else.
+ */
+export function artifactKind(filename: string): ArtifactKind | undefined {
+  if (filename.startsWith('deadletter/')) return 'deadletter';
+  if (filename.endsWith('.txt')) return 'raw';
+  if (filename.endsWith('.json')) return 'json';
+  if (filename.endsWith('.md')) return 'markdown';
+  return undefined;
+}
+
+/**
+ * Parses an ARTIFACT_STORAGE_CLASSES value, e.g. "raw=COLDLINE,json=STANDARD".
+ * Storage class names are case-insensitive.
+ *
+ * @throws Error on an unknown kind or storage class, or a malformed entry
+ */
+export function parseStorageClassPolicy(source: string): StorageClassPolicy {
+  const policy: StorageClassPolicy = {};
+  for (const part of source.split(',')) {
+    const entry = part.trim();
+    if (entry.length === 0) continue;
+    const [rawKind, rawClass, ...rest] = entry.split('=');
+    const kind = rawKind.trim().toLowerCase();
+    const storageClass = (rawClass ?? '').trim().toUpperCase();
+    if (rest.length > 0 || !storageClass) {
+      throw new Error(`Invalid ${ARTIFACT_STORAGE_CLASSES_ENV}: expected kind=CLASS, got "${entry}"`);
+    }
+    if (!(ARTIFACT_KINDS as readonly string[]).includes(kind)) {
+      throw new Error(
+        `Invalid ${ARTIFACT_STORAGE_CLASSES_ENV}: unknown artifact kind "${kind}" (expected ${ARTIFACT_KINDS.join(', ')})`
+      );
+    }
+    if (!(STORAGE_CLASSES as readonly string[]).includes(storageClass)) {
+      throw new Error(
+        `Invalid ${ARTIFACT_STORAGE_CLASSES_ENV}: unknown storage class "${storageClass}" (expected ${STORAGE_CLASSES.join(', ')})`
+      );
+    }
+    policy[kind as ArtifactKind] = storageClass as StorageClass;
+  }
+  return policy;
+}
+
+/**
+ * Reads the storage class policy from the environment.
+ *
+ * @returns The policy; empty (bucket default for everything) when unset
+ * @throws If ARTIFACT_STORAGE_CLASSES is invalid
+ */
+export function resolveStorageClassPolicy(env: NodeJS.ProcessEnv = process.env): StorageClassPolicy {
+  const source = env[ARTIFACT_STORAGE_CLASSES_ENV]?.trim();
+  return source ? parseStorageClassPolicy(source) : {};
+}
+
+/** The part of a GCS File that writeArtifact needs. */
+export interface SavableFile {
+  save(data: string | Buffer, options: { contentType: string; metadata: Record<string, string> }): Promise<unknown>;
+}
+
+export const ARTIFACT_WRITE_RETRY: RetryOptions = { maxAttempts: 3, delayMs: 1000, backoffMultiplier: 2 };
+
+/**
+ * Reads deployment-wide artifact tags from the environment. Unset or empty
+ * variables are left out.
+ */
+export function artifactMetadataFromEnv(env: NodeJS.ProcessEnv = process.env): Record<string, string> {
+  const metadata: Record<string, string> = {};
+  for (const [name, key] of Object.entries(ENV_METADATA)) {
+    const value = env[name]?.trim();
+    if (value) metadata[key] = value;
+  }
+  return metadata;
+}
+
+/**
+ * Merges extra metadata into the artifact's base metadata. Reserved keys
+ * (`jobId`, `storageClass`) always keep the storage layer's value; an
+ * attempt to override one is dropped with a warning.
+ */
+export function buildArtifactMetadata(
+  jobId: string,
+  extra: Record<string, string> = {}
+): Record<string, string> {
+  const metadata: Record<string, string> = {};
+  for (const [key, value] of Object.entries(extra)) {
+    if (RESERVED_METADATA_KEYS.has(key)) {
+      if (key !== 'jobId' || value !== jobId) console.warn(`Ignoring reserved artifact metadata key "${key}"`);
+      continue;
+    }
+    metadata[key] = value;
+  }
+  return { ...metadata, jobId };
+}
+
+/**
+ * Saves an artifact with its content type and merged metadata, retrying
+ * transient (5xx/network) errors. When the policy has a storage class for
+ * the artifact's kind, it is set on the object as `storageClass`.
+ */
+export async function writeArtifact(
+  file: SavableFile,
+  filename: string,
+  data: string | Buffer,
+  jobId: string,
+  extraMetadata?: Record<string, string>,
+  options: RetryOptions = ARTIFACT_WRITE_RETRY,
+  storageClasses: StorageClassPolicy = {}
+): Promise<void> {
+  const contentType = artifactContentType(filename);
+  const metadata = buildArtifactMetadata(jobId, extraMetadata);
+  const kind = artifactKind(filename);
+  const storageClass = kind && storageClasses[kind];
+  if (storageClass) metadata.storageClass = storageClass;
+  await withRetry(
+    async () => {
+      await file.save(data, { contentType, metadata });
+    },
+    options,
+    `GCS upload ${filename}`,
+    isRetryableGcsError
+  );
+}
@@ -156,6 +156,23 @@ async function runTests() {
     }
   });
 
+  await test('skips excluded jobs', async () => {
+    const jobA = createJob(DECKS, 4).id;
+    await new Promise((r) => setTimeout(r, 5)); // ensure distinct created_at
+    const jobB = createJob(DECKS, 4).id;
+    try {
+      initializeSimulations(jobA, 2);
+      initializeSimulations(jobB, 1);
+      const first = claimNextSim('w', 'W', [jobA]);
+      assertEqual(first?.jobId, jobB, 'excluded oldest job skipped');
+      const second = claimNextSim('w', 'W', [jobA, jobB]);
+      assertEqual(second, undefined, 'every job excluded → no claim');
+    } finally {
+      cleanup(jobA);
+      cleanup(jobB);
+    }
+  });
+
   console.log('\n-------------------');
   const passed = results.filter((r) => r.passed).length;
   const failed = results.filter((r) => !r.passed).length;
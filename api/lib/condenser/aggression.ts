@@ -0,0 +1,130 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Aggression Index
+ * =============================================================================
+ *
+ * A 0-100 number per deck for how much life pressure it puts on the table
+ * early: the combat and direct damage it dealt opponents in the first
+ * `rounds` rounds. It tells a deck that attacks from turn two apart from one
+ * that durdles until its combo, whatever their win rates.
+ *
+ * ## Attribution
+ *
+ * Forge's damage lines name the source, not its controller:
+ *
+ *   "Damage: Goblin Guide (23) deals 2 combat damage to Ai(1)-Alpha."
+ *
+ * Combat damage is credited to the active player (the attacker). Other
+ * damage is credited to whoever cast or played the source's card id earlier
+ * in the log (EXTRACT_CARD_OWNER); damage from a source with no known owner
+ * (tokens, emblems) isn't counted. Only damage to a player other than the
+ * dealer counts; damage to permanents and to yourself doesn't.
+ *
+ * ## Index
+ *
+ * A deck's early damage is averaged over the job's games and scaled so that
+ * `fullPressure` damage per game (one Commander life total by default)
+ * scores 100. Higher averages are capped at 100.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { EXTRACT_CARD_OWNER, EXTRACT_DAMAGE } from './patterns';
+import { getNumPlayers, indexTurns, segmentToRound, sliceByTurn, type TurnIndex } from './turns';
+import { resolveWinnerName } from './deck-match';
+
+/**
+ * Window and scale for the aggression index.
+ */
+export interface AggressionOptions {
+  /** Rounds from the start of the game that count as early */
+  rounds: number;
+  /** Average early damage per game that scores 100 */
+  fullPressure: number;
+}
+
+export const DEFAULT_AGGRESSION: AggressionOptions = {
+  rounds: 5,
+  fullPressure: 40,
+};
+
+/** The card id in a damage source, e.g. "Goblin Guide (23)" -> 23 */
+const SOURCE_ID = /\((\d+)\)\s*$/;
+
+/**
+ * Totals the damage each player dealt opponents in the first rounds.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param rounds - Rounds that count as early (default DEFAULT_AGGRESSION.rounds)
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of player (as logged) -> damage dealt to opponents. Players
+ *          who dealt none are omitted.
+ */
+export function earlyDamageDealt(
+  rawLog: string,
+  rounds: number = DEFAULT_AGGRESSION.rounds,
+  turns: TurnIndex = indexTurns(rawLog)
+): Record<string, number> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const players = new Set(ranges.map((r) => r.player).filter((p): p is string => !!p));
+  const owners = new Map<string, string>();
+  const result: Record<string, number> = {};
+
+  for (const { turnNumber, player: activePlayer, chunk } of sliceByTurn(normalized, ranges)) {
+    const early = segmentToRound(turnNumber, numPlayers) <= rounds;
+    for (const line of chunk.split('\n')) {
+      const owned = EXTRACT_CARD_OWNER.exec(line);
+      if (owned) {
+        owners.set(owned[2] ?? owned[4], (owned[1] ?? owned[3]).trim());
+        continue;
+      }
+      if (!early) continue;
+
+      const damage = EXTRACT_DAMAGE.exec(line.trim());
+      if (!damage) continue;
+      const [, source, amount, combat, target] = damage;
+      const sourceId = SOURCE_ID.exec(source.trim())?.[1];
+      const dealer = combat ? activePlayer : sourceId ? owners.get(sourceId) : undefined;
+      const victim = target.trim();
+      if (!dealer || !players.has(victim) || victim === dealer) continue;
+      result[dealer] = (result[dealer] ?? 0) + Number(amount);
+    }
+  }
+
+  return result;
+}
+
+/**
+ * Scores each deck's early pressure across a job's games.
+ *
+ * @param games - Condensed games (with earlyDamageDealt)
+ * @param deckNames - Deck names; players are resolved against these
+ * @param options - Scale (the window was applied when earlyDamageDealt was
+ *   built)
+ * @returns Deck -> aggression index (0-100), with every deck in deckNames
+ *          listed (0 when it dealt no early damage)
+ */
+export function aggressionIndex(
+  games: CondensedGame[],
+  deckNames: string[],
+  options: AggressionOptions = DEFAULT_AGGRESSION
+): Record<string, number> {
+  const totals: Record<string, number> = Object.fromEntries(deckNames.map((name) => [name, 0]));
+  for (const game of games) {
+    for (const [player, damage] of Object.entries(game.earlyDamageDealt ?? {})) {
+      const deck = resolveWinnerName(player, deckNames);
+      totals[deck] = (totals[deck] ?? 0) + damage;
+    }
+  }
+  if (games.length === 0 || options.fullPressure <= 0) {
+    return Object.fromEntries(Object.keys(totals).map((deck) => [deck, 0]));
+  }
+  return Object.fromEntries(
+    Object.entries(totals).map(([deck, total]) => [
+      deck,
+      Math.min(100, Math.round((total / games.length / options.fullPressure) * 100)),
+    ])
+  );
+}
@@ -0,0 +1,58 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - AI Profiles
+ * =============================================================================
+ *
+ * Reads which Forge AI profile (difficulty / personality) played each seat.
+ * Pods that mix profiles confound deck comparisons, so the profiles are
+ * carried on condensed and structured games and summarized per deck in job
+ * results.
+ *
+ * ## Normalization
+ *
+ * Forge names profiles after their files ("Reckless.ai") and logs them with
+ * inconsistent case. Profiles are normalized to the bare name in title case:
+ * "reckless.ai" and "RECKLESS" are both "Reckless".
+ *
+ * When a seat has several profile lines (e.g. "AI:" and "Personality:"),
+ * the last one wins.
+ *
+ * =============================================================================
+ */
+
+import { EXTRACT_AI_PROFILE } from './patterns';
+
+/**
+ * Normalizes a logged AI profile name.
+ *
+ * @param raw - The profile as logged, e.g. "reckless.ai"
+ * @returns The normalized name, e.g. "Reckless"; "" for a blank name
+ */
+export function normalizeAiProfile(raw: string): string {
+  return raw
+    .trim()
+    .replace(/\.ai$/i, '')
+    .split(/[\s_]+/)
+    .filter(Boolean)
+    .map((word) => word.charAt(0).toUpperCase() + word.slice(1).toLowerCase())
+    .join(' ');
+}
+
+/**
+ * Maps each player to their AI profile.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns Player label (as logged) -> normalized profile; empty when the
+ *   log has no profile lines
+ */
+export function extractAiProfiles(rawLog: string): Record<string, string> {
+  const profiles: Record<string, string> = {};
+  for (const line of rawLog.split(/\r?\n/)) {
+    const match = EXTRACT_AI_PROFILE.exec(line);
+    if (!match) continue;
+    const player = (match[1] ?? match[2])?.trim();
+    const profile = normalizeAiProfile(match[3]);
+    if (player && profile) profiles[player] = profile;
+  }
+  return profiles;
+}
@@ -0,0 +1,147 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Archenemy
+ * =============================================================================
+ *
+ * Names the deck the rest of the pod ganged up on. In multiplayer one deck
+ * often draws far more attacks and removal than its board explains, and a
+ * deck that is always the archenemy wins less than its power suggests, a
+ * confound worth surfacing next to its win rate.
+ *
+ * ## Heuristic
+ *
+ * A player's targeting is the attacks they received (attacksReceived, one
+ * per attacking creature; see EXTRACT_ATTACK) plus the interaction they
+ * received (interaction.ts). Their board presence is the permanents they
+ * put onto the battlefield over the game (board.ts, tokens excluded).
+ *
+ * Each player's share of the pod's targeting is compared with their share
+ * of its board presence; the archenemy is the player whose targeting share
+ * exceeds their board share by the most. A deck with a big board drawing
+ * attacks is just the threat; a deck drawing attacks beyond its board is
+ * the archenemy. A player needs at least `minTargeted` attacks plus
+ * interaction to qualify, and a tie names nobody.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { EXTRACT_ATTACK } from './patterns';
+import { indexTurns, type TurnIndex } from './turns';
+import { boardDevelopmentPerTurn } from './board';
+import { INTERACTION_UNKNOWN } from './interaction';
+import { resolveWinnerName } from './deck-match';
+
+/**
+ * Threshold for archenemy.
+ */
+export interface ArchenemyOptions {
+  /** Attacks plus interaction a player must receive to be the archenemy */
+  minTargeted: number;
+}
+
+export const DEFAULT_ARCHENEMY: ArchenemyOptions = {
+  minTargeted: 3,
+};
+
+/** Card ids in an attacker list, e.g. "(258)" */
+const CARD_ID = /\(\d+\)/g;
+
+/**
+ * Counts the attacking creatures each player was attacked by.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of player (as logged) -> attacking creatures. Players never
+ *          attacked are omitted.
+ */
+export function attacksReceived(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): Record<string, number> {
+  const { log: normalized, ranges } = turns;
+  const players = new Set(ranges.map((r) => r.player).filter((p): p is string => !!p));
+  const result: Record<string, number> = {};
+  for (const line of normalized.split('\n')) {
+    const match = line.match(EXTRACT_ATTACK);
+    if (!match) continue;
+    const [, attacker, creatures, defender] = match;
+    if (!players.has(defender) || defender === attacker) continue;
+    result[defender] = (result[defender] ?? 0) + Math.max(creatures.match(CARD_ID)?.length ?? 0, 1);
+  }
+  return result;
+}
+
+/**
+ * Counts the permanents each player put onto the battlefield.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of player (as logged) -> permanents entered, tokens excluded
+ */
+export function boardPresence(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): Record<string, number> {
+  const result: Record<string, number> = {};
+  for (const perPlayer of Object.values(boardDevelopmentPerTurn(rawLog, {}, turns))) {
+    for (const [player, count] of Object.entries(perPlayer)) {
+      result[player] = (result[player] ?? 0) + count;
+    }
+  }
+  return result;
+}
+
+/**
+ * Names the game's archenemy.
+ *
+ * @param game - Attacks and interaction received (from the condensed game)
+ * @param board - Board presence per player (see boardPresence)
+ * @param options - Targeting a player needs to qualify
+ * @returns The archenemy (as logged), or undefined when no player qualifies
+ *          or two are tied
+ */
+export function archenemy(
+  game: Pick<CondensedGame, 'attacksReceived' | 'interactionReceived'>,
+  board: Record<string, number>,
+  options: ArchenemyOptions = DEFAULT_ARCHENEMY
+): string | undefined {
+  const targeted: Record<string, number> = {};
+  for (const received of [game.attacksReceived ?? {}, game.interactionReceived ?? {}]) {
+    for (const [player, count] of Object.entries(received)) {
+      if (player === INTERACTION_UNKNOWN) continue;
+      targeted[player] = (targeted[player] ?? 0) + count;
+    }
+  }
+  const totalTargeted = Object.values(targeted).reduce((sum, n) => sum + n, 0);
+  const totalBoard = Object.values(board).reduce((sum, n) => sum + n, 0);
+  if (totalTargeted === 0) return undefined;
+
+  let best: { player: string; excess: number } | undefined;
+  let tied = false;
+  for (const [player, count] of Object.entries(targeted)) {
+    if (count < options.minTargeted) continue;
+    const boardShare = totalBoard > 0 ? (board[player] ?? 0) / totalBoard : 0;
+    const excess = count / totalTargeted - boardShare;
+    if (excess <= 0) continue;
+    if (!best || excess > best.excess) {
+      best = { player, excess };
+      tied = false;
+    } else if (excess === best.excess) {
+      tied = true;
+    }
+  }
+  return best && !tied ? best.player : undefined;
+}
+
+/**
+ * Counts how often each deck was the archenemy across a job's games.
+ *
+ * @param games - Condensed games (with archenemy)
+ * @param deckNames - Deck names; archenemies are resolved against these
+ * @returns Deck -> games as the archenemy, with every deck in deckNames
+ *          listed (0 when never)
+ */
+export function archenemyCounts(games: CondensedGame[], deckNames: string[]): Record<string, number> {
+  const counts: Record<string, number> = Object.fromEntries(deckNames.map((name) => [name, 0]));
+  for (const game of games) {
+    if (!game.archenemy) continue;
+    const deck = resolveWinnerName(game.archenemy, deckNames);
+    counts[deck] = (counts[deck] ?? 0) + 1;
+  }
+  return counts;
+}
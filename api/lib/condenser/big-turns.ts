@@ -0,0 +1,68 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Big Turns
+ * =============================================================================
+ *
+ * Flags explosive turns: a player turn that cast far more spells than
+ * normal single-spell tempo, usually fueled by rituals, free spells or cost
+ * reducers. Highlights "the turn it went off" for the UI and for combo
+ * detection.
+ *
+ * ## Heuristic
+ *
+ * Uses the condensed game's castsPerTurn (spells cast and mana added per
+ * player turn). A player turn is big when either:
+ *
+ *   - it cast at least `minCasts` spells, regardless of mana, or
+ *   - it cast at least `minRatioCasts` spells and more than
+ *     `maxCastsPerMana` spells per mana added.
+ *
+ * A normal Commander turn casts one or two spells off three to eight mana
+ * (well under 0.5 casts per mana); a ritual chain casts several cheap
+ * spells off little more mana than the rituals made.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame, TurnCastInfo } from '../types';
+
+/**
+ * Thresholds for bigTurns.
+ */
+export interface BigTurnOptions {
+  /** Casts in one player turn that make it big on their own */
+  minCasts: number;
+  /** Casts needed before the casts-per-mana check applies */
+  minRatioCasts: number;
+  /** Casts per mana added above which a turn is big */
+  maxCastsPerMana: number;
+}
+
+export const DEFAULT_BIG_TURNS: BigTurnOptions = {
+  minCasts: 5,
+  minRatioCasts: 3,
+  maxCastsPerMana: 0.5,
+};
+
+/**
+ * True when one player turn exceeded the cast thresholds.
+ */
+export function isBigTurn(turn: TurnCastInfo, options: BigTurnOptions = DEFAULT_BIG_TURNS): boolean {
+  if (turn.casts >= options.minCasts) return true;
+  // No mana seen (free spells, or lines the patterns missed) counts as one
+  return turn.casts >= options.minRatioCasts && turn.casts / Math.max(turn.manaAdded, 1) > options.maxCastsPerMana;
+}
+
+/**
+ * Finds the rounds with at least one big player turn.
+ *
+ * @param game - A condensed game
+ * @param options - Cast thresholds
+ * @returns Rounds in ascending order; empty without castsPerTurn
+ */
+export function bigTurns(game: CondensedGame, options: BigTurnOptions = DEFAULT_BIG_TURNS): number[] {
+  return Object.entries(game.castsPerTurn ?? {})
+    .filter(([, turns]) => turns.some((turn) => isBigTurn(turn, options)))
+    .map(([round]) => Number(round))
+    .sort((a, b) => a - b);
+}
@@ -0,0 +1,76 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Board Development
+ * =============================================================================
+ *
+ * Counts permanents entering the battlefield per player per round, for a
+ * chart of how fast each player built their board.
+ *
+ * ## Attribution
+ *
+ * An ETB line that names a controller ("enters the battlefield under X's
+ * control") counts for that player. Otherwise it counts for the ACTIVE
+ * player, which is right for permanents cast on your own turn and wrong for
+ * flash / instant-speed permanents on someone else's turn.
+ *
+ * ETBs credited to a player after they've been eliminated (their delayed
+ * triggers resolving on the way out) are skipped.
+ *
+ * ## Tokens
+ *
+ * Token ETBs are excluded by default, since token makers can flood the
+ * count without the deck having developed. Pass `includeTokens` to count
+ * them too.
+ *
+ * A Clone entering "as a copy of" something is a real permanent and counts;
+ * a token copy enters as a token and follows the token rule. Either way the
+ * copy itself is counted once, in CondensedGame.cloneCount.
+ *
+ * =============================================================================
+ */
+
+import { EXTRACT_ETB, DETECT_TOKEN } from './patterns';
+import { indexTurns, sliceByTurn, getNumPlayers, segmentToRound, eliminatedPlayerOf, isEliminated, type TurnIndex } from './turns';
+
+export interface BoardDevelopmentOptions {
+  /** Count token ETBs as well as real permanents (default false) */
+  includeTokens?: boolean;
+}
+
+/**
+ * Counts permanents entering the battlefield per player per round.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param options - Whether to include tokens
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of round number -> player -> permanents entered. Rounds
+ *   with no ETBs are omitted.
+ */
+export function boardDevelopmentPerTurn(
+  rawLog: string,
+  options: BoardDevelopmentOptions = {},
+  turns: TurnIndex = indexTurns(rawLog)
+): Record<number, Record<string, number>> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const result: Record<number, Record<string, number>> = {};
+  const eliminated: string[] = [];
+
+  for (const { turnNumber, player, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      const out = eliminatedPlayerOf(line);
+      if (out) eliminated.push(out);
+      const match = EXTRACT_ETB.exec(line.trim());
+      if (!match) continue;
+      if (!options.includeTokens && DETECT_TOKEN.test(match[1])) continue;
+
+      const controller = match[2]?.trim() || player;
+      if (!controller || isEliminated(controller, eliminated)) continue;
+      const counts = (result[round] ??= {});
+      counts[controller] = (counts[controller] ?? 0) + 1;
+    }
+  }
+
+  return result;
+}
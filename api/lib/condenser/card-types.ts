@@ -0,0 +1,115 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Card Type Profile
+ * =============================================================================
+ *
+ * Counts the card types each deck casts (creature, instant, sorcery,
+ * artifact, enchantment, planeswalker), to tell a creature deck from a
+ * spells deck or an enchantress deck.
+ *
+ * ## Where types come from
+ *
+ * Forge cast lines don't say what a card is, so for each cast (a cast event
+ * in the condensed game's keptEvents) the types are, in order:
+ *
+ *   1. What the `resolver` returns for the card name (e.g. a lookup in the
+ *      deck lists or a card database), when one is given.
+ *   2. The game's `cardTypeHints`: type lines Forge printed when the card
+ *      resolved ("Resolve stack: Ripjaw Raptor - Creature 4 / 5"). Forge
+ *      prints these for creatures and some other permanents only.
+ *   3. Type words on the cast line itself.
+ *
+ * A card with several types (an artifact creature) counts once under each.
+ * A cast whose types are still unknown counts under CARD_TYPE_UNKNOWN, so
+ * the profile shows how much of it is guesswork.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { EXTRACT_CAST_CARD, EXTRACT_RESOLVED_TYPES } from './patterns';
+import { SPELL_CAST_EVENT_TYPES } from './classify';
+import { resolveWinnerName } from './deck-match';
+
+/** The card types profiled, in display order */
+export const CARD_TYPES: readonly string[] = ['creature', 'instant', 'sorcery', 'artifact', 'enchantment', 'planeswalker'];
+
+/** Profile bucket for casts whose card types aren't known */
+export const CARD_TYPE_UNKNOWN = 'unknown';
+
+/**
+ * Looks up a card's types by name (e.g. ["Artifact", "Creature"]), or
+ * returns undefined when the card is unknown. Case doesn't matter; types
+ * other than CARD_TYPES are ignored.
+ */
+export type CardTypeResolver = (cardName: string) => readonly string[] | undefined;
+
+function profiledTypes(types: readonly string[] | undefined): string[] {
+  const lower = new Set((types ?? []).map((t) => t.toLowerCase()));
+  return CARD_TYPES.filter((t) => lower.has(t));
+}
+
+function typesOnLine(text: string): string[] {
+  return CARD_TYPES.filter((t) => new RegExp(`\\b${t}\\b`, 'i').test(text));
+}
+
+/**
+ * Collects the type lines Forge printed for cards that were cast.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns Card name -> profiled types (lowercase), for cast cards whose
+ *          resolve line gave a type line
+ */
+export function extractCardTypeHints(rawLog: string): Record<string, string[]> {
+  const cast = new Set<string>();
+  const hints: Record<string, string[]> = {};
+  for (const line of rawLog.split(/\r\n|\r|\n/)) {
+    const castMatch = line.match(EXTRACT_CAST_CARD);
+    if (castMatch) {
+      cast.add(castMatch[2]);
+      continue;
+    }
+    const resolved = line.match(EXTRACT_RESOLVED_TYPES);
+    if (resolved) {
+      const types = profiledTypes(resolved[2].split(/\s+/));
+      if (types.length > 0) hints[resolved[1]] = types;
+    }
+  }
+  return Object.fromEntries(Object.entries(hints).filter(([name]) => cast.has(name)));
+}
+
+/**
+ * Counts the card types each deck cast across a job's games.
+ *
+ * @param games - Condensed games (with cast events and cardTypeHints)
+ * @param deckNames - Deck names; casting players are resolved against these
+ * @param resolver - Optional card type lookup, tried before the log's hints
+ * @returns Deck -> card type (or CARD_TYPE_UNKNOWN) -> casts. Decks that
+ *          cast nothing are omitted.
+ */
+export function cardTypeProfile(
+  games: CondensedGame[],
+  deckNames: string[],
+  resolver?: CardTypeResolver
+): Record<string, Record<string, number>> {
+  const profile: Record<string, Record<string, number>> = {};
+  for (const game of games) {
+    for (const event of game.keptEvents) {
+      if (!SPELL_CAST_EVENT_TYPES.has(event.type)) continue;
+      const match = event.line.match(EXTRACT_CAST_CARD);
+      if (!match) continue;
+      const card = match[2];
+      let types = profiledTypes(resolver?.(card));
+      if (types.length === 0) types = game.cardTypeHints?.[card] ?? [];
+      if (types.length === 0) types = typesOnLine(event.line.slice(match[0].length));
+      if (types.length === 0) types = [CARD_TYPE_UNKNOWN];
+
+      const deck = resolveWinnerName(event.player ?? match[1], deckNames);
+      const counts = (profile[deck] ??= {});
+      for (const type of types) {
+        counts[type] = (counts[type] ?? 0) + (event.repeat ?? 1);
+      }
+    }
+  }
+  return profile;
+}
@@ -0,0 +1,215 @@
+/**
+ * Tests for line classification (classify.ts).
+ *
+ * Run with: npx tsx lib/condenser/classify.test.ts
+ */
+
+import {
+  classifyLine,
+  classifyLines,
+  buildClassificationRules,
+  withClassificationRules,
+  DEFAULT_CLASSIFICATION_PRIORITY,
+  DEFAULT_MAX_CLASSIFY_LINE_LENGTH,
+} from './classify';
+import { condenseGame } from './index';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running classify tests...\n');
+
+  // =========================================================================
+  // Default priority
+  // =========================================================================
+
+  await test('classifyLine: default priority matches documented order', () => {
+    assertEqual(
+      DEFAULT_CLASSIFICATION_PRIORITY.join(','),
+      [
+        'win_condition',
+        'life_change',
+        'zone_change_gy_to_bf',
+        'land_destruction',
+        'creature_death',
+        'clone',
+        'goad',
+        'protection',
+        'ritual',
+        'spell_cast_high_cmc',
+        'commander_cast',
+        'draw_extra',
+        'combat',
+        'land_played',
+        'mill',
+        'library_manip',
+        'free_cast',
+        'alt_cost_cast',
+        'spell_cast',
+      ].join(','),
+      'default priority'
+    );
+  });
+
+  await test('classifyLine: default classification of representative lines', () => {
+    assertEqual(classifyLine('Player A wins the game.'), 'win_condition', 'win');
+    assertEqual(classifyLine('Player A loses 5 life.'), 'life_change', 'life');
+    assertEqual(classifyLine('Player A casts Expropriate (9)'), 'spell_cast_high_cmc', 'high cmc');
+    assertEqual(classifyLine('Player A casts their commander'), 'commander_cast', 'commander');
+    assertEqual(classifyLine('Land: Player A played Forest'), 'land_played', 'land');
+    assertEqual(classifyLine('Player A casts Sol Ring (1)'), 'spell_cast', 'spell');
+    assertEqual(classifyLine('Turn 3: Player B'), null, 'turn marker');
+  });
+
+  await test('classifyLine: a line matching several rules gets the highest priority type', () => {
+    const line = 'Player A casts their commander and loses 2 life.';
+    assertEqual(classifyLine(line), 'life_change', 'life_change outranks commander_cast by default');
+  });
+
+  // =========================================================================
+  // Custom priority
+  // =========================================================================
+
+  await test('classifyLine: reordered priority changes the chosen type', () => {
+    const line = 'Player A casts their commander and loses 2 life.';
+    const priority = [
+      'commander_cast',
+      ...DEFAULT_CLASSIFICATION_PRIORITY.filter((t) => t !== 'commander_cast'),
+    ];
+    assertEqual(classifyLine(line, { priority }), 'commander_cast', 'commander_cast first');
+  });
+
+  await test('classifyLine: types left out of the priority are disabled', () => {
+    const priority = DEFAULT_CLASSIFICATION_PRIORITY.filter((t) => t !== 'land_played');
+    assertEqual(classifyLine('Land: Player A played Forest', { priority }), null, 'land_played disabled');
+    assertEqual(classifyLine('Player A loses 5 life.', { priority }), 'life_change', 'other rules still apply');
+  });
+
+  await test('buildClassificationRules: ignores duplicate types', () => {
+    const rules = buildClassificationRules(['combat', 'combat', 'spell_cast']);
+    assertEqual(rules.map((r) => r.type).join(','), 'combat,spell_cast', 'deduplicated rules');
+  });
+
+  await test('withClassificationRules: builds the rules once for the options', () => {
+    assertEqual(withClassificationRules(undefined), undefined, 'no options');
+    const plain = { maxLineLength: 50 };
+    assertEqual(withClassificationRules(plain), plain, 'no priority leaves the options alone');
+    const prepared = withClassificationRules({ priority: ['combat', 'spell_cast'] });
+    assertEqual(prepared?.rules?.map((r) => r.type).join(','), 'combat,spell_cast', 'rules built');
+    assertEqual(withClassificationRules(prepared), prepared, 'already built');
+    const line = 'Player A casts Lightning Bolt (3)';
+    assertEqual(classifyLine(line, { priority: ['spell_cast'], rules: [] }), null, 'prebuilt rules win over priority');
+  });
+
+  await test('classifyLines: applies the priority to every line', () => {
+    const lines = ['Player A casts their commander and loses 2 life.', 'Player B loses 3 life.'];
+    const events = classifyLines(lines, { priority: ['commander_cast', 'life_change'] });
+    assertEqual(events.length, 2, 'event count');
+    assertEqual(events[0].type, 'commander_cast', 'first event');
+    assertEqual(events[1].type, 'life_change', 'second event');
+  });
+
+  await test('condenseGame: classify options are threaded through the pipeline', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Ai(1)-Alpha casts their commander and loses 2 life.',
+    ].join('\n');
+    const byDefault = condenseGame(log);
+    const reordered = condenseGame(log, { classify: { priority: ['commander_cast', 'life_change'] } });
+    assertEqual(byDefault.keptEvents[0]?.type, 'life_change', 'default pipeline');
+    assertEqual(reordered.keptEvents[0]?.type, 'commander_cast', 'reordered pipeline');
+    assert(reordered.keptEvents.length === 1, 'only one event kept');
+  });
+
+  // =========================================================================
+  // Line length cap
+  // =========================================================================
+
+  await test('classifyLine: text beyond the cap is not matched', () => {
+    const line = 'x'.repeat(DEFAULT_MAX_CLASSIFY_LINE_LENGTH) + ' Player A loses 5 life.';
+    assertEqual(classifyLine(line), null, 'match past the cap is ignored');
+    assertEqual(classifyLine(line, { maxLineLength: Infinity }), 'life_change', 'Infinity disables the cap');
+  });
+
+  await test('classifyLine: maxLineLength is configurable', () => {
+    const line = 'Player A loses 5 life.';
+    assertEqual(classifyLine(line, { maxLineLength: 10 }), null, 'truncated before the match');
+    assertEqual(classifyLine(line, { maxLineLength: line.length }), 'life_change', 'cap at exact length');
+  });
+
+  await test('classifyLine: adversarial lines classify within a bounded time', () => {
+    // Long runs of repeated fragments that several keep patterns backtrack on.
+    const seeds = ['1', 'casts ', '(', 'CMC ', 'life ', 'Ai(1)-', 'attacks ', "can't ", ' '];
+    for (const seed of seeds) {
+      const line = seed.repeat(Math.ceil(200_000 / seed.length));
+      const start = performance.now();
+      classifyLine(line);
+      const elapsed = performance.now() - start;
+      assert(elapsed < 250, `${JSON.stringify(seed)} line took ${elapsed.toFixed(1)}ms`);
+    }
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
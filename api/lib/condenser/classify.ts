@@ -25,12 +25,33 @@
  *   1. WIN_CONDITION - Game-ending events are most critical
  *   2. LIFE_CHANGE - Damage and life gain affect game state
  *   3. ZONE_CHANGE_GY_BF - Reanimation/recursion (powerful)
- *   4. SPELL_HIGH_CMC - Big spells indicate power
- *   5. COMMANDER_CAST - Commander-specific
- *   6. EXTRA_DRAW - Card advantage
- *   7. COMBAT - Attack declarations
- *   8. LAND_PLAYED - Land drops for mana development
- *   9. SPELL_CAST - Generic spell activity
+ *   4. LAND_DESTRUCTION - Land destruction and forced land sacrifice
+ *   5. CREATURE_DEATH - A creature dies or is destroyed
+ *   6. CLONE - Clones and token copies
+ *   7. GOAD - Goad and "must attack if able" effects
+ *   8. PROTECTION - Protection keywords and "can't be countered"
+ *   9. RITUAL - A spell that adds mana when it resolves
+ *  10. SPELL_HIGH_CMC - Big spells indicate power
+ *  11. COMMANDER_CAST - Commander-specific
+ *  12. EXTRA_DRAW - Card advantage
+ *  13. COMBAT - Attack declarations
+ *  14. LAND_PLAYED - Land drops for mana development
+ *  15. MILL - Cards milled from a library into a graveyard
+ *  16. LIBRARY_MANIP - Scry / surveil
+ *  17. FREE_CAST - Cascade, suspend, "without paying its mana cost"
+ *  18. ALT_COST_CAST - Flashback, escape and other alternative-cost casts
+ *  19. SPELL_CAST - Generic spell activity
+ *
+ * The order is data-driven (CLASSIFICATION_RULES) and can be changed per
+ * call via ClassifyOptions.priority, which reorders or disables rules.
+ *
+ * ## Line Length Cap
+ *
+ * Lines are truncated to ClassifyOptions.maxLineLength (default
+ * DEFAULT_MAX_CLASSIFY_LINE_LENGTH) before matching. Some keep patterns
+ * backtrack heavily on long runs of repeated text, so the cap bounds the
+ * worst-case cost of a single pathological line. Real Forge lines are far
+ * shorter, and the text that decides a classification comes first.
  *
  * =============================================================================
  */
@@ -40,133 +61,327 @@ import {
   KEEP_WIN_CONDITION,
   KEEP_LIFE_CHANGE,
   KEEP_ZONE_CHANGE_GY_BF,
+  KEEP_LAND_DESTRUCTION,
+  KEEP_CREATURE_DEATH,
+  KEEP_CLONE,
+  KEEP_GOAD,
+  KEEP_PROTECTION,
+  KEEP_RITUAL,
   KEEP_SPELL_HIGH_CMC,
   KEEP_SPELL_CAST,
   KEEP_COMMANDER_CAST,
   KEEP_EXTRA_DRAW,
   KEEP_COMBAT,
   KEEP_LAND_PLAYED,
+  KEEP_MILL,
+  KEEP_LIBRARY_MANIP,
+  KEEP_FREE_CAST,
+  KEEP_ALT_COST_CAST,
   EXTRACT_CMC,
+  EXTRACT_ACTIVE_PLAYER,
+  DETECT_LOCK_EFFECT,
 } from './patterns';
 
+// -----------------------------------------------------------------------------
+// Classification Rules
+// -----------------------------------------------------------------------------
+
 /**
- * Classifies a single log line into an event type.
- *
- * Returns the event type if the line is significant, or null if it
- * should not be kept (doesn't match any "keep" pattern).
- *
- * @param line - A filtered log line (already passed noise filter)
- * @returns The event type, or null if line is not significant
- *
- * @example
- * classifyLine("Player A wins the game.")        // "win_condition"
- * classifyLine("Player A loses 5 life.")         // "life_change"
- * classifyLine("Player A casts Expropriate (9)") // "spell_cast_high_cmc"
- * classifyLine("Turn 3: Player B")               // null (turn markers aren't events)
+ * A single (pattern, event type) entry in the classification priority list.
+ */
+export interface ClassificationRule {
+  /** The event type assigned when this rule matches */
+  type: EventType;
+  /** Returns true if the line belongs to this event type */
+  matches: (line: string) => boolean;
+}
+
+/**
+ * Default cap on the number of characters of a line that are matched
+ * against the classification patterns.
+ */
+export const DEFAULT_MAX_CLASSIFY_LINE_LENGTH = 1000;
+
+/**
+ * Options that change how lines are classified.
+ */
+export interface ClassifyOptions {
+  /**
+   * Event types in priority order (highest first). Rules are tried in this
+   * order and the first match wins; types left out are disabled entirely.
+   *
+   * Reordering changes which single type a line that matches several
+   * patterns receives. For example, putting 'commander_cast' ahead of
+   * 'life_change' makes "casts their commander and loses 2 life" a
+   * commander_cast instead of a life_change.
+   *
+   * Defaults to DEFAULT_CLASSIFICATION_PRIORITY.
+   */
+  priority?: EventType[];
+  /**
+   * Only the first maxLineLength characters of a line are matched. Use
+   * Infinity to disable the cap.
+   *
+   * Defaults to DEFAULT_MAX_CLASSIFY_LINE_LENGTH.
+   */
+  maxLineLength?: number;
+  /**
+   * The rules built from `priority` (see withClassificationRules). Set once
+   * per condense so classifying each line doesn't rebuild them; takes
+   * precedence over `priority`.
+   */
+  rules?: ClassificationRule[];
+}
+
+/**
+ * The built-in classification rules, in default priority order.
  */
-export function classifyLine(line: string): EventType | null {
-  // -------------------------------------------------------------------------
+export const CLASSIFICATION_RULES: ClassificationRule[] = [
+  // ---------------------------------------------------------------------------
   // Priority 1: Win Condition
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
   // Game-ending events are the most important. If someone won, we need to
   // know immediately. This helps calculate "win turn" for power assessment.
-  if (KEEP_WIN_CONDITION.test(line)) {
-    return 'win_condition';
-  }
+  // Lock effects ("can't win the game") mention winning but are not wins.
+  {
+    type: 'win_condition',
+    matches: (line) => KEEP_WIN_CONDITION.test(line) && !DETECT_LOCK_EFFECT.test(line),
+  },
 
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
   // Priority 2: Life Changes
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
   // Life total changes indicate damage dealt or life gain. Critical for
   // understanding game pacing (how fast is damage being dealt?).
-  if (KEEP_LIFE_CHANGE.test(line)) {
-    return 'life_change';
-  }
+  { type: 'life_change', matches: (line) => KEEP_LIFE_CHANGE.test(line) },
 
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
   // Priority 3: Zone Changes (Graveyard -> Battlefield)
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
   // Reanimation and recursion are powerful strategies. Moving cards from
   // graveyard to battlefield often indicates combo or value engines.
-  if (KEEP_ZONE_CHANGE_GY_BF.test(line)) {
-    return 'zone_change_gy_to_bf';
-  }
+  { type: 'zone_change_gy_to_bf', matches: (line) => KEEP_ZONE_CHANGE_GY_BF.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 4: Land Destruction
+  // ---------------------------------------------------------------------------
+  // Stripping lands (Strip Mine, Armageddon, Pox) is high-impact and
+  // contentious at low brackets. Checked before high CMC because resolve
+  // lines carry card ids like "(11)" that would read as a CMC.
+  { type: 'land_destruction', matches: (line) => KEEP_LAND_DESTRUCTION.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 5: Creature Death
+  // ---------------------------------------------------------------------------
+  // Deaths measure attrition and feed aristocrats engines. A removal spell
+  // and the death it causes are separate lines, so both are kept: the cast
+  // as a spell event, the death as creature_death. Recursion ("return ...
+  // from your graveyard to the battlefield") and land destruction are
+  // checked first; death is checked before high CMC because the dead
+  // creature's id "(33)" would otherwise read as a CMC.
+  { type: 'creature_death', matches: (line) => KEEP_CREATURE_DEATH.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 6: Clone
+  // ---------------------------------------------------------------------------
+  // Clones and token copies ("enters as a copy of", "token copy of") copy
+  // whatever is best on the table. Checked before high CMC because the copied
+  // card's id "(11)" would otherwise read as a CMC.
+  { type: 'clone', matches: (line) => KEEP_CLONE.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 7: Goad
+  // ---------------------------------------------------------------------------
+  // Goad and "must attack if able" effects force combat at other players.
+  // Checked before high CMC because goaded creatures' ids "(11)" would
+  // otherwise read as a CMC. The forced attacks themselves stay combat
+  // events, marked `goaded` (see goad.ts).
+  { type: 'goad', matches: (line) => KEEP_GOAD.test(line) },
 
-  // -------------------------------------------------------------------------
-  // Priority 4: High CMC Spell Cast
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 8: Protection
+  // ---------------------------------------------------------------------------
+  // Hexproof, shroud, protection, indestructible and "can't be countered"
+  // keep threats and combo pieces safe from interaction. Checked before high
+  // CMC because the protected card's id "(10)" would otherwise read as a CMC.
+  { type: 'protection', matches: (line) => KEEP_PROTECTION.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 9: Ritual
+  // ---------------------------------------------------------------------------
+  // Rituals (Dark Ritual, Seething Song) are one-shot mana bursts behind
+  // explosive turns. Checked before high CMC for the same card id reason as
+  // protection.
+  { type: 'ritual', matches: (line) => KEEP_RITUAL.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 10: High CMC Spell Cast
+  // ---------------------------------------------------------------------------
   // Casting expensive spells (CMC 5+) indicates power and ramp capability.
   // We check this BEFORE generic spell cast to give it higher priority.
   //
   // There are two ways to detect high CMC:
   //   a) Pattern matches "CMC 5", "CMC 6", etc. directly
   //   b) Extract CMC from "(CMC N)" or "(N)" and check if >= 5
-  if (KEEP_SPELL_HIGH_CMC.test(line)) {
-    return 'spell_cast_high_cmc';
-  }
+  {
+    type: 'spell_cast_high_cmc',
+    matches: (line) => {
+      if (KEEP_SPELL_HIGH_CMC.test(line)) return true;
+      // Also check for CMC in parentheses that the main pattern might miss
+      const cmcMatch = EXTRACT_CMC.exec(line);
+      return cmcMatch !== null && parseInt(cmcMatch[1], 10) >= 5;
+    },
+  },
 
-  // Also check for CMC in parentheses that the main pattern might miss
-  const cmcMatch = EXTRACT_CMC.exec(line);
-  if (cmcMatch) {
-    const cmc = parseInt(cmcMatch[1], 10);
-    if (cmc >= 5) {
-      return 'spell_cast_high_cmc';
-    }
-  }
-
-  // -------------------------------------------------------------------------
-  // Priority 5: Commander Cast
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 11: Commander Cast
+  // ---------------------------------------------------------------------------
   // In Commander format, casting your commander is significant. Commanders
   // often enable the deck's core strategy.
-  if (KEEP_COMMANDER_CAST.test(line)) {
-    return 'commander_cast';
-  }
+  { type: 'commander_cast', matches: (line) => KEEP_COMMANDER_CAST.test(line) },
 
-  // -------------------------------------------------------------------------
-  // Priority 6: Extra Card Draw
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 12: Extra Card Draw
+  // ---------------------------------------------------------------------------
   // Drawing extra cards indicates card advantage engines (Rhystic Study,
   // Consecrated Sphinx, etc.). More cards = more power.
-  if (KEEP_EXTRA_DRAW.test(line)) {
-    return 'draw_extra';
-  }
+  { type: 'draw_extra', matches: (line) => KEEP_EXTRA_DRAW.test(line) },
 
-  // -------------------------------------------------------------------------
-  // Priority 7: Combat
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 13: Combat
+  // ---------------------------------------------------------------------------
   // Combat damage is how most games end. Tracking attacks helps understand
   // the deck's aggression level and threat generation.
-  if (KEEP_COMBAT.test(line)) {
-    return 'combat';
-  }
+  { type: 'combat', matches: (line) => KEEP_COMBAT.test(line) },
 
-  // -------------------------------------------------------------------------
-  // Priority 8: Land Played
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 14: Land Played
+  // ---------------------------------------------------------------------------
   // Land drops indicate mana development. Tracking lands helps understand
   // ramp and curve consistency.
-  if (KEEP_LAND_PLAYED.test(line)) {
-    return 'land_played';
-  }
+  { type: 'land_played', matches: (line) => KEEP_LAND_PLAYED.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 15: Mill
+  // ---------------------------------------------------------------------------
+  // Milling feeds graveyard strategies (self-mill) or is the win condition
+  // itself (opponent-mill). Checked before generic spell cast so a line like
+  // "casts Glimpse the Unthinkable and mills 10 cards" counts as a mill.
+  { type: 'mill', matches: (line) => KEEP_MILL.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 16: Library Manipulation
+  // ---------------------------------------------------------------------------
+  // Scry and surveil indicate card selection; surveil also fills the graveyard.
+  { type: 'library_manip', matches: (line) => KEEP_LIBRARY_MANIP.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 17: Free Cast
+  // ---------------------------------------------------------------------------
+  // Cascade, suspend and "without paying its mana cost" spells are free
+  // value. Checked before generic spell cast; a free high-CMC spell keeps the
+  // spell_cast_high_cmc type (CondensedGame.freeCastCount still counts it).
+  { type: 'free_cast', matches: (line) => KEEP_FREE_CAST.test(line) },
+
+  // ---------------------------------------------------------------------------
+  // Priority 18: Alternative-Cost Cast
+  // ---------------------------------------------------------------------------
+  // Flashback, escape, disturb, jump-start and retrace recast spells from the
+  // graveyard. Like free casts, a high-CMC one keeps spell_cast_high_cmc
+  // (CondensedGame.altCostCastCount still counts it).
+  { type: 'alt_cost_cast', matches: (line) => KEEP_ALT_COST_CAST.test(line) },
 
-  // -------------------------------------------------------------------------
-  // Priority 9: Generic Spell Cast
-  // -------------------------------------------------------------------------
+  // ---------------------------------------------------------------------------
+  // Priority 19: Generic Spell Cast
+  // ---------------------------------------------------------------------------
   // Any spell cast is activity worth noting, even if it's not high CMC.
   // A deck casting 5 spells per turn is more active than one casting 1.
-  if (KEEP_SPELL_CAST.test(line)) {
-    return 'spell_cast';
+  { type: 'spell_cast', matches: (line) => KEEP_SPELL_CAST.test(line) },
+];
+
+/**
+ * Event types that are a spell being cast, for per-turn spell totals.
+ */
+export const SPELL_CAST_EVENT_TYPES: ReadonlySet<EventType> = new Set<EventType>([
+  'spell_cast',
+  'spell_cast_high_cmc',
+  'commander_cast',
+  'free_cast',
+  'alt_cost_cast',
+]);
+
+/**
+ * Default classification priority (highest first).
+ */
+export const DEFAULT_CLASSIFICATION_PRIORITY: EventType[] = CLASSIFICATION_RULES.map((r) => r.type);
+
+/**
+ * Orders the built-in rules by the given priority, dropping any type that
+ * isn't listed. Unknown or duplicate types are ignored.
+ *
+ * @param priority - Event types in priority order (highest first)
+ * @returns The rules to try, in order
+ */
+export function buildClassificationRules(priority: EventType[]): ClassificationRule[] {
+  const rules: ClassificationRule[] = [];
+  const seen = new Set<EventType>();
+  for (const type of priority) {
+    if (seen.has(type)) continue;
+    const rule = CLASSIFICATION_RULES.find((r) => r.type === type);
+    if (rule) {
+      rules.push(rule);
+      seen.add(type);
+    }
+  }
+  return rules;
+}
+
+/**
+ * Builds the rules for `options.priority` once, ahead of classifying many
+ * lines with the same options.
+ *
+ * @param options - Classification options
+ * @returns The options with `rules` set, or the options unchanged when
+ *   there's no priority or the rules are already built
+ */
+export function withClassificationRules(options?: ClassifyOptions): ClassifyOptions | undefined {
+  if (!options?.priority || options.rules) return options;
+  return { ...options, rules: buildClassificationRules(options.priority) };
+}
+
+/**
+ * Classifies a single log line into an event type.
+ *
+ * Returns the event type if the line is significant, or null if it
+ * should not be kept (doesn't match any "keep" pattern).
+ *
+ * Rules are tried in priority order and the first match wins. Lines that
+ * match no rule might be turn markers (handled separately), phase
+ * announcements, or other unclassified text. Long lines are truncated to
+ * the configured cap before matching.
+ *
+ * @param line - A filtered log line (already passed noise filter)
+ * @param options - Optional classification options (e.g. priority order)
+ * @returns The event type, or null if line is not significant
+ *
+ * @example
+ * classifyLine("Player A wins the game.")        // "win_condition"
+ * classifyLine("Player A loses 5 life.")         // "life_change"
+ * classifyLine("Player A casts Expropriate (9)") // "spell_cast_high_cmc"
+ * classifyLine("Turn 3: Player B")               // null (turn markers aren't events)
+ */
+export function classifyLine(line: string, options?: ClassifyOptions): EventType | null {
+  const rules = options?.rules
+    ?? (options?.priority ? buildClassificationRules(options.priority) : CLASSIFICATION_RULES);
+  const maxLength = options?.maxLineLength ?? DEFAULT_MAX_CLASSIFY_LINE_LENGTH;
+  const candidate = line.length > maxLength ? line.slice(0, maxLength) : line;
+
+  for (const rule of rules) {
+    if (rule.matches(candidate)) {
+      return rule.type;
+    }
   }
 
-  // -------------------------------------------------------------------------
-  // No Match
-  // -------------------------------------------------------------------------
-  // Line didn't match any "keep" pattern. It might be:
-  //   - A turn marker (handled separately)
-  //   - A phase announcement
-  //   - Other unclassified text
-  // Return null to indicate this line shouldn't become an event.
   return null;
 }
 
@@ -178,14 +393,18 @@ export function classifyLine(line: string): EventType | null {
  * @param line - A filtered log line
  * @param turn - Optional turn number for context
  * @param player - Optional active player for context
+ * @param options - Optional classification options
+ * @param lineNo - Optional 1-based line number in the raw log
  * @returns A GameEvent object, or null if the line is not significant
  */
 export function createEvent(
   line: string,
   turn?: number,
-  player?: string
+  player?: string,
+  options?: ClassifyOptions,
+  lineNo?: number
 ): GameEvent | null {
-  const type = classifyLine(line);
+  const type = classifyLine(line, options);
 
   if (type === null) {
     return null;
@@ -205,6 +424,9 @@ export function createEvent(
   if (player !== undefined) {
     event.player = player;
   }
+  if (lineNo !== undefined) {
+    event.lineNo = lineNo;
+  }
 
   return event;
 }
@@ -215,13 +437,17 @@ export function createEvent(
  * Filters out lines that don't classify to any event type.
  *
  * @param lines - Array of filtered log lines
+ * @param options - Optional classification options
+ * @param lineNumbers - Optional raw-log line number of each line (see
+ *   splitAndFilterNumbered); sets GameEvent.lineNo
  * @returns Array of GameEvent objects
  */
-export function classifyLines(lines: string[]): GameEvent[] {
+export function classifyLines(lines: string[], options?: ClassifyOptions, lineNumbers?: number[]): GameEvent[] {
   const events: GameEvent[] = [];
+  const prepared = withClassificationRules(options);
 
-  for (const line of lines) {
-    const event = createEvent(line);
+  for (const [i, line] of lines.entries()) {
+    const event = createEvent(line, undefined, undefined, prepared, lineNumbers?.[i]);
     if (event !== null) {
       events.push(event);
     }
@@ -229,3 +455,66 @@ export function classifyLines(lines: string[]): GameEvent[] {
 
   return events;
 }
+
+/**
+ * Collapses runs of identical consecutive events into one event with a
+ * `repeat` count. Events are identical when type, line, turn and player all
+ * match. Loops and repeated triggers produce long runs like this on combo
+ * turns.
+ *
+ * @param events - Events in log order
+ * @returns Events with each run collapsed; single events have no `repeat`
+ */
+export function compactRepeatedEvents(events: GameEvent[]): GameEvent[] {
+  const compacted: GameEvent[] = [];
+
+  for (const event of events) {
+    const last = compacted[compacted.length - 1];
+    if (
+      last &&
+      last.type === event.type &&
+      last.line === event.line &&
+      last.turn === event.turn &&
+      last.player === event.player
+    ) {
+      last.repeat = (last.repeat ?? 1) + (event.repeat ?? 1);
+    } else {
+      compacted.push({ ...event });
+    }
+  }
+
+  return compacted;
+}
+
+/**
+ * Classifies lines like classifyLines, then compacts repeated events with
+ * compactRepeatedEvents. Turn marker lines end a run, so identical events
+ * on different turns are never merged.
+ *
+ * @param lines - Array of filtered log lines
+ * @param options - Optional classification options
+ * @param lineNumbers - Optional raw-log line number of each line; a
+ *   compacted event keeps the first line of its run
+ * @returns Array of GameEvent objects
+ */
+export function classifyLinesCompacted(lines: string[], options?: ClassifyOptions, lineNumbers?: number[]): GameEvent[] {
+  const events: GameEvent[] = [];
+  const prepared = withClassificationRules(options);
+  let turnLines: string[] = [];
+  let turnLineNumbers: number[] = [];
+
+  const flush = () => {
+    events.push(...compactRepeatedEvents(classifyLines(turnLines, prepared, lineNumbers && turnLineNumbers)));
+    turnLines = [];
+    turnLineNumbers = [];
+  };
+
+  for (const [i, line] of lines.entries()) {
+    if (EXTRACT_ACTIVE_PLAYER.test(line)) flush();
+    turnLines.push(line);
+    if (lineNumbers) turnLineNumbers.push(lineNumbers[i]);
+  }
+  flush();
+
+  return events;
+}
@@ -0,0 +1,286 @@
+/**
+ * Tests for the log-tool CLI (condense, patterns and backfill-ids subcommands).
+ *
+ * Run with: npx tsx lib/condenser/cli.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { CondensedGame, StructuredGame } from '../types';
+import { runCli, PATTERNS_FILE_ENV, type CliIO, type PatternReport } from './cli';
+import type { HighlightFeed } from './highlights';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const FIXTURE_PATH = path.join(__dirname, 'fixtures', 'real-4game-log.txt');
+
+/** In-memory IO that records output and serves stdin from a string. */
+function memoryIO(stdin = '') {
+  const out: string[] = [];
+  const err: string[] = [];
+  const io: CliIO = {
+    readStdin: async () => stdin,
+    readFile: (filePath) => fs.readFileSync(filePath, 'utf-8'),
+    stdout: (text) => out.push(text),
+    stderr: (text) => err.push(text),
+  };
+  return { io, stdout: () => out.join(''), stderr: () => err.join('') };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running CLI tests...\n');
+
+  const rawLog = fs.readFileSync(FIXTURE_PATH, 'utf-8');
+
+  await test('condense -: reads stdin and emits one CondensedGame per game', async () => {
+    const run = memoryIO(rawLog);
+    const code = await runCli(['condense', '-'], run.io);
+    assertEqual(code, 0, 'exit code');
+    const games = JSON.parse(run.stdout()) as CondensedGame[];
+    assertEqual(games.length, 4, 'game count');
+    assert(games.every((g) => Array.isArray(g.keptEvents) && g.winner), 'each game condensed with a winner');
+    assertEqual(run.stderr(), '', 'no stderr');
+  });
+
+  await test('condense with no input argument reads stdin', async () => {
+    const run = memoryIO(rawLog);
+    assertEqual(await runCli(['condense'], run.io), 0, 'exit code');
+    assertEqual((JSON.parse(run.stdout()) as CondensedGame[]).length, 4, 'game count');
+  });
+
+  await test('condense FILE reads the file', async () => {
+    const run = memoryIO('');
+    assertEqual(await runCli(['condense', FIXTURE_PATH], run.io), 0, 'exit code');
+    assertEqual((JSON.parse(run.stdout()) as CondensedGame[]).length, 4, 'game count');
+  });
+
+  await test('condense -structured emits StructuredGame[]', async () => {
+    const run = memoryIO(rawLog);
+    assertEqual(await runCli(['condense', '-structured', '-'], run.io), 0, 'exit code');
+    const games = JSON.parse(run.stdout()) as StructuredGame[];
+    assertEqual(games.length, 4, 'game count');
+    assert(games.every((g) => Array.isArray(g.decks) && g.totalTurns > 0), 'structured shape');
+  });
+
+  await test('condense -turn-reset splits games on a turn counter reset', async () => {
+    const turnResetPath = path.join(__dirname, 'fixtures', 'turn-reset-log.txt');
+    const run = memoryIO();
+    assertEqual(await runCli(['condense', '-turn-reset', turnResetPath], run.io), 0, 'exit code');
+    assertEqual((JSON.parse(run.stdout()) as CondensedGame[]).length, 2, 'game count');
+
+    // Without a win line before the reset, only -turn-reset splits
+    const noWin = fs.readFileSync(turnResetPath, 'utf-8').replace(/^Game outcome: .* has won .*\n/m, '');
+    const single = memoryIO(noWin);
+    await runCli(['condense', '-'], single.io);
+    assertEqual((JSON.parse(single.stdout()) as CondensedGame[]).length, 1, 'default keeps one game');
+  });
+
+  await test('condense -line-numbers sets lineNo on events', async () => {
+    const run = memoryIO(rawLog);
+    assertEqual(await runCli(['condense', '-line-numbers', '-'], run.io), 0, 'exit code');
+    const games = JSON.parse(run.stdout()) as CondensedGame[];
+    assert(games.every((g) => g.keptEvents.every((e) => typeof e.lineNo === 'number')), 'lineNo on every event');
+
+    const plain = memoryIO(rawLog);
+    await runCli(['condense', '-'], plain.io);
+    assert(!plain.stdout().includes('"lineNo"'), 'off by default');
+  });
+
+  await test('condense -highlights emits the highlight feed', async () => {
+    const run = memoryIO(rawLog);
+    assertEqual(await runCli(['condense', '-highlights', '-'], run.io), 0, 'exit code');
+    const feed = JSON.parse(run.stdout()) as HighlightFeed;
+    assertEqual(feed.games, 4, 'game count');
+    assertEqual(feed.highlights.filter((h) => h.kind === 'win').length, 4, 'one win per game');
+    assert(feed.highlights.every((h) => h.gameIndex >= 0 && h.gameIndex < 4), 'each highlight references its game');
+
+    const both = memoryIO(rawLog);
+    assertEqual(await runCli(['condense', '-highlights', '-structured', '-'], both.io), 2, 'exclusive with -structured');
+  });
+
+  await test('condense: missing file exits 1 with an error', async () => {
+    const run = memoryIO();
+    assertEqual(await runCli(['condense', '/nonexistent/game.txt'], run.io), 1, 'exit code');
+    assert(run.stderr().includes('Failed to read'), 'error message');
+    assertEqual(run.stdout(), '', 'no stdout');
+  });
+
+  await test('usage errors exit 2', async () => {
+    for (const argv of [[], ['explode'], ['condense', '-bogus'], ['condense', 'a.txt', 'b.txt']]) {
+      const run = memoryIO();
+      assertEqual(await runCli(argv, run.io), 2, `exit code for ${JSON.stringify(argv)}`);
+      assert(run.stderr().includes('Usage:'), `usage shown for ${JSON.stringify(argv)}`);
+    }
+  });
+
+  // =========================================================================
+  // patterns
+  // =========================================================================
+
+  const goodPatterns = path.join(__dirname, 'fixtures', 'patterns-good.json');
+  const badPatterns = path.join(__dirname, 'fixtures', 'patterns-bad.json');
+  const sampleLog = path.join(__dirname, 'fixtures', 'cmc-histogram-log.txt');
+
+  type PatternsOutput = { source: string; compiled: number; patterns?: PatternReport[] };
+
+  await test('patterns: built-in patterns compile and report matches against a sample', async () => {
+    const run = memoryIO();
+    assertEqual(await runCli(['patterns', FIXTURE_PATH], run.io), 0, 'exit code');
+    const output = JSON.parse(run.stdout()) as PatternsOutput;
+    assertEqual(output.source, 'built-in', 'source');
+    assertEqual(output.compiled, output.patterns!.length, 'one report per pattern');
+    const castBy = output.patterns!.find((p) => p.name === 'EXTRACT_CAST_BY')!;
+    assert(castBy.matches > 3, 'cast lines matched');
+    assertEqual(castBy.examples.length, 3, 'three examples by default');
+    assert(castBy.examples.every((line) => line.includes(' cast ')), 'examples are cast lines');
+  });
+
+  await test('patterns -file: counts matches and keeps the first examples', async () => {
+    const run = memoryIO();
+    assertEqual(await runCli(['patterns', '-file', goodPatterns, '-examples', '1', sampleLog], run.io), 0, 'exit code');
+    const output = JSON.parse(run.stdout()) as PatternsOutput;
+    assertEqual(output.source, goodPatterns, 'source');
+    const byName = Object.fromEntries(output.patterns!.map((p) => [p.name, p]));
+    assertEqual(byName.land_played.matches, 6, 'land drops');
+    assertEqual(JSON.stringify(byName.land_played.examples), JSON.stringify(['Land: Ai(1)-Alpha played Island (1)']), 'first example only');
+    assertEqual(byName.cast.flags, 'i', 'flags kept');
+    assertEqual(byName.cast.matches, 7, 'casts, including the trigger text');
+    assertEqual(byName.never.matches, 0, 'unmatched pattern reported');
+  });
+
+  await test('patterns: without a sample only checks compilation', async () => {
+    const run = memoryIO();
+    assertEqual(await runCli(['patterns', '-file', goodPatterns], run.io), 0, 'exit code');
+    assertEqual(run.stdout().trim(), JSON.stringify({ source: goodPatterns, compiled: 3 }, null, 2), 'no reports');
+  });
+
+  await test('patterns: a bad pattern file exits 1 naming each bad regex', async () => {
+    const run = memoryIO();
+    assertEqual(await runCli(['patterns', '-file', badPatterns, sampleLog], run.io), 1, 'exit code');
+    assert(run.stderr().includes('Invalid pattern unclosed_group:'), 'unclosed group reported');
+    assert(run.stderr().includes('Invalid pattern bad_flags:'), 'bad flags reported');
+    assert(!run.stderr().includes('land_played'), 'valid pattern not reported');
+    assertEqual(run.stdout(), '', 'no stdout');
+  });
+
+  await test('patterns: reads the pattern file from PATTERNS_FILE', async () => {
+    const previous = process.env[PATTERNS_FILE_ENV];
+    process.env[PATTERNS_FILE_ENV] = badPatterns;
+    try {
+      const run = memoryIO();
+      assertEqual(await runCli(['patterns'], run.io), 1, 'exit code');
+      assert(run.stderr().includes('unclosed_group'), 'env file used');
+    } finally {
+      if (previous === undefined) delete process.env[PATTERNS_FILE_ENV];
+      else process.env[PATTERNS_FILE_ENV] = previous;
+    }
+  });
+
+  await test('backfill-ids: backfills each job and reports counts', async () => {
+    const run = memoryIO();
+    const calls: string[] = [];
+    run.io.backfillJob = async (jobId) => {
+      calls.push(jobId);
+      return jobId === 'missing' ? null : { total: 4, added: jobId === 'old' ? 4 : 0 };
+    };
+    assertEqual(await runCli(['backfill-ids', 'old', 'new'], run.io), 0, 'exit code');
+    assertEqual(calls.join(','), 'old,new', 'every job backfilled in order');
+    assertEqual(
+      JSON.stringify(JSON.parse(run.stdout())),
+      JSON.stringify([{ jobId: 'old', total: 4, added: 4 }, { jobId: 'new', total: 4, added: 0 }]),
+      'report'
+    );
+
+    const failing = memoryIO();
+    failing.io.backfillJob = run.io.backfillJob;
+    assertEqual(await runCli(['backfill-ids', 'missing', 'old'], failing.io), 1, 'missing artifact exits 1');
+    assert(failing.stderr().includes('missing: no condensed artifact stored'), 'missing job named');
+    assertEqual(JSON.parse(failing.stdout()).length, 1, 'other jobs still backfilled');
+  });
+
+  await test('backfill-ids: usage errors exit 2; no job storage exits 1', async () => {
+    for (const argv of [['backfill-ids'], ['backfill-ids', '-x', 'job']]) {
+      const run = memoryIO();
+      assertEqual(await runCli(argv, run.io), 2, `exit code for ${JSON.stringify(argv)}`);
+      assert(run.stderr().includes('Usage:'), `usage shown for ${JSON.stringify(argv)}`);
+    }
+    const run = memoryIO();
+    assertEqual(await runCli(['backfill-ids', 'job'], run.io), 1, 'no storage');
+  });
+
+  await test('patterns: usage errors exit 2', async () => {
+    for (const argv of [['patterns', '-file'], ['patterns', '-examples', 'x'], ['patterns', '-bogus'], ['patterns', 'a.txt', 'b.txt']]) {
+      const run = memoryIO();
+      assertEqual(await runCli(argv, run.io), 2, `exit code for ${JSON.stringify(argv)}`);
+      assert(run.stderr().includes('Usage:'), `usage shown for ${JSON.stringify(argv)}`);
+    }
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
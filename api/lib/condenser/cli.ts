@@ -0,0 +1,371 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Command Line Entry Point
+ * =============================================================================
+ *
+ * Ad-hoc condensing outside the API, for debugging and scripting. No GCS,
+ * database or API access; input comes from a file or stdin and JSON goes to
+ * stdout. The one exception is `backfill-ids`, which goes through the job
+ * storage the script passes in.
+ *
+ *   cat game.txt | npx tsx scripts/log-tool.ts condense -
+ *   npx tsx scripts/log-tool.ts condense -structured game.txt
+ *   npx tsx scripts/log-tool.ts condense -highlights games.txt
+ *   npx tsx scripts/log-tool.ts patterns -file candidate.json game.txt
+ *   npx tsx scripts/log-tool.ts backfill-ids <jobId>...
+ *
+ * The input may hold several concatenated games; it is split with
+ * splitConcatenatedGames and one entry per game is emitted. Pass
+ * -turn-reset for logs whose games are separated only by the turn counter
+ * going back to 1.
+ *
+ * `patterns` is for pattern development: it compiles the built-in patterns
+ * (or a JSON file of name -> regex, from -file or PATTERNS_FILE) and, given
+ * a sample log, reports how many lines each one matched with a few
+ * examples. A pattern that doesn't compile fails the command.
+ *
+ * `backfill-ids` adds game IDs to the stored condensed artifact of jobs
+ * ingested before game IDs existed (see backfillGameIds in game-id.ts).
+ * Jobs that already have them are left alone, so it's safe to re-run.
+ *
+ * runCli is kept free of process globals so tests can drive it directly.
+ *
+ * =============================================================================
+ */
+
+import * as fs from 'fs';
+import * as builtInPatterns from './patterns';
+import { splitConcatenatedGames, type SplitStrategy } from './patterns';
+import { condenseGames, structureGames } from './index';
+import { buildHighlights, getHighlightKinds, type HighlightKind } from './highlights';
+
+/**
+ * Input/output used by the CLI. The script wires these to the process;
+ * tests pass in-memory versions.
+ */
+export interface CliIO {
+  readStdin: () => Promise<string>;
+  readFile: (filePath: string) => string;
+  stdout: (text: string) => void;
+  stderr: (text: string) => void;
+  /**
+   * Backfills a stored job's game IDs for backfill-ids, returning null when
+   * the job has no condensed artifact. Unset when no job storage is wired.
+   */
+  backfillJob?: (jobId: string) => Promise<{ total: number; added: number } | null>;
+}
+
+/** Environment variable naming a JSON pattern file for the patterns command. */
+export const PATTERNS_FILE_ENV = 'PATTERNS_FILE';
+
+/** Example lines shown per pattern by default. */
+const DEFAULT_PATTERN_EXAMPLES = 3;
+
+/** Longest example line shown, matching the event line cap. */
+const MAX_EXAMPLE_LENGTH = 200;
+
+export const CLI_USAGE = [
+  'Usage: log-tool condense [-structured|-highlights] [-turn-reset] [-line-numbers] [FILE|-]',
+  '       log-tool patterns [-file PATTERNS.json] [-examples N] [SAMPLE|-]',
+  '       log-tool backfill-ids JOB_ID...',
+  '',
+  '  Condenses a Forge game log (one or more concatenated games) to JSON.',
+  '  Reads FILE, or stdin when FILE is "-" or omitted.',
+  '',
+  '  -structured   emit StructuredGame[] instead of CondensedGame[]',
+  '  -highlights   emit only the highlight feed (wins, eliminations, board',
+  '                wipes, big turns, fastest kill); HIGHLIGHT_KINDS narrows it',
+  '  -turn-reset   also split games where the turn counter resets to 1',
+  '  -line-numbers record each event\'s line number in its game (lineNo)',
+  '',
+  '  patterns checks that every pattern compiles and, given a SAMPLE log,',
+  '  reports per pattern how many lines it matched and a few examples.',
+  '  Without -file (or PATTERNS_FILE) the built-in patterns are checked;',
+  '  a pattern file is a JSON object of name -> regex source, or',
+  '  name -> { "source": ..., "flags": ... }.',
+  '',
+  '  -file         JSON pattern file to check instead of the built-ins',
+  '  -examples     example lines per pattern (default 3)',
+  '',
+  '  backfill-ids adds stable game IDs to the stored condensed games of',
+  '  each job that lacks them, hashing the raw logs when they are still',
+  '  stored. Re-running leaves the IDs unchanged.',
+].join('\n');
+
+/**
+ * One pattern's result against the sample log.
+ */
+export interface PatternReport {
+  name: string;
+  source: string;
+  flags: string;
+  /** Sample lines the pattern matched */
+  matches: number;
+  /** The first few matching lines */
+  examples: string[];
+}
+
+/**
+ * The default IO, bound to the current process.
+ */
+export function processIO(): CliIO {
+  return {
+    readStdin: async () => {
+      const chunks: Buffer[] = [];
+      for await (const chunk of process.stdin) {
+        chunks.push(typeof chunk === 'string' ? Buffer.from(chunk) : chunk);
+      }
+      return Buffer.concat(chunks).toString('utf-8');
+    },
+    readFile: (filePath) => fs.readFileSync(filePath, 'utf-8'),
+    stdout: (text) => process.stdout.write(text),
+    stderr: (text) => process.stderr.write(text),
+  };
+}
+
+async function condenseCommand(args: string[], io: CliIO): Promise<number> {
+  let structured = false;
+  let highlights = false;
+  let strategy: SplitStrategy = 'result-line';
+  let includeLineNumbers = false;
+  let input: string | undefined;
+
+  for (const arg of args) {
+    if (arg === '-structured' || arg === '--structured') {
+      structured = true;
+    } else if (arg === '-highlights' || arg === '--highlights') {
+      highlights = true;
+    } else if (arg === '-turn-reset' || arg === '--turn-reset') {
+      strategy = 'turn-reset';
+    } else if (arg === '-line-numbers' || arg === '--line-numbers') {
+      includeLineNumbers = true;
+    } else if (arg !== '-' && arg.startsWith('-')) {
+      io.stderr(`Unknown flag: ${arg}\n\n${CLI_USAGE}\n`);
+      return 2;
+    } else if (input !== undefined) {
+      io.stderr(`Only one input may be given\n\n${CLI_USAGE}\n`);
+      return 2;
+    } else {
+      input = arg;
+    }
+  }
+  if (structured && highlights) {
+    io.stderr(`-structured and -highlights can't be combined\n\n${CLI_USAGE}\n`);
+    return 2;
+  }
+
+  let rawLog: string;
+  try {
+    rawLog = input === undefined || input === '-' ? await io.readStdin() : io.readFile(input);
+  } catch (err) {
+    io.stderr(`Failed to read ${input}: ${err instanceof Error ? err.message : String(err)}\n`);
+    return 1;
+  }
+
+  const games = splitConcatenatedGames(rawLog, { strategy });
+  let output: unknown;
+  if (structured) {
+    output = structureGames(games);
+  } else if (highlights) {
+    let kinds: HighlightKind[];
+    try {
+      kinds = getHighlightKinds();
+    } catch (err) {
+      io.stderr(`${err instanceof Error ? err.message : String(err)}\n`);
+      return 2;
+    }
+    output = buildHighlights(games, condenseGames(games, { includeLineNumbers }), kinds);
+  } else {
+    output = condenseGames(games, { includeLineNumbers });
+  }
+  io.stdout(JSON.stringify(output, null, 2) + '\n');
+  return 0;
+}
+
+/** The exported RegExps of patterns.ts, by name. */
+function builtInPatternSet(): Record<string, RegExp> {
+  const set: Record<string, RegExp> = {};
+  for (const [name, value] of Object.entries(builtInPatterns)) {
+    if (value instanceof RegExp) set[name] = value;
+  }
+  return set;
+}
+
+/**
+ * Compiles a pattern file.
+ *
+ * @returns The compiled patterns, and one message per entry that failed
+ */
+function compilePatternFile(text: string): { patterns: Record<string, RegExp>; errors: string[] } {
+  const parsed: unknown = JSON.parse(text);
+  if (typeof parsed !== 'object' || parsed === null || Array.isArray(parsed)) {
+    return { patterns: {}, errors: ['pattern file must be a JSON object of name -> regex'] };
+  }
+  const patterns: Record<string, RegExp> = {};
+  const errors: string[] = [];
+  for (const [name, entry] of Object.entries(parsed)) {
+    const { source, flags } =
+      typeof entry === 'string'
+        ? { source: entry, flags: undefined }
+        : (entry ?? {}) as { source?: unknown; flags?: unknown };
+    if (typeof source !== 'string' || (flags !== undefined && typeof flags !== 'string')) {
+      errors.push(`${name}: expected a regex source string or { source, flags }`);
+      continue;
+    }
+    try {
+      patterns[name] = new RegExp(source, flags);
+    } catch (err) {
+      errors.push(`${name}: ${err instanceof Error ? err.message : String(err)}`);
+    }
+  }
+  return { patterns, errors };
+}
+
+/**
+ * Counts the sample lines each pattern matches, keeping the first few.
+ */
+function samplePatterns(patterns: Record<string, RegExp>, sample: string, maxExamples: number): PatternReport[] {
+  const lines = sample.split(/\r?\n|\r/).filter((line) => line.trim().length > 0);
+  return Object.entries(patterns).map(([name, pattern]) => {
+    // Drop g/y so test() doesn't carry lastIndex from one line to the next
+    const matcher = new RegExp(pattern.source, pattern.flags.replace(/[gy]/g, ''));
+    const report: PatternReport = { name, source: pattern.source, flags: pattern.flags, matches: 0, examples: [] };
+    for (const line of lines) {
+      if (!matcher.test(line)) continue;
+      report.matches++;
+      if (report.examples.length < maxExamples) {
+        report.examples.push(line.trim().slice(0, MAX_EXAMPLE_LENGTH));
+      }
+    }
+    return report;
+  });
+}
+
+async function patternsCommand(args: string[], io: CliIO): Promise<number> {
+  let patternFile = process.env[PATTERNS_FILE_ENV]?.trim() || undefined;
+  let maxExamples = DEFAULT_PATTERN_EXAMPLES;
+  let input: string | undefined;
+
+  for (let i = 0; i < args.length; i++) {
+    const arg = args[i];
+    if (arg === '-file' || arg === '--file') {
+      patternFile = args[++i];
+      if (patternFile === undefined) {
+        io.stderr(`-file needs a path\n\n${CLI_USAGE}\n`);
+        return 2;
+      }
+    } else if (arg === '-examples' || arg === '--examples') {
+      maxExamples = Number(args[++i]);
+      if (!Number.isInteger(maxExamples) || maxExamples < 0) {
+        io.stderr(`-examples needs a non-negative integer\n\n${CLI_USAGE}\n`);
+        return 2;
+      }
+    } else if (arg !== '-' && arg.startsWith('-')) {
+      io.stderr(`Unknown flag: ${arg}\n\n${CLI_USAGE}\n`);
+      return 2;
+    } else if (input !== undefined) {
+      io.stderr(`Only one sample may be given\n\n${CLI_USAGE}\n`);
+      return 2;
+    } else {
+      input = arg;
+    }
+  }
+
+  let patterns: Record<string, RegExp>;
+  if (patternFile === undefined) {
+    patterns = builtInPatternSet();
+  } else {
+    let compiled: { patterns: Record<string, RegExp>; errors: string[] };
+    try {
+      compiled = compilePatternFile(io.readFile(patternFile));
+    } catch (err) {
+      io.stderr(`Failed to load ${patternFile}: ${err instanceof Error ? err.message : String(err)}\n`);
+      return 1;
+    }
+    if (compiled.errors.length > 0) {
+      io.stderr(compiled.errors.map((error) => `Invalid pattern ${error}\n`).join(''));
+      return 1;
+    }
+    patterns = compiled.patterns;
+  }
+
+  let sample = '';
+  if (input !== undefined) {
+    try {
+      sample = input === '-' ? await io.readStdin() : io.readFile(input);
+    } catch (err) {
+      io.stderr(`Failed to read ${input}: ${err instanceof Error ? err.message : String(err)}\n`);
+      return 1;
+    }
+  }
+
+  const output = {
+    source: patternFile ?? 'built-in',
+    compiled: Object.keys(patterns).length,
+    ...(input !== undefined && { patterns: samplePatterns(patterns, sample, maxExamples) }),
+  };
+  io.stdout(JSON.stringify(output, null, 2) + '\n');
+  return 0;
+}
+
+async function backfillIdsCommand(args: string[], io: CliIO): Promise<number> {
+  const flag = args.find((arg) => arg.startsWith('-'));
+  if (flag) {
+    io.stderr(`Unknown flag: ${flag}\n\n${CLI_USAGE}\n`);
+    return 2;
+  }
+  if (args.length === 0) {
+    io.stderr(`backfill-ids needs at least one job ID\n\n${CLI_USAGE}\n`);
+    return 2;
+  }
+  if (!io.backfillJob) {
+    io.stderr('backfill-ids needs job storage, which this IO does not provide\n');
+    return 1;
+  }
+
+  const jobs: Array<{ jobId: string; total: number; added: number }> = [];
+  let failed = false;
+  for (const jobId of args) {
+    try {
+      const result = await io.backfillJob(jobId);
+      if (!result) {
+        io.stderr(`${jobId}: no condensed artifact stored\n`);
+        failed = true;
+        continue;
+      }
+      jobs.push({ jobId, ...result });
+    } catch (err) {
+      io.stderr(`${jobId}: ${err instanceof Error ? err.message : String(err)}\n`);
+      failed = true;
+    }
+  }
+  io.stdout(JSON.stringify(jobs, null, 2) + '\n');
+  return failed ? 1 : 0;
+}
+
+/**
+ * Runs the CLI.
+ *
+ * @param argv - Arguments after the script name, starting with the subcommand
+ * @param io - Input/output to use
+ * @returns The process exit code
+ */
+export async function runCli(argv: string[], io: CliIO): Promise<number> {
+  const [command, ...args] = argv;
+  switch (command) {
+    case 'condense':
+      return condenseCommand(args, io);
+    case 'patterns':
+      return patternsCommand(args, io);
+    case 'backfill-ids':
+      return backfillIdsCommand(args, io);
+    case undefined:
+    case '-h':
+    case '--help':
+      io.stderr(`${CLI_USAGE}\n`);
+      return command === undefined ? 2 : 0;
+    default:
+      io.stderr(`Unknown command: ${command}\n\n${CLI_USAGE}\n`);
+      return 2;
+  }
+}
@@ -0,0 +1,105 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Player Colors
+ * =============================================================================
+ *
+ * Tags each kept event with the acting player's color identity (e.g.
+ * ["B", "R"]) so the frontend can color-code the timeline.
+ *
+ * ## Acting player
+ *
+ * Most Forge lines name the actor first ("Land: Ai(1)-Alpha played ...",
+ * "Ai(2)-Beta cast ..."). When a line starts with a player from the color
+ * map (optionally after a "Category: " prefix), that player acts. Otherwise
+ * the ACTIVE player is credited, which is right for most of a turn and
+ * wrong for instant-speed responses that don't name their caster.
+ *
+ * Color map keys are deck names or full log labels; they are matched with
+ * matchesDeckName, so "Blood Rites" also matches "Ai(2)-Blood Rites".
+ *
+ * =============================================================================
+ */
+
+import type { GameEvent } from '../types';
+import type { ClassifyOptions } from './classify';
+import { classifyLines, compactRepeatedEvents, withClassificationRules } from './classify';
+import { EXTRACT_ACTIVE_PLAYER } from './patterns';
+import { matchesDeckName } from './deck-match';
+
+/** Player (deck name or log label) -> color identity, e.g. { "Blood Rites": ["B", "R"] } */
+export type PlayerColorMap = Record<string, string[]>;
+
+const CATEGORY_PREFIX = /^[A-Za-z ]{1,30}:\s*/;
+
+function escapeRegExp(text: string): string {
+  return text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function colorKey(player: string, colors: PlayerColorMap): string | undefined {
+  return Object.keys(colors).find((key) => matchesDeckName(player, key));
+}
+
+/**
+ * Finds the color map key for the player named at the start of a line.
+ * When several keys match, the longest wins ("Ai(1)-Elves" vs "Ai(1)-Elves Deluxe").
+ */
+export function linePlayerKey(line: string, colors: PlayerColorMap): string | undefined {
+  const body = line.trim().replace(CATEGORY_PREFIX, '');
+  let best: string | undefined;
+  for (const key of Object.keys(colors)) {
+    const pattern = new RegExp(`^(?:Ai\\(\\d+\\)-)?${escapeRegExp(key)}(?=\\s|'|$)`);
+    if (pattern.test(body) && (!best || key.length > best.length)) best = key;
+  }
+  return best;
+}
+
+/**
+ * Classifies lines like classifyLines (or classifyLinesCompacted) and tags
+ * each event with its acting player's colors. Events whose player has no
+ * entry in the map are left untagged.
+ *
+ * @param lines - Array of filtered log lines
+ * @param colors - Player -> color identity
+ * @param options - Optional classification options
+ * @param compact - Compact repeated events within each turn
+ * @param lineNumbers - Optional raw-log line number of each line
+ * @returns Array of GameEvent objects
+ */
+export function classifyLinesWithColors(
+  lines: string[],
+  colors: PlayerColorMap,
+  options?: ClassifyOptions,
+  compact = false,
+  lineNumbers?: number[]
+): GameEvent[] {
+  const events: GameEvent[] = [];
+  const prepared = withClassificationRules(options);
+  let turnLines: string[] = [];
+  let turnLineNumbers: number[] = [];
+  let activeKey: string | undefined;
+
+  const flush = () => {
+    const classified = classifyLines(turnLines, prepared, lineNumbers && turnLineNumbers);
+    for (const event of compact ? compactRepeatedEvents(classified) : classified) {
+      const key = linePlayerKey(event.line, colors) ?? activeKey;
+      if (key && colors[key]?.length) event.playerColors = [...colors[key]];
+      events.push(event);
+    }
+    turnLines = [];
+    turnLineNumbers = [];
+  };
+
+  for (const [i, line] of lines.entries()) {
+    const turn = EXTRACT_ACTIVE_PLAYER.exec(line);
+    if (turn) {
+      flush();
+      const player = (turn[1] ?? turn[2])?.trim();
+      activeKey = player ? colorKey(player, colors) : undefined;
+    }
+    turnLines.push(line);
+    if (lineNumbers) turnLineNumbers.push(lineNumbers[i]);
+  }
+  flush();
+
+  return events;
+}
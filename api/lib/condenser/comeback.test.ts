@@ -0,0 +1,141 @@
+/**
+ * Tests for comeback win detection.
+ *
+ * Run with: npx tsx lib/condenser/comeback.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { StructuredGame } from '../types';
+import { buildStructuredGame } from './structured';
+import { wasComebackWin, DEFAULT_COMEBACK } from './comeback';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function makeGame(overrides: Partial<StructuredGame>): StructuredGame {
+  return {
+    totalTurns: 6,
+    players: ['Ai(1)-A', 'Ai(2)-B', 'Ai(3)-C'],
+    turns: [],
+    decks: [],
+    winner: 'Ai(1)-A',
+    ...overrides,
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running comeback win tests...\n');
+
+  await test('comeback fixture: winner at 12 life mid-game is flagged', () => {
+    const log = fs.readFileSync(path.join(__dirname, 'fixtures', 'comeback-log.txt'), 'utf-8');
+    const game = buildStructuredGame(log);
+    assertEqual(game.winner, 'Ai(1)-Alpha', 'winner');
+    assertEqual(game.lifePerTurn?.[3]?.['Ai(1)-Alpha'], 12, 'low life at the midpoint');
+    assertEqual(wasComebackWin(game), true, 'comeback');
+    assertEqual(game.comebackWin, true, 'surfaced on the structured game');
+  });
+
+  await test('wasComebackWin: a winner ahead on life is not a comeback', () => {
+    const game = makeGame({
+      lifePerTurn: { 3: { 'Ai(1)-A': 40, 'Ai(2)-B': 20, 'Ai(3)-C': 25 } },
+    });
+    assertEqual(wasComebackWin(game), false, 'leader won');
+  });
+
+  await test('wasComebackWin: last on board counts too', () => {
+    const game = makeGame({
+      boardDevelopmentPerTurn: {
+        1: { 'Ai(1)-A': 1, 'Ai(2)-B': 2, 'Ai(3)-C': 2 },
+        3: { 'Ai(2)-B': 3, 'Ai(3)-C': 1 },
+        5: { 'Ai(1)-A': 6 },
+      },
+    });
+    assertEqual(wasComebackWin(game), true, '1 permanent vs 5 at round 3');
+    assertEqual(wasComebackWin(game, { ...DEFAULT_COMEBACK, minBoardGap: 5 }), false, 'custom gap');
+  });
+
+  await test('wasComebackWin: thresholds are configurable', () => {
+    const game = makeGame({
+      lifePerTurn: { 3: { 'Ai(1)-A': 32, 'Ai(2)-B': 40, 'Ai(3)-C': 38 } },
+    });
+    assertEqual(wasComebackWin(game), false, '8 behind is under the default gap');
+    assertEqual(wasComebackWin(game, { ...DEFAULT_COMEBACK, minLifeGap: 5 }), true, 'smaller gap');
+  });
+
+  await test('wasComebackWin: eliminated players and missing data', () => {
+    const withDead = makeGame({
+      lifePerTurn: { 3: { 'Ai(1)-A': 10, 'Ai(2)-B': 0, 'Ai(3)-C': 30 } },
+    });
+    assertEqual(wasComebackWin(withDead), true, 'dead player at 0 is ignored');
+    assertEqual(wasComebackWin(makeGame({})), false, 'no life or board data');
+    assertEqual(wasComebackWin(makeGame({ winner: undefined, lifePerTurn: withDead.lifePerTurn })), false, 'no winner');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,104 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Comeback Wins
+ * =============================================================================
+ *
+ * Flags wins where the eventual winner was behind at the game's pivotal
+ * round: a sign of a resilient deck (or a lucky topdeck).
+ *
+ * ## Heuristic
+ *
+ * The pivotal round is `pivotFraction` of the way through the game (the
+ * midpoint by default). At the end of that round the winner must be in
+ * sole last place among the players still in the game on either:
+ *
+ *   - life, at least `minLifeGap` below the life leader, or
+ *   - board (permanents entered so far), at least `minBoardGap` behind the
+ *     board leader.
+ *
+ * Needs the structured game's lifePerTurn (Forge `[LIFE]` entries) or
+ * boardDevelopmentPerTurn; without either, no game is a comeback.
+ *
+ * =============================================================================
+ */
+
+import type { StructuredGame } from '../types';
+import { matchesDeckName } from './deck-match';
+
+/**
+ * Thresholds for wasComebackWin.
+ */
+export interface ComebackOptions {
+  /** How far through the game the pivotal round is (0-1] */
+  pivotFraction: number;
+  /** Life the winner must trail the life leader by */
+  minLifeGap: number;
+  /** Permanents the winner must trail the board leader by */
+  minBoardGap: number;
+}
+
+export const DEFAULT_COMEBACK: ComebackOptions = {
+  pivotFraction: 0.5,
+  minLifeGap: 10,
+  minBoardGap: 3,
+};
+
+/**
+ * True when `values` has the winner strictly last, at least `gap` behind
+ * the leader.
+ */
+function trailsField(values: Record<string, number>, winnerKey: string, gap: number): boolean {
+  const winnerValue = values[winnerKey];
+  const others = Object.entries(values).filter(([player]) => player !== winnerKey).map(([, v]) => v);
+  if (winnerValue === undefined || others.length === 0) return false;
+  return winnerValue < Math.min(...others) && Math.max(...others) - winnerValue >= gap;
+}
+
+function findKey(keys: string[], winner: string): string | undefined {
+  return keys.find((k) => matchesDeckName(k, winner) || matchesDeckName(winner, k));
+}
+
+/**
+ * Decides whether a game's winner came from behind.
+ *
+ * @param game - A structured game
+ * @param options - Pivot and gap thresholds
+ * @returns true for a comeback win; false for no winner or missing data
+ */
+export function wasComebackWin(
+  game: StructuredGame,
+  options: ComebackOptions = DEFAULT_COMEBACK
+): boolean {
+  if (!game.winner) return false;
+  const rounds = Object.keys(game.lifePerTurn ?? game.boardDevelopmentPerTurn ?? {}).map(Number);
+  const lastRound = Math.max(game.totalTurns, ...rounds, 0);
+  if (lastRound < 2) return false;
+  const pivot = Math.max(1, Math.min(lastRound - 1, Math.ceil(lastRound * options.pivotFraction)));
+
+  // Players at 0 or less life by the pivot are out and don't count
+  const life = game.lifePerTurn?.[pivot];
+  const alive = life
+    ? Object.keys(life).filter((player) => life[player] > 0)
+    : game.players;
+
+  if (life) {
+    const key = findKey(alive, game.winner);
+    const standing = Object.fromEntries(alive.map((p) => [p, life[p]]));
+    if (key && trailsField(standing, key, options.minLifeGap)) return true;
+  }
+
+  if (game.boardDevelopmentPerTurn) {
+    const board: Record<string, number> = Object.fromEntries(alive.map((p) => [p, 0]));
+    for (const [round, counts] of Object.entries(game.boardDevelopmentPerTurn)) {
+      if (Number(round) > pivot) continue;
+      for (const [player, count] of Object.entries(counts)) {
+        const key = findKey(alive, player);
+        if (key) board[key] += count;
+      }
+    }
+    const key = findKey(alive, game.winner);
+    if (key && trailsField(board, key, options.minBoardGap)) return true;
+  }
+
+  return false;
+}
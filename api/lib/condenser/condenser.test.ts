@@ -6,10 +6,32 @@
 
 import * as fs from 'fs';
 import * as path from 'path';
-import { condenseGame, condenseGames } from './index';
-import { extractWinner, extractWinningTurn, getNumPlayers, extractTurnRanges, calculatePerDeckTurns } from './turns';
-import { splitConcatenatedGames } from './patterns';
-import { matchesDeckName } from './deck-match';
+import { condenseGame, condenseGames, structureGame } from './index';
+import { extractWinner, trimWinnerCapture, getWinnerCapture, extractWinners, extractSimultaneousWinners, resolveWinner, getSimultaneousWinScoring, SIMULTANEOUS_WIN_SCORING_ENV, extractWinningTurn, getNumPlayers, extractTurnRanges, calculatePerDeckTurns, detectLockEffect, lifeLossRatePerTurn, isFastClock, extractLastStanding, detectLockStall, eventsPerRound, creatureDeathsPerRound, firstBloodRound, castCmcHistogram, CMC_UNKNOWN, calculateCastsPerTurn, eliminatedPlayerOf, isEliminated, detectFormat, indexTurns, matchWinner } from './turns';
+import { classifyLine, compactRepeatedEvents, SPELL_CAST_EVENT_TYPES, DEFAULT_CLASSIFICATION_PRIORITY } from './classify';
+import { splitConcatenatedGames, compileWinLinePattern, WINNER_CAPTURE_ENV, buildAltCostPattern, ALT_COST_KEYWORDS, KEEP_ALT_COST_CAST, buildProtectionPattern, PROTECTION_KEYWORDS, KEEP_PROTECTION } from './patterns';
+import { matchesDeckName, resolveWinnerName } from './deck-match';
+import { calculateLibraryStats } from './library';
+import { extractKillingBlow } from './kill';
+import { boardDevelopmentPerTurn } from './board';
+import { calculateGoadStats } from './goad';
+import { extractAiProfiles, normalizeAiProfile } from './ai-profile';
+import { extractMulliganDetails } from './mulligan';
+import { backfillGameIds, gameIdFromCondensed, gameIdFromLog, GAME_ID_LENGTH } from './game-id';
+import { bigTurns, isBigTurn } from './big-turns';
+import { offTurnActions } from './off-turn';
+import { interactionReceived, INTERACTION_UNKNOWN } from './interaction';
+import { normalizeUnmatchedLine, tallyUnmatchedLines, buildUnmatchedSample } from './unmatched';
+import { buildHighlights, parseHighlightKinds, HIGHLIGHT_KINDS } from './highlights';
+import { protectedCombo, protectionPerRound, DEFAULT_PROTECTED_COMBO } from './protected-combo';
+import { recurringEngines } from './engines';
+import { cardTypeProfile, extractCardTypeHints, CARD_TYPE_UNKNOWN } from './card-types';
+import { archenemy, archenemyCounts, attacksReceived, boardPresence } from './archenemy';
+import { aggressionIndex, earlyDamageDealt } from './aggression';
+import { cardsDrawnByPlayer } from './draws';
+import { extraTurnCombo, extraTurnComboCounts, extraTurnsTaken } from './extra-turns';
+import { classifyLifePayment, opponentLifeLoss, selfLifePayments } from './life-payments';
+import { isLowSignal, parseLowSignalThreshold, getLowSignalThreshold, DEFAULT_LOW_SIGNAL, LOW_SIGNAL_THRESHOLD_ENV } from './low-signal';
 
 // ---------------------------------------------------------------------------
 // Test Utilities (same pattern as game-logs.test.ts)
@@ -99,6 +121,62 @@ async function runTests() {
     assertEqual(games.length, 0, 'whitespace input should return empty array');
   });
 
+  const turnResetLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'turn-reset-log.txt'), 'utf-8');
+
+  // The same two games with no win line before the reset
+  const turnResetNoWinLog = turnResetLog.replace('Game outcome: Ai(2)-Beta has won because all opponents have lost\n', '');
+
+  await test('splitConcatenatedGames: result-line strategy keeps turn-reset games together', () => {
+    assertEqual(splitConcatenatedGames(turnResetNoWinLog).length, 1, 'default strategy');
+    assertEqual(splitConcatenatedGames(turnResetNoWinLog, { strategy: 'result-line' }).length, 1, 'explicit strategy');
+  });
+
+  await test('splitConcatenatedGames: result-line strategy splits at a turn 1 after a win line', () => {
+    const games = splitConcatenatedGames(turnResetLog);
+    assertEqual(games.length, 2, 'game count');
+    assertEqual(extractWinner(games[0]), 'Ai(2)-Beta', 'game 1 winner');
+    assertEqual(extractWinner(games[1]), 'Ai(1)-Alpha', 'game 2 winner');
+    assert(games[1].startsWith('Turn: Turn 1 (Ai(2)-Beta)'), 'game 2 starts at its turn 1');
+  });
+
+  const bestOfThreeLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'best-of-three-log.txt'), 'utf-8');
+
+  await test('splitConcatenatedGames: best-of-3 file yields one winner per game', () => {
+    const games = splitConcatenatedGames(bestOfThreeLog);
+    assertEqual(games.length, 3, 'game count');
+    const condensed = condenseGames(games);
+    assertEqual(JSON.stringify(condensed.map((g) => g.winner)), JSON.stringify(['Ai(1)-Alpha', 'Ai(2)-Beta', 'Ai(1)-Alpha']), 'winners');
+    assertEqual(JSON.stringify(condensed.map((g) => g.turnCount)), JSON.stringify([2, 3, 3]), 'turn counts');
+    assert(games[0].includes('Mulligan: Ai(2)-Beta'), 'pregame lines stay with the previous game');
+  });
+
+  await test('matchWinner: tallies game wins and declares the match winner', () => {
+    const match = matchWinner(condenseGames(splitConcatenatedGames(bestOfThreeLog)));
+    assertEqual(JSON.stringify(match.wins), JSON.stringify({ 'Ai(1)-Alpha': 2, 'Ai(2)-Beta': 1 }), 'wins');
+    assertEqual(match.winner, 'Ai(1)-Alpha', 'match winner');
+  });
+
+  await test('matchWinner: no winner on a tie or without game winners', () => {
+    assertEqual(matchWinner([{ winner: 'A' }, { winner: 'B' }, {}]).winner, undefined, 'tie');
+    assertEqual(JSON.stringify(matchWinner([{}, {}])), JSON.stringify({ wins: {} }), 'no winners');
+    assertEqual(matchWinner([{ winner: 'A' }]).winner, 'A', 'single game');
+  });
+
+  await test('splitConcatenatedGames: turn-reset strategy splits where the turn counter resets', () => {
+    const games = splitConcatenatedGames(turnResetLog, { strategy: 'turn-reset' });
+    assertEqual(games.length, 2, 'game count');
+    assertEqual(extractWinner(games[0]), 'Ai(2)-Beta', 'game 1 winner');
+    assertEqual(extractWinner(games[1]), 'Ai(1)-Alpha', 'game 2 winner');
+    assert(games[1].startsWith('Turn: Turn 1 (Ai(2)-Beta)'), 'game 2 starts at its turn 1');
+    assertEqual(extractTurnRanges(games[0]).length, 4, 'game 1 turn segments');
+    assertEqual(extractTurnRanges(games[1]).length, 3, 'game 2 turn segments');
+  });
+
+  await test('splitConcatenatedGames: turn-reset strategy leaves result-line splits intact', () => {
+    const games = splitConcatenatedGames(rawLog, { strategy: 'turn-reset' });
+    assertEqual(games.length, 4, 'game count');
+  });
+
   // =========================================================================
   // extractWinner
   // =========================================================================
@@ -262,6 +340,52 @@ async function runTests() {
     }
   });
 
+  // =========================================================================
+  // Decorated win lines (WINNER_CAPTURE)
+  // =========================================================================
+
+  await test('extractWinner: decorated win lines capture just the name, which maps to a deck', () => {
+    const decorated = fs.readFileSync(path.join(__dirname, 'fixtures', 'decorated-win-log.txt'), 'utf-8');
+    assertEqual(extractWinner(decorated), 'Alice', '"Turn 7: Alice attacks, Alice wins the game"');
+    assertEqual(resolveWinnerName(extractWinner(decorated)!, ['Alice', 'Bob']), 'Alice', 'maps to a deck');
+
+    const gameResult = fs.readFileSync(path.join(__dirname, 'fixtures', 'game-result-win-log.txt'), 'utf-8');
+    assertEqual(extractWinner(gameResult), 'Ai(2)-Goblin Swarm', 'Game Result prefix stripped');
+    assertEqual(resolveWinnerName(extractWinner(gameResult)!, ['Four Color Counters', 'Goblin Swarm']), 'Goblin Swarm', 'maps to a deck');
+
+    const commander = fs.readFileSync(path.join(__dirname, 'fixtures', 'commander-winner-log.txt'), 'utf-8');
+    assertEqual(extractWinner(commander), "Atraxa, Praetors' Voice", 'a name with a comma is kept whole');
+  });
+
+  await test('trimWinnerCapture: strips known prefixes and cuts to a known player', () => {
+    const players = ['Ai(1)-Alpha', 'Alpha'];
+    assertEqual(trimWinnerCapture('Game outcome: Ai(1)-Alpha', players, 'strict'), 'Ai(1)-Alpha', 'Game outcome');
+    assertEqual(trimWinnerCapture('Turn: Turn 9 (Ai(1)-Alpha): Ai(1)-Alpha', players, 'strict'), 'Ai(1)-Alpha', 'current turn marker');
+    assertEqual(trimWinnerCapture('Ai(2)-Beta concedes; Ai(1)-Alpha', players, 'strict'), 'Ai(1)-Alpha', 'longest player wins');
+    assertEqual(trimWinnerCapture('Betalpha', ['Alpha'], 'strict'), 'Betalpha', 'only whole names');
+    assertEqual(trimWinnerCapture('Unknown Player', players, 'strict'), 'Unknown Player', 'unknown players kept');
+  });
+
+  await test('extractWinner: WINNER_CAPTURE=legacy keeps the old capture', () => {
+    const decorated = fs.readFileSync(path.join(__dirname, 'fixtures', 'decorated-win-log.txt'), 'utf-8');
+    const previous = process.env[WINNER_CAPTURE_ENV];
+    try {
+      process.env[WINNER_CAPTURE_ENV] = 'legacy';
+      assertEqual(extractWinner(decorated), 'Turn 7: Alice attacks, Alice', 'legacy capture');
+      process.env[WINNER_CAPTURE_ENV] = 'greedy';
+      let threw = false;
+      try {
+        getWinnerCapture();
+      } catch (err) {
+        threw = err instanceof Error && err.message.startsWith(`Invalid ${WINNER_CAPTURE_ENV}`);
+      }
+      assert(threw, 'unknown mode rejected');
+    } finally {
+      if (previous === undefined) delete process.env[WINNER_CAPTURE_ENV];
+      else process.env[WINNER_CAPTURE_ENV] = previous;
+    }
+  });
+
   // =========================================================================
   // Per-deck turn counting (calculatePerDeckTurns)
   // =========================================================================
@@ -361,6 +485,1529 @@ async function runTests() {
     }
   });
 
+  // =========================================================================
+  // Lock effects ("can't lose / can't win")
+  // =========================================================================
+
+  const lockLog = [
+    'Turn: Turn 1 (Ai(1)-Angel Deck)',
+    'Add to stack: Ai(1)-Angel Deck cast Platinum Angel (CMC 7)',
+    'Resolve stack: Platinum Angel - You can\'t lose the game and your opponents can\'t win the game.',
+    'Turn: Turn 2 (Ai(2)-Burn Deck)',
+    'Effect: Players can\'t lose the game.',
+    '[LIFE] Life: Ai(1)-Angel Deck 3 -> -2',
+    'Turn: Turn 3 (Ai(1)-Angel Deck)',
+  ].join('\n');
+
+  await test('detectLockEffect: flags "can\'t lose the game" lines', () => {
+    assert(detectLockEffect(lockLog), 'lock log should be flagged');
+  });
+
+  await test('detectLockEffect: real games have no lock effect', () => {
+    const games = splitConcatenatedGames(rawLog);
+    for (let i = 0; i < games.length; i++) {
+      assert(!detectLockEffect(games[i]), `Game ${i + 1} should not be flagged`);
+    }
+  });
+
+  await test('condenseGame: lockEffectDetected set and no winner for lock log', () => {
+    const condensed = condenseGame(lockLog);
+    assertEqual(condensed.lockEffectDetected, true, 'lockEffectDetected');
+    assertEqual(condensed.winner, undefined, 'lock line must not register a winner');
+    assert(
+      !condensed.keptEvents.some((e) => e.type === 'win_condition'),
+      'lock lines must not be classified as win_condition'
+    );
+  });
+
+  await test('condenseGame: lockEffectDetected omitted for normal games', () => {
+    const games = splitConcatenatedGames(rawLog);
+    const condensed = condenseGame(games[0]);
+    assertEqual(condensed.lockEffectDetected, undefined, 'lockEffectDetected');
+  });
+
+  await test('classifyLine: "can\'t win the game" is not a win_condition', () => {
+    assert(
+      classifyLine('Your opponents can\'t win the game.') !== 'win_condition',
+      'lock line should not classify as a win'
+    );
+    assertEqual(classifyLine('Ai(1)-Angel Deck wins the game.'), 'win_condition', 'real win line');
+  });
+
+  // =========================================================================
+  // Indented / prefixed turn markers
+  // =========================================================================
+
+  for (const name of ['indented-turns-log.txt', 'timestamped-turns-log.txt']) {
+    const prefixedLog = fs.readFileSync(path.join(__dirname, 'fixtures', name), 'utf-8');
+
+    await test(`extractTurnRanges: ${name} segments into 6 turns`, () => {
+      const ranges = extractTurnRanges(prefixedLog);
+      assertEqual(ranges.length, 6, 'turn marker count');
+      assertEqual(ranges.map((r) => r.turnNumber).join(','), '1,2,3,4,5,6', 'turn numbers');
+      assertEqual(ranges[0].player, 'Ai(1)-Alpha', 'first active player');
+      assertEqual(ranges[5].player, 'Ai(2)-Beta', 'last active player');
+    });
+
+    await test(`condenseGame: ${name} has correct turn count and winning turn`, () => {
+      const condensed = condenseGame(prefixedLog);
+      assertEqual(condensed.turnCount, 3, 'rounds');
+      assertEqual(condensed.winner, 'Ai(2)-Beta', 'winner');
+      assertEqual(condensed.winningTurn, 3, 'Beta took 3 turns');
+    });
+  }
+
+  // =========================================================================
+  // Mill & library manipulation
+  // =========================================================================
+
+  const millLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'mill-scry-log.txt'), 'utf-8');
+
+  await test('classifyLine: mill and scry/surveil lines', () => {
+    assertEqual(classifyLine('Zone Change: Ai(1)-Alpha milled Entomb (14) and Reanimate (15).'), 'mill', 'Forge milled');
+    assertEqual(classifyLine('Player A mills 3 cards.'), 'mill', 'mills N cards');
+    assertEqual(
+      classifyLine('Player A puts the top 4 cards of their library into their graveyard.'),
+      'mill',
+      'top N cards into graveyard'
+    );
+    assertEqual(classifyLine('Resolve stack: Ai(1)-Alpha scried 1 card(s) to the top of the library'), 'library_manip', 'scried');
+    assertEqual(classifyLine('Player A scries 2.'), 'library_manip', 'scries');
+    assertEqual(classifyLine('Player A surveils 2.'), 'library_manip', 'surveils');
+    assertEqual(classifyLine('When Temple of Mystery enters, scry 1.'), null, 'rules text is not a scry');
+  });
+
+  await test('calculateLibraryStats: separates self-mill from opponent-mill', () => {
+    const stats = calculateLibraryStats(millLog);
+    assertEqual(stats.mill, 3, 'mill lines');
+    assertEqual(stats.selfMill, 2, 'Alpha milled on own turn twice');
+    assertEqual(stats.opponentMill, 1, 'Alpha milled on Beta turn');
+    assertEqual(stats.libraryManip, 2, 'one scry and one surveil');
+  });
+
+  await test('condenseGame: mill and library manipulation counts', () => {
+    const condensed = condenseGame(millLog);
+    assertEqual(condensed.millCount, 3, 'millCount');
+    assertEqual(condensed.selfMillCount, 2, 'selfMillCount');
+    assertEqual(condensed.opponentMillCount, 1, 'opponentMillCount');
+    assertEqual(condensed.libraryManipCount, 2, 'libraryManipCount');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'mill').length, 3, 'mill events kept');
+  });
+
+  await test('condenseGame: no mill fields when nothing is milled', () => {
+    const condensed = condenseGame(fs.readFileSync(path.join(__dirname, 'fixtures', 'indented-turns-log.txt'), 'utf-8'));
+    assertEqual(condensed.millCount, undefined, 'millCount omitted');
+    assertEqual(condensed.libraryManipCount, undefined, 'libraryManipCount omitted');
+  });
+
+  // =========================================================================
+  // Last player standing
+  // =========================================================================
+
+  const lastStandingLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'last-standing-log.txt'), 'utf-8');
+
+  await test('condenseGame: credits the last player standing when no win line exists', () => {
+    assertEqual(extractWinner(lastStandingLog), undefined, 'no explicit win line');
+    const condensed = condenseGame(lastStandingLog);
+    assertEqual(condensed.winner, 'Ai(1)-Alpha', 'survivor credited');
+    assertEqual(condensed.winReason, 'last_standing', 'winReason');
+    assertEqual(condensed.winningTurn, 3, "survivor's turn count");
+  });
+
+  await test('condenseGame: explicit win line leaves winReason unset', () => {
+    const condensed = condenseGame(lastStandingLog + '\nGame outcome: Ai(1)-Alpha has won because all opponents have lost\n');
+    assertEqual(condensed.winner, 'Ai(1)-Alpha', 'winner from win line');
+    assertEqual(condensed.winReason, undefined, 'not inferred');
+  });
+
+  await test('extractLastStanding: no inference while two players remain', () => {
+    const twoLeft = lastStandingLog.split('\n').filter((l) => !l.includes('Ai(4)-Delta has lost')).join('\n');
+    assertEqual(extractLastStanding(twoLeft), undefined, 'two survivors');
+    assertEqual(condenseGame(twoLeft).winner, undefined, 'no winner');
+  });
+
+  // =========================================================================
+  // Simultaneous wins
+  // =========================================================================
+
+  const simultaneousWinLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'simultaneous-win-log.txt'), 'utf-8');
+
+  await test('extractWinners: one entry per winner, repeated win lines folded', () => {
+    const winners = extractWinners(simultaneousWinLog).map((w) => w.winner);
+    assertEqual(JSON.stringify(winners), JSON.stringify(['Ai(1)-Alpha', 'Ai(2)-Beta']), 'Game outcome / Game Result repeat Alpha');
+    assertEqual(extractWinners(rawLog).length > 1, true, 'concatenated games list each winner');
+    const altWin = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      "Game outcome: Ai(2)-Beta has lost because an opponent has won by spell Thassa's Oracle",
+      'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+    ].join('\n');
+    assertEqual(JSON.stringify(extractWinners(altWin).map((w) => w.winner)), JSON.stringify(['Ai(1)-Alpha']), 'loss line is not a win line');
+  });
+
+  await test('extractSimultaneousWinners: two win lines in one turn', () => {
+    assertEqual(
+      JSON.stringify(extractSimultaneousWinners(simultaneousWinLog)),
+      JSON.stringify(['Ai(1)-Alpha', 'Ai(2)-Beta']),
+      'both winners'
+    );
+    assertEqual(extractSimultaneousWinners(bestOfThreeLog).length, 0, 'wins in different games are not simultaneous');
+    for (const game of splitConcatenatedGames(rawLog)) {
+      assertEqual(extractSimultaneousWinners(game).length, 0, 'a single winner');
+    }
+  });
+
+  await test('resolveWinner: simultaneous wins score as a draw or a shared win', () => {
+    const draw = resolveWinner(simultaneousWinLog, 'draw');
+    assertEqual(draw.winner, undefined, 'draw: no winner');
+    assertEqual(draw.simultaneousWinners?.length, 2, 'draw: winners listed');
+    const shared = resolveWinner(simultaneousWinLog, 'shared');
+    assertEqual(shared.winner, 'Ai(1)-Alpha', 'shared: first winner');
+    assertEqual(shared.simultaneousWinners?.length, 2, 'shared: winners listed');
+  });
+
+  await test('condenseGame: simultaneousWin follows SIMULTANEOUS_WIN_SCORING', () => {
+    const previous = process.env[SIMULTANEOUS_WIN_SCORING_ENV];
+    try {
+      delete process.env[SIMULTANEOUS_WIN_SCORING_ENV];
+      const draw = condenseGame(simultaneousWinLog);
+      assertEqual(draw.simultaneousWin, true, 'flagged');
+      assertEqual(draw.winner, undefined, 'a draw by default');
+      assertEqual(structureGame(simultaneousWinLog).endReason, 'draw', 'structured endReason');
+
+      process.env[SIMULTANEOUS_WIN_SCORING_ENV] = 'Shared';
+      const shared = condenseGame(simultaneousWinLog);
+      assertEqual(shared.winner, 'Ai(1)-Alpha', 'shared win');
+      assertEqual(JSON.stringify(shared.simultaneousWinners), JSON.stringify(['Ai(1)-Alpha', 'Ai(2)-Beta']), 'winners');
+      assertEqual(structureGame(simultaneousWinLog).simultaneousWin, true, 'structured flag');
+
+      process.env[SIMULTANEOUS_WIN_SCORING_ENV] = 'coinflip';
+      let threw = false;
+      try {
+        getSimultaneousWinScoring();
+      } catch (err) {
+        threw = err instanceof Error && err.message.startsWith(`Invalid ${SIMULTANEOUS_WIN_SCORING_ENV}`);
+      }
+      assert(threw, 'unknown scoring rejected');
+    } finally {
+      if (previous === undefined) delete process.env[SIMULTANEOUS_WIN_SCORING_ENV];
+      else process.env[SIMULTANEOUS_WIN_SCORING_ENV] = previous;
+    }
+    assertEqual(condenseGame(splitConcatenatedGames(rawLog)[0]).simultaneousWin, undefined, 'unset for a single winner');
+  });
+
+  // =========================================================================
+  // Raw line numbers
+  // =========================================================================
+
+  await test('condenseGame: includeLineNumbers maps events back to the raw log', () => {
+    const game = splitConcatenatedGames(rawLog)[0];
+    const rawLines = game.split(/\r?\n/);
+    const condensed = condenseGame(game, { includeLineNumbers: true });
+    assert(condensed.keptEvents.length > 0, 'has events');
+    for (const event of condensed.keptEvents) {
+      assert(event.lineNo !== undefined, `lineNo set on ${event.line}`);
+      assertEqual(rawLines[event.lineNo! - 1].trim().slice(0, 200), event.line, `line ${event.lineNo}`);
+    }
+    assertEqual(condenseGame(game).keptEvents[0].lineNo, undefined, 'off by default');
+  });
+
+  await test('condenseGame: line numbers survive compaction and color tagging', () => {
+    const game = splitConcatenatedGames(rawLog)[0];
+    const rawLines = game.split(/\r?\n/);
+    for (const options of [
+      { includeLineNumbers: true, compactRepeats: true },
+      { includeLineNumbers: true, playerColors: { 'Ai(1)-Doran Big Butts': ['B', 'G', 'W'] } },
+    ]) {
+      for (const event of condenseGame(game, options).keptEvents) {
+        assertEqual(rawLines[event.lineNo! - 1].trim().slice(0, 200), event.line, `line ${event.lineNo}`);
+      }
+    }
+  });
+
+  // =========================================================================
+  // Clone / copy effects
+  // =========================================================================
+
+  const cloneLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'clone-log.txt'), 'utf-8');
+
+  await test('classifyLine: clone and copy lines', () => {
+    assertEqual(classifyLine('Zone Change: Clone (3) enters the battlefield as a copy of Craterhoof Behemoth (9).'), 'clone', 'enters as a copy (not high CMC)');
+    assertEqual(classifyLine("Resolve stack: Cytoshape (4) - Ai(2)-Beta's Llanowar Elves (2) becomes a copy of Clone (3) until end of turn."), 'clone', 'becomes a copy');
+    assertEqual(classifyLine('Resolve stack: Ai(1)-Alpha creates a token copy of Clone (3).'), 'clone', 'token copy');
+  });
+
+  await test('condenseGame: cloneCount counts each copy once', () => {
+    const condensed = condenseGame(cloneLog);
+    assertEqual(condensed.cloneCount, 3, 'three copy lines');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'clone').length, 3, 'clone events');
+    assertEqual(condenseGame(lastStandingLog).cloneCount, undefined, 'omitted without clones');
+  });
+
+  await test('boardDevelopmentPerTurn: a clone is a permanent, a token copy is a token', () => {
+    // Round 2: Clone enters as a copy; round 3: the token copy enters
+    assertEqual(boardDevelopmentPerTurn(cloneLog)[2]?.['Ai(1)-Alpha'], 1, 'clone counted as a permanent');
+    assertEqual(boardDevelopmentPerTurn(cloneLog)[3], undefined, 'token copy excluded by default');
+    assertEqual(boardDevelopmentPerTurn(cloneLog, { includeTokens: true })[3]?.['Ai(1)-Alpha'], 1, 'token copy counted once with tokens');
+  });
+
+  // =========================================================================
+  // Goad
+  // =========================================================================
+
+  const goadLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'goad-log.txt'), 'utf-8');
+
+  await test('classifyLine: goad and forced-attack lines', () => {
+    assertEqual(classifyLine("Resolve stack: Vengeful Ancestor (1) - Ai(2)-Beta's Grizzly Bears (3) is goaded."), 'goad', 'is goaded (not high CMC)');
+    assertEqual(classifyLine("Resolve stack: Disrupt Decorum (4) - Ai(1)-Alpha goads each creature you don't control."), 'goad', 'goads');
+    assertEqual(classifyLine('Effect: Grizzly Bears (3) must attack if able.'), 'goad', 'must attack if able');
+    assertEqual(classifyLine('Effect: Grizzly Bears (3) must attack each combat if able.'), 'goad', 'must attack each combat');
+    assertEqual(classifyLine('Combat: Ai(2)-Beta assigned Grizzly Bears (3) to attack Ai(1)-Alpha.'), 'combat', 'attack stays combat');
+  });
+
+  await test('condenseGame: a goaded attack is marked, voluntary attacks are not', () => {
+    const condensed = condenseGame(goadLog);
+    assertEqual(condensed.goadCount, 1, 'one goad line');
+    const attacks = condensed.keptEvents.filter((e) => e.type === 'combat');
+    assertEqual(attacks.length, 4, 'attack events');
+    assertEqual(attacks.map((e) => e.goaded === true).join(','), 'false,true,false,false', 'only the forced attack');
+    assert(attacks[1].line.includes('to attack Ai(3)-Gamma'), 'the goaded attack is away from the goader');
+    assertEqual(condenseGame(cloneLog).goadCount, undefined, 'omitted without goad');
+  });
+
+  await test('calculateGoadStats: goad expires on the goader\'s next turn', () => {
+    const stats = calculateGoadStats(goadLog.split('\n'));
+    assertEqual(stats.goadedAttackLines.size, 1, 'the attack after the goader\'s next turn is voluntary');
+  });
+
+  await test('calculateGoadStats: mass goad forces every other player\'s attacks', () => {
+    const lines = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      "Resolve stack: Disrupt Decorum (4) - Ai(1)-Alpha goads each creature you don't control.",
+      'Combat: Ai(1)-Alpha assigned Goblin Guide (1) to attack Ai(2)-Beta.',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+      'Combat: Ai(2)-Beta assigned Grizzly Bears (3) to attack Ai(3)-Gamma.',
+      'Turn: Turn 3 (Ai(3)-Gamma)',
+      'Combat: Ai(3)-Gamma assigned Walking Corpse (2) to attack Ai(2)-Beta.',
+      'Turn: Turn 4 (Ai(1)-Alpha)',
+      'Turn: Turn 5 (Ai(2)-Beta)',
+      'Combat: Ai(2)-Beta assigned Grizzly Bears (3) to attack Ai(1)-Alpha.',
+    ];
+    const stats = calculateGoadStats(lines);
+    assertEqual(stats.goadCount, 1, 'goad lines');
+    assertEqual(
+      [...stats.goadedAttackLines].map((l) => /Ai\((\d)\)-\w+ assigned/.exec(l)?.[1]).join(','),
+      '2,3',
+      "Beta's and Gamma's attacks before Alpha's next turn"
+    );
+  });
+
+  // =========================================================================
+  // AI profiles
+  // =========================================================================
+
+  const aiProfileLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'ai-profile-log.txt'), 'utf-8');
+
+  await test('extractAiProfiles: maps each seat to its normalized profile', () => {
+    const profiles = extractAiProfiles(aiProfileLog);
+    assertEqual(JSON.stringify(profiles), JSON.stringify({
+      'Ai(1)-Alpha': 'Default',
+      'Ai(2)-Beta': 'Reckless',
+      'Ai(3)-Gamma': 'Cautious',
+    }), 'per-player profiles (last line per seat wins)');
+    assertEqual(JSON.stringify(condenseGame(aiProfileLog).aiProfiles), JSON.stringify(profiles), 'on the condensed game');
+  });
+
+  await test('extractAiProfiles: empty without profile lines', () => {
+    assertEqual(Object.keys(extractAiProfiles(goadLog)).length, 0, 'no profiles');
+    assertEqual(condenseGame(goadLog).aiProfiles, undefined, 'omitted from the condensed game');
+    assertEqual(Object.keys(extractAiProfiles('Stack: Ai(1)-Alpha cast Sol Ring (1)\nCombat: Ai(1)-Alpha assigned Goblin Guide (1) to attack Ai(2)-Beta.')).length, 0, 'ordinary lines');
+  });
+
+  // =========================================================================
+  // Game IDs
+  // =========================================================================
+
+  const mulliganLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'mulligan-log.txt'), 'utf-8');
+
+  await test('gameIdFromLog: same log gives the same ID, different logs differ', () => {
+    const id = gameIdFromLog(mulliganLog);
+    assertEqual(id.length, GAME_ID_LENGTH, 'length');
+    assert(/^[0-9a-f]+$/.test(id), 'hex');
+    assertEqual(gameIdFromLog(mulliganLog), id, 'deterministic');
+    assertEqual(gameIdFromLog(mulliganLog.replace(/\n/g, '\r\n') + '\r\n'), id, 'line endings and trailing whitespace ignored');
+    assert(gameIdFromLog(goadLog) !== id, 'different game');
+    assert(gameIdFromLog(mulliganLog.replace('down to 5', 'down to 4')) !== id, 'one line changed');
+  });
+
+  await test('condenseGame: gameId is stable across re-runs and batch order', () => {
+    const id = condenseGame(mulliganLog).gameId;
+    assertEqual(id, gameIdFromLog(mulliganLog), 'set on the condensed game');
+    const [a, b] = condenseGames([goadLog, mulliganLog]);
+    const [c, d] = condenseGames([mulliganLog, goadLog]);
+    assertEqual(b.gameId, c.gameId, 'same game, different index');
+    assertEqual(a.gameId, d.gameId, 'other game, different index');
+  });
+
+  await test('backfillGameIds: adds IDs from raw logs, or from the condensed game without them', () => {
+    const legacy = condenseGames([mulliganLog, goadLog]).map(({ gameId: _gameId, ...game }) => game);
+    const fromRaw = backfillGameIds(legacy, [mulliganLog, goadLog]);
+    assertEqual(fromRaw.added, 2, 'added');
+    assertEqual(fromRaw.games[0].gameId, gameIdFromLog(mulliganLog), 'same ID a fresh ingest gives');
+    assertEqual(fromRaw.games[1].gameId, gameIdFromLog(goadLog), 'second game');
+    assertEqual(legacy[0].gameId, undefined, 'input not mutated');
+
+    const fromCondensed = backfillGameIds(legacy, null);
+    assertEqual(fromCondensed.games[0].gameId, gameIdFromCondensed(legacy[0]), 'hashed from condensed content');
+    assert(fromCondensed.games[0].gameId !== fromCondensed.games[1].gameId, 'distinct per game');
+    assertEqual(backfillGameIds(legacy, [mulliganLog]).games[0].gameId, fromCondensed.games[0].gameId, 'raw count mismatch falls back');
+  });
+
+  await test('backfillGameIds: re-running produces identical output', () => {
+    const legacy = condenseGames([mulliganLog, goadLog]).map(({ gameId: _gameId, ...game }) => game);
+    for (const rawLogs of [[mulliganLog, goadLog], null]) {
+      const first = backfillGameIds(legacy, rawLogs);
+      const second = backfillGameIds(first.games, rawLogs);
+      assertEqual(second.added, 0, 'nothing added the second time');
+      assertEqual(JSON.stringify(second.games), JSON.stringify(first.games), 'unchanged');
+      assertEqual(backfillGameIds(first.games, null).games[0].gameId, first.games[0].gameId, 'existing IDs kept');
+    }
+  });
+
+  // =========================================================================
+  // Mulligans
+  // =========================================================================
+
+  await test('extractMulliganDetails: records the sequence and the kept hand', () => {
+    const details = extractMulliganDetails(mulliganLog);
+    assertEqual(JSON.stringify(details['Ai(1)-Alpha']), JSON.stringify({ mulligans: 2, handSizes: [6, 5], keptHandSize: 5 }), 'Alpha went to 5');
+    assertEqual(JSON.stringify(details['Ai(2)-Beta']), JSON.stringify({ mulligans: 0, handSizes: [], keptHandSize: 7 }), 'Beta kept a clean 7');
+    assertEqual(JSON.stringify(details['Ai(3)-Gamma']), JSON.stringify({ mulligans: 1, handSizes: [7], keptHandSize: 7 }), 'free mulligan still counts');
+    assertEqual(JSON.stringify(condenseGame(mulliganLog).mulliganDetails), JSON.stringify(details), 'on the condensed game');
+  });
+
+  await test('extractMulliganDetails: keeps the last size without a keep line, empty without mulligan lines', () => {
+    const details = extractMulliganDetails('Mulligan: Ai(1)-Alpha has mulliganed down to 6 cards.');
+    assertEqual(details['Ai(1)-Alpha'].keptHandSize, 6, 'last size');
+    assertEqual(Object.keys(extractMulliganDetails(goadLog)).length, 0, 'no mulligan lines');
+    assertEqual(condenseGame(goadLog).mulliganDetails, undefined, 'omitted from the condensed game');
+  });
+
+  await test('normalizeAiProfile: strips the file suffix and fixes case', () => {
+    assertEqual(normalizeAiProfile('reckless.ai'), 'Reckless', 'suffix');
+    assertEqual(normalizeAiProfile('  EXPERIMENTAL  '), 'Experimental', 'case and spaces');
+    assertEqual(normalizeAiProfile('very_hard'), 'Very Hard', 'underscores');
+  });
+
+  // =========================================================================
+  // Lock stall (Stasis-style)
+  // =========================================================================
+
+  const stasisLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'stasis-lock-log.txt'), 'utf-8');
+
+  await test('condenseGame: flags a Stasis lock and the round it started', () => {
+    const condensed = condenseGame(stasisLog);
+    assertEqual(condensed.lockStallDetected, true, 'lockStallDetected');
+    assertEqual(condensed.lockStallStartRound, 4, 'first quiet round after Stasis');
+    assertEqual(eventsPerRound(stasisLog)[6], 1, 'a lone land drop stays under the threshold');
+  });
+
+  await test('detectLockStall: a slow game with a play every turn is not a lock', () => {
+    const slow: string[] = [];
+    for (let t = 1; t <= 16; t++) {
+      const p = t % 2 === 1 ? 'Ai(1)-Alpha' : 'Ai(2)-Beta';
+      slow.push(`Turn: Turn ${t} (${p})`, `Land: ${p} played Forest (3)`);
+    }
+    assertEqual(detectLockStall(slow.join('\n')), undefined, 'density 1 per turn');
+    assertEqual(detectLockStall(slow.join('\n'), { maxDensity: 1, minRounds: 4 }), 1, 'custom threshold');
+    for (const game of splitConcatenatedGames(rawLog)) {
+      assertEqual(condenseGame(game).lockStallDetected, undefined, 'real games are not locked');
+    }
+  });
+
+  await test('detectLockStall: counts events with the pipeline\'s classify options', () => {
+    const slow: string[] = [];
+    for (let t = 1; t <= 16; t++) {
+      const p = t % 2 === 1 ? 'Ai(1)-Alpha' : 'Ai(2)-Beta';
+      slow.push(`Turn: Turn ${t} (${p})`, `Land: ${p} played Forest (3)`);
+    }
+    const log = slow.join('\n');
+    const classify = { priority: DEFAULT_CLASSIFICATION_PRIORITY.filter((type) => type !== 'land_played') };
+    assertEqual(eventsPerRound(log, undefined, classify)[1], undefined, 'land drops not counted');
+    assertEqual(detectLockStall(log, undefined, undefined, classify), 1, 'quiet from round 1');
+    assertEqual(condenseGame(log, { classify }).lockStallStartRound, 1, 'condenseGame passes them through');
+    assertEqual(condenseGame(log).lockStallDetected, undefined, 'default rules see the land drops');
+  });
+
+  // =========================================================================
+  // Life loss rate / fast clock
+  // =========================================================================
+
+  const fastClockLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'fast-clock-log.txt'), 'utf-8');
+
+  await test('lifeLossRatePerTurn: totals life lost across the table per round', () => {
+    const loss = lifeLossRatePerTurn(fastClockLog);
+    assertEqual(loss[1], undefined, 'no damage in round 1');
+    assertEqual(loss[2], 41, 'round 2 (life gain ignored)');
+    assertEqual(loss[3], 61, 'round 3');
+  });
+
+  await test('condenseGame: heavy early damage sets fastClock', () => {
+    const condensed = condenseGame(fastClockLog);
+    assertEqual(condensed.fastClock, true, 'fastClock');
+    assertEqual(condensed.lifeLossPerTurn?.[2], 41, 'lifeLossPerTurn surfaced');
+  });
+
+  await test('condenseGame: fastClock and lifeLossPerTurn omitted when not reached', () => {
+    const condensed = condenseGame(fastClockLog, { fastClock: { threshold: 80, window: 2 } });
+    assertEqual(condensed.fastClock, undefined, 'custom threshold not reached');
+    assertEqual(condenseGame(fs.readFileSync(path.join(__dirname, 'fixtures', 'flashback-log.txt'), 'utf-8')).lifeLossPerTurn, undefined, 'no [LIFE] entries');
+  });
+
+  await test('isFastClock: sliding window averages consecutive rounds', () => {
+    assertEqual(isFastClock({ 1: 10, 2: 50 }, { threshold: 30, window: 2 }), true, 'rounds 1-2 average 30');
+    assertEqual(isFastClock({ 1: 50, 3: 50 }, { threshold: 30, window: 2 }), false, 'missing round counts as 0');
+    assertEqual(isFastClock({ 1: 35 }, { threshold: 30, window: 2 }), true, 'game shorter than the window');
+    assertEqual(isFastClock({}), false, 'no data');
+  });
+
+  // =========================================================================
+  // Self Life Payments
+  // =========================================================================
+
+  const lifePaymentLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'life-payment-log.txt'), 'utf-8');
+
+  await test('classifyLifePayment: categorizes the source of a self payment', () => {
+    const lines = lifePaymentLog.split('\n');
+    const fetch = lines.find((l) => l.includes('activated Polluted Delta'))!;
+    const phyrexian = lines.find((l) => l.includes('cast Dismember'))!;
+    const painland = lines.find((l) => l.startsWith('Mana: Llanowar Wastes'))!;
+    assertEqual(JSON.stringify(classifyLifePayment(fetch)), JSON.stringify({ amount: 1, category: 'fetch' }), 'fetchland crack');
+    assertEqual(JSON.stringify(classifyLifePayment(phyrexian)), JSON.stringify({ amount: 4, category: 'phyrexian' }), 'Phyrexian mana cast');
+    assertEqual(JSON.stringify(classifyLifePayment(painland)), JSON.stringify({ amount: 1, category: 'painland' }), 'painland');
+    assertEqual(
+      JSON.stringify(classifyLifePayment('Resolve stack: City of Brass (9) - Whenever City of Brass becomes tapped, it deals 1 damage to you.')),
+      JSON.stringify({ amount: 1, category: 'city' }),
+      'City of Brass'
+    );
+    assertEqual(JSON.stringify(classifyLifePayment('Ai(1)-Alpha pays 2 life.')), JSON.stringify({ amount: 2, category: 'other' }), 'uncategorized payment');
+    assertEqual(classifyLifePayment(lines.find((l) => l.includes('you may pay 2 life'))!), undefined, 'rules text');
+    assertEqual(classifyLifePayment(lines.find((l) => l.includes('combat damage'))!), undefined, 'combat damage');
+  });
+
+  await test('classifyLine: self payments are not combat', () => {
+    const payments = lifePaymentLog.split('\n').filter((l) => classifyLifePayment(l));
+    assertEqual(payments.length, 3, 'payments in fixture');
+    for (const line of payments) assert(classifyLine(line) !== 'combat', `classified as combat: ${line}`);
+  });
+
+  await test('selfLifePayments: totals payments per round and category', () => {
+    const payments = selfLifePayments(lifePaymentLog);
+    assertEqual(JSON.stringify(payments.perRound), JSON.stringify({ 1: 1, 2: 5 }), 'perRound');
+    assertEqual(JSON.stringify(payments.byCategory), JSON.stringify({ fetch: 1, painland: 1, phyrexian: 4 }), 'byCategory');
+  });
+
+  await test('opponentLifeLoss: subtracts self payments from life lost', () => {
+    assertEqual(JSON.stringify(opponentLifeLoss(lifeLossRatePerTurn(lifePaymentLog), { 1: 1, 2: 5 })), JSON.stringify({ 2: 2 }), 'only the combat damage is left');
+    assertEqual(JSON.stringify(opponentLifeLoss({ 1: 3 }, {})), JSON.stringify({ 1: 3 }), 'no payments');
+  });
+
+  await test('condenseGame: surfaces self payments and keeps them off the clock', () => {
+    const condensed = condenseGame(lifePaymentLog, { fastClock: { threshold: 3, window: 1 } });
+    assertEqual(JSON.stringify(condensed.selfLifePaymentsPerTurn), JSON.stringify({ 1: 1, 2: 5 }), 'selfLifePaymentsPerTurn');
+    assertEqual(condensed.selfLifePaymentsByCategory?.phyrexian, 4, 'selfLifePaymentsByCategory');
+    assertEqual(condensed.lifeLossPerTurn?.[2], 7, 'lifeLossPerTurn still counts all life lost');
+    assertEqual(condensed.fastClock, undefined, '2 damage from opponents is below the threshold');
+    assertEqual(condenseGame(fastClockLog).selfLifePaymentsPerTurn, undefined, 'omitted without payments');
+  });
+
+  // =========================================================================
+  // Cards Drawn per Player
+  // =========================================================================
+
+  const symmetricDrawLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'symmetric-draw-log.txt'), 'utf-8');
+
+  await test('cardsDrawnByPlayer: symmetric draws credit every player, not the caster', () => {
+    const { perPlayer, symmetricEvents } = cardsDrawnByPlayer(symmetricDrawLog);
+    // Temple Bell (all 3), Howling Mine on Beta's and Gamma's turns, Prosperity (Alpha and Beta; Gamma conceded)
+    assertEqual(symmetricEvents, 4, 'symmetric events (the wheel is not one)');
+    assertEqual(perPlayer['Ai(1)-Alpha'], 3, 'Alpha: Temple Bell 1 + Prosperity 2');
+    assertEqual(perPlayer['Ai(2)-Beta'], 6, 'Beta: Temple Bell, Howling Mine, Opt, Omen trigger, Prosperity 2');
+    assertEqual(perPlayer['Ai(3)-Gamma'], 2, 'Gamma: Temple Bell, Howling Mine; nothing after conceding');
+  });
+
+  await test('cardsDrawnByPlayer: "each opponent draws" skips the controller', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Add to stack: Ai(1)-Alpha cast Truce (1)',
+      'Resolve stack: Truce (1) - Each opponent draws a card.',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+      'Turn: Turn 3 (Ai(3)-Gamma)',
+    ].join('\n');
+    const { perPlayer } = cardsDrawnByPlayer(log);
+    assertEqual(JSON.stringify(perPlayer), JSON.stringify({ 'Ai(2)-Beta': 1, 'Ai(3)-Gamma': 1 }), 'opponents only');
+  });
+
+  await test('condenseGame: sets cardsDrawnByPlayer and symmetricDrawEvents', () => {
+    const condensed = condenseGame(symmetricDrawLog);
+    assertEqual(condensed.symmetricDrawEvents, 4, 'symmetricDrawEvents');
+    assertEqual(condensed.cardsDrawnByPlayer?.['Ai(3)-Gamma'], 2, 'cardsDrawnByPlayer');
+    assertEqual(condenseGame(fastClockLog).symmetricDrawEvents, undefined, 'omitted without symmetric draws');
+  });
+
+  // =========================================================================
+  // Aggression
+  // =========================================================================
+
+  const aggressionLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'aggression-log.txt'), 'utf-8');
+
+  await test('earlyDamageDealt: credits early damage to opponents by source player', () => {
+    const dealt = earlyDamageDealt(aggressionLog);
+    assertEqual(dealt['Ai(1)-Alpha'], 11, 'combat to the attacker, Lightning Bolt to its caster; token, self damage and round 6 left out');
+    assertEqual(dealt['Ai(2)-Beta'], 2, 'damage to a creature left out');
+    assertEqual(earlyDamageDealt(aggressionLog, 2)['Ai(1)-Alpha'], 5, 'custom window');
+  });
+
+  await test('aggressionIndex: aggressive deck scores high, control deck low', () => {
+    const games = [condenseGame(aggressionLog), condenseGame(aggressionLog, { aggression: { rounds: 6, fullPressure: 40 } })];
+    assertEqual(games[1].earlyDamageDealt?.['Ai(1)-Alpha'], 13, 'condenseGame uses the window option');
+    const index = aggressionIndex(games, ['Alpha', 'Beta']);
+    assertEqual(index.Alpha, 30, 'Alpha: 12 damage per game of 40');
+    assertEqual(index.Beta, 5, 'Beta: 2 damage per game of 40');
+    assertEqual(aggressionIndex(games, ['Alpha', 'Beta'], { rounds: 5, fullPressure: 10 }).Alpha, 100, 'capped at 100');
+    assertEqual(JSON.stringify(aggressionIndex([], ['Alpha'])), JSON.stringify({ Alpha: 0 }), 'no games');
+  });
+
+  // =========================================================================
+  // Extra Turns
+  // =========================================================================
+
+  const extraTurnComboLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'extra-turn-combo-log.txt'), 'utf-8');
+  const extraTurnSingleLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'extra-turn-single-log.txt'), 'utf-8');
+
+  await test('extraTurnsTaken: attributes turns taken back to back to their player', () => {
+    assertEqual(JSON.stringify(extraTurnsTaken(extraTurnComboLog)), JSON.stringify({ 'Ai(1)-Alpha': 2 }), 'chained');
+    assertEqual(JSON.stringify(extraTurnsTaken(extraTurnSingleLog)), JSON.stringify({ 'Ai(1)-Alpha': 1 }), 'single');
+    assertEqual(Object.keys(extraTurnsTaken(loadFixture())).length, 0, 'no extra turns in real games');
+    const relogged = 'Turn: Turn 1 (Ai(1)-Alpha)\nTurn: Turn 1 (Ai(1)-Alpha)\nTurn: Turn 2 (Ai(2)-Beta)\n';
+    assertEqual(Object.keys(extraTurnsTaken(relogged)).length, 0, 'repeated marker is not an extra turn');
+  });
+
+  await test('extraTurnCombo: winner chaining extra turns through the end of the game', () => {
+    assertEqual(extraTurnCombo(extraTurnComboLog, 'Ai(1)-Alpha'), true, 'three turns in a row');
+    assertEqual(extraTurnCombo(extraTurnSingleLog, 'Ai(1)-Alpha'), false, 'one-off Time Warp');
+    assertEqual(extraTurnCombo(extraTurnComboLog, 'Ai(2)-Beta'), false, 'streak is not the winner\'s');
+    assertEqual(extraTurnCombo(extraTurnComboLog, undefined), false, 'no winner');
+    assertEqual(extraTurnCombo(extraTurnSingleLog, 'Ai(1)-Alpha', { minConsecutive: 1 }), true, 'custom threshold');
+  });
+
+  await test('condenseGame: sets extraTurns and extraTurnCombo', () => {
+    const combo = condenseGame(extraTurnComboLog);
+    assertEqual(combo.extraTurnCombo, true, 'combo');
+    assertEqual(combo.extraTurns?.['Ai(1)-Alpha'], 2, 'extraTurns');
+    const single = condenseGame(extraTurnSingleLog);
+    assertEqual(single.extraTurnCombo, undefined, 'single extra turn');
+    assertEqual(single.extraTurns?.['Ai(1)-Alpha'], 1, 'single extraTurns');
+    assertEqual(condenseGame(loadFixture()).extraTurns, undefined, 'omitted without extra turns');
+  });
+
+  await test('extraTurnComboCounts: counts combo wins per deck', () => {
+    const games = [condenseGame(extraTurnComboLog), condenseGame(extraTurnSingleLog), condenseGame(extraTurnComboLog)];
+    assertEqual(JSON.stringify(extraTurnComboCounts(games, ['Alpha', 'Beta'])), JSON.stringify({ Alpha: 2, Beta: 0 }), 'counts');
+  });
+
+  // =========================================================================
+  // Protection
+  // =========================================================================
+
+  const protectionLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'protection-log.txt'), 'utf-8');
+
+  await test('classifyLine: protection keywords and "can\'t be countered"', () => {
+    assertEqual(classifyLine("Resolve stack: Carnage Tyrant (10) - This spell can't be countered."), 'protection', "can't be countered (not high CMC)");
+    assertEqual(classifyLine('Resolve stack: Gods Willing (23) - Baral (24) gains protection from green until end of turn.'), 'protection', 'gains protection');
+    assertEqual(classifyLine('Resolve stack: Heroic Intervention (2) - Permanents you control gain hexproof and indestructible until end of turn.'), 'protection', 'gain hexproof');
+    assertEqual(classifyLine('Effect: Sigarda (7) gains shroud until end of turn.'), 'protection', 'gains shroud');
+    assertEqual(classifyLine('Effect: Ai(1)-Alpha gains 3 life.'), 'life_change', 'gaining life is not protection');
+    assertEqual(classifyLine('Stack: Ai(1)-Alpha cast Counterspell (3)'), 'spell_cast', 'a counterspell is a cast');
+  });
+
+  await test('condenseGame: protection lines are counted as protection events', () => {
+    const condensed = condenseGame(protectionLog);
+    assertEqual(condensed.protectionCount, 2, 'both protection lines');
+    const protection = condensed.keptEvents.filter((e) => e.type === 'protection');
+    assertEqual(protection.length, 2, 'protection events');
+    assert(protection[0].line.includes("can't be countered"), 'uncounterable line');
+    assert(protection[1].line.includes('gains protection'), 'gains protection line');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'combat').length, 1, 'the attack stays combat');
+    assertEqual(condenseGame(goadLog).protectionCount, undefined, 'omitted without protection');
+  });
+
+  await test('buildProtectionPattern: keyword list is extensible', () => {
+    const pattern = buildProtectionPattern([...PROTECTION_KEYWORDS, 'ward']);
+    assert(pattern.test('Effect: Sigarda (7) gains ward 2 until end of turn.'), 'ward added');
+    assert(!KEEP_PROTECTION.test('Effect: Sigarda (7) gains ward 2 until end of turn.'), 'ward not in the defaults');
+  });
+
+  // =========================================================================
+  // Off-turn actions
+  // =========================================================================
+
+  const offTurnLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'off-turn-log.txt'), 'utf-8');
+
+  await test('offTurnActions: off-turn counter and removal are credited to the reactive player', () => {
+    const counts = offTurnActions(offTurnLog);
+    assertEqual(counts['Ai(2)-Beta'], 2, 'Counterspell on Alpha\'s turn, Snap on Gamma\'s');
+    assertEqual(counts['Ai(1)-Alpha'], undefined, 'Alpha only cast on its own turns');
+    assertEqual(counts['Ai(3)-Gamma'], undefined, 'Gamma only cast on its own turns');
+    assertEqual(Object.keys(counts).length, 1, 'the "Cast Out" trigger is not a cast');
+  });
+
+  await test('condenseGame: offTurnActions surfaced per player', () => {
+    assertEqual(condenseGame(offTurnLog).offTurnActions?.['Ai(2)-Beta'], 2, 'Beta');
+    assertEqual(condenseGame(goadLog).offTurnActions, undefined, 'omitted without off-turn casts');
+  });
+
+  // =========================================================================
+  // Interaction received
+  // =========================================================================
+
+  const interactionLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'interaction-log.txt'), 'utf-8');
+
+  await test('interactionReceived: a countered combo piece is credited to its caster', () => {
+    const counts = interactionReceived(interactionLog);
+    assertEqual(counts['Ai(1)-Alpha'], 1, "Counterspell on Alpha's Thassa's Oracle");
+    assertEqual(counts['Ai(3)-Gamma'], 1, 'Hymn to Tourach targeting Gamma; Ephemerate on its own Bitterblossom not counted');
+    assertEqual(counts['Ai(2)-Beta'], 1, "Into the Roil on Beta's Island; its draw clause is not interaction");
+    assertEqual(counts[INTERACTION_UNKNOWN], 1, 'Murder on a token nobody cast');
+    assertEqual(JSON.stringify(condenseGame(interactionLog).interactionReceived), JSON.stringify(counts), 'on the condensed game');
+  });
+
+  await test('interactionReceived: untargeted interaction is unknown, other lines are ignored', () => {
+    assertEqual(
+      interactionReceived('Resolve stack: Murder (3) - Destroy target creature.')[INTERACTION_UNKNOWN],
+      1,
+      'no Targeting section'
+    );
+    assertEqual(
+      Object.keys(interactionReceived('Resolve stack: Giant Growth (3) - Target creature gets +3/+3 until end of turn. (Targeting: Grizzly Bears (4))')).length,
+      0,
+      'pump spell'
+    );
+    assertEqual(condenseGame(goadLog).interactionReceived, undefined, 'omitted without interaction');
+  });
+
+  // =========================================================================
+  // Archenemy
+  // =========================================================================
+
+  const archenemyLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'archenemy-log.txt'), 'utf-8');
+
+  await test('archenemy: the player drawing most attacks and interaction is named', () => {
+    assertEqual(
+      JSON.stringify(attacksReceived(archenemyLog)),
+      JSON.stringify({ 'Ai(1)-Alpha': 4, 'Ai(4)-Delta': 1 }),
+      'one attack per creature; blocks are not attacks'
+    );
+    const game = condenseGame(archenemyLog);
+    assertEqual(game.archenemy, 'Ai(1)-Alpha', 'attacked four times and countered with one permanent');
+    assertEqual(
+      JSON.stringify(archenemyCounts([game, game, condenseGame(interactionLog)], ['Alpha', 'Beta', 'Gamma', 'Delta'])),
+      JSON.stringify({ Alpha: 2, Beta: 0, Gamma: 0, Delta: 0 }),
+      'counted per deck across games'
+    );
+  });
+
+  await test('archenemy: targeting in line with board presence names nobody', () => {
+    const board = boardPresence(archenemyLog);
+    assertEqual(board['Ai(3)-Gamma'], 3, 'Gamma put three permanents onto the battlefield');
+    assertEqual(
+      archenemy({ attacksReceived: { 'Ai(3)-Gamma': 3, 'Ai(1)-Alpha': 1, 'Ai(2)-Beta': 1, 'Ai(4)-Delta': 1 } }, board),
+      undefined,
+      'Gamma draws half the attacks with half the board'
+    );
+    assertEqual(archenemy({ attacksReceived: { 'Ai(1)-Alpha': 2 } }, board), undefined, 'below minTargeted');
+    assertEqual(archenemy({ attacksReceived: { 'Ai(1)-Alpha': 2 } }, board, { minTargeted: 1 }), 'Ai(1)-Alpha', 'minTargeted is configurable');
+    assertEqual(
+      archenemy({ attacksReceived: { 'Ai(1)-Alpha': 3 }, interactionReceived: { 'Ai(2)-Beta': 3 } }, { 'Ai(1)-Alpha': 1, 'Ai(2)-Beta': 1 }),
+      undefined,
+      'a tie names nobody'
+    );
+    assertEqual(condenseGame(interactionLog).archenemy, undefined, 'one interaction each is not an archenemy');
+  });
+
+  // =========================================================================
+  // Eliminated players
+  // =========================================================================
+
+  const eliminatedTriggerLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'eliminated-trigger-log.txt'), 'utf-8');
+
+  await test('eliminatedPlayerOf: loss and concession lines name the player', () => {
+    assertEqual(eliminatedPlayerOf('Game outcome: Ai(2)-Beta has lost because life total reached 0'), 'Ai(2)-Beta', 'loss');
+    assertEqual(eliminatedPlayerOf('Game outcome: Ai(3)-Gamma has conceded'), 'Ai(3)-Gamma', 'concession');
+    assertEqual(eliminatedPlayerOf('Game outcome: Ai(1)-Alpha has won because all opponents have lost'), undefined, 'win');
+    assert(isEliminated('Ai(2)-Beta', ['Ai(2)-Beta']), 'same name');
+    assert(!isEliminated('Ai(1)-Alpha', ['Ai(2)-Beta']), 'other player');
+  });
+
+  await test('offTurnActions: casts credited to an eliminated player are not counted', () => {
+    const counts = offTurnActions(eliminatedTriggerLog);
+    assertEqual(counts['Ai(2)-Beta'], 1, 'only the Counterspell before Beta lost');
+    assertEqual(Object.keys(counts).length, 1, 'no one else cast off-turn');
+  });
+
+  await test('boardDevelopmentPerTurn: ETBs under an eliminated player\'s control are not counted', () => {
+    const board = boardDevelopmentPerTurn(eliminatedTriggerLog);
+    assertEqual(board[1]['Ai(2)-Beta'], 1, 'Kiki-Jiki before Beta lost');
+    assertEqual(board[2], undefined, 'the Scout and Rager after Beta lost are dropped');
+  });
+
+  // =========================================================================
+  // Unmatched line sampling
+  // =========================================================================
+
+  await test('normalizeUnmatchedLine: masks card ids and numbers, keeps seats', () => {
+    assertEqual(
+      normalizeUnmatchedLine('Add to stack: Ai(2)-Beta   triggered Setessan Champion (287)'),
+      'Add to stack: Ai(2)-Beta triggered Setessan Champion (#)',
+      'card id'
+    );
+    assertEqual(
+      normalizeUnmatchedLine('Resolve stack: create a 3/3 green Wurm creature token.'),
+      'Resolve stack: create a #/# green Wurm creature token.',
+      'numbers'
+    );
+  });
+
+  await test('buildUnmatchedSample: top unmatched lines are tallied by normalized form', () => {
+    const game = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      "Phase: Ai(1)-Alpha' Main phase, precombat",
+      'Land: Ai(1)-Alpha played Forest (1)',
+      'Add to stack: Ai(1)-Alpha triggered Llanowar Reborn (2)',
+      'Replacement Effect: Riot',
+      'Add to stack: Ai(1)-Alpha triggered Llanowar Reborn (5)',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+      'Add to stack: Ai(2)-Beta triggered Setessan Champion (7)',
+      'Add to stack: Ai(1)-Alpha triggered Llanowar Reborn (9)',
+    ].join('\n');
+    const tally = tallyUnmatchedLines(game);
+    assertEqual(tally.get('Add to stack: Ai(1)-Alpha triggered Llanowar Reborn (#)'), 3, 'same trigger, different ids');
+    assertEqual(tally.has('Land: Ai(1)-Alpha played Forest (#)'), false, 'classified lines are not unmatched');
+    assertEqual([...tally.keys()].some((k) => k.startsWith('Turn') || k.startsWith('Phase')), false, 'markers skipped');
+
+    const sample = buildUnmatchedSample([game, game], 2);
+    assertEqual(sample.totalUnmatched, 10, 'unmatched lines across both games');
+    assertEqual(sample.distinct, 3, 'distinct normalized lines');
+    assertEqual(sample.top.length, 2, 'top N');
+    assertEqual(sample.top[0].count, 6, 'most common first');
+    assertEqual(sample.top[0].line, 'Add to stack: Ai(1)-Alpha triggered Llanowar Reborn (#)', 'most common line');
+    assertEqual(sample.top[1].line, 'Add to stack: Ai(2)-Beta triggered Setessan Champion (#)', 'ties in line order');
+  });
+
+  // =========================================================================
+  // Big turns
+  // =========================================================================
+
+  const bigTurnLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'big-turn-log.txt'), 'utf-8');
+
+  await test('calculateCastsPerTurn: counts stack casts and mana added per player turn', () => {
+    const casts = calculateCastsPerTurn(bigTurnLog);
+    assertEqual(casts[1].length, 2, 'both players cast in round 1');
+    assertEqual(JSON.stringify(casts[1][1]), JSON.stringify({ player: 'Ai(2)-Beta', casts: 1, manaAdded: 2 }), '"one mana" counts as one');
+    // The "Whenever you cast" trigger isn't a cast; the ritual's {B}{B}{B} is mana
+    assertEqual(JSON.stringify(casts[2][0]), JSON.stringify({ player: 'Ai(1)-Alpha', casts: 4, manaAdded: 5 }), 'ritual turn');
+  });
+
+  await test('condenseGame: ritual-fueled multi-spell turn is a big turn', () => {
+    const condensed = condenseGame(bigTurnLog);
+    assertEqual(JSON.stringify(condensed.bigTurns), '[2]', 'bigTurns');
+    assertEqual(condensed.castsPerTurn?.[2]?.[0]?.casts, 4, 'castsPerTurn surfaced');
+  });
+
+  await test('condenseGame: bigTurns omitted when thresholds are not exceeded', () => {
+    const condensed = condenseGame(bigTurnLog, { bigTurns: { minCasts: 6, minRatioCasts: 3, maxCastsPerMana: 1 } });
+    assertEqual(condensed.bigTurns, undefined, 'custom thresholds');
+    assert(condensed.castsPerTurn !== undefined, 'castsPerTurn kept');
+    assertEqual(condenseGame(fs.readFileSync(path.join(__dirname, 'fixtures', 'flashback-log.txt'), 'utf-8')).bigTurns, undefined, 'two flashback casts are normal tempo');
+  });
+
+  await test('bigTurns: raw cast count or casts per mana', () => {
+    assertEqual(isBigTurn({ casts: 5, manaAdded: 20 }), true, 'five casts is big regardless of mana');
+    assertEqual(isBigTurn({ casts: 3, manaAdded: 0 }), true, 'three free casts');
+    assertEqual(isBigTurn({ casts: 3, manaAdded: 6 }), false, 'exactly 0.5 casts per mana');
+    assertEqual(isBigTurn({ casts: 2, manaAdded: 0 }), false, 'below minRatioCasts');
+    const game = condenseGame(bigTurnLog);
+    game.castsPerTurn = { 3: [{ casts: 6, manaAdded: 9 }], 1: [{ casts: 1, manaAdded: 1 }, { casts: 3, manaAdded: 2 }] };
+    assertEqual(JSON.stringify(bigTurns(game)), '[1,3]', 'any big player turn flags its round, ascending');
+    assertEqual(bigTurns({ ...game, castsPerTurn: undefined }).length, 0, 'no cast data');
+  });
+
+  await test('condenseGame: real games flag only their explosive rounds', () => {
+    for (const game of splitConcatenatedGames(rawLog)) {
+      const condensed = condenseGame(game);
+      const rounds = Object.keys(condensed.castsPerTurn ?? {}).length;
+      assert((condensed.bigTurns?.length ?? 0) < rounds / 2, `${condensed.bigTurns?.length} of ${rounds} rounds`);
+    }
+  });
+
+  // =========================================================================
+  // Alternative-cost casts (flashback, escape, ...)
+  // =========================================================================
+
+  const flashbackLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'flashback-log.txt'), 'utf-8');
+  const escapeLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'escape-log.txt'), 'utf-8');
+  const spellTotal = (log: string) =>
+    condenseGame(log).keptEvents.filter((e) => SPELL_CAST_EVENT_TYPES.has(e.type)).length;
+
+  await test('classifyLine: alternative-cost casts rank above generic spell casts', () => {
+    assertEqual(classifyLine('Stack: Ai(1)-Alpha cast Deep Analysis (3) using Flashback'), 'alt_cost_cast', 'flashback');
+    assertEqual(classifyLine("Stack: Ai(1)-Alpha cast Uro, Titan of Nature's Wrath (2) with Escape"), 'alt_cost_cast', 'escape');
+    assertEqual(classifyLine('Stack: Ai(1)-Alpha cast Ghostly Flicker (2) using Jump-start'), 'alt_cost_cast', 'jump-start');
+    assertEqual(classifyLine('Stack: Ai(1)-Alpha cast Faithless Looting (1)'), 'spell_cast', 'hand cast');
+  });
+
+  await test('condenseGame: altCostCastCount counts flashback casts', () => {
+    const condensed = condenseGame(flashbackLog);
+    assertEqual(condensed.altCostCastCount, 2, 'two flashback casts');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'alt_cost_cast').length, 2, 'events kept');
+    assertEqual(spellTotal(flashbackLog), 4, 'spell total includes flashback casts');
+  });
+
+  await test('condenseGame: altCostCastCount counts escape casts', () => {
+    const condensed = condenseGame(escapeLog);
+    assertEqual(condensed.altCostCastCount, 2, 'two escape casts');
+    assertEqual(spellTotal(escapeLog), 4, 'spell total includes escape casts');
+  });
+
+  await test('condenseGame: no altCostCastCount without alternative-cost casts', () => {
+    assertEqual(condenseGame(fs.readFileSync(path.join(__dirname, 'fixtures', 'free-cast-log.txt'), 'utf-8')).altCostCastCount, undefined, 'omitted');
+  });
+
+  await test('buildAltCostPattern: keyword list is extensible', () => {
+    const pattern = buildAltCostPattern([...ALT_COST_KEYWORDS, 'aftermath']);
+    assert(pattern.test('Stack: Ai(1)-Alpha cast Cut // Ribbons (4) using Aftermath'), 'added keyword matches');
+    assert(!KEEP_ALT_COST_CAST.test('Stack: Ai(1)-Alpha cast Cut // Ribbons (4) using Aftermath'), 'default list unchanged');
+  });
+
+  // =========================================================================
+  // Free casts (cascade, suspend, without paying its mana cost)
+  // =========================================================================
+
+  const freeCastLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'free-cast-log.txt'), 'utf-8');
+
+  await test('classifyLine: free casts rank above generic spell casts', () => {
+    assertEqual(classifyLine("Ai(1)-Alpha cascades into Kodama's Reach (3)"), 'free_cast', 'cascade');
+    assertEqual(
+      classifyLine('Ai(1)-Alpha casts Ancestral Vision (1) without paying its mana cost'),
+      'free_cast',
+      'without paying its mana cost'
+    );
+    assertEqual(classifyLine('Stack: Ai(2)-Beta cast Rift Bolt (4) from suspend'), 'free_cast', 'suspend');
+    assertEqual(classifyLine('Stack: Ai(1)-Alpha cast Bloodbraid Elf (2)'), 'spell_cast', 'paid cast');
+  });
+
+  await test('condenseGame: freeCastCount includes free high-CMC spells', () => {
+    const condensed = condenseGame(freeCastLog);
+    assertEqual(condensed.freeCastCount, 4, 'freeCastCount');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'free_cast').length, 3, 'free_cast events');
+    assertEqual(
+      condensed.keptEvents.filter((e) => e.type === 'spell_cast_high_cmc').length,
+      1,
+      'free Emrakul stays high CMC'
+    );
+    assertEqual(condenseGame(rawLog.split('\n').slice(0, 5).join('\n')).freeCastCount, undefined, 'omitted when zero');
+  });
+
+  await test('structureGame: free casts count toward spell totals', () => {
+    const structured = structureGame(freeCastLog);
+    const alpha = structured.decks.find((d) => d.deckLabel === 'Ai(1)-Alpha');
+    assert(alpha !== undefined, 'Alpha deck found');
+    const spellsOnTurn = (turnNumber: number) =>
+      alpha!.turns
+        .find((t) => t.turnNumber === turnNumber)!
+        .actions.filter((a) => a.eventType !== undefined && SPELL_CAST_EVENT_TYPES.has(a.eventType)).length;
+    assertEqual(spellsOnTurn(1), 2, 'Bloodbraid Elf + cascade');
+    assertEqual(spellsOnTurn(2), 2, 'two free spells on Alpha turn 2');
+  });
+
+  const ritualLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'ritual-log.txt'), 'utf-8');
+
+  await test('classifyLine: a resolving ritual is a ritual, a land or rock tap is not', () => {
+    assertEqual(classifyLine('Resolve stack: Dark Ritual (3) - Add {B}{B}{B}.'), 'ritual', 'Dark Ritual');
+    assertEqual(classifyLine('Resolve stack: Seething Song (5) - Add {R}{R}{R}{R}{R}.'), 'ritual', 'id 5 is not a CMC');
+    assertEqual(
+      classifyLine('Resolve stack: Pyretic Ritual (8) - Add three mana in any combination of colors.'),
+      'ritual',
+      'spelled-out mana'
+    );
+    assert(classifyLine('Mana: Swamp (11) - {T}: Add {B}.') !== 'ritual', 'land tap');
+    assert(classifyLine('Mana: Sol Ring (22) - {T}: Add {C}{C}.') !== 'ritual', 'rock tap');
+    assert(
+      classifyLine('Resolve stack: Whenever you cast an instant or sorcery spell, Birgi, God of Storytelling (24) - Add {R}.') !== 'ritual',
+      'trigger'
+    );
+  });
+
+  await test('condenseGame: ritualCount counts rituals only', () => {
+    const condensed = condenseGame(ritualLog);
+    assertEqual(condensed.ritualCount, 2, 'Dark Ritual + Cabal Ritual');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'ritual').length, 2, 'ritual events');
+    assert(!condensed.keptEvents.some((e) => e.type === 'ritual' && e.line.startsWith('Mana:')), 'no tap is a ritual');
+    assertEqual(condenseGame(rawLog.split('\n').slice(0, 5).join('\n')).ritualCount, undefined, 'omitted when zero');
+  });
+
+  // =========================================================================
+  // Land destruction
+  // =========================================================================
+
+  const landDestructionLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'land-destruction-log.txt'), 'utf-8');
+  const massLandDestructionLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'mass-land-destruction-log.txt'), 'utf-8');
+
+  await test('classifyLine: land destruction ranks above high CMC card ids', () => {
+    assertEqual(
+      classifyLine('Resolve stack: Strip Mine (11) - Ai(1)-Alpha destroys target land Volcanic Island (46)'),
+      'land_destruction',
+      'Strip Mine'
+    );
+    assertEqual(classifyLine('Resolve stack: Pox (3) - Each player sacrifices a land'), 'land_destruction', 'Pox');
+    assertEqual(classifyLine('Resolve stack: Armageddon (4) - Destroy all lands.'), 'land_destruction', 'Armageddon');
+    assert(
+      classifyLine('Ai(1)-Alpha sacrifices Evolving Wilds (2)') !== 'land_destruction',
+      'fetch land sacrifice is not land destruction'
+    );
+  });
+
+  await test('condenseGame: single land destruction counts without the mass flag', () => {
+    const condensed = condenseGame(landDestructionLog);
+    assertEqual(condensed.landDestructionCount, 3, 'landDestructionCount');
+    assertEqual(condensed.massLandDestructionCount, undefined, 'no mass destruction');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'land_destruction').length, 3, 'land_destruction events');
+  });
+
+  await test('condenseGame: mass land destruction is flagged separately', () => {
+    const condensed = condenseGame(massLandDestructionLog);
+    assertEqual(condensed.landDestructionCount, 3, 'landDestructionCount includes mass');
+    assertEqual(condensed.massLandDestructionCount, 2, 'Armageddon + Ravages of War');
+    assertEqual(condenseGame(freeCastLog).landDestructionCount, undefined, 'omitted when zero');
+  });
+
+  // =========================================================================
+  // Creature death
+  // =========================================================================
+
+  const combatDeathLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'creature-death-combat-log.txt'), 'utf-8');
+  const removalDeathLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'creature-death-removal-log.txt'), 'utf-8');
+
+  await test('classifyLine: death reports are creature_death, rules text and removal spells are not', () => {
+    assertEqual(classifyLine('Zone Change: Serra Angel (33) dies.'), 'creature_death', 'dies');
+    assertEqual(classifyLine('Zone Change: Serra Angel (33) is destroyed.'), 'creature_death', 'destroyed');
+    assertEqual(
+      classifyLine("Zone Change: Serra Angel (33) was put into its owner's graveyard from the battlefield."),
+      'creature_death',
+      'battlefield to graveyard'
+    );
+    assert(
+      classifyLine('Resolve stack: Whenever another creature you control dies, you may pay 2 life.') !== 'creature_death',
+      'death trigger text'
+    );
+    assert(
+      classifyLine('Resolve stack: Murder (3) - Destroy target creature. (Targeting: Serra Angel (33))') !== 'creature_death',
+      'the removal spell itself'
+    );
+    assertEqual(
+      classifyLine('Resolve stack: Strip Mine (11) - Ai(1)-Alpha destroys target land Volcanic Island (46)'),
+      'land_destruction',
+      'land destruction ranks first'
+    );
+    assertEqual(
+      classifyLine('Resolve stack: Disentomb (24) - Put target creature card from your graveyard onto the battlefield.'),
+      'zone_change_gy_to_bf',
+      'recursion ranks first'
+    );
+  });
+
+  await test('creatureDeathsPerRound: combat trade counts both creatures', () => {
+    assertEqual(JSON.stringify(creatureDeathsPerRound(combatDeathLog)), JSON.stringify({ 2: 2 }), 'both blockers died in round 2');
+    assertEqual(JSON.stringify(condenseGame(combatDeathLog).creatureDeathsPerTurn), JSON.stringify({ 2: 2 }), 'on the condensed game');
+  });
+
+  await test('creatureDeathsPerRound: removal counts the death as well as the spell', () => {
+    const condensed = condenseGame(removalDeathLog);
+    assertEqual(JSON.stringify(condensed.creatureDeathsPerTurn), JSON.stringify({ 2: 2 }), 'Murder and Doom Blade deaths');
+    assert(condensed.keptEvents.some((e) => e.line.includes('cast Murder')), 'Murder cast kept as a spell event');
+    assertEqual(condensed.keptEvents.filter((e) => e.type === 'creature_death').length, 2, 'creature_death events');
+    assertEqual(condenseGame(freeCastLog).creatureDeathsPerTurn, undefined, 'omitted without deaths');
+  });
+
+  await test('creatureDeathsPerRound: counts with the pipeline\'s classify options', () => {
+    const classify = { priority: DEFAULT_CLASSIFICATION_PRIORITY.filter((type) => type !== 'creature_death') };
+    assertEqual(JSON.stringify(creatureDeathsPerRound(combatDeathLog, undefined, classify)), '{}', 'type disabled');
+    const condensed = condenseGame(combatDeathLog, { classify });
+    assertEqual(condensed.keptEvents.some((e) => e.type === 'creature_death'), false, 'no creature_death events');
+    assertEqual(condensed.creatureDeathsPerTurn, undefined, 'so no deaths per round either');
+  });
+
+  // =========================================================================
+  // First blood
+  // =========================================================================
+
+  const firstBloodLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'first-blood-log.txt'), 'utf-8');
+
+  await test('firstBloodRound: earliest elimination round', () => {
+    assertEqual(firstBloodRound(firstBloodLog), 5, 'Gamma dies in round 5, before Beta and Delta');
+    assertEqual(condenseGame(firstBloodLog).firstBloodTurn, 5, 'on the condensed game');
+    assertEqual(structureGame(firstBloodLog).firstBloodTurn, 5, 'on the structured game');
+    assertEqual(firstBloodRound(lastStandingLog), 2, 'Beta lost on segment 5 of 4 players');
+  });
+
+  await test('firstBloodRound: 0 when nobody was eliminated', () => {
+    const log = 'Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (1)\nTurn: Turn 2 (Ai(2)-Beta)\nLand: Ai(2)-Beta played Island (2)';
+    assertEqual(firstBloodRound(log), 0, 'no eliminations');
+    assertEqual(condenseGame(log).firstBloodTurn, 0, 'reported as 0');
+  });
+
+  // =========================================================================
+  // Cast CMC histogram
+  // =========================================================================
+
+  const cmcLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'cmc-histogram-log.txt'), 'utf-8');
+
+  await test('castCmcHistogram: tallies stated CMCs, bare card ids go under unknown', () => {
+    const expected = { 1: 2, 3: 1, 6: 1, 15: 1, [CMC_UNKNOWN]: 1 };
+    assertEqual(JSON.stringify(castCmcHistogram(cmcLog)), JSON.stringify(expected), 'Lightning Bolt (57) is unknown; the Guttersnipe trigger is not a cast');
+    assertEqual(JSON.stringify(condenseGame(cmcLog).castCmcHistogram), JSON.stringify(expected), 'on the condensed game');
+  });
+
+  await test('castCmcHistogram: omitted without casts', () => {
+    const log = 'Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (1)';
+    assertEqual(JSON.stringify(castCmcHistogram(log)), '{}', 'empty histogram');
+    assertEqual(condenseGame(log).castCmcHistogram, undefined, 'not on the condensed game');
+  });
+
+  // =========================================================================
+  // Protected combo
+  // =========================================================================
+
+  const protectedLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'protected-combo-log.txt'), 'utf-8');
+
+  await test('protectedCombo: Heroic Intervention on the winning turn', () => {
+    assertEqual(JSON.stringify(protectionPerRound(protectedLog)), JSON.stringify({ 3: 1 }), 'protection in round 3');
+    const condensed = condenseGame(protectedLog);
+    assertEqual(JSON.stringify(condensed.protectionPerTurn), JSON.stringify({ 3: 1 }), 'on the condensed game');
+    assertEqual(condensed.protectedCombo, true, 'flagged');
+  });
+
+  await test('protectedCombo: protection turns earlier count only inside the window', () => {
+    // Move the Heroic Intervention to round 2 (Alpha's second turn)
+    const lines = protectedLog.split('\n');
+    const shield = lines.splice(lines.findIndex((l) => l.startsWith('Add to stack: Ai(1)-Alpha cast Heroic')), 2);
+    lines.splice(lines.findIndex((l) => l.includes('cast Devoted Druid')) + 1, 0, ...shield);
+    const early = lines.join('\n');
+    assertEqual(condenseGame(early).protectedCombo, undefined, 'not flagged by default');
+    assertEqual(
+      condenseGame(early, { protectedCombo: { ...DEFAULT_PROTECTED_COMBO, windowRounds: 1 } }).protectedCombo,
+      true,
+      'flagged with a one-round window'
+    );
+  });
+
+  await test('protectedCombo: counter backup and keywords are configurable', () => {
+    const counterLog = protectedLog.replace(
+      /Resolve stack: Heroic Intervention.*\n/,
+      'Resolve stack: Counterspell (8) - Counter target spell.\n'
+    );
+    assertEqual(condenseGame(counterLog).protectedCombo, true, 'counterspell backup counts');
+    const noCounters = { ...DEFAULT_PROTECTED_COMBO, includeCounters: false };
+    assertEqual(condenseGame(counterLog, { protectedCombo: noCounters }).protectedCombo, undefined, 'counters off');
+    const ward = { ...DEFAULT_PROTECTED_COMBO, keywords: ['ward'] };
+    assertEqual(condenseGame(protectedLog, { protectedCombo: ward }).protectedCombo, undefined, 'hexproof not a keyword');
+  });
+
+  await test('protectedCombo: a big turn with protection counts without a winner', () => {
+    const game = { keptEvents: [], manaPerTurn: {}, cardsDrawnPerTurn: {}, turnCount: 6, bigTurns: [4], protectionPerTurn: { 4: 2 } };
+    assertEqual(protectedCombo(game), true, 'big turn');
+    assertEqual(protectedCombo(game, { ...DEFAULT_PROTECTED_COMBO, includeBigTurns: false }), false, 'big turns off');
+    assertEqual(protectedCombo({ ...game, protectionPerTurn: undefined }), false, 'no protection');
+  });
+
+  // =========================================================================
+  // Recurring engines
+  // =========================================================================
+
+  const engineLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'recurring-engine-log.txt'), 'utf-8');
+
+  await test('recurringEngines: Phyrexian Arena firing every upkeep is an engine', () => {
+    // Sandwurm Convergence fired in one end step; Blood Artist fired twice, but not in an upkeep or end step
+    assertEqual(JSON.stringify(recurringEngines(engineLog)), JSON.stringify(['Phyrexian Arena']), 'engines');
+    assertEqual(JSON.stringify(condenseGame(engineLog).recurringEngines), JSON.stringify(['Phyrexian Arena']), 'on the condensed game');
+  });
+
+  await test('recurringEngines: minTurns is configurable', () => {
+    assertEqual(
+      JSON.stringify(recurringEngines(engineLog, { minTurns: 1 })),
+      JSON.stringify(['Phyrexian Arena', 'Sandwurm Convergence']),
+      'single-turn triggers count at minTurns 1'
+    );
+    assertEqual(condenseGame(engineLog, { recurringEngines: { minTurns: 4 } }).recurringEngines, undefined, 'three turns is not four');
+  });
+
+  await test('recurringEngines: trigger text is used without phase markers', () => {
+    const noPhases = engineLog
+      .split('\n')
+      .filter((l) => !l.startsWith('Phase:'))
+      .map((l) => l.replace('triggered Phyrexian Arena (3)', 'triggered Phyrexian Arena (3) - At the beginning of your upkeep, you draw a card and you lose 1 life.'))
+      .join('\n');
+    assertEqual(JSON.stringify(recurringEngines(noPhases)), JSON.stringify(['Phyrexian Arena']), 'engines');
+  });
+
+  // =========================================================================
+  // Card type profile
+  // =========================================================================
+
+  await test('cardTypeProfile: resolver maps casts to types per deck', () => {
+    const types: Record<string, string[]> = {
+      'dark ritual': ['Instant'],
+      'phyrexian arena': ['Enchantment'],
+      'blood artist': ['Creature'],
+    };
+    const resolver = (name: string) => types[name.toLowerCase()];
+    const games = [condenseGame(engineLog), condenseGame(protectedLog)];
+    const profile = cardTypeProfile(games, ['Alpha', 'Beta'], resolver);
+    assertEqual(
+      JSON.stringify(profile),
+      JSON.stringify({
+        Alpha: { instant: 1, enchantment: 1, creature: 1, [CARD_TYPE_UNKNOWN]: 4 },
+      }),
+      'Alpha: the resolver knows the engine-log casts, not the protected-combo ones'
+    );
+  });
+
+  await test('cardTypeProfile: falls back to type lines in the log', () => {
+    assertEqual(
+      JSON.stringify(extractCardTypeHints(engineLog)),
+      JSON.stringify({ 'Phyrexian Arena': ['enchantment'] }),
+      'hints for cast cards'
+    );
+    const game = condenseGame(engineLog);
+    assertEqual(JSON.stringify(game.cardTypeHints), JSON.stringify({ 'Phyrexian Arena': ['enchantment'] }), 'on the condensed game');
+    const profile = cardTypeProfile([game], ['Alpha', 'Beta']);
+    assertEqual(JSON.stringify(profile), JSON.stringify({ Alpha: { unknown: 2, enchantment: 1 } }), 'no resolver');
+    const artifactCreature = 'Turn: Turn 1 (Ai(1)-Alpha)\nAdd to stack: Ai(1)-Alpha cast Myr Enforcer (1)\nResolve stack: Myr Enforcer - Artifact Creature 4 / 4';
+    assertEqual(
+      JSON.stringify(cardTypeProfile([condenseGame(artifactCreature)], ['Alpha'])),
+      JSON.stringify({ Alpha: { creature: 1, artifact: 1 } }),
+      'an artifact creature counts under both types'
+    );
+  });
+
+  // =========================================================================
+  // Low-signal games
+  // =========================================================================
+
+  const oneTurnLog = [
+    'Turn 1: Ai(1)-Alpha',
+    'Land: Ai(1)-Alpha played Forest (1)',
+    'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+    'Game outcome: Ai(2)-Beta has conceded',
+  ].join('\n');
+
+  await test('isLowSignal: a 1-turn game is flagged, real games are not', () => {
+    assertEqual(condenseGame(oneTurnLog).lowSignal, true, 'condensed 1-turn game');
+    assertEqual(structureGame(oneTurnLog).lowSignal, true, 'structured 1-turn game');
+    const real = condenseGames(splitConcatenatedGames(loadFixture()));
+    assert(real.every((g) => g.lowSignal === undefined), 'real games are not flagged');
+  });
+
+  await test('isLowSignal: either threshold flags a game', () => {
+    assertEqual(isLowSignal(100, 1, DEFAULT_LOW_SIGNAL), true, 'too few turns');
+    assertEqual(isLowSignal(4, 10, DEFAULT_LOW_SIGNAL), true, 'too few events');
+    assertEqual(isLowSignal(5, 2, DEFAULT_LOW_SIGNAL), false, 'at both thresholds');
+    assertEqual(condenseGame(oneTurnLog, { lowSignal: { minEvents: 0, minTurns: 1 } }).lowSignal, undefined, 'thresholds via CondenseOptions');
+  });
+
+  await test('parseLowSignalThreshold: parses keys and rejects bad values', () => {
+    assertEqual(JSON.stringify(parseLowSignalThreshold('turns=3, Events=10')), JSON.stringify({ minEvents: 10, minTurns: 3 }), 'both keys');
+    assertEqual(JSON.stringify(parseLowSignalThreshold('turns=4')), JSON.stringify({ minEvents: 5, minTurns: 4 }), 'missing key keeps its default');
+    let unknown = '';
+    try { parseLowSignalThreshold('rounds=3'); } catch (err) { unknown = (err as Error).message; }
+    assert(unknown.includes('unknown key "rounds"'), `unknown key error, got "${unknown}"`);
+    let bad = '';
+    try { parseLowSignalThreshold('turns=-1'); } catch (err) { bad = (err as Error).message; }
+    assert(bad.startsWith('Invalid LOW_SIGNAL_THRESHOLD'), `bad value error, got "${bad}"`);
+  });
+
+  await test('getLowSignalThreshold: reads LOW_SIGNAL_THRESHOLD', () => {
+    const saved = process.env[LOW_SIGNAL_THRESHOLD_ENV];
+    try {
+      delete process.env[LOW_SIGNAL_THRESHOLD_ENV];
+      assertEqual(getLowSignalThreshold(), DEFAULT_LOW_SIGNAL, 'default when unset');
+      process.env[LOW_SIGNAL_THRESHOLD_ENV] = 'turns=1,events=1';
+      assertEqual(getLowSignalThreshold().minTurns, 1, 'override');
+      assertEqual(condenseGame(oneTurnLog).lowSignal, undefined, 'override applies to condenseGame');
+    } finally {
+      if (saved === undefined) delete process.env[LOW_SIGNAL_THRESHOLD_ENV];
+      else process.env[LOW_SIGNAL_THRESHOLD_ENV] = saved;
+    }
+  });
+
+  // =========================================================================
+  // Killing blow
+  // =========================================================================
+
+  const combatKillLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'killing-blow-combat-log.txt'), 'utf-8');
+  const burnKillLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'killing-blow-burn-log.txt'), 'utf-8');
+
+  await test('extractKillingBlow: combat kill credits the attacker', () => {
+    const kill = extractKillingBlow(combatKillLog);
+    assertEqual(kill?.type, 'combat', 'type');
+    assertEqual(kill?.source, 'Craterhoof Behemoth (2)', 'last damage to the victim, not to a creature');
+    assertEqual(kill?.victim, 'Ai(2)-Beta', 'victim');
+    assertEqual(kill?.player, 'Ai(1)-Alpha', 'attacker');
+  });
+
+  await test('extractKillingBlow: burn kill credits the caster', () => {
+    const kill = condenseGame(burnKillLog).killingBlow;
+    assertEqual(kill?.type, 'spell', 'type');
+    assertEqual(kill?.source, 'Lightning Bolt (3)', 'source');
+    assertEqual(kill?.victim, 'Ai(1)-Alpha', 'victim');
+    assertEqual(kill?.player, 'Ai(2)-Beta', 'caster');
+  });
+
+  await test('extractKillingBlow: undetermined without a damage line', () => {
+    assertEqual(extractKillingBlow(freeCastLog), undefined, 'life total kill with no damage line');
+    assertEqual(condenseGame(freeCastLog).killingBlow, undefined, 'omitted from condensed game');
+  });
+
+  // =========================================================================
+  // Repeated event compaction
+  // =========================================================================
+
+  const repeatedTriggerLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'repeated-trigger-log.txt'), 'utf-8');
+  const zulaport = 'Resolve stack: Whenever a creature you control dies, each opponent loses 1 life. [Card: Zulaport Cutthroat (2)]';
+
+  await test('condenseGame: compactRepeats collapses a run of identical events', () => {
+    const events = condenseGame(repeatedTriggerLog, { compactRepeats: true }).keptEvents.filter((e) => e.line === zulaport);
+    assertEqual(events.length, 3, 'run of 5, single after Viscera Seer, run of 2 on the next turn');
+    assertEqual(events[0].repeat, 5, 'first run');
+    assertEqual(events[1].repeat, undefined, 'single event has no repeat');
+    assertEqual(events[2].repeat, 2, 'run on the next turn is kept separate');
+  });
+
+  await test('condenseGame: repeats are kept by default', () => {
+    const events = condenseGame(repeatedTriggerLog).keptEvents.filter((e) => e.line === zulaport);
+    assertEqual(events.length, 8, 'every trigger kept');
+    assert(events.every((e) => e.repeat === undefined), 'no repeat counts');
+  });
+
+  await test('compactRepeatedEvents: adds existing repeat counts', () => {
+    const compacted = compactRepeatedEvents([
+      { type: 'combat', line: 'attack', repeat: 3 },
+      { type: 'combat', line: 'attack' },
+      { type: 'combat', line: 'attack', turn: 2 },
+    ]);
+    assertEqual(compacted.length, 2, 'different turn breaks the run');
+    assertEqual(compacted[0].repeat, 4, 'counts summed');
+  });
+
+  // =========================================================================
+  // Player colors
+  // =========================================================================
+
+  const colorsLog = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Land: Ai(1)-Alpha played Forest (1)',
+    'Zone Change: Gravecrawler (2) moved from Graveyard -> Battlefield',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Land: Ai(2)-Beta played Island (3)',
+    'Stack: Ai(1)-Alpha cast Lightning Bolt (4)',
+    'Turn: Turn 3 (Ai(3)-Gamma)',
+    'Land: Ai(3)-Gamma played Plains (1)',
+  ].join('\n');
+  const playerColors = { Alpha: ['B', 'G'], 'Ai(2)-Beta': ['U'] };
+
+  await test('condenseGame: playerColors tags events with the acting player\'s colors', () => {
+    const events = condenseGame(colorsLog, { playerColors }).keptEvents;
+    const colorsOf = (fragment: string) => events.find((e) => e.line.includes(fragment))?.playerColors;
+    assertEqual(JSON.stringify(colorsOf('played Forest')), '["B","G"]', 'named player (deck name key)');
+    assertEqual(JSON.stringify(colorsOf('Gravecrawler')), '["B","G"]', 'unnamed line falls back to the active player');
+    assertEqual(JSON.stringify(colorsOf('played Island')), '["U"]', 'named player (log label key)');
+    assertEqual(JSON.stringify(colorsOf('Lightning Bolt')), '["B","G"]', 'off-turn cast credited to its caster');
+    assertEqual(colorsOf('played Plains'), undefined, 'player missing from the map is untagged');
+  });
+
+  await test('condenseGame: playerColors is omitted without a color map', () => {
+    const plain = condenseGame(colorsLog);
+    assert(plain.keptEvents.every((e) => !('playerColors' in e)), 'no playerColors field');
+    const tagged = condenseGame(colorsLog, { playerColors });
+    assertEqual(
+      JSON.stringify(tagged.keptEvents.map(({ playerColors: _, ...e }) => e)),
+      JSON.stringify(plain.keptEvents),
+      'same events otherwise'
+    );
+  });
+
+  // =========================================================================
+  // WIN_LINE_PATTERN override
+  // =========================================================================
+
+  const customWinLog = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Turn: Turn 3 (Ai(1)-Alpha)',
+    'Turn: Turn 4 (Ai(2)-Beta)',
+    'Turn: Turn 5 (Ai(1)-Alpha)',
+    'Victory: Ai(2)-Beta is the last player standing',
+  ].join('\n');
+
+  await test('condenseGame: custom WIN_LINE_PATTERN finds the winner and winning turn', () => {
+    assertEqual(condenseGame(customWinLog).winner, undefined, 'default patterns miss the custom line');
+    process.env.WIN_LINE_PATTERN = '^Victory: (.+?) is the last player standing';
+    try {
+      const condensed = condenseGame(customWinLog);
+      assertEqual(condensed.winner, 'Ai(2)-Beta', 'winner');
+      assertEqual(condensed.winningTurn, 2, 'Beta took 2 turns');
+      // Logs without the custom line still use the default pattern
+      assertEqual(extractWinner('Ai(1)-Alpha has won!'), 'Ai(1)-Alpha', 'default fallback');
+    } finally {
+      delete process.env.WIN_LINE_PATTERN;
+    }
+  });
+
+  await test('compileWinLinePattern: rejects invalid regex and missing capture group', () => {
+    let invalid = '';
+    try { compileWinLinePattern('(unclosed'); } catch (err) { invalid = (err as Error).message; }
+    assert(invalid.startsWith('Invalid WIN_LINE_PATTERN'), `invalid regex error, got "${invalid}"`);
+    let noGroup = '';
+    try { compileWinLinePattern('is the last player standing'); } catch (err) { noGroup = (err as Error).message; }
+    assert(noGroup.includes('capture group'), `missing group error, got "${noGroup}"`);
+    assert(compileWinLinePattern('^(.+) wins$').test('X WINS'), 'valid pattern is case-insensitive');
+  });
+
+  await test('extractTurnRanges: "Turn" mid-line is not a turn marker', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Game outcome: Turn 20',
+      'Ai(1)-Alpha casts Time Walk on Turn: Turn 2 (Ai(1)-Alpha)',
+      '[LIFE] Life: Ai(1)-Alpha 40 -> 39',
+    ].join('\n');
+    assertEqual(extractTurnRanges(log).length, 1, 'only the real marker counts');
+  });
+
+  await test('detectFormat: current "Turn: Turn N (Player)" markers', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Land: Ai(1)-Alpha played Forest (1)',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+    ].join('\n');
+    assertEqual(detectFormat(log), 'current', 'current format');
+  });
+
+  await test('detectFormat: legacy "Turn N: Player" markers', () => {
+    const log = [
+      'Turn 1: Player A',
+      'Land: Player A played Forest (1)',
+      'Turn 2: Player B',
+      'Land: Player B played Island (2)',
+    ].join('\n');
+    assertEqual(detectFormat(log), 'legacy', 'legacy format');
+    const ranges = extractTurnRanges(log);
+    assertEqual(ranges.length, 2, 'two legacy turns');
+    assertEqual(ranges[0].player, 'Player A', 'first legacy player');
+    assertEqual(ranges[1].player, 'Player B', 'second legacy player');
+  });
+
+  await test('detectFormat: mixed or missing markers are unknown', () => {
+    const mixed = ['Turn: Turn 1 (Ai(1)-Alpha)', 'Turn 2: Ai(2)-Beta'].join('\n');
+    assertEqual(detectFormat(mixed), 'unknown', 'mixed markers');
+    assertEqual(extractTurnRanges(mixed).length, 2, 'unknown format uses both patterns');
+    assertEqual(detectFormat('Land: Alpha played Forest (1)'), 'unknown', 'no markers');
+    assertEqual(detectFormat('Turn 3:\nTurn 4:'), 'unknown', 'bare "Turn N:" is not a marker');
+  });
+
+  await test('extractTurnRanges: detected format ignores the other format\'s markers', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+      'Turn: Turn 3 (Ai(1)-Alpha)',
+      'Turn: Turn 4 (Ai(2)-Beta)',
+      'Turn 5: stray legacy-looking line',
+      'Turn: Turn 5 (Ai(1)-Alpha)',
+    ].join('\n');
+    assertEqual(detectFormat(log), 'current', 'sniffed from the first markers');
+    assertEqual(extractTurnRanges(log).length, 5, 'stray legacy line does not split a turn');
+  });
+
+  await test('indexTurns: helpers give the same results from a shared index', () => {
+    const log = splitConcatenatedGames(rawLog)[0].replace(/\n/g, '\r\n');
+    const turns = indexTurns(log);
+    assert(!turns.log.includes('\r'), 'line endings normalized');
+    assertEqual(JSON.stringify(turns.ranges), JSON.stringify(extractTurnRanges(log)), 'same turn ranges');
+    assertEqual(JSON.stringify(resolveWinner(log, 'draw', turns)), JSON.stringify(resolveWinner(log, 'draw')), 'same winner');
+    assertEqual(JSON.stringify(calculateCastsPerTurn(log, undefined, turns)), JSON.stringify(calculateCastsPerTurn(log)), 'same casts');
+    assertEqual(detectLockStall(log, undefined, turns), detectLockStall(log), 'same lock stall');
+    assertEqual(firstBloodRound(log, turns), firstBloodRound(log), 'same first blood');
+  });
+
+  // =========================================================================
+  // Highlights
+  // =========================================================================
+
+  const highlightLogs = ['mass-land-destruction-log.txt', 'last-standing-log.txt', 'big-turn-log.txt']
+    .map((name) => fs.readFileSync(path.join(__dirname, 'fixtures', name), 'utf-8'));
+
+  await test('buildHighlights: only highlight-tier events, each with its game reference', () => {
+    const condensed = condenseGames(highlightLogs);
+    const feed = buildHighlights(highlightLogs, condensed);
+    assertEqual(feed.games, 3, 'game count');
+    const summary = feed.highlights.map((h) => `${h.gameIndex}:${h.kind}:${h.player ?? h.line ?? h.turn}`);
+    assertEqual(JSON.stringify(summary), JSON.stringify([
+      '0:board_wipe:Resolve stack: Armageddon (4) - Destroy all lands.',
+      '0:board_wipe:Resolve stack: Ravages of War (3) - Destroy all lands.',
+      '0:elimination:Ai(2)-Beta',
+      '0:win:Ai(1)-Alpha',
+      '1:elimination:Ai(2)-Beta',
+      '1:elimination:Ai(3)-Gamma',
+      '1:elimination:Ai(4)-Delta',
+      '1:win:Ai(1)-Alpha',
+      '2:big_turn:2',
+      '2:elimination:Ai(2)-Beta',
+      '2:win:Ai(1)-Alpha',
+      '2:fastest_kill:Ai(1)-Alpha',
+    ]), 'highlights in game then round order');
+    for (const h of feed.highlights) {
+      assertEqual(h.gameId, condensed[h.gameIndex].gameId, `gameId for ${h.kind} in game ${h.gameIndex}`);
+      assert((HIGHLIGHT_KINDS as readonly string[]).includes(h.kind), `highlight kind ${h.kind}`);
+    }
+    assert(!JSON.stringify(feed).includes('Strip Mine'), 'targeted land destruction is not a highlight');
+  });
+
+  await test('buildHighlights: kinds narrow the feed', () => {
+    const feed = buildHighlights(highlightLogs, condenseGames(highlightLogs), ['board_wipe', 'fastest_kill']);
+    assertEqual(JSON.stringify(feed.kinds), JSON.stringify(['board_wipe', 'fastest_kill']), 'kinds recorded');
+    assertEqual(JSON.stringify(feed.highlights.map((h) => h.kind)), JSON.stringify(['board_wipe', 'board_wipe', 'fastest_kill']), 'only requested kinds');
+  });
+
+  await test('buildHighlights: creature wraths are board wipes, rules text is not', () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Resolve stack: Wrath of God (55) - Destroy all creatures. They can\'t be regenerated.',
+      'Turn: Turn 2 (Ai(2)-Beta)',
+      'Resolve stack: Blasphemous Act (9) - Blasphemous Act deals 13 damage to each creature.',
+      'Resolve stack: Murder (3) - Destroy target creature.',
+    ].join('\n');
+    const feed = buildHighlights([log], condenseGames([log]));
+    assertEqual(feed.highlights.filter((h) => h.kind === 'board_wipe').length, 2, 'two wraths');
+  });
+
+  await test('parseHighlightKinds: parses, dedupes and rejects unknown kinds', () => {
+    assertEqual(JSON.stringify(parseHighlightKinds(' Win, board_wipe,win ')), JSON.stringify(['win', 'board_wipe']), 'parsed');
+    let unknown = '';
+    try { parseHighlightKinds('win,combo'); } catch (err) { unknown = (err as Error).message; }
+    assert(unknown.includes('unknown kind "combo"'), `unknown kind error, got "${unknown}"`);
+    let empty = '';
+    try { parseHighlightKinds(' , '); } catch (err) { empty = (err as Error).message; }
+    assert(empty.startsWith('Invalid HIGHLIGHT_KINDS'), `empty list error, got "${empty}"`);
+  });
+
   // =========================================================================
   // Summary
   // =========================================================================
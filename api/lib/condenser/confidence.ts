@@ -0,0 +1,113 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Sample Size Confidence
+ * =============================================================================
+ *
+ * Win rates from a handful of games are mostly noise. This labels a job's
+ * sample size so readers (and anything summarizing the results) know how
+ * much to trust the numbers:
+ *
+ *   - low:    fewer than `low` games
+ *   - medium: `low` up to `high` games
+ *   - high:   `high` games or more
+ *
+ * CONFIDENCE_THRESHOLDS overrides the defaults, e.g. "low=30,high=300"; a
+ * key left out keeps its default.
+ *
+ * =============================================================================
+ */
+
+export type ConfidenceLevel = 'low' | 'medium' | 'high';
+
+/**
+ * Game counts at which confidence rises to medium and to high.
+ */
+export interface ConfidenceThresholds {
+  low: number;
+  high: number;
+}
+
+export const DEFAULT_CONFIDENCE_THRESHOLDS: ConfidenceThresholds = {
+  low: 20,
+  high: 200,
+};
+
+/** Environment variable overriding the thresholds, e.g. "low=30,high=300". */
+export const CONFIDENCE_THRESHOLDS_ENV = 'CONFIDENCE_THRESHOLDS';
+
+const THRESHOLD_KEYS: readonly (keyof ConfidenceThresholds)[] = ['low', 'high'];
+
+/**
+ * Parses a CONFIDENCE_THRESHOLDS value, e.g. "low=30,high=300".
+ *
+ * @throws Error when a key is unknown, a value isn't a positive integer, or
+ *   low isn't below high
+ */
+export function parseConfidenceThresholds(source: string): ConfidenceThresholds {
+  const thresholds = { ...DEFAULT_CONFIDENCE_THRESHOLDS };
+  for (const part of source.split(',')) {
+    if (part.trim().length === 0) continue;
+    const [rawKey, rawValue = ''] = part.split('=', 2);
+    const key = rawKey.trim().toLowerCase() as keyof ConfidenceThresholds;
+    if (!THRESHOLD_KEYS.includes(key)) {
+      throw new Error(`Invalid ${CONFIDENCE_THRESHOLDS_ENV}: unknown key "${rawKey.trim()}" (expected low, high)`);
+    }
+    const value = rawValue.trim();
+    if (!/^\d+$/.test(value) || Number(value) < 1) {
+      throw new Error(`Invalid ${CONFIDENCE_THRESHOLDS_ENV}: "${key}" must be a positive integer, got "${value}"`);
+    }
+    thresholds[key] = Number(value);
+  }
+  if (thresholds.low >= thresholds.high) {
+    throw new Error(
+      `Invalid ${CONFIDENCE_THRESHOLDS_ENV}: low (${thresholds.low}) must be below high (${thresholds.high})`
+    );
+  }
+  return thresholds;
+}
+
+let thresholdOverride: { source: string; thresholds: ConfidenceThresholds } | undefined;
+
+/**
+ * Returns the configured CONFIDENCE_THRESHOLDS, or
+ * DEFAULT_CONFIDENCE_THRESHOLDS when unset. Reparses only when the
+ * environment value changes.
+ *
+ * @throws If CONFIDENCE_THRESHOLDS is set but invalid
+ */
+export function getConfidenceThresholds(): ConfidenceThresholds {
+  const source = process.env[CONFIDENCE_THRESHOLDS_ENV]?.trim();
+  if (!source) return DEFAULT_CONFIDENCE_THRESHOLDS;
+  if (thresholdOverride?.source !== source) {
+    thresholdOverride = { source, thresholds: parseConfidenceThresholds(source) };
+  }
+  return thresholdOverride.thresholds;
+}
+
+export interface SampleConfidence {
+  level: ConfidenceLevel;
+  /** Human-readable label, e.g. "low (<20 games)" */
+  label: string;
+  sampleSize: number;
+}
+
+/**
+ * Labels a sample size with a confidence level.
+ *
+ * @param sampleSize - Number of games played
+ * @param thresholds - Game counts for medium and high confidence (default:
+ *   getConfidenceThresholds())
+ */
+export function sampleConfidence(
+  sampleSize: number,
+  thresholds: ConfidenceThresholds = getConfidenceThresholds()
+): SampleConfidence {
+  const { low, high } = thresholds;
+  if (sampleSize < low) {
+    return { level: 'low', label: `low (<${low} games)`, sampleSize };
+  }
+  if (sampleSize < high) {
+    return { level: 'medium', label: `medium (${low}-${high - 1} games)`, sampleSize };
+  }
+  return { level: 'high', label: `high (>=${high} games)`, sampleSize };
+}
@@ -8,7 +8,19 @@
  * Run with: npx tsx lib/condenser/deck-match.test.ts
  */
 
-import { matchesDeckName, resolveWinnerName } from './deck-match';
+import {
+  matchesDeckName,
+  resolveWinnerName,
+  resolveSeatLabel,
+  seatMapFromDeckNames,
+  normalizeDeckName,
+  DEFAULT_NORMALIZE_RULES,
+  parseWinnerAliases,
+  WINNER_ALIASES_ENV,
+} from './deck-match';
+import * as fs from 'fs';
+import * as path from 'path';
+import { condenseGame } from './index';
 
 // ---------------------------------------------------------------------------
 // Test Utilities
@@ -161,6 +173,104 @@ async function runTests() {
     );
   });
 
+  // =========================================================================
+  // Seat labels
+  // =========================================================================
+
+  await test('seatMapFromDeckNames: Player/Seat/Ai labels follow deck order', () => {
+    const map = seatMapFromDeckNames(deckNames);
+    assertEqual(resolveSeatLabel('Player 2', map), 'Blood Rites', 'Player 2');
+    assertEqual(resolveSeatLabel('seat 3', map), 'Counter Blitz', 'seat 3');
+    assertEqual(resolveSeatLabel('Ai(4)', map), 'World Shaper', 'bare Ai(4)');
+    assertEqual(resolveSeatLabel('Player 5', map), 'Player 5', 'unknown seat unchanged');
+  });
+
+  await test('resolveWinnerName: seat label resolves to deck name', () => {
+    assertEqual(resolveWinnerName('Player 1', deckNames), 'Doran Big Butts', 'seat 1');
+    assertEqual(resolveWinnerName('PLAYER  4', deckNames), 'World Shaper', 'case and spacing ignored');
+  });
+
+  await test('resolveWinnerName: explicit seat map wins over deck order', () => {
+    const map = { 'Player 1': 'Counter Blitz' };
+    assertEqual(resolveWinnerName('Player 1', deckNames, map), 'Counter Blitz', 'explicit map');
+    assertEqual(resolveWinnerName('Player 2', deckNames, map), 'Player 2', 'unmapped seat unchanged');
+  });
+
+  // =========================================================================
+  // Deck label normalization
+  // =========================================================================
+
+  await test('normalizeDeckName: label variants collapse to one key', () => {
+    const variants = [
+      'Blood Rites',
+      'blood rites',
+      '  Blood   Rites ',
+      'Precon: Blood Rites',
+      '[Precon] Blood Rites',
+      'Blood Rites - The Lost Caverns of Ixalan Commander',
+      'Blood Rites (Precon)',
+    ];
+    const keys = new Set(variants.map((v) => normalizeDeckName(v)));
+    assertEqual(keys.size, 1, `one key, got ${JSON.stringify([...keys])}`);
+    assertEqual([...keys][0], 'blood rites', 'key');
+  });
+
+  await test('normalizeDeckName: rules are data-driven', () => {
+    const rules = { ...DEFAULT_NORMALIZE_RULES, lowercase: false, stripPrefixes: ['Test: '] };
+    assertEqual(normalizeDeckName('Test: Blood Rites', rules), 'Blood Rites', 'custom prefix, casing kept');
+    assertEqual(normalizeDeckName('Precon: Blood Rites', rules), 'Precon: Blood Rites', 'default prefix not applied');
+  });
+
+  await test('resolveWinnerName: falls back to normalized labels', () => {
+    assertEqual(resolveWinnerName('Ai(2)-blood  rites', deckNames), 'Blood Rites', 'casing and spacing');
+    assertEqual(resolveWinnerName('Precon: Counter Blitz', deckNames), 'Counter Blitz', 'prefix');
+  });
+
+  // =========================================================================
+  // Winner aliases
+  // =========================================================================
+
+  await test('resolveWinnerName: winner reported by commander name resolves through an alias', () => {
+    const log = fs.readFileSync(path.join(__dirname, 'fixtures', 'commander-winner-log.txt'), 'utf-8');
+    const winner = condenseGame(log).winner!;
+    assertEqual(winner, "Atraxa, Praetors' Voice", 'log names the commander');
+    const names = ['Four Color Counters', 'Goblin Swarm'];
+    assertEqual(resolveWinnerName(winner, names), winner, 'unresolved without an alias');
+    const aliases = { "Atraxa, Praetors' Voice": 'Four Color Counters' };
+    assertEqual(
+      resolveWinnerName(winner, names, undefined, undefined, aliases),
+      'Four Color Counters',
+      'alias resolves the deck'
+    );
+    assertEqual(
+      resolveWinnerName("Ai(1)-ATRAXA,  Praetors' Voice", names, undefined, undefined, aliases),
+      'Four Color Counters',
+      'aliases compare normalized labels'
+    );
+  });
+
+  await test('resolveWinnerName: aliases come from WINNER_ALIASES and need a deck in the job', () => {
+    process.env[WINNER_ALIASES_ENV] = JSON.stringify({ Atraxa: 'Four Color Counters', Krenko: 'Not In This Job' });
+    try {
+      assertEqual(resolveWinnerName('Atraxa', ['Four Color Counters']), 'Four Color Counters', 'env alias');
+      assertEqual(resolveWinnerName('Krenko', ['Four Color Counters']), 'Krenko', 'alias to a deck not in the job');
+      assertEqual(resolveWinnerName('Ai(1)-Four Color Counters', ['Four Color Counters']), 'Four Color Counters', 'names still match');
+    } finally {
+      delete process.env[WINNER_ALIASES_ENV];
+    }
+  });
+
+  await test('parseWinnerAliases: rejects non-objects and non-string decks', () => {
+    const errorOf = (source: string) => {
+      try { parseWinnerAliases(source); } catch (err) { return (err as Error).message; }
+      return '';
+    };
+    assert(errorOf('{bad').startsWith('Invalid WINNER_ALIASES'), 'invalid JSON');
+    assert(errorOf('["Atraxa"]').includes('JSON object'), 'array');
+    assert(errorOf('{"Atraxa": 1}').includes('must be a string'), 'number value');
+    assertEqual(parseWinnerAliases('{"Atraxa": "Deck"}').Atraxa, 'Deck', 'valid');
+  });
+
   // =========================================================================
   // Full tally regression with sample sim data from job bI9EDRyCU3GJDVBqM2Vi
   // =========================================================================
@@ -0,0 +1,98 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Cards Drawn per Player
+ * =============================================================================
+ *
+ * Credits each card drawn to the player who drew it. Symmetric draw effects
+ * (Temple Bell, Howling Mine, "each player draws a card") help the whole
+ * table; crediting them to their controller would make a Howling Mine deck
+ * look like it out-drew everyone when every player drew the same.
+ *
+ * ## Attribution
+ *
+ * A draw line (see countCardsDrawn; stack adds are skipped, their draw is
+ * read from the resolve line) is credited:
+ *
+ *   - "each player draws": to every player still in the game
+ *   - "each opponent draws": to every player still in the game but the
+ *     controller
+ *   - a draw-step trigger for "that player" (Howling Mine): to the player
+ *     whose turn it is
+ *   - anything else: to the controller
+ *
+ * The controller is the line's Activator, else whoever cast the resolving
+ * card id, else the active player. The first three are symmetric draw
+ * events (DETECT_SYMMETRIC_DRAW). Wheels are not: they replace hands
+ * rather than add to them.
+ *
+ * =============================================================================
+ */
+
+import { DETECT_SYMMETRIC_DRAW, EXTRACT_ACTIVATOR, EXTRACT_CARD_OWNER, EXTRACT_RESOLVE_SOURCE } from './patterns';
+import { countCardsDrawn, eliminatedPlayerOf, indexTurns, isEliminated, sliceByTurn, type TurnIndex } from './turns';
+
+/** A spell or ability put on the stack; its draw happens when it resolves. */
+const STACK_ADD = /^\s*(?:Add\s+to\s+stack|Stack):/i;
+
+/**
+ * Counts the cards each player drew, crediting symmetric draws to every
+ * player they reach.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns `perPlayer`: player (as logged) -> cards drawn (players who drew
+ *          none omitted); `symmetricEvents`: symmetric draw lines seen
+ */
+export function cardsDrawnByPlayer(
+  rawLog: string,
+  turns: TurnIndex = indexTurns(rawLog)
+): { perPlayer: Record<string, number>; symmetricEvents: number } {
+  const { log: normalized, ranges } = turns;
+  const players = [...new Set(ranges.map((r) => r.player).filter((p): p is string => !!p))];
+  const owners = new Map<string, string>();
+  const eliminated: string[] = [];
+  const perPlayer: Record<string, number> = {};
+  let symmetricEvents = 0;
+
+  const credit = (player: string, cards: number) => {
+    perPlayer[player] = (perPlayer[player] ?? 0) + cards;
+  };
+
+  for (const { player: activePlayer, chunk } of sliceByTurn(normalized, ranges)) {
+    for (const line of chunk.split('\n')) {
+      const out = eliminatedPlayerOf(line);
+      if (out) {
+        eliminated.push(out);
+        continue;
+      }
+      const owned = EXTRACT_CARD_OWNER.exec(line);
+      if (owned) owners.set(owned[2] ?? owned[4], (owned[1] ?? owned[3]).trim());
+      if (STACK_ADD.test(line)) continue;
+
+      const cards = countCardsDrawn(line);
+      if (cards === 0) continue;
+
+      const sourceId = EXTRACT_RESOLVE_SOURCE.exec(line)?.[1];
+      const controller =
+        EXTRACT_ACTIVATOR.exec(line)?.[1] ?? (sourceId ? owners.get(sourceId) : undefined) ?? activePlayer;
+      const symmetric = DETECT_SYMMETRIC_DRAW.exec(line);
+      if (!symmetric) {
+        if (controller) credit(controller, cards);
+        continue;
+      }
+
+      symmetricEvents++;
+      if (!symmetric[1]) {
+        if (activePlayer) credit(activePlayer, cards);
+        continue;
+      }
+      for (const player of players) {
+        if (isEliminated(player, eliminated)) continue;
+        if (symmetric[1].toLowerCase() === 'opponent' && player === controller) continue;
+        credit(player, cards);
+      }
+    }
+  }
+
+  return { perPlayer, symmetricEvents };
+}
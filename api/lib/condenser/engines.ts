@@ -0,0 +1,80 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Recurring Engines
+ * =============================================================================
+ *
+ * Finds permanents whose upkeep or end-step trigger fires turn after turn:
+ * Phyrexian Arena, Bitterblossom, Sandwurm Convergence. One trigger is a
+ * card doing its job; the same source firing every turn is a card-advantage
+ * or token engine the deck is built around.
+ *
+ * ## Heuristic
+ *
+ * Each turn is scanned for triggered abilities put on the stack
+ * (EXTRACT_TRIGGER_SOURCE) during the upkeep or end step, as told by the
+ * preceding phase marker. Logs without phase markers still count a trigger
+ * whose text says "at the beginning of ... upkeep/end step". A source is a
+ * recurring engine when it triggered in at least `minTurns` different turns.
+ *
+ * Sources are keyed by card name, so two copies of a card are one engine.
+ *
+ * =============================================================================
+ */
+
+import {
+  DETECT_BEGINNING_TRIGGER,
+  DETECT_UPKEEP_OR_END_STEP,
+  EXTRACT_PHASE,
+  EXTRACT_TRIGGER_SOURCE,
+} from './patterns';
+import { indexTurns, sliceByTurn, type TurnIndex } from './turns';
+
+/**
+ * Threshold for recurringEngines.
+ */
+export interface RecurringEngineOptions {
+  /** Different turns a source must trigger in */
+  minTurns: number;
+}
+
+export const DEFAULT_RECURRING_ENGINES: RecurringEngineOptions = {
+  minTurns: 2,
+};
+
+/**
+ * Lists the sources whose upkeep or end-step triggers fired in several turns.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param options - Turns a source must trigger in
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Source card names, sorted; empty when there are none
+ */
+export function recurringEngines(
+  rawLog: string,
+  options: RecurringEngineOptions = DEFAULT_RECURRING_ENGINES,
+  turns: TurnIndex = indexTurns(rawLog)
+): string[] {
+  const { log: normalized, ranges } = turns;
+  const turnsBySource = new Map<string, Set<number>>();
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    let inUpkeepOrEnd = false;
+    for (const line of chunk.split('\n')) {
+      const phase = line.match(EXTRACT_PHASE);
+      if (phase) {
+        inUpkeepOrEnd = DETECT_UPKEEP_OR_END_STEP.test(phase[1]);
+        continue;
+      }
+      const trigger = line.match(EXTRACT_TRIGGER_SOURCE);
+      if (!trigger || !(inUpkeepOrEnd || DETECT_BEGINNING_TRIGGER.test(line))) continue;
+      const source = trigger[1];
+      if (!turnsBySource.has(source)) turnsBySource.set(source, new Set());
+      turnsBySource.get(source)!.add(turnNumber);
+    }
+  }
+
+  return [...turnsBySource]
+    .filter(([, turns]) => turns.size >= options.minTurns)
+    .map(([source]) => source)
+    .sort((a, b) => a.localeCompare(b));
+}
@@ -0,0 +1,197 @@
+/**
+ * Tests for the per-deck explosiveness score.
+ *
+ * Run with: npx tsx lib/condenser/explosiveness.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { StructuredGame, DeckAction, EventType } from '../types';
+import {
+  explosivenessScore,
+  explosivenessComponents,
+  DEFAULT_EXPLOSIVENESS_WEIGHTS,
+  COMBO_SPELLS_PER_TURN,
+  ritualsPerGame,
+} from './explosiveness';
+import { structureGames } from './index';
+import { splitConcatenatedGames } from './patterns';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const FIXTURE_PATH = path.join(__dirname, 'fixtures', 'real-4game-log.txt');
+
+function action(line: string, eventType?: EventType): DeckAction {
+  return eventType ? { line, eventType } : { line };
+}
+
+function manaLines(count: number): DeckAction[] {
+  return Array.from({ length: count }, () => action('adds {G} to mana pool'));
+}
+
+function spellLines(count: number): DeckAction[] {
+  return Array.from({ length: count }, (_, i) => action(`casts Ritual ${i} (1)`, 'spell_cast'));
+}
+
+/**
+ * A two-deck game: "Fast" ramps hard on turns 3-4, while "Slow" only plays
+ * lands. The winner and winning turn are set by the caller.
+ */
+function makeGame(winner: string | undefined, winningTurn: number | undefined, fastStorm: boolean): StructuredGame {
+  return {
+    totalTurns: winningTurn ?? 12,
+    players: ['Ai(1)-Fast', 'Ai(2)-Slow'],
+    turns: [],
+    decks: [
+      {
+        deckLabel: 'Fast',
+        turns: [
+          { turnNumber: 1, actions: [action('Land: Forest', 'land_played')] },
+          { turnNumber: 3, actions: manaLines(6) },
+          { turnNumber: 4, actions: [...manaLines(6), ...(fastStorm ? spellLines(COMBO_SPELLS_PER_TURN) : [])] },
+        ],
+      },
+      {
+        deckLabel: 'Slow',
+        turns: [
+          { turnNumber: 3, actions: [action('Land: Plains', 'land_played')] },
+          { turnNumber: 4, actions: [action('Land: Plains', 'land_played')] },
+        ],
+      },
+    ],
+    ...(winner && { winner }),
+    ...(winningTurn !== undefined && { winningTurn }),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running explosiveness tests...\n');
+
+  await test('explosivenessScore: fast combo deck scores far above slow deck', () => {
+    const games = [
+      makeGame('Ai(1)-Fast', 4, true),
+      makeGame('Ai(1)-Fast', 5, true),
+      makeGame('Ai(2)-Slow', 12, false),
+    ];
+    const fast = explosivenessScore('Fast', games);
+    const slow = explosivenessScore('Slow', games);
+    assert(fast >= 80, `fast deck should score high, got ${fast}`);
+    assert(slow <= 10, `slow deck should score low, got ${slow}`);
+  });
+
+  await test('explosivenessComponents: each component is normalized to 0-1', () => {
+    const games = [makeGame('Ai(1)-Fast', 4, true), makeGame('Ai(1)-Fast', 6, false)];
+    const c = explosivenessComponents('Fast', games);
+    assertEqual(c.earlyMana, 1, 'six mana events per early turn caps earlyMana');
+    assertEqual(c.winSpeed, 0.875, 'average win turn 5 between turn 4 and 12');
+    assertEqual(c.combo, 0.5, 'storm turn in one of two games');
+  });
+
+  await test('explosivenessComponents: deck with no wins has zero win speed', () => {
+    const c = explosivenessComponents('Slow', [makeGame('Ai(1)-Fast', 5, false)]);
+    assertEqual(c.winSpeed, 0, 'no wins');
+    assertEqual(c.earlyMana, 0, 'no mana events');
+  });
+
+  await test('explosivenessScore: weights are configurable', () => {
+    const games = [makeGame('Ai(2)-Slow', 12, true)];
+    // Fast never wins here but ramps every game, so a mana-only weighting maxes it out.
+    assertEqual(explosivenessScore('Fast', games, { earlyMana: 1, winSpeed: 0, combo: 0 }), 100, 'mana only');
+    assertEqual(explosivenessScore('Fast', games, { earlyMana: 0, winSpeed: 1, combo: 0 }), 0, 'speed only');
+    assertEqual(explosivenessScore('Fast', games, { earlyMana: 0, winSpeed: 0, combo: 0 }), 0, 'zero weights');
+  });
+
+  await test('explosivenessScore: unknown deck or no games scores 0', () => {
+    assertEqual(explosivenessScore('Missing', [makeGame('Ai(1)-Fast', 4, true)]), 0, 'unknown deck');
+    assertEqual(explosivenessScore('Fast', []), 0, 'no games');
+  });
+
+  await test('ritualsPerGame: averages a deck\'s rituals over its games', () => {
+    const ritualLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'ritual-log.txt'), 'utf-8');
+    const games = structureGames([ritualLog, ritualLog]);
+    assertEqual(ritualsPerGame('Alpha', games), 2, 'two rituals each game');
+    assertEqual(ritualsPerGame('Beta', games), 0, 'Sol Ring and Birgi are not rituals');
+    assertEqual(ritualsPerGame('Nobody', games), 0, 'deck not in the job');
+  });
+
+  await test('explosivenessScore: real fixture scores stay within 0-100', () => {
+    const deckNames = ['Doran Big Butts', 'Enduring Enchantments', 'Explorers of the Deep', 'Veloci-RAMP-Tor'];
+    const games = structureGames(splitConcatenatedGames(fs.readFileSync(FIXTURE_PATH, 'utf-8')), deckNames);
+    for (const name of deckNames) {
+      const score = explosivenessScore(name, games, DEFAULT_EXPLOSIVENESS_WEIGHTS);
+      assert(score >= 0 && score <= 100, `${name} score out of range: ${score}`);
+    }
+    assert(
+      explosivenessScore('Enduring Enchantments', games) > explosivenessScore('Veloci-RAMP-Tor', games),
+      'a deck with wins should outscore a deck with none'
+    );
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,197 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Explosiveness Score
+ * =============================================================================
+ *
+ * A single 0-100 number per deck summarizing how quickly it develops mana
+ * and wins, for a quick read on whether a deck fits a low bracket.
+ *
+ * This is a HEURISTIC built from log text, not a rules-accurate measure.
+ * It combines three components, each normalized to 0-1:
+ *
+ *   - earlyMana: average mana events on the deck's own turns 3-4
+ *                (EARLY_MANA_CAP or more per turn scores 1)
+ *   - winSpeed:  average winning turn across the deck's wins
+ *                (FAST_WIN_TURN or earlier scores 1, SLOW_WIN_TURN or later 0;
+ *                a deck with no wins scores 0)
+ *   - combo:     fraction of games with a "storm" turn, i.e. a turn where the
+ *                deck cast COMBO_SPELLS_PER_TURN or more spells
+ *
+ * The weighted average of the components is scaled to 0-100. Weights are
+ * configurable and don't need to sum to 1.
+ *
+ * ritualsPerGame is reported next to the score rather than folded into it:
+ * how many rituals (Dark Ritual, Seething Song) a deck resolves per game.
+ *
+ * =============================================================================
+ */
+
+import type { StructuredGame, DeckHistory } from '../types';
+import { countManaEvents } from './turns';
+import { matchesDeckName } from './deck-match';
+import { SPELL_CAST_EVENT_TYPES } from './classify';
+
+// -----------------------------------------------------------------------------
+// Configuration
+// -----------------------------------------------------------------------------
+
+/**
+ * The score components, each normalized to 0-1.
+ */
+export interface ExplosivenessComponents {
+  earlyMana: number;
+  winSpeed: number;
+  combo: number;
+}
+
+/**
+ * Relative weight of each score component.
+ */
+export type ExplosivenessWeights = ExplosivenessComponents;
+
+export const DEFAULT_EXPLOSIVENESS_WEIGHTS: ExplosivenessWeights = {
+  earlyMana: 0.35,
+  winSpeed: 0.45,
+  combo: 0.2,
+};
+
+/** Mana events per turn (on turns 3-4) that count as fully explosive. */
+export const EARLY_MANA_CAP = 6;
+
+/** Average winning turn at or below which winSpeed is 1. */
+export const FAST_WIN_TURN = 4;
+
+/** Average winning turn at or above which winSpeed is 0. */
+export const SLOW_WIN_TURN = 12;
+
+/** Spells cast in a single turn that mark a storm/combo turn. */
+export const COMBO_SPELLS_PER_TURN = 8;
+
+const EARLY_TURNS = [3, 4];
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+function clamp01(value: number): number {
+  return Math.min(1, Math.max(0, value));
+}
+
+function average(values: number[]): number | undefined {
+  if (values.length === 0) return undefined;
+  return values.reduce((a, b) => a + b, 0) / values.length;
+}
+
+function findDeck(game: StructuredGame, deckName: string): DeckHistory | undefined {
+  return game.decks.find((d) => matchesDeckName(d.deckLabel, deckName));
+}
+
+function countSpellsCast(turn: DeckHistory['turns'][number]): number {
+  return turn.actions.filter((a) => a.eventType !== undefined && SPELL_CAST_EVENT_TYPES.has(a.eventType)).length;
+}
+
+// -----------------------------------------------------------------------------
+// Components
+// -----------------------------------------------------------------------------
+
+/**
+ * Computes the normalized (0-1) score components for a deck.
+ *
+ * @param deckName - Deck name as used in deckNames / deck labels
+ * @param games - Structured games the deck played in
+ */
+export function explosivenessComponents(
+  deckName: string,
+  games: StructuredGame[]
+): ExplosivenessComponents {
+  const earlyMana: number[] = [];
+  const winTurns: number[] = [];
+  let comboGames = 0;
+  let gamesPlayed = 0;
+
+  for (const game of games) {
+    const deck = findDeck(game, deckName);
+    if (!deck) continue;
+    gamesPlayed++;
+
+    for (const turnNumber of EARLY_TURNS) {
+      const turn = deck.turns.find((t) => t.turnNumber === turnNumber);
+      if (turn) {
+        // Count per line so one match can't swallow several mana lines
+        earlyMana.push(turn.actions.reduce((sum, a) => sum + countManaEvents(a.line), 0));
+      }
+    }
+
+    if (deck.turns.some((t) => countSpellsCast(t) >= COMBO_SPELLS_PER_TURN)) {
+      comboGames++;
+    }
+
+    if (game.winner && game.winningTurn !== undefined && matchesDeckName(game.winner, deckName)) {
+      winTurns.push(game.winningTurn);
+    }
+  }
+
+  const avgMana = average(earlyMana);
+  const avgWinTurn = average(winTurns);
+
+  return {
+    earlyMana: avgMana === undefined ? 0 : clamp01(avgMana / EARLY_MANA_CAP),
+    winSpeed:
+      avgWinTurn === undefined
+        ? 0
+        : clamp01((SLOW_WIN_TURN - avgWinTurn) / (SLOW_WIN_TURN - FAST_WIN_TURN)),
+    combo: gamesPlayed === 0 ? 0 : comboGames / gamesPlayed,
+  };
+}
+
+// -----------------------------------------------------------------------------
+// Score
+// -----------------------------------------------------------------------------
+
+/**
+ * Heuristic 0-100 explosiveness score for a deck across a job's games.
+ *
+ * @param deckName - Deck name as used in deckNames / deck labels
+ * @param games - Structured games for the job
+ * @param weights - Optional component weights (defaults to DEFAULT_EXPLOSIVENESS_WEIGHTS)
+ * @returns Score rounded to one decimal place; 0 if the deck played no games
+ */
+export function explosivenessScore(
+  deckName: string,
+  games: StructuredGame[],
+  weights: ExplosivenessWeights = DEFAULT_EXPLOSIVENESS_WEIGHTS
+): number {
+  const totalWeight = weights.earlyMana + weights.winSpeed + weights.combo;
+  if (totalWeight <= 0) return 0;
+
+  const c = explosivenessComponents(deckName, games);
+  const weighted =
+    c.earlyMana * weights.earlyMana + c.winSpeed * weights.winSpeed + c.combo * weights.combo;
+  return Math.round((weighted / totalWeight) * 1000) / 10;
+}
+
+// -----------------------------------------------------------------------------
+// Rituals
+// -----------------------------------------------------------------------------
+
+/**
+ * Average rituals resolved per game by a deck, a signal of one-shot mana
+ * bursts that the mana-event component doesn't tell apart from land taps.
+ *
+ * @param deckName - Deck name as used in deckNames / deck labels
+ * @param games - Structured games for the job
+ * @returns Rituals per game played, rounded to one decimal place; 0 if the deck played no games
+ */
+export function ritualsPerGame(deckName: string, games: StructuredGame[]): number {
+  let rituals = 0;
+  let gamesPlayed = 0;
+  for (const game of games) {
+    const deck = findDeck(game, deckName);
+    if (!deck) continue;
+    gamesPlayed++;
+    for (const turn of deck.turns) {
+      rituals += turn.actions.filter((a) => a.eventType === 'ritual').length;
+    }
+  }
+  return gamesPlayed === 0 ? 0 : Math.round((rituals / gamesPlayed) * 10) / 10;
+}
@@ -0,0 +1,113 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Extra Turns
+ * =============================================================================
+ *
+ * Attributes extra turns (Time Warp, Nexus of Fate, Expropriate) to the
+ * player who took them, and flags games won by chaining them. A one-off
+ * Time Warp is a good turn; taking turn after turn until the table is dead
+ * is a combo, a much stronger power signal.
+ *
+ * ## Detection
+ *
+ * Forge numbers every turn, extra or not, and names its active player
+ * ("Turn: Turn 7 (Ai(1)-Alpha)"). A turn taken by the same player as the
+ * turn before it is an extra turn. Markers that repeat a turn number are
+ * ignored, so a re-logged marker isn't read as an extra turn.
+ *
+ * A game is an extra-turn combo when the winner took at least
+ * `minConsecutive` extra turns in a row and the game ended in that streak:
+ * with the default of 2, the winner took the game's last three turns.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { indexTurns, type TurnIndex, type TurnRange } from './turns';
+import { matchesDeckName, resolveWinnerName } from './deck-match';
+
+/**
+ * Threshold for extraTurnCombo.
+ */
+export interface ExtraTurnOptions {
+  /** Consecutive extra turns ending the game for a combo */
+  minConsecutive: number;
+}
+
+export const DEFAULT_EXTRA_TURN: ExtraTurnOptions = {
+  minConsecutive: 2,
+};
+
+/** Turn markers with a player, in order, without repeated turn numbers. */
+function playerTurns(ranges: readonly TurnRange[]): Array<TurnRange & { player: string }> {
+  const turns: Array<TurnRange & { player: string }> = [];
+  for (const range of ranges) {
+    if (!range.player) continue;
+    const last = turns[turns.length - 1];
+    if (last && range.turnNumber <= last.turnNumber) continue;
+    turns.push({ ...range, player: range.player });
+  }
+  return turns;
+}
+
+/**
+ * Counts the extra turns each player took.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param index - The game's turn index (built if not provided)
+ * @returns Map of player (as logged) -> extra turns. Players who took none
+ *          are omitted.
+ */
+export function extraTurnsTaken(rawLog: string, index: TurnIndex = indexTurns(rawLog)): Record<string, number> {
+  const turns = playerTurns(index.ranges);
+  const result: Record<string, number> = {};
+  for (let i = 1; i < turns.length; i++) {
+    if (turns[i].player === turns[i - 1].player) {
+      result[turns[i].player] = (result[turns[i].player] ?? 0) + 1;
+    }
+  }
+  return result;
+}
+
+/**
+ * True when the winner chained extra turns through the end of the game.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param winner - The game's winner (as extracted), if any
+ * @param options - Extra turns in a row needed
+ * @param index - The game's turn index (built if not provided)
+ */
+export function extraTurnCombo(
+  rawLog: string,
+  winner: string | undefined,
+  options: ExtraTurnOptions = DEFAULT_EXTRA_TURN,
+  index: TurnIndex = indexTurns(rawLog)
+): boolean {
+  if (!winner) return false;
+  const turns = playerTurns(index.ranges);
+  if (turns.length === 0 || !matchesDeckName(turns[turns.length - 1].player, winner)) return false;
+
+  let streak = 0;
+  for (let i = turns.length - 1; i > 0 && turns[i].player === turns[i - 1].player; i--) {
+    streak++;
+  }
+  return streak >= options.minConsecutive;
+}
+
+/**
+ * Counts each deck's extra-turn combo wins across a job's games.
+ *
+ * @param games - Condensed games (with extraTurnCombo)
+ * @param deckNames - Deck names; winners are resolved against these
+ * @returns Deck -> extra-turn combo wins, with every deck in deckNames
+ *          listed (0 when none)
+ */
+export function extraTurnComboCounts(games: CondensedGame[], deckNames: string[]): Record<string, number> {
+  const counts: Record<string, number> = Object.fromEntries(deckNames.map((name) => [name, 0]));
+  for (const game of games) {
+    if (!game.extraTurnCombo || !game.winner) continue;
+    const deck = resolveWinnerName(game.winner, deckNames);
+    counts[deck] = (counts[deck] ?? 0) + 1;
+  }
+  return counts;
+}
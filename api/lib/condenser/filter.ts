@@ -119,9 +119,24 @@ export function filterLines(lines: string[]): string[] {
  * // Result: ["Turn 1: Player A", "Player A casts Sol Ring."]
  */
 export function splitAndFilter(rawLog: string): string[] {
-  // Split on newlines (handles both \n and \r\n)
-  const lines = rawLog.split(/\r?\n/);
+  return splitAndFilterNumbered(rawLog).lines;
+}
 
-  // Apply filter to remove noise
-  return filterLines(lines);
+/**
+ * Like splitAndFilter, but also returns each kept line's 1-based line
+ * number in the raw log, so events can point back at their source line.
+ *
+ * @param rawLog - The complete raw log text for a game
+ * @returns The kept lines and a parallel array of their line numbers
+ */
+export function splitAndFilterNumbered(rawLog: string): { lines: string[]; lineNumbers: number[] } {
+  const lines: string[] = [];
+  const lineNumbers: number[] = [];
+  // Split on newlines (handles both \n and \r\n), then remove noise
+  rawLog.split(/\r?\n/).forEach((line, index) => {
+    if (shouldIgnoreLine(line)) return;
+    lines.push(line);
+    lineNumbers.push(index + 1);
+  });
+  return { lines, lineNumbers };
 }
@@ -0,0 +1,198 @@
+/**
+ * Fuzz tests for the condenser pipeline.
+ *
+ * Feeds deterministic pseudo-random log text (a mix of real fixture lines,
+ * Forge-like fragments, and arbitrary characters) through condenseGame,
+ * structureGame, splitConcatenatedGames and extractTurnRanges, and checks
+ * that they never throw and never produce non-finite or negative numbers.
+ *
+ * The regression corpus at the bottom holds inputs that previously made
+ * the pipeline take seconds per line.
+ *
+ * Run with: npx tsx lib/condenser/fuzz.test.ts
+ * Set FUZZ_ITERATIONS / FUZZ_SEED to run a longer or different campaign.
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import {
+  condenseGame,
+  structureGame,
+  splitConcatenatedGames,
+  extractTurnRanges,
+} from './index';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const FIXTURE_PATH = path.join(__dirname, 'fixtures', 'real-4game-log.txt');
+const FIXTURE_LINES = fs.readFileSync(FIXTURE_PATH, 'utf-8').split('\n');
+
+const ITERATIONS = Number(process.env.FUZZ_ITERATIONS ?? 500);
+const SEED = Number(process.env.FUZZ_SEED ?? 1);
+
+/** Forge-like fragments that steer generated input toward parser edge cases. */
+const FRAGMENTS = [
+  'Turn ', 'Turn: ', 'Turn 0', 'Turn -1', 'Turn 99999999999999999999', '(Ai(1)-Alpha)', 'Ai(', '(', ')',
+  '\n', '\r\n', '\r', ' ', 'Game outcome: ', ' has won', ' has lost', 'Game Result: Game 1 ended',
+  '[LIFE] Life: ', ' -> ', 'casts ', '(CMC ', 'Land: ', 'Mana: ', 'wins the game', '\u0000', '\ud800', '😀',
+  '|', ':', '-', '1', 'NaN', 'Infinity',
+];
+
+/** Small LCG so failures reproduce from FUZZ_SEED alone. */
+function makeRandom(seed: number): (n: number) => number {
+  let state = seed;
+  return (n) => {
+    state = (state * 1103515245 + 12345) & 0x7fffffff;
+    return state % n;
+  };
+}
+
+function generateLog(rand: (n: number) => number): string {
+  const parts: string[] = [];
+  const count = rand(60);
+  for (let i = 0; i < count; i++) {
+    switch (rand(3)) {
+      case 0:
+        parts.push(FRAGMENTS[rand(FRAGMENTS.length)]);
+        break;
+      case 1:
+        parts.push(FIXTURE_LINES[rand(FIXTURE_LINES.length)] + '\n');
+        break;
+      default:
+        parts.push(String.fromCharCode(rand(0x3000)));
+    }
+  }
+  return parts.join('');
+}
+
+/** Throws if any number in the value is non-finite or negative. */
+function assertSaneNumbers(value: unknown, where: string): void {
+  if (typeof value === 'number') {
+    assert(Number.isFinite(value) && value >= 0, `${where} is ${value}`);
+    return;
+  }
+  if (value && typeof value === 'object') {
+    for (const [key, child] of Object.entries(value)) {
+      assertSaneNumbers(child, `${where}.${key}`);
+    }
+  }
+}
+
+function runPipeline(input: string): void {
+  const condensed = condenseGame(input);
+  assertSaneNumbers(condensed, 'condensed');
+  assertSaneNumbers(structureGame(input, ['Alpha', 'Beta']), 'structured');
+  const games = splitConcatenatedGames(input);
+  assert(Array.isArray(games), 'splitConcatenatedGames should return an array');
+  let previousOffset = 0;
+  for (const range of extractTurnRanges(input)) {
+    assert(range.startOffset >= previousOffset, 'turn ranges should be in log order');
+    assert(range.startOffset <= input.length, 'turn range should start inside the log');
+    previousOffset = range.startOffset;
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Regression corpus
+// ---------------------------------------------------------------------------
+
+/**
+ * Long single lines that used to be quadratic in extractWinner and the mana
+ * and life patterns (seconds per line at 30-50k characters).
+ */
+const SLOW_LINE_SEEDS = ['(', '1', 'casts ', 'Turn 1 ', 'tap ', 'adds ', 'Ai(1)-', 'Game outcome: '];
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running fuzz tests...\n');
+
+  await test(`fuzz: ${ITERATIONS} generated logs (seed ${SEED}) never throw or emit bad numbers`, () => {
+    const rand = makeRandom(SEED);
+    for (let i = 0; i < ITERATIONS; i++) {
+      const input = generateLog(rand);
+      try {
+        runPipeline(input);
+      } catch (error) {
+        const message = error instanceof Error ? error.message : String(error);
+        throw new Error(`iteration ${i}: ${message}\n  input: ${JSON.stringify(input.slice(0, 500))}`);
+      }
+    }
+  });
+
+  await test('fuzz: empty and whitespace-only logs', () => {
+    for (const input of ['', ' ', '\n', '\r\n\r\n', '\t']) {
+      runPipeline(input);
+    }
+  });
+
+  await test('fuzz: regression corpus of long lines completes quickly', () => {
+    for (const seed of SLOW_LINE_SEEDS) {
+      const line = seed.repeat(Math.ceil(50_000 / seed.length));
+      const start = performance.now();
+      runPipeline(line);
+      const elapsed = performance.now() - start;
+      assert(elapsed < 1000, `${JSON.stringify(seed)} line took ${elapsed.toFixed(0)}ms`);
+    }
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
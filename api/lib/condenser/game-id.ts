@@ -0,0 +1,85 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Game IDs
+ * =============================================================================
+ *
+ * Games in a job are stored as an array, so an index stops pointing at the
+ * same game once a log is added, removed or reordered. A game ID derived
+ * from the log content stays the same across re-runs and merges.
+ *
+ * ## Hashing
+ *
+ * The ID is a SHA-256 of the raw log with line endings normalized and
+ * trailing whitespace trimmed, so the same game read from a CRLF file or
+ * re-uploaded with a trailing newline keeps its ID. Logs with the same seed
+ * and decks still differ in their lines, so nothing beyond the text is
+ * hashed.
+ *
+ * ## Backfill
+ *
+ * Condensed artifacts written before game IDs existed lack them.
+ * backfillGameIds fills them in from the stored raw logs when there is one
+ * per game; otherwise (raw logs cleaned up, or a count that doesn't line up)
+ * it hashes the condensed game itself. Those IDs won't match what a fresh
+ * ingest of the raw log would give, but they are stable. Games that already
+ * have an ID keep it, so a backfill can be re-run safely.
+ *
+ * =============================================================================
+ */
+
+import { createHash } from 'crypto';
+import type { CondensedGame } from '../types';
+
+/** Hex characters kept from the hash (64 bits). */
+export const GAME_ID_LENGTH = 16;
+
+/**
+ * Derives a stable ID for one game from its raw log.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns A hex string of GAME_ID_LENGTH characters
+ *
+ * @example
+ * gameIdFromLog(log) // "3f1c9a0b7d2e4f65"
+ */
+export function gameIdFromLog(rawLog: string): string {
+  const normalized = rawLog
+    .replace(/\r\n?/g, '\n')
+    .split('\n')
+    .map((line) => line.trimEnd())
+    .join('\n')
+    .trimEnd();
+  return createHash('sha256').update(normalized, 'utf-8').digest('hex').slice(0, GAME_ID_LENGTH);
+}
+
+/**
+ * Derives a stable ID for a condensed game whose raw log is gone. Any
+ * existing gameId is left out of the hash.
+ */
+export function gameIdFromCondensed(game: CondensedGame): string {
+  const { gameId: _gameId, ...rest } = game;
+  return createHash('sha256').update(JSON.stringify(rest), 'utf-8').digest('hex').slice(0, GAME_ID_LENGTH);
+}
+
+/**
+ * Adds a gameId to each condensed game that lacks one.
+ *
+ * @param games - A job's condensed games
+ * @param rawLogs - The job's stored raw logs, one per game in the same
+ *   order; ignored unless there is exactly one per game
+ * @returns The games (new objects where an ID was added) and how many got
+ *          an ID
+ */
+export function backfillGameIds(
+  games: CondensedGame[],
+  rawLogs?: string[] | null
+): { games: CondensedGame[]; added: number } {
+  const fromRaw = rawLogs?.length === games.length;
+  let added = 0;
+  const result = games.map((game, i) => {
+    if (game.gameId) return game;
+    added++;
+    return { ...game, gameId: fromRaw ? gameIdFromLog(rawLogs![i]) : gameIdFromCondensed(game) };
+  });
+  return { games: result, added };
+}
@@ -0,0 +1,135 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Goad
+ * =============================================================================
+ *
+ * Counts goad and "must attack if able" lines and finds the attacks they
+ * forced, so combat events can separate forced attacks from voluntary ones.
+ *
+ * ## Who is goaded
+ *
+ * Goad lines name the goaded creature by card id ("Grizzly Bears (2) is
+ * goaded"); the goading player is taken to be the ACTIVE player, which
+ * holds for sorcery-speed goad and ETB triggers. A mass goad ("goads each
+ * creature you don't control") covers every creature the goading player
+ * doesn't control.
+ *
+ * ## How long
+ *
+ * Goad lasts until the goading player's next turn, so a goad expires when
+ * that player's next turn marker is reached.
+ *
+ * ## Goaded attacks
+ *
+ * An attack line ("... assigned X (12) to attack ...") is goaded when one of
+ * its attackers is goaded, or when a mass goad by another player is active.
+ *
+ * =============================================================================
+ */
+
+import type { GameEvent } from '../types';
+import { KEEP_GOAD, DETECT_MASS_GOAD, EXTRACT_ACTIVE_PLAYER } from './patterns';
+import { matchesDeckName } from './deck-match';
+
+/** The attacking creatures of a Forge attack declaration. */
+const EXTRACT_ATTACKERS = /\bassigned\s+(.{1,400}?)\s+to\s+attack\b/i;
+
+/** Card ids, e.g. "(12)"; not the seat number in a player label like "Ai(2)-Beta" */
+const CARD_ID = /(?<!\bAi)\((\d+)\)/g;
+
+/**
+ * Goad counts for a single game.
+ */
+export interface GoadStats {
+  /** Goad and "must attack if able" lines */
+  goadCount: number;
+  /** Attack lines forced by an active goad, trimmed as event lines are */
+  goadedAttackLines: Set<string>;
+}
+
+function samePlayer(a: string, b: string): boolean {
+  return matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+function eventLine(line: string): string {
+  return line.trim().slice(0, 200);
+}
+
+/**
+ * Ids of the creatures a goad line applies to. Only the text after the last
+ * " - " counts, so the source card in "Resolve stack: Source (3) - ..." isn't
+ * mistaken for a target.
+ */
+function goadedIds(line: string): string[] {
+  const effect = line.slice(line.lastIndexOf(' - ') + 1);
+  const goads = /\bgoads\s+/i.exec(effect);
+  const targets = goads ? effect.slice(goads.index + goads[0].length) : effect;
+  return [...targets.matchAll(CARD_ID)].map((m) => m[1]);
+}
+
+/**
+ * Walks a game's filtered lines, counting goad lines and collecting the
+ * attack lines they forced.
+ *
+ * @param lines - Filtered log lines, in order, including turn markers
+ * @returns Goad counts
+ */
+export function calculateGoadStats(lines: string[]): GoadStats {
+  const stats: GoadStats = { goadCount: 0, goadedAttackLines: new Set() };
+  // Goaded card id -> goading player
+  const goaded = new Map<string, string>();
+  const massGoaders = new Set<string>();
+  let active: string | undefined;
+
+  for (const line of lines) {
+    const turn = EXTRACT_ACTIVE_PLAYER.exec(line);
+    if (turn) {
+      active = (turn[1] ?? turn[2])?.trim();
+      if (!active) continue;
+      for (const [id, goader] of goaded) {
+        if (samePlayer(goader, active)) goaded.delete(id);
+      }
+      for (const goader of massGoaders) {
+        if (samePlayer(goader, active)) massGoaders.delete(goader);
+      }
+      continue;
+    }
+
+    if (KEEP_GOAD.test(line)) {
+      stats.goadCount++;
+      if (!active) continue;
+      if (DETECT_MASS_GOAD.test(line)) {
+        massGoaders.add(active);
+      } else {
+        for (const id of goadedIds(line)) goaded.set(id, active);
+      }
+      continue;
+    }
+
+    const attackers = EXTRACT_ATTACKERS.exec(line)?.[1];
+    if (!attackers || !active) continue;
+    const attacker = active;
+    const forced =
+      [...massGoaders].some((goader) => !samePlayer(goader, attacker)) ||
+      [...attackers.matchAll(CARD_ID)].some((m) => goaded.has(m[1]));
+    if (forced) stats.goadedAttackLines.add(eventLine(line));
+  }
+
+  return stats;
+}
+
+/**
+ * Marks combat events whose line is a goaded attack with `goaded: true`.
+ * Lines are matched by text, the way colors.ts credits players.
+ *
+ * @param events - Classified events
+ * @param stats - Goad stats from the same game's lines
+ */
+export function markGoadedAttacks(events: GameEvent[], stats: GoadStats): void {
+  if (stats.goadedAttackLines.size === 0) return;
+  for (const event of events) {
+    if (event.type === 'combat' && stats.goadedAttackLines.has(event.line)) {
+      event.goaded = true;
+    }
+  }
+}
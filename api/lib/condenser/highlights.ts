@@ -0,0 +1,190 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Highlights
+ * =============================================================================
+ *
+ * Collects the highest-value moments of a job's games into one small feed,
+ * `highlights.json`, for a dashboard that shouldn't load every condensed
+ * game. Each highlight points back to its game by index (job order) and
+ * gameId.
+ *
+ * ## Kinds
+ *
+ *   - win:          the game's winner and winning turn
+ *   - elimination:  a player losing or conceding
+ *   - board_wipe:   a wrath or mass land destruction resolving
+ *   - big_turn:     a round with an explosive turn (see big-turns.ts); the
+ *                   closest thing to combo detection the condenser has
+ *   - fastest_kill: the job's quickest win (several on a tie)
+ *
+ * Every kind is collected by default. HIGHLIGHT_KINDS, a comma-separated
+ * list of kinds, narrows the feed.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { DETECT_BOARD_WIPE, DETECT_MASS_LAND_DESTRUCTION } from './patterns';
+import { eliminatedPlayerOf, extractTurnRanges, getNumPlayers, segmentToRound, sliceByTurn } from './turns';
+import { shouldIgnoreLine } from './filter';
+
+export const HIGHLIGHT_KINDS = ['win', 'elimination', 'board_wipe', 'big_turn', 'fastest_kill'] as const;
+
+export type HighlightKind = (typeof HIGHLIGHT_KINDS)[number];
+
+/** Environment variable holding a comma-separated list of highlight kinds. */
+export const HIGHLIGHT_KINDS_ENV = 'HIGHLIGHT_KINDS';
+
+/** Longest log line kept on a highlight, matching the event line cap. */
+const MAX_HIGHLIGHT_LINE_LENGTH = 200;
+
+/**
+ * One highlight and the game it came from.
+ */
+export interface Highlight {
+  kind: HighlightKind;
+  /** 0-based index of the game in the job */
+  gameIndex: number;
+  gameId?: string;
+  /** Round the highlight happened in; the winning turn for win and fastest_kill */
+  turn?: number;
+  /** The winner or eliminated player (as logged) */
+  player?: string;
+  /** The log line it was read from */
+  line?: string;
+}
+
+/**
+ * The `highlights.json` artifact.
+ */
+export interface HighlightFeed {
+  /** Games the feed was built from */
+  games: number;
+  /** Kinds collected */
+  kinds: HighlightKind[];
+  /** Highlights in game order, then round order, with each game's win last */
+  highlights: Highlight[];
+}
+
+function isHighlightKind(value: string): value is HighlightKind {
+  return (HIGHLIGHT_KINDS as readonly string[]).includes(value);
+}
+
+/**
+ * Parses a HIGHLIGHT_KINDS value, e.g. "win,board_wipe".
+ *
+ * @throws Error when a kind is unknown or the list is empty
+ */
+export function parseHighlightKinds(source: string): HighlightKind[] {
+  const kinds: HighlightKind[] = [];
+  for (const part of source.split(',')) {
+    const kind = part.trim().toLowerCase();
+    if (kind.length === 0) continue;
+    if (!isHighlightKind(kind)) {
+      throw new Error(`Invalid ${HIGHLIGHT_KINDS_ENV}: unknown kind "${kind}" (expected ${HIGHLIGHT_KINDS.join(', ')})`);
+    }
+    if (!kinds.includes(kind)) kinds.push(kind);
+  }
+  if (kinds.length === 0) {
+    throw new Error(`Invalid ${HIGHLIGHT_KINDS_ENV}: no kinds given`);
+  }
+  return kinds;
+}
+
+let kindsOverride: { source: string; kinds: HighlightKind[] } | undefined;
+
+/**
+ * Returns the configured HIGHLIGHT_KINDS, or every kind when unset.
+ * Reparses only when the environment value changes.
+ *
+ * @throws If HIGHLIGHT_KINDS is set but invalid
+ */
+export function getHighlightKinds(): HighlightKind[] {
+  const source = process.env[HIGHLIGHT_KINDS_ENV]?.trim();
+  if (!source) return [...HIGHLIGHT_KINDS];
+  if (kindsOverride?.source !== source) {
+    kindsOverride = { source, kinds: parseHighlightKinds(source) };
+  }
+  return kindsOverride.kinds;
+}
+
+/**
+ * Reads the eliminations and board wipes from one raw log.
+ */
+function logHighlights(rawLog: string): Array<Omit<Highlight, 'gameIndex' | 'gameId'>> {
+  const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
+  const ranges = extractTurnRanges(normalized);
+  const numPlayers = getNumPlayers(ranges);
+  const found: Array<Omit<Highlight, 'gameIndex' | 'gameId'>> = [];
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const turn = segmentToRound(turnNumber, numPlayers);
+    for (const raw of chunk.split('\n')) {
+      const line = raw.trim().slice(0, MAX_HIGHLIGHT_LINE_LENGTH);
+      const eliminated = eliminatedPlayerOf(raw);
+      if (eliminated) {
+        found.push({ kind: 'elimination', turn, player: eliminated, line });
+      } else if (!shouldIgnoreLine(raw) && (DETECT_BOARD_WIPE.test(raw) || DETECT_MASS_LAND_DESTRUCTION.test(raw))) {
+        found.push({ kind: 'board_wipe', turn, line });
+      }
+    }
+  }
+
+  return found;
+}
+
+/**
+ * Builds the highlight feed for a job.
+ *
+ * @param rawLogs - One raw log per game, in job order
+ * @param condensed - The condensed games, in the same order
+ * @param kinds - Kinds to collect (default: every kind)
+ * @returns The feed, with only the requested kinds
+ */
+export function buildHighlights(
+  rawLogs: string[],
+  condensed: CondensedGame[],
+  kinds: readonly HighlightKind[] = HIGHLIGHT_KINDS
+): HighlightFeed {
+  const wanted = new Set(kinds);
+  const winningTurns = condensed
+    .filter((game) => game.winner !== undefined && game.winningTurn !== undefined)
+    .map((game) => game.winningTurn!);
+  const fastest = winningTurns.length > 0 ? Math.min(...winningTurns) : undefined;
+
+  const highlights: Highlight[] = [];
+  condensed.forEach((game, gameIndex) => {
+    const ref = game.gameId ? { gameIndex, gameId: game.gameId } : { gameIndex };
+    const found: Array<Omit<Highlight, 'gameIndex' | 'gameId'>> = [];
+
+    for (const round of game.bigTurns ?? []) {
+      found.push({ kind: 'big_turn', turn: round });
+    }
+    if (rawLogs[gameIndex] !== undefined && (wanted.has('elimination') || wanted.has('board_wipe'))) {
+      found.push(...logHighlights(rawLogs[gameIndex]));
+    }
+    // Stable sort keeps log order within a round
+    found.sort((a, b) => a.turn! - b.turn!);
+
+    // The winning turn counts the winner's own turns, not rounds, so the
+    // win goes after the game's other highlights rather than among them
+    if (game.winner !== undefined) {
+      found.push({ kind: 'win', turn: game.winningTurn, player: game.winner });
+      if (game.winningTurn === fastest) {
+        found.push({ kind: 'fastest_kill', turn: game.winningTurn, player: game.winner });
+      }
+    }
+
+    found
+      .filter((highlight) => wanted.has(highlight.kind))
+      .forEach((highlight) => {
+        const entry: Highlight = { kind: highlight.kind, ...ref };
+        if (highlight.turn !== undefined) entry.turn = highlight.turn;
+        if (highlight.player !== undefined) entry.player = highlight.player;
+        if (highlight.line !== undefined) entry.line = highlight.line;
+        highlights.push(entry);
+      });
+  });
+
+  return { games: condensed.length, kinds: HIGHLIGHT_KINDS.filter((kind) => wanted.has(kind)), highlights };
+}
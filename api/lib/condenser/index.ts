@@ -26,6 +26,7 @@
  * │ STEP 2: CLASSIFY (classify.ts)                                         │
  * │   - Categorize each line into an event type                            │
  * │   - Priority: win > life > zone_change > high_cmc > commander > ...    │
+ * │     (overridable via CondenseOptions.classify.priority)                │
  * │   - Discard lines that don't match any "keep" pattern                  │
  * └─────────────────────────────────────────────────────────────────────────┘
  *    │
@@ -65,31 +66,145 @@
  */
 
 import type { CondensedGame, StructuredGame } from '../types';
-import { splitAndFilter } from './filter';
-import { classifyLines } from './classify';
+import { splitAndFilterNumbered } from './filter';
+import { classifyLines, classifyLinesCompacted, withClassificationRules, type ClassifyOptions } from './classify';
+import { classifyLinesWithColors, type PlayerColorMap } from './colors';
 import {
-  extractTurnRanges,
+  indexTurns,
   getNumPlayers,
   getMaxRound,
   calculateManaPerTurn,
   calculateCardsDrawnPerTurn,
+  calculateCastsPerTurn,
   calculatePerDeckTurns,
-  extractWinner,
+  resolveWinner,
+  detectLockEffect,
+  lifeLossRatePerTurn,
+  creatureDeathsPerRound,
+  firstBloodRound,
+  castCmcHistogram,
+  isFastClock,
+  type FastClockOptions,
+  detectLockStall,
+  type LockStallOptions,
 } from './turns';
 import { buildStructuredGame } from './structured';
-import { matchesDeckName } from './deck-match';
+import { matchesDeckName, type SeatMap } from './deck-match';
+import { calculateLibraryStats } from './library';
+import { extractKillingBlow } from './kill';
+import { calculateGoadStats, markGoadedAttacks } from './goad';
+import { extractAiProfiles } from './ai-profile';
+import { extractMulliganDetails } from './mulligan';
+import { gameIdFromLog } from './game-id';
+import { bigTurns, type BigTurnOptions } from './big-turns';
+import { offTurnActions } from './off-turn';
+import { interactionReceived } from './interaction';
+import { protectionPerRound, protectedCombo, type ProtectedComboOptions } from './protected-combo';
+import { recurringEngines, type RecurringEngineOptions } from './engines';
+import { isLowSignal, type LowSignalOptions } from './low-signal';
+import { archenemy, attacksReceived, boardPresence, type ArchenemyOptions } from './archenemy';
+import { opponentLifeLoss, selfLifePayments } from './life-payments';
+import { extraTurnCombo, extraTurnsTaken, type ExtraTurnOptions } from './extra-turns';
+import { earlyDamageDealt, DEFAULT_AGGRESSION, type AggressionOptions } from './aggression';
+import { cardsDrawnByPlayer } from './draws';
+import { extractCardTypeHints } from './card-types';
+import { KEEP_FREE_CAST, KEEP_ALT_COST_CAST, KEEP_CLONE, KEEP_PROTECTION, KEEP_RITUAL, KEEP_LAND_DESTRUCTION, DETECT_MASS_LAND_DESTRUCTION } from './patterns';
 
 // Re-export sub-modules for direct access if needed
-export { shouldIgnoreLine, filterLines, splitAndFilter } from './filter';
-export { classifyLine, createEvent, classifyLines } from './classify';
+export { shouldIgnoreLine, filterLines, splitAndFilter, splitAndFilterNumbered } from './filter';
+export {
+  classifyLine,
+  createEvent,
+  classifyLines,
+  classifyLinesCompacted,
+  compactRepeatedEvents,
+  buildClassificationRules,
+  withClassificationRules,
+  CLASSIFICATION_RULES,
+  DEFAULT_CLASSIFICATION_PRIORITY,
+  DEFAULT_MAX_CLASSIFY_LINE_LENGTH,
+  SPELL_CAST_EVENT_TYPES,
+} from './classify';
+export type { ClassificationRule, ClassifyOptions } from './classify';
 export * from './turns';
 export * from './structured';
 export * from './patterns';
+export { buildMarkdownSummary } from './summary';
+export * from './explosiveness';
+export * from './library';
+export * from './board';
+export * from './kill';
+export * from './goad';
+export * from './ai-profile';
+export * from './big-turns';
+export * from './off-turn';
+export * from './interaction';
+export * from './mulligan';
+export * from './game-id';
+export * from './unmatched';
+export * from './highlights';
+export * from './colors';
+export * from './confidence';
+export * from './turn-stats';
+export * from './seeding';
+export * from './comeback';
+export * from './protected-combo';
+export * from './engines';
+export * from './win-reason';
+export * from './card-types';
+export * from './tempo';
+export * from './low-signal';
+export * from './archenemy';
+export * from './life-payments';
+export * from './extra-turns';
+export * from './aggression';
+export * from './draws';
 
 // -----------------------------------------------------------------------------
 // Main Condensing Functions
 // -----------------------------------------------------------------------------
 
+/**
+ * Options for the condensing pipeline.
+ */
+export interface CondenseOptions {
+  /** Classification options for STEP 2 (e.g. a custom priority order) */
+  classify?: ClassifyOptions;
+  /**
+   * Collapse runs of identical consecutive events within a turn into one
+   * event with a `repeat` count (default false)
+   */
+  compactRepeats?: boolean;
+  /**
+   * Player -> color identity. When given, each event is tagged with its
+   * acting player's colors (`playerColors`)
+   */
+  playerColors?: PlayerColorMap;
+  /**
+   * Record each event's 1-based line number in the raw log (`lineNo`), so
+   * a viewer can jump to the source line (default false)
+   */
+  includeLineNumbers?: boolean;
+  /** Aggro clock threshold and window (default DEFAULT_FAST_CLOCK) */
+  fastClock?: FastClockOptions;
+  /** Quiet-round thresholds for lockStallDetected (default DEFAULT_LOCK_STALL) */
+  lockStall?: LockStallOptions;
+  /** Cast thresholds for bigTurns (default DEFAULT_BIG_TURNS) */
+  bigTurns?: BigTurnOptions;
+  /** Keywords and window for protectedCombo (default DEFAULT_PROTECTED_COMBO) */
+  protectedCombo?: ProtectedComboOptions;
+  /** Turn threshold for recurringEngines (default DEFAULT_RECURRING_ENGINES) */
+  recurringEngines?: RecurringEngineOptions;
+  /** Thresholds for lowSignal (default: LOW_SIGNAL_THRESHOLD or DEFAULT_LOW_SIGNAL) */
+  lowSignal?: LowSignalOptions;
+  /** Targeting threshold for archenemy (default DEFAULT_ARCHENEMY) */
+  archenemy?: ArchenemyOptions;
+  /** Extra turns in a row for extraTurnCombo (default DEFAULT_EXTRA_TURN) */
+  extraTurn?: ExtraTurnOptions;
+  /** Early window for earlyDamageDealt (default DEFAULT_AGGRESSION) */
+  aggression?: AggressionOptions;
+}
+
 /**
  * Condenses a single raw game log into a structured summary.
  *
@@ -97,6 +212,7 @@ export * from './patterns';
  * format expected by the Analysis Service.
  *
  * @param rawLog - The complete raw log text for one game
+ * @param options - Optional pipeline options
  * @returns A CondensedGame object with significant events and metrics
  *
  * @example
@@ -105,14 +221,15 @@ export * from './patterns';
  * console.log(`Game had ${condensed.turnCount} turns`);
  * console.log(`Winner: ${condensed.winner}`);
  */
-export function condenseGame(rawLog: string): CondensedGame {
+export function condenseGame(rawLog: string, options?: CondenseOptions): CondensedGame {
   // ===========================================================================
   // STEP 1: FILTER
   // ===========================================================================
   // Remove noise lines (priority passes, phase markers, etc.)
   // This typically reduces log size by ~80%.
 
-  const filteredLines = splitAndFilter(rawLog);
+  const { lines: filteredLines, lineNumbers: filteredLineNumbers } = splitAndFilterNumbered(rawLog);
+  const lineNumbers = options?.includeLineNumbers ? filteredLineNumbers : undefined;
 
   // ===========================================================================
   // STEP 2: CLASSIFY
@@ -120,7 +237,22 @@ export function condenseGame(rawLog: string): CondensedGame {
   // Categorize remaining lines into event types (life_change, spell_cast, etc.)
   // Lines that don't match any pattern are discarded here.
 
-  const keptEvents = classifyLines(filteredLines);
+  // With compactRepeats, runs of identical events (loops, repeated triggers)
+  // within a turn collapse into one event with a repeat count.
+
+  // With playerColors, each event also carries its acting player's colors.
+
+  // With includeLineNumbers, each event records its line in the raw log.
+
+  // The classification rules are built once and shared with the per-round
+  // event and creature death counts below.
+
+  const classify = withClassificationRules(options?.classify);
+  const keptEvents = options?.playerColors
+    ? classifyLinesWithColors(filteredLines, options.playerColors, classify, options.compactRepeats, lineNumbers)
+    : options?.compactRepeats
+      ? classifyLinesCompacted(filteredLines, classify, lineNumbers)
+      : classifyLines(filteredLines, classify, lineNumbers);
 
   // ===========================================================================
   // STEP 3: EXTRACT METRICS (round-based)
@@ -131,17 +263,21 @@ export function condenseGame(rawLog: string): CondensedGame {
   //
   // A "round" is one full rotation where each player takes a turn.
   // In a 4-player Commander game, round 1 = segments 1-4, round 2 = segments 5-8, etc.
+  //
+  // The format is sniffed and the turns found once here; every metric
+  // helper below reuses the index instead of re-scanning the log.
 
-  const turnRanges = extractTurnRanges(rawLog);
+  const turns = indexTurns(rawLog);
+  const turnRanges = turns.ranges;
   const numPlayers = getNumPlayers(turnRanges);
-  const manaPerTurn = calculateManaPerTurn(rawLog, numPlayers);
-  const cardsDrawnPerTurn = calculateCardsDrawnPerTurn(rawLog, numPlayers);
+  const manaPerTurn = calculateManaPerTurn(rawLog, numPlayers, turns);
+  const cardsDrawnPerTurn = calculateCardsDrawnPerTurn(rawLog, numPlayers, turns);
 
   // ===========================================================================
   // STEP 4: DETECT WINNER & PER-DECK TURNS
   // ===========================================================================
 
-  const winner = extractWinner(rawLog);
+  const { winner, winReason, simultaneousWinners } = resolveWinner(rawLog, undefined, turns);
   const perDeckTurns = calculatePerDeckTurns(turnRanges);
 
   // turnCount = winner's personal turn count (accurate with eliminations).
@@ -169,6 +305,7 @@ export function condenseGame(rawLog: string): CondensedGame {
   // Assemble all pieces into the final CondensedGame structure.
 
   const condensed: CondensedGame = {
+    gameId: gameIdFromLog(rawLog),
     keptEvents,
     manaPerTurn,
     cardsDrawnPerTurn,
@@ -179,12 +316,173 @@ export function condenseGame(rawLog: string): CondensedGame {
   if (winner !== undefined) {
     condensed.winner = winner;
   }
+  if (winReason !== undefined) {
+    condensed.winReason = winReason;
+  }
+  if (simultaneousWinners !== undefined) {
+    condensed.simultaneousWin = true;
+    condensed.simultaneousWinners = simultaneousWinners;
+  }
   if (winningTurn !== undefined) {
     condensed.winningTurn = winningTurn;
   }
+  const eventCount = keptEvents.reduce((sum, e) => sum + (e.repeat ?? 1), 0);
+  if (isLowSignal(eventCount, turnCount, options?.lowSignal)) {
+    condensed.lowSignal = true;
+  }
   if (Object.keys(perDeckTurns).length > 0) {
     condensed.perDeckTurns = perDeckTurns;
   }
+  const aiProfiles = extractAiProfiles(rawLog);
+  if (Object.keys(aiProfiles).length > 0) {
+    condensed.aiProfiles = aiProfiles;
+  }
+  const mulliganDetails = extractMulliganDetails(rawLog);
+  if (Object.keys(mulliganDetails).length > 0) {
+    condensed.mulliganDetails = mulliganDetails;
+  }
+  if (detectLockEffect(rawLog)) {
+    condensed.lockEffectDetected = true;
+  }
+  const lockStallStart = detectLockStall(rawLog, options?.lockStall, turns, classify);
+  if (lockStallStart !== undefined) {
+    condensed.lockStallDetected = true;
+    condensed.lockStallStartRound = lockStallStart;
+  }
+  const payments = selfLifePayments(rawLog, turns);
+  if (Object.keys(payments.perRound).length > 0) {
+    condensed.selfLifePaymentsPerTurn = payments.perRound;
+    condensed.selfLifePaymentsByCategory = payments.byCategory;
+  }
+  const lifeLoss = lifeLossRatePerTurn(rawLog, turns);
+  if (Object.keys(lifeLoss).length > 0) {
+    condensed.lifeLossPerTurn = lifeLoss;
+    // Fetchlands and Phyrexian mana are a deck's own tempo, not a clock
+    if (isFastClock(opponentLifeLoss(lifeLoss, payments.perRound), options?.fastClock)) {
+      condensed.fastClock = true;
+    }
+  }
+  condensed.firstBloodTurn = firstBloodRound(rawLog, turns);
+  const deaths = creatureDeathsPerRound(rawLog, turns, classify);
+  if (Object.keys(deaths).length > 0) {
+    condensed.creatureDeathsPerTurn = deaths;
+  }
+  const cmcHistogram = castCmcHistogram(rawLog);
+  if (Object.keys(cmcHistogram).length > 0) {
+    condensed.castCmcHistogram = cmcHistogram;
+  }
+  const castsPerTurn = calculateCastsPerTurn(rawLog, numPlayers, turns);
+  if (Object.keys(castsPerTurn).length > 0) {
+    condensed.castsPerTurn = castsPerTurn;
+    const big = bigTurns(condensed, options?.bigTurns);
+    if (big.length > 0) {
+      condensed.bigTurns = big;
+    }
+  }
+  const offTurn = offTurnActions(rawLog, turns);
+  if (Object.keys(offTurn).length > 0) {
+    condensed.offTurnActions = offTurn;
+  }
+  const interaction = interactionReceived(rawLog, turns);
+  if (Object.keys(interaction).length > 0) {
+    condensed.interactionReceived = interaction;
+  }
+  const attacks = attacksReceived(rawLog, turns);
+  if (Object.keys(attacks).length > 0) {
+    condensed.attacksReceived = attacks;
+  }
+  const enemy = archenemy(condensed, boardPresence(rawLog, turns), options?.archenemy);
+  if (enemy) {
+    condensed.archenemy = enemy;
+  }
+  const draws = cardsDrawnByPlayer(rawLog, turns);
+  if (Object.keys(draws.perPlayer).length > 0) {
+    condensed.cardsDrawnByPlayer = draws.perPlayer;
+  }
+  if (draws.symmetricEvents > 0) {
+    condensed.symmetricDrawEvents = draws.symmetricEvents;
+  }
+  const earlyDamage = earlyDamageDealt(rawLog, (options?.aggression ?? DEFAULT_AGGRESSION).rounds, turns);
+  if (Object.keys(earlyDamage).length > 0) {
+    condensed.earlyDamageDealt = earlyDamage;
+  }
+  const extraTurns = extraTurnsTaken(rawLog, turns);
+  if (Object.keys(extraTurns).length > 0) {
+    condensed.extraTurns = extraTurns;
+    if (extraTurnCombo(rawLog, condensed.winner, options?.extraTurn, turns)) {
+      condensed.extraTurnCombo = true;
+    }
+  }
+  const killingBlow = extractKillingBlow(rawLog);
+  if (killingBlow) {
+    condensed.killingBlow = killingBlow;
+  }
+  const protection = protectionPerRound(rawLog, options?.protectedCombo, turns);
+  if (Object.keys(protection).length > 0) {
+    condensed.protectionPerTurn = protection;
+    if (protectedCombo(condensed, options?.protectedCombo)) {
+      condensed.protectedCombo = true;
+    }
+  }
+  const engines = recurringEngines(rawLog, options?.recurringEngines, turns);
+  if (engines.length > 0) {
+    condensed.recurringEngines = engines;
+  }
+  const typeHints = extractCardTypeHints(rawLog);
+  if (Object.keys(typeHints).length > 0) {
+    condensed.cardTypeHints = typeHints;
+  }
+
+  const library = calculateLibraryStats(rawLog, turns);
+  if (library.mill > 0) {
+    condensed.millCount = library.mill;
+    condensed.selfMillCount = library.selfMill;
+    condensed.opponentMillCount = library.opponentMill;
+  }
+  if (library.libraryManip > 0) {
+    condensed.libraryManipCount = library.libraryManip;
+  }
+
+  // Counted over filtered lines rather than events, so free spells that
+  // classify as a higher-priority type (e.g. high CMC) still count
+  const freeCastCount = filteredLines.filter((line) => KEEP_FREE_CAST.test(line)).length;
+  if (freeCastCount > 0) {
+    condensed.freeCastCount = freeCastCount;
+  }
+  const altCostCastCount = filteredLines.filter((line) => KEEP_ALT_COST_CAST.test(line)).length;
+  if (altCostCastCount > 0) {
+    condensed.altCostCastCount = altCostCastCount;
+  }
+
+  const ritualCount = filteredLines.filter((line) => KEEP_RITUAL.test(line)).length;
+  if (ritualCount > 0) {
+    condensed.ritualCount = ritualCount;
+  }
+
+  const cloneCount = filteredLines.filter((line) => KEEP_CLONE.test(line)).length;
+  if (cloneCount > 0) {
+    condensed.cloneCount = cloneCount;
+  }
+
+  const goad = calculateGoadStats(filteredLines);
+  if (goad.goadCount > 0) {
+    condensed.goadCount = goad.goadCount;
+  }
+  markGoadedAttacks(keptEvents, goad);
+
+  const protectionCount = filteredLines.filter((line) => KEEP_PROTECTION.test(line)).length;
+  if (protectionCount > 0) {
+    condensed.protectionCount = protectionCount;
+  }
+
+  const landDestructionLines = filteredLines.filter((line) => KEEP_LAND_DESTRUCTION.test(line));
+  if (landDestructionLines.length > 0) {
+    condensed.landDestructionCount = landDestructionLines.length;
+    const mass = landDestructionLines.filter((line) => DETECT_MASS_LAND_DESTRUCTION.test(line)).length;
+    if (mass > 0) {
+      condensed.massLandDestructionCount = mass;
+    }
+  }
 
   return condensed;
 }
@@ -195,6 +493,7 @@ export function condenseGame(rawLog: string): CondensedGame {
  * Convenience wrapper for processing an array of games.
  *
  * @param rawLogs - Array of raw log strings (one per game)
+ * @param options - Optional pipeline options, applied to every game
  * @returns Array of CondensedGame objects
  *
  * @example
@@ -202,8 +501,8 @@ export function condenseGame(rawLog: string): CondensedGame {
  * const condensed = condenseGames(allLogs);
  * // condensed.length === 3
  */
-export function condenseGames(rawLogs: string[]): CondensedGame[] {
-  return rawLogs.map((log) => condenseGame(log));
+export function condenseGames(rawLogs: string[], options?: CondenseOptions): CondensedGame[] {
+  return rawLogs.map((log) => condenseGame(log, options));
 }
 
 /**
@@ -214,13 +513,15 @@ export function condenseGames(rawLogs: string[]): CondensedGame[] {
  *
  * @param rawLog - The complete raw log text for one game
  * @param deckNames - Optional deck names [hero, opp1, opp2, opp3]
+ * @param seatMap - Optional seat label map; defaults to deck order
  * @returns StructuredGame object
  */
 export function structureGame(
   rawLog: string,
-  deckNames?: string[]
+  deckNames?: string[],
+  seatMap?: SeatMap
 ): StructuredGame {
-  return buildStructuredGame(rawLog, deckNames);
+  return buildStructuredGame(rawLog, deckNames, seatMap);
 }
 
 /**
@@ -228,12 +529,14 @@ export function structureGame(
  *
  * @param rawLogs - Array of raw log strings
  * @param deckNames - Optional deck names
+ * @param seatMap - Optional seat label map; defaults to deck order
  * @returns Array of StructuredGame objects
  */
 export function structureGames(
   rawLogs: string[],
-  deckNames?: string[]
+  deckNames?: string[],
+  seatMap?: SeatMap
 ): StructuredGame[] {
-  return rawLogs.map((log) => buildStructuredGame(log, deckNames));
+  return rawLogs.map((log) => buildStructuredGame(log, deckNames, seatMap));
 }
 
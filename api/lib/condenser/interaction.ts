@@ -0,0 +1,126 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Interaction Received
+ * =============================================================================
+ *
+ * Counts how often each player's spells, permanents and hand were
+ * interacted with: countered, destroyed, exiled, bounced or discarded. A
+ * deck that draws a lot of interaction is read as the threat at the table.
+ *
+ * ## Targets
+ *
+ * Forge resolve lines carry the rules text with a "(Targeting: ...)"
+ * section after each targeted clause:
+ *
+ *   "Resolve stack: Counterspell (22) - Counter target spell. (Targeting: Thassa's Oracle (5))"
+ *
+ * Each clause that counters, removes or discards is credited to the owner
+ * of each of its targets. A target that is a player is that player; a card
+ * target is owned by whoever cast or played that card id earlier in the
+ * log. Targets whose owner can't be determined (tokens, cards never cast)
+ * go to INTERACTION_UNKNOWN, as does an interaction clause with no parsed
+ * targets. Interaction with your own cards (flicker, self-bounce) isn't
+ * counted.
+ *
+ * =============================================================================
+ */
+
+import { EXTRACT_CARD_OWNER, EXTRACT_RESOLVE_SOURCE, DETECT_INTERACTION_CLAUSE } from './patterns';
+import { indexTurns, type TurnIndex } from './turns';
+
+/** Key for interaction whose target owner can't be determined. */
+export const INTERACTION_UNKNOWN = 'unknown';
+
+/** Card ids in a target list, e.g. "(33)" -> 33 */
+const CARD_ID = /\((\d+)\)/g;
+
+/**
+ * Splits a resolve line into its targeted clauses: the text before each
+ * "(Targeting: ...)" section and the section's contents. Sections contain
+ * card ids in parentheses, so the closing parenthesis is found by depth.
+ * A line without a section is one clause with no targets.
+ */
+function targetedClauses(line: string): { clause: string; targets: string }[] {
+  const clauses: { clause: string; targets: string }[] = [];
+  let clauseStart = 0;
+  let open = line.indexOf('(Targeting:');
+  while (open !== -1) {
+    let depth = 0;
+    let close = open;
+    for (; close < line.length; close++) {
+      if (line[close] === '(') depth++;
+      else if (line[close] === ')' && --depth === 0) break;
+    }
+    clauses.push({
+      clause: line.slice(clauseStart, open),
+      targets: line.slice(open + '(Targeting:'.length, close),
+    });
+    clauseStart = close + 1;
+    open = line.indexOf('(Targeting:', clauseStart);
+  }
+  if (clauses.length === 0) clauses.push({ clause: line, targets: '' });
+  return clauses;
+}
+
+/**
+ * Counts interaction received per player.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of player (as logged) -> times their cards or hand were
+ *          targeted by interaction, plus INTERACTION_UNKNOWN for targets
+ *          with no known owner. Players never targeted are omitted.
+ *
+ * @example
+ * interactionReceived(log)
+ * // { "Ai(1)-Alpha": 2, "unknown": 1 }  (Alpha's combo piece was countered, ...)
+ */
+export function interactionReceived(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): Record<string, number> {
+  const { log: normalized, ranges } = turns;
+  const players = [...new Set(ranges.map((r) => r.player).filter((p): p is string => !!p))];
+  // Longest first, so "Ai(1)-Alpha Beta" isn't read as "Ai(1)-Alpha"
+  players.sort((a, b) => b.length - a.length);
+
+  const owners = new Map<string, string>();
+  const counts: Record<string, number> = {};
+  const credit = (player: string) => {
+    counts[player] = (counts[player] ?? 0) + 1;
+  };
+
+  for (const line of normalized.split('\n')) {
+    const owned = EXTRACT_CARD_OWNER.exec(line);
+    if (owned) {
+      owners.set(owned[2] ?? owned[4], (owned[1] ?? owned[3]).trim());
+      continue;
+    }
+
+    const source = EXTRACT_RESOLVE_SOURCE.exec(line);
+    if (!source) continue;
+    const sourceOwner = owners.get(source[1]);
+
+    for (const { clause, targets } of targetedClauses(line)) {
+      if (!DETECT_INTERACTION_CLAUSE.test(clause)) continue;
+
+      let remaining = targets;
+      const targetOwners: string[] = [];
+      for (const player of players) {
+        if (!remaining.includes(player)) continue;
+        targetOwners.push(player);
+        remaining = remaining.split(player).join('');
+      }
+      for (const [, id] of remaining.matchAll(CARD_ID)) {
+        targetOwners.push(owners.get(id) ?? INTERACTION_UNKNOWN);
+      }
+
+      if (targetOwners.length === 0) {
+        credit(INTERACTION_UNKNOWN);
+        continue;
+      }
+      for (const owner of targetOwners) {
+        if (owner !== sourceOwner) credit(owner);
+      }
+    }
+  }
+
+  return counts;
+}
@@ -0,0 +1,112 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Killing Blow
+ * =============================================================================
+ *
+ * Finds what dealt the final damage of a game, for a "killed by X" label.
+ *
+ * ## Heuristic
+ *
+ * Forge doesn't log what killed a player, so this reads adjacent lines:
+ *
+ *   1. The end-of-game lines are the run of "Game outcome:" / elimination
+ *      lines ending at the last elimination. Every player they name as
+ *      having lost is a possible victim.
+ *   2. Within KILLING_BLOW_WINDOW lines before that run, the nearest damage
+ *      line that hits a victim is the killing blow.
+ *   3. Combat damage is credited to the active player (the attacker). A
+ *      source that was cast earlier in the turn is a spell, credited to its
+ *      caster. Anything else is an ability with no player.
+ *
+ * Kills with no damage line (life loss triggers, poison, decking,
+ * concessions) have no killing blow.
+ *
+ * =============================================================================
+ */
+
+import type { KillInfo } from '../types';
+import { EXTRACT_DAMAGE, EXTRACT_ELIMINATED_PLAYER, EXTRACT_ACTIVE_PLAYER } from './patterns';
+import { matchesDeckName } from './deck-match';
+
+/** How many lines before the end-of-game lines to search for the blow. */
+export const KILLING_BLOW_WINDOW = 15;
+
+const OUTCOME_LINE = /^Game outcome:/i;
+const WIN_LINE = /\b(?:wins\s+the\s+game|has\s+won)\b/i;
+
+function samePlayer(a: string, b: string): boolean {
+  return matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+function escapeRegExp(text: string): string {
+  return text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function eliminatedPlayer(line: string): string | undefined {
+  const match = EXTRACT_ELIMINATED_PLAYER.exec(line);
+  return (match?.[1] ?? match?.[2])?.trim();
+}
+
+/**
+ * Finds the active player and the caster of `source`, searching back from
+ * `index` to the start of the turn.
+ */
+function turnContext(lines: string[], index: number, source: string): { activePlayer?: string; caster?: string } {
+  const castPattern = new RegExp(`^(?:[A-Za-z ]{1,30}:\\s*)?(.{1,80}?)\\s+casts?\\s+${escapeRegExp(source)}`, 'i');
+  let caster: string | undefined;
+  for (let i = index - 1; i >= 0; i--) {
+    const turn = EXTRACT_ACTIVE_PLAYER.exec(lines[i]);
+    if (turn) return { activePlayer: (turn[1] ?? turn[2])?.trim(), caster };
+    caster ??= castPattern.exec(lines[i])?.[1]?.trim();
+  }
+  return { caster };
+}
+
+/**
+ * Extracts the killing blow from a raw game log.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns The killing blow, or undefined if it can't be determined
+ */
+export function extractKillingBlow(rawLog: string): KillInfo | undefined {
+  const lines = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n').split('\n').map((l) => l.trim());
+
+  let lastElimination = -1;
+  for (let i = lines.length - 1; i >= 0; i--) {
+    if (eliminatedPlayer(lines[i])) {
+      lastElimination = i;
+      break;
+    }
+  }
+  if (lastElimination < 0) return undefined;
+
+  // Walk back over the end-of-game run, collecting the victims
+  let start = lastElimination;
+  const victims: string[] = [];
+  for (let i = lastElimination; i >= 0; i--) {
+    const line = lines[i];
+    const victim = eliminatedPlayer(line);
+    if (!victim && !OUTCOME_LINE.test(line) && !WIN_LINE.test(line)) break;
+    if (victim) victims.push(victim);
+    start = i;
+  }
+
+  for (let i = start - 1; i >= Math.max(0, start - KILLING_BLOW_WINDOW); i--) {
+    const damage = EXTRACT_DAMAGE.exec(lines[i]);
+    if (!damage) continue;
+    const victim = victims.find((v) => samePlayer(damage[4].trim(), v));
+    if (!victim) continue;
+
+    const source = damage[1].trim();
+    const { activePlayer, caster } = turnContext(lines, i, source);
+    if (damage[3]) {
+      return { victim, source, type: 'combat', ...(activePlayer && { player: activePlayer }) };
+    }
+    if (caster) {
+      return { victim, source, type: 'spell', player: caster };
+    }
+    return { victim, source, type: 'ability' };
+  }
+
+  return undefined;
+}
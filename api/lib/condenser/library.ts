@@ -0,0 +1,89 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Mill & Library Manipulation Counts
+ * =============================================================================
+ *
+ * Counts mill and scry/surveil lines in a game. These feed graveyard-engine
+ * detection (self-mill) and mill-as-a-win-condition detection
+ * (opponent-mill).
+ *
+ * ## Self vs Opponent Mill
+ *
+ * Forge names the player whose library was milled ("X milled ...") but not
+ * who caused it. A mill of the ACTIVE player's library is counted as
+ * self-mill, a mill of anyone else's library as opponent-mill. Lines where
+ * the milled player or the active player can't be determined only count
+ * toward the total.
+ *
+ * =============================================================================
+ */
+
+import {
+  KEEP_MILL,
+  KEEP_LIBRARY_MANIP,
+  EXTRACT_MILLED_PLAYER,
+} from './patterns';
+import { indexTurns, sliceByTurn, type TurnIndex } from './turns';
+import { matchesDeckName } from './deck-match';
+
+/**
+ * Mill and library manipulation counts for a single game.
+ */
+export interface LibraryStats {
+  /** All mill lines */
+  mill: number;
+  /** Mill lines where the active player milled themselves */
+  selfMill: number;
+  /** Mill lines where a non-active player was milled */
+  opponentMill: number;
+  /** Scry / surveil lines */
+  libraryManip: number;
+}
+
+/**
+ * Same comparison either way round, since log names and turn-marker names
+ * can differ in how much of the deck name they include.
+ */
+function samePlayer(a: string, b: string): boolean {
+  return matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+/**
+ * Counts mill and scry/surveil lines in a raw game log.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Mill and library manipulation counts
+ */
+export function calculateLibraryStats(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): LibraryStats {
+  const stats: LibraryStats = { mill: 0, selfMill: 0, opponentMill: 0, libraryManip: 0 };
+  const { log: normalized, ranges } = turns;
+
+  // Text before the first turn marker has no active player
+  const firstStart = ranges.length > 0 ? ranges[0].startOffset : normalized.length;
+  const chunks = [
+    { player: undefined as string | undefined, chunk: normalized.slice(0, firstStart) },
+    ...sliceByTurn(normalized, ranges),
+  ];
+
+  for (const { player, chunk } of chunks) {
+    for (const line of chunk.split('\n')) {
+      if (KEEP_LIBRARY_MANIP.test(line)) {
+        stats.libraryManip++;
+        continue;
+      }
+      if (!KEEP_MILL.test(line)) continue;
+
+      stats.mill++;
+      const milled = EXTRACT_MILLED_PLAYER.exec(line.trim())?.[1]?.trim();
+      if (!milled || !player) continue;
+      if (samePlayer(milled, player)) {
+        stats.selfMill++;
+      } else {
+        stats.opponentMill++;
+      }
+    }
+  }
+
+  return stats;
+}
@@ -0,0 +1,105 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Self Life Payments
+ * =============================================================================
+ *
+ * Separates life a deck spent on itself (cracking a fetchland, casting with
+ * Phyrexian mana, tapping a painland or City of Brass) from damage its
+ * opponents dealt. Paying life for tempo is a speed signal; counting it as
+ * damage makes a fast deck's own fetchlands look like an aggro clock.
+ *
+ * ## Categories
+ *
+ *   - fetch:     life paid to an activated ability that sacrifices the
+ *                permanent to search for a land (Polluted Delta)
+ *   - painland:  damage a mana source deals its controller as it taps
+ *                (Llanowar Wastes, Ancient Tomb)
+ *   - phyrexian: life paid for Phyrexian mana ({B/P}) while casting
+ *   - city:      damage a permanent deals its controller on becoming
+ *                tapped (City of Brass, Mana Confluence)
+ *   - other:     any other life paid (Necropotence, alternate costs)
+ *
+ * See EXTRACT_LIFE_PAYMENT for the lines that count.
+ *
+ * =============================================================================
+ */
+
+import { DETECT_FETCH, DETECT_PHYREXIAN_MANA, EXTRACT_LIFE_PAYMENT } from './patterns';
+import { getNumPlayers, indexTurns, segmentToRound, sliceByTurn, type TurnIndex } from './turns';
+
+export const LIFE_PAYMENT_CATEGORIES = ['fetch', 'painland', 'phyrexian', 'city', 'other'] as const;
+
+export type LifePaymentCategory = (typeof LIFE_PAYMENT_CATEGORIES)[number];
+
+/**
+ * Reads a self life payment from a log line.
+ *
+ * @param line - One log line
+ * @returns Life paid and its category, or undefined when the line isn't a
+ *          self life payment
+ */
+export function classifyLifePayment(line: string): { amount: number; category: LifePaymentCategory } | undefined {
+  const match = line.match(EXTRACT_LIFE_PAYMENT);
+  if (!match) return undefined;
+  const [, cast, activated, mana, tapped, paid] = match;
+  if (cast) return { amount: Number(cast), category: DETECT_PHYREXIAN_MANA.test(line) ? 'phyrexian' : 'other' };
+  if (activated) return { amount: Number(activated), category: DETECT_FETCH.test(line) ? 'fetch' : 'other' };
+  if (mana) return { amount: Number(mana), category: 'painland' };
+  if (tapped) return { amount: Number(tapped), category: 'city' };
+  return { amount: Number(paid), category: 'other' };
+}
+
+/**
+ * Totals self life payments per round and per category.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns `perRound`: round number -> life paid (rounds without payments
+ *          omitted); `byCategory`: category -> life paid (categories
+ *          without payments omitted)
+ */
+export function selfLifePayments(
+  rawLog: string,
+  turns: TurnIndex = indexTurns(rawLog)
+): {
+  perRound: Record<number, number>;
+  byCategory: Partial<Record<LifePaymentCategory, number>>;
+} {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const perRound: Record<number, number> = {};
+  const byCategory: Partial<Record<LifePaymentCategory, number>> = {};
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      const payment = classifyLifePayment(line);
+      if (!payment || payment.amount === 0) continue;
+      perRound[round] = (perRound[round] ?? 0) + payment.amount;
+      byCategory[payment.category] = (byCategory[payment.category] ?? 0) + payment.amount;
+    }
+  }
+
+  return { perRound, byCategory };
+}
+
+/**
+ * Life lost per round minus the life players paid themselves, i.e. the
+ * damage and drain opponents dealt.
+ *
+ * @param lossPerRound - Output of lifeLossRatePerTurn
+ * @param paymentsPerRound - `perRound` from selfLifePayments
+ * @returns Map of round number -> life lost to opponents. Rounds with none
+ *          are omitted.
+ */
+export function opponentLifeLoss(
+  lossPerRound: Record<number, number>,
+  paymentsPerRound: Record<number, number>
+): Record<number, number> {
+  const result: Record<number, number> = {};
+  for (const [round, loss] of Object.entries(lossPerRound)) {
+    const net = loss - (paymentsPerRound[Number(round)] ?? 0);
+    if (net > 0) result[Number(round)] = net;
+  }
+  return result;
+}
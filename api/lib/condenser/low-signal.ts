@@ -0,0 +1,101 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Low-Signal Games
+ * =============================================================================
+ *
+ * Flags games too short or too quiet to say anything about the decks: a
+ * simulator crash after the opening hands, an instant concession, a game
+ * cut off on turn 1. Such games would otherwise count as draws or freak
+ * wins and drag the win rates around.
+ *
+ * A flagged game is still condensed, structured and uploaded (with
+ * `lowSignal: true`), but is left out of the job's aggregate statistics,
+ * summary.md and the analysis prompt.
+ *
+ * ## Threshold
+ *
+ * A game is low-signal when it has fewer than `minTurns` turns (the
+ * winner's turn count, or the longest-lived player's) or fewer than
+ * `minEvents` classified events. LOW_SIGNAL_THRESHOLD overrides the
+ * defaults, e.g. "turns=3,events=10"; a key left out keeps its default.
+ *
+ * =============================================================================
+ */
+
+/**
+ * Thresholds below which a game is low-signal.
+ */
+export interface LowSignalOptions {
+  /** Fewest classified events for a game to count */
+  minEvents: number;
+  /** Fewest turns for a game to count */
+  minTurns: number;
+}
+
+export const DEFAULT_LOW_SIGNAL: LowSignalOptions = {
+  minEvents: 5,
+  minTurns: 2,
+};
+
+/** Environment variable overriding the thresholds, e.g. "turns=3,events=10". */
+export const LOW_SIGNAL_THRESHOLD_ENV = 'LOW_SIGNAL_THRESHOLD';
+
+const THRESHOLD_KEYS: Record<string, keyof LowSignalOptions> = {
+  events: 'minEvents',
+  turns: 'minTurns',
+};
+
+/**
+ * Parses a LOW_SIGNAL_THRESHOLD value, e.g. "turns=3,events=10".
+ *
+ * @throws Error when a key is unknown or a value isn't a nonnegative integer
+ */
+export function parseLowSignalThreshold(source: string): LowSignalOptions {
+  const options = { ...DEFAULT_LOW_SIGNAL };
+  for (const part of source.split(',')) {
+    if (part.trim().length === 0) continue;
+    const [rawKey, rawValue = ''] = part.split('=', 2);
+    const key = THRESHOLD_KEYS[rawKey.trim().toLowerCase()];
+    if (!key) {
+      throw new Error(`Invalid ${LOW_SIGNAL_THRESHOLD_ENV}: unknown key "${rawKey.trim()}" (expected events, turns)`);
+    }
+    const value = rawValue.trim();
+    if (!/^\d+$/.test(value)) {
+      throw new Error(`Invalid ${LOW_SIGNAL_THRESHOLD_ENV}: "${rawKey.trim()}" must be a nonnegative integer, got "${value}"`);
+    }
+    options[key] = Number(value);
+  }
+  return options;
+}
+
+let thresholdOverride: { source: string; options: LowSignalOptions } | undefined;
+
+/**
+ * Returns the configured LOW_SIGNAL_THRESHOLD, or DEFAULT_LOW_SIGNAL when
+ * unset. Reparses only when the environment value changes.
+ *
+ * @throws If LOW_SIGNAL_THRESHOLD is set but invalid
+ */
+export function getLowSignalThreshold(): LowSignalOptions {
+  const source = process.env[LOW_SIGNAL_THRESHOLD_ENV]?.trim();
+  if (!source) return DEFAULT_LOW_SIGNAL;
+  if (thresholdOverride?.source !== source) {
+    thresholdOverride = { source, options: parseLowSignalThreshold(source) };
+  }
+  return thresholdOverride.options;
+}
+
+/**
+ * True when a game is below either threshold.
+ *
+ * @param events - Classified events in the game (repeats included)
+ * @param turns - The game's turn count
+ * @param options - Thresholds (default: getLowSignalThreshold())
+ */
+export function isLowSignal(
+  events: number,
+  turns: number,
+  options: LowSignalOptions = getLowSignalThreshold()
+): boolean {
+  return turns < options.minTurns || events < options.minEvents;
+}
@@ -0,0 +1,60 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Mulligans
+ * =============================================================================
+ *
+ * Reads each player's mulligan sequence and the hand size they kept, for
+ * variance analysis: a deck that mulligans aggressively keeps smaller hands
+ * on average.
+ *
+ * ## Forge Lines
+ *
+ * Every mulligan logs the hand size it went down to ("has mulliganed down
+ * to 6 cards."), and the keep logs the final size ("has kept a hand of 6
+ * cards"). A player who keeps without mulliganing is a clean 7. In
+ * Commander the first mulligan is free, so "mulliganed down to 7" still
+ * counts as a mulligan.
+ *
+ * A player with mulligan lines but no keep line is taken to have kept the
+ * last size they went down to.
+ *
+ * =============================================================================
+ */
+
+import type { MulliganInfo } from '../types';
+import { EXTRACT_MULLIGAN, EXTRACT_MULLIGAN_KEEP } from './patterns';
+
+/** Opening hand size before any mulligan. */
+export const OPENING_HAND_SIZE = 7;
+
+/**
+ * Maps each player to their mulligans and kept hand size.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns Player label (as logged) -> mulligan info; empty when the log
+ *   has no mulligan lines
+ *
+ * @example
+ * extractMulliganDetails(log)
+ * // { "Ai(1)-Alpha": { mulligans: 2, handSizes: [6, 5], keptHandSize: 5 } }
+ */
+export function extractMulliganDetails(rawLog: string): Record<string, MulliganInfo> {
+  const details: Record<string, MulliganInfo> = {};
+  for (const line of rawLog.split(/\r?\n/)) {
+    const mulligan = EXTRACT_MULLIGAN.exec(line);
+    if (mulligan) {
+      const size = parseInt(mulligan[2], 10);
+      const info = (details[mulligan[1].trim()] ??= { mulligans: 0, handSizes: [], keptHandSize: OPENING_HAND_SIZE });
+      info.mulligans++;
+      info.handSizes.push(size);
+      info.keptHandSize = size;
+      continue;
+    }
+    const keep = EXTRACT_MULLIGAN_KEEP.exec(line);
+    if (keep) {
+      const info = (details[keep[1].trim()] ??= { mulligans: 0, handSizes: [], keptHandSize: OPENING_HAND_SIZE });
+      info.keptHandSize = parseInt(keep[2], 10);
+    }
+  }
+  return details;
+}
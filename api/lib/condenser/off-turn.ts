@@ -0,0 +1,60 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Off-Turn Actions
+ * =============================================================================
+ *
+ * Counts the spells each player casts during other players' turns:
+ * counterspells, instant-speed removal and combat tricks. A high count
+ * marks a reactive deck that holds up interaction instead of developing
+ * on its own turn.
+ *
+ * ## Attribution
+ *
+ * The caster comes from the stack line itself ("Add to stack: Ai(2)-Beta
+ * cast Counterspell (4)"), independent of whose turn it is; the active
+ * player comes from the turn marker. A cast is off-turn when the two
+ * differ. Casts in a segment without a known active player are skipped,
+ * and so are casts credited to a player after they've been eliminated
+ * (delayed triggers and suspended spells firing on the way out).
+ *
+ * =============================================================================
+ */
+
+import { EXTRACT_CAST_BY } from './patterns';
+import { indexTurns, sliceByTurn, eliminatedPlayerOf, isEliminated, type TurnIndex } from './turns';
+import { matchesDeckName } from './deck-match';
+
+function samePlayer(a: string, b: string): boolean {
+  return matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+/**
+ * Counts spells cast during another player's turn, per caster.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of caster (as logged) -> off-turn casts. Players with no
+ *          off-turn casts are omitted.
+ *
+ * @example
+ * offTurnActions(log)
+ * // { "Ai(2)-Beta": 3 }  (Beta countered or removed things on others' turns)
+ */
+export function offTurnActions(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): Record<string, number> {
+  const { log: normalized, ranges } = turns;
+  const counts: Record<string, number> = {};
+  const eliminated: string[] = [];
+
+  for (const { player: active, chunk } of sliceByTurn(normalized, ranges)) {
+    for (const line of chunk.split('\n')) {
+      const out = eliminatedPlayerOf(line);
+      if (out) eliminated.push(out);
+      if (!active) continue;
+      const caster = EXTRACT_CAST_BY.exec(line)?.[1];
+      if (!caster || samePlayer(caster, active) || isEliminated(caster, eliminated)) continue;
+      counts[caster] = (counts[caster] ?? 0) + 1;
+    }
+  }
+
+  return counts;
+}
@@ -86,8 +86,11 @@ export const IGNORE_DRAW_STEP = /draw\s+step/i;
  * turn information separately; keeping empty turn lines adds noise.
  *
  * Forge example: "Turn 5:\n"
+ *
+ * Like all turn-marker patterns, tolerates the turn marker prefix described
+ * above EXTRACT_TURN_LINE.
  */
-export const IGNORE_BARE_TURN = /^Turn\s+\d+:\s*$/i;
+export const IGNORE_BARE_TURN = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn\s+\d+:\s*$/i;
 
 /**
  * All ignore patterns collected for easy iteration.
@@ -142,8 +145,10 @@ export const KEEP_EXTRA_DRAW = /draw(s)?\s+(an?\s+)?(additional|extra|\d+)\s+car
  *   - "[LIFE] Life: Ai(1)-Doran Big Butts 40 -> 37" (native, post-2.0.10)
  *   - "Player A loses 5 life." (legacy heuristic)
  *   - "Player B gains 4 life." (legacy heuristic)
+ *
+ * `(?<!\d)` keeps "N life" from rescanning a digit run at every position.
  */
-export const KEEP_LIFE_CHANGE = /^\[LIFE\]\s+Life:|life\s+(total\s+)?(change|loss|gain|to)|(\d+)\s+life|loses?\s+\d+\s+life|gains?\s+\d+\s+life/i;
+export const KEEP_LIFE_CHANGE = /^\[LIFE\]\s+Life:|life\s+(total\s+)?(change|loss|gain|to)|(?<!\d)(\d+)\s+life|loses?\s+\d+\s+life|gains?\s+\d+\s+life/i;
 
 /**
  * Pattern: High mana value spell cast (CMC >= 5)
@@ -175,6 +180,140 @@ export const KEEP_SPELL_HIGH_CMC = /cast(s|ing)?\s+.*?(?:\(?\s*CMC\s*([5-9]|\d{2
  */
 export const KEEP_SPELL_CAST = /\bcasts?\s+/i;
 
+/**
+ * Pattern: Ritual (a spell that adds mana when it resolves)
+ *
+ * Why keep: Rituals are one-shot mana bursts that power explosive turns;
+ * they say more about a deck's speed than its lands and rocks do.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Dark Ritual (3) - Add {B}{B}{B}."
+ *   - "Resolve stack: Seething Song (5) - Add {R}{R}{R}{R}{R}."
+ *   - "Resolve stack: Pyretic Ritual (8) - Add three mana in any combination of colors."
+ *
+ * Mana abilities don't use the stack, so land and rock taps are logged as
+ * "Mana: Swamp (11) - {T}: Add {B}." and never match. Only a resolving
+ * object whose effect starts with "Add" counts; triggers ("Whenever you
+ * cast ..., Birgi (24) - Add {R}.") don't.
+ */
+export const KEEP_RITUAL = /^\s*Resolve stack:(?![^\n]*\b(?:when(?:ever)?|at\s+the\s+beginning)\b)\s[^\n]{1,120}?\(\d+\)\s+-\s+Add\s+(?:\{[^}\s]{1,3}\}|(?:one|two|three|four|five|six|seven|X)\s+mana\b)/i;
+
+/**
+ * Pattern: Free spell cast (cascade, suspend, "without paying its mana cost")
+ *
+ * Why keep: Spells cast for free are value and tempo that the mana metrics
+ * miss, and they're what make explosive turns possible.
+ *
+ * Forge examples:
+ *   - "Ai(1)-Alpha cascades into Kodama's Reach (3)"
+ *   - "Ai(1)-Alpha casts Ancestral Vision (1) without paying its mana cost"
+ *   - "Ai(2)-Beta cast Rift Bolt (4) from suspend"
+ *
+ * Rules text like "you may cast it without paying its mana cost" also
+ * matches; the classifier only sees it when it isn't filtered as noise.
+ * Gaps are bounded so long lines stay linear.
+ */
+export const KEEP_FREE_CAST = /\bcascades?\s+into\b|without\s+paying\s+(?:its|their|his|her)\s+mana\s+costs?|\bcasts?\b[^.\n]{0,80}?\b(?:from\s+suspend|suspended)\b/i;
+
+/**
+ * Keywords for casting a spell through an alternative cost, mostly from the
+ * graveyard (flashback, escape, disturb, jump-start, retrace).
+ *
+ * Add a keyword here to track a new mechanic; KEEP_ALT_COST_CAST is built
+ * from this list with buildAltCostPattern.
+ */
+export const ALT_COST_KEYWORDS: readonly string[] = ['flashback', 'escape', 'disturb', 'jump-start', 'retrace'];
+
+/**
+ * Builds the alternative-cost cast pattern: a cast line that names one of
+ * the keywords later on the line.
+ *
+ * @param keywords - Alternative cost keywords (matched case-insensitively as whole words)
+ */
+export function buildAltCostPattern(keywords: readonly string[]): RegExp {
+  const alternatives = keywords.map((k) => k.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')).join('|');
+  return new RegExp(`\\bcasts?\\b[^\\n]{0,120}?\\b(?:${alternatives})\\b`, 'i');
+}
+
+/**
+ * Pattern: Alternative-cost cast (flashback, escape, ...)
+ *
+ * Why keep: Recasting spells from the graveyard is card advantage that
+ * generic cast detection lumps in with everything else, and repeated
+ * graveyard casting signals a resilient value engine.
+ *
+ * Forge examples:
+ *   - "Ai(1)-Alpha cast Deep Analysis (3) using Flashback"
+ *   - "Ai(2)-Beta cast Uro, Titan of Nature's Wrath (4) with Escape"
+ *
+ * A card whose NAME contains a keyword (e.g. "Escape Velocity") also
+ * matches. The gap is bounded so long lines stay linear.
+ */
+export const KEEP_ALT_COST_CAST = buildAltCostPattern(ALT_COST_KEYWORDS);
+
+/**
+ * Pattern: Land destruction and forced land sacrifice
+ *
+ * Why keep: Land destruction (Strip Mine, Stone Rain, Armageddon, Pox) sets
+ * opponents back on mana and is one of the most contentious strategies at
+ * lower brackets.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Strip Mine (11) - Ai(1)-Alpha destroys target land Volcanic Island (46)"
+ *   - "Resolve stack: Pox (3) - Each player sacrifices a land"
+ *   - "Resolve stack: Armageddon (4) - Destroy all lands."
+ *
+ * A player sacrificing their own land to a fetch land names the land, not
+ * "a land", so it doesn't match.
+ */
+export const KEEP_LAND_DESTRUCTION = /\bdestroys?\s+(?:target|all|each)\s+(?:nonbasic\s+)?lands?\b|\beach\s+player\s+sacrifices\s+\w+\s+lands?\b/i;
+
+/**
+ * Pattern: Mass land destruction (Armageddon-style)
+ *
+ * Why detect: Destroying or sacrificing every land resets the whole table's
+ * mana, which plays like a board wipe rather than targeted removal.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Armageddon (4) - Destroy all lands."
+ *   - "Resolve stack: Ravages of War (3) - Destroy all lands."
+ *   - "Resolve stack: Worldslayer (5) - Each player sacrifices all lands"
+ */
+export const DETECT_MASS_LAND_DESTRUCTION = /\bdestroys?\s+(?:all|each)\s+lands?\b|\bsacrifices?\s+all\s+lands\b/i;
+
+/**
+ * Pattern: Creature board wipe
+ *
+ * Why detect: A wrath resets every board at once and is one of the swings
+ * worth surfacing in a job's highlights.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Wrath of God (55) - Destroy all creatures. They can't be regenerated."
+ *   - "Resolve stack: Farewell (8) - Exile all creatures."
+ *   - "Resolve stack: Blasphemous Act (9) - Blasphemous Act deals 13 damage to each creature."
+ *   - "Resolve stack: Toxic Deluge (12) - All creatures get -5/-5 until end of turn."
+ */
+export const DETECT_BOARD_WIPE = /\b(?:destroys?|exiles?)\s+all\s+(?:other\s+|nonland\s+)?(?:creatures|permanents)\b|\bdeals?\s+\d+\s+damage\s+to\s+each\s+creature\b|\ball\s+(?:other\s+)?creatures\s+get\s+-\d+\/-\d+/i;
+
+/**
+ * Pattern: Creature death
+ *
+ * Why keep: Creatures dying drive aristocrats value and combat attrition;
+ * many deaths per round mark a grindy game rather than a quick combo kill.
+ *
+ * Forge examples:
+ *   - "Zone Change: Serra Angel (33) dies."
+ *   - "Zone Change: Goblin Guide (2) is destroyed."
+ *   - "Zone Change: Llanowar Elves (4) was put into its owner's graveyard from the battlefield."
+ *
+ * Only reports of a death count: rules text ("Whenever another creature
+ * you control dies") and the removal spell itself ("Destroy target
+ * creature") don't. The log doesn't say what type a destroyed permanent
+ * was, so lines naming a land, artifact, enchantment or planeswalker are
+ * left out and other destroyed permanents count as creatures.
+ */
+export const KEEP_CREATURE_DEATH = /^(?!.*\bwhen(?:ever)?\b)(?!.*\b(?:land|artifact|enchantment|planeswalker)s?\b).*?(?:\bdies\b|\b(?:is|was)\s+destroyed\b|\b(?:is|was)\s+put\s+into\s+(?:a\s+|the\s+|its\s+owner['’]s\s+)?graveyard\s+from\s+(?:the\s+)?battlefield\b)/i;
+
 /**
  * Pattern: Graveyard to battlefield zone change
  *
@@ -202,6 +341,24 @@ export const KEEP_ZONE_CHANGE_GY_BF = /graveyard\s*->\s*battlefield|graveyard\s+
  */
 export const KEEP_WIN_CONDITION = /wins?\s+the\s+game|game\s+over|winner|wins\s+the\s+match|loses\s+the\s+game/i;
 
+/**
+ * Pattern: Lock effects that suppress winning or losing
+ *
+ * Why detect: Rare but game-warping effects (Platinum Angel, Angel's Grace,
+ * Gideon's Intervention-style fogs) can stop a game from ever producing a
+ * win line. When a game has no detected winner, the presence of one of these
+ * is a likely explanation for the stall.
+ *
+ * Note: "can't win the game" also matches KEEP_WIN_CONDITION, so the
+ * classifier checks this pattern first to avoid treating a lock as a win.
+ *
+ * Forge examples:
+ *   - "Players can't lose the game."
+ *   - "Your opponents can't win the game."
+ *   - "Damage can't be dealt this turn."
+ */
+export const DETECT_LOCK_EFFECT = /can['’]?t\s+(?:lose|win)\s+the\s+game|cannot\s+(?:lose|win)\s+the\s+game|damage\s+can['’]?t\s+be\s+dealt/i;
+
 /**
  * Pattern: Commander cast
  *
@@ -241,11 +398,148 @@ export const KEEP_COMBAT = /attacks?\s+with|declares?\s+attack|combat\s+damage|a
  */
 export const KEEP_LAND_PLAYED = /^Land:/i;
 
+/**
+ * Pattern: Mill (cards put from library into graveyard)
+ *
+ * Why keep: Self-mill fuels reanimator and graveyard engines, and milling
+ * opponents can itself be a win condition. Either way it's a signal about
+ * the deck's strategy.
+ *
+ * Forge examples:
+ *   - "Zone Change: Ai(2)-Enduring Enchantments milled Calix, Destiny's Hand (141) and Mirari's Wake (167)."
+ *   - "Player A mills 3 cards."
+ *   - "Player A puts the top 4 cards of their library into their graveyard."
+ *
+ * Gaps are bounded so long lines stay linear.
+ */
+export const KEEP_MILL = /\bmill(?:s|ed)\s|puts?\s+the\s+top\s+\S+\s+cards?\s+of\s+[^.]{0,60}?library\s+into\s+[^.]{0,40}?graveyard/i;
+
+/**
+ * Pattern: Library manipulation (scry / surveil)
+ *
+ * Why keep: Scry and surveil smooth draws and, with surveil, fill the
+ * graveyard. Frequent use indicates card selection density.
+ *
+ * Only the resolved forms are matched, not the bare rules text ("scry 1."),
+ * which Forge also prints in trigger descriptions.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Ai(4)-Veloci-RAMP-Tor scried 1 card(s) to the top of the library"
+ *   - "Resolve stack: Omen of the Hunt (117) - Ai(2)-Enduring Enchantments scries 2."
+ *   - "Player A surveils 2."
+ */
+export const KEEP_LIBRARY_MANIP = /\b(?:scries|scried|surveils|surveilled)\s+\d+/i;
+
+/**
+ * Pattern: Clone / copy effect
+ *
+ * Why keep: Clones and token copies change the board in ways the ETB and
+ * token detectors can't see (a Clone entering "as a copy of" a Craterhoof
+ * is a Craterhoof). Frequent copying indicates a value or combo engine.
+ *
+ * Forge examples:
+ *   - "Zone Change: Clone (7) enters the battlefield as a copy of Craterhoof Behemoth (9)."
+ *   - "Resolve stack: Cytoshape (3) - Ai(2)-Beta's Llanowar Elves (2) becomes a copy of Ghalta, Primal Hunger (11) until end of turn."
+ *   - "Resolve stack: Ai(1)-Alpha creates a token copy of Dockside Extortionist (4)."
+ *
+ * A token copy is a clone AND a token: it counts here, and its own ETB line
+ * is a token ETB for board development. A non-token clone's ETB counts as a
+ * real permanent.
+ */
+export const KEEP_CLONE = /\b(?:enters?\s+(?:the\s+battlefield\s+)?as\s+a\s+copy\s+of|becomes?\s+a\s+copy\s+of|token\s+cop(?:y|ies)\s+of)\b/i;
+
+/**
+ * Pattern: Goad and forced attacks
+ *
+ * Why keep: Goad ("attacks each combat if able and attacks a player other
+ * than you if able") redirects combat at the rest of the table. Goad-heavy
+ * decks play politics, and a goaded attack isn't a sign of the attacker's
+ * own aggression.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Disrupt Decorum (4) - Ai(1)-Alpha goads each creature you don't control."
+ *   - "Resolve stack: Vengeful Ancestor (3) - Ai(2)-Beta's Grizzly Bears (2) is goaded."
+ *   - "Effect: Grizzly Bears (2) must attack if able."
+ *
+ * Card ids "(2)" would read as a CMC, so goad is classified before high CMC.
+ */
+export const KEEP_GOAD = /\bgoads\s+\S|\bis\s+goaded\b|\bmust\s+attack\s+(?:[^.\n]{0,40}?\s)?if\s+able\b/i;
+
+/**
+ * Keywords a permanent can gain to protect itself (hexproof, shroud,
+ * protection, indestructible).
+ *
+ * Add a keyword here to track a new protection; KEEP_PROTECTION is built
+ * from this list with buildProtectionPattern.
+ */
+export const PROTECTION_KEYWORDS: readonly string[] = ['hexproof', 'shroud', 'protection', 'indestructible'];
+
+/**
+ * Builds the protection pattern: "can't be countered", or a line where
+ * something gains one of the keywords.
+ *
+ * @param keywords - Protection keywords (matched case-insensitively as whole words)
+ */
+export function buildProtectionPattern(keywords: readonly string[]): RegExp {
+  const alternatives = keywords.map((k) => k.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')).join('|');
+  return new RegExp(`\\bcan(?:'|’|no)t\\s+be\\s+countered\\b|\\bgains?\\s+(?:[^.\\n]{0,40}?\\s)?(?:${alternatives})\\b`, 'i');
+}
+
+/**
+ * Pattern: Protection and "can't be countered"
+ *
+ * Why keep: Decks that protect their combo pieces and threats (Heroic
+ * Intervention, Lightning Greaves, uncounterable spells) make interaction
+ * less reliable against them, which matters for combo reliability.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Heroic Intervention (2) - Permanents you control gain hexproof and indestructible until end of turn."
+ *   - "Effect: Progenitus (10) gains protection from red until end of turn."
+ *   - "Stack: Ai(1)-Alpha cast Abrupt Decay (4), which can't be countered."
+ *
+ * Card ids "(10)" would read as a CMC, so protection is classified before
+ * high CMC. The gap before the keyword is bounded so long lines stay linear.
+ */
+export const KEEP_PROTECTION = buildProtectionPattern(PROTECTION_KEYWORDS);
+
+/**
+ * Pattern: Counter or silence backup
+ *
+ * Used to: Spot a combo turn defended by interaction as well as by
+ * protection (see protected-combo.ts): a counterspell resolving, or a
+ * Silence effect stopping opponents from casting spells.
+ *
+ * Forge examples:
+ *   - "Resolve stack: Counterspell (12) - Counter target spell."
+ *   - "Resolve stack: Silence (7) - Your opponents can't cast spells this turn."
+ *   - "Resolve stack: Grand Abolisher (5) - During your turn, your opponents can't cast spells ..."
+ */
+export const DETECT_COUNTER_BACKUP = /\bcounter\s+target\s+(?:\w+\s+){0,3}?(?:spell|ability)\b|\bcan(?:'|’|no)t\s+cast\s+spells\b/i;
+
+/**
+ * A goad of every creature the goading player doesn't control
+ * ("goads each creature you don't control", "goads all creatures ...").
+ */
+export const DETECT_MASS_GOAD = /\bgoads\s+(?:each|all)\b/i;
+
 // -----------------------------------------------------------------------------
 // SECTION 3: EXTRACTION PATTERNS (Metadata)
 // -----------------------------------------------------------------------------
 // These patterns extract structured information from lines.
 
+/**
+ * Turn marker prefix
+ *
+ * Some simulator outputs indent turn markers or prefix them with a
+ * "[timestamp]" or a line number ("0042:", "42."). Every turn-marker pattern
+ * allows, at the start of the line, optional whitespace followed by at most
+ * one bracketed prefix or one line number. Anything else before "Turn" (e.g.
+ * "Game outcome: Turn 20" or "...on Turn 5...") is still not a turn marker.
+ *
+ * The prefix is spelled out in each regex literal below:
+ *   ^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?
+ */
+
 /**
  * Pattern: Turn line with player
  *
@@ -258,7 +552,7 @@ export const KEEP_LAND_PLAYED = /^Land:/i;
  *
  * Note: This is a MULTILINE pattern - ^ matches start of each line.
  */
-export const EXTRACT_TURN_LINE = /^Turn\s+(\d+)(?::\s*(.+?)\s*)?$/im;
+export const EXTRACT_TURN_LINE = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn\s+(\d+)(?::\s*(.+?)\s*)?$/im;
 
 /**
  * Pattern: Turn number only (for finding all turns in a log)
@@ -270,9 +564,24 @@ export const EXTRACT_TURN_LINE = /^Turn\s+(\d+)(?::\s*(.+?)\s*)?$/im;
  *   - "Turn N: Player X" (older format)
  *   - "Turn: Turn N (PlayerName)" (current format)
  *
- * We match both by allowing an optional "Turn:" prefix before "Turn N".
+ * We match both by allowing an optional "Turn:" prefix before "Turn N";
+ * the number must be followed by "(", ": <player>" or the end of the line.
+ * A bare "Turn N:" with no player is noise (IGNORE_BARE_TURN), as in the
+ * worker's parser. This is
+ * the permissive pattern for logs whose format isn't known (see
+ * detectFormat); the two below match one format each.
+ */
+export const EXTRACT_TURN_NUMBER = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?(?:Turn:\s*)?Turn\s+(\d+)(?=[ \t]*\(|[ \t]*:[ \t]*\S|[ \t]*$)/gim;
+
+/**
+ * Pattern: Turn number, current format only ("Turn: Turn N (PlayerName)")
  */
-export const EXTRACT_TURN_NUMBER = /^Turn:?\s*Turn\s+(\d+)/gim;
+export const EXTRACT_TURN_NUMBER_CURRENT = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn:\s*Turn\s+(\d+)/gim;
+
+/**
+ * Pattern: Turn number, older format only ("Turn N: Player X")
+ */
+export const EXTRACT_TURN_NUMBER_LEGACY = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn\s+(\d+):(?=[ \t]*\S)/gim;
 
 /**
  * Pattern: Mana production/usage
@@ -284,15 +593,55 @@ export const EXTRACT_TURN_NUMBER = /^Turn:?\s*Turn\s+(\d+)/gim;
  *   - "produces 2 mana"
  *   - "taps for {W}"
  *   - "Tap Sol Ring for 2 mana"
+ *
+ * The gap before "mana" is bounded and the `(?<!\d)` lookbehind anchors
+ * number matches to the start of a digit run, so long lines can't make the
+ * match quadratic.
  */
-export const EXTRACT_MANA_PRODUCED = /(?:adds?|produces?|tap(s|ped)?\s+for)\s+[\w\s{}\d]*mana|(\d+)\s+mana\s+produced/i;
+export const EXTRACT_MANA_PRODUCED = /(?:adds?|produces?|tap(s|ped)?\s+for)\s+[\w\s{}\d]{0,80}mana|(?<!\d)(\d+)\s+mana\s+produced/i;
 
 /**
  * Pattern: Tap for mana (additional mana detection)
  *
  * Used to: Catch "Tap X for Y" patterns that indicate mana production.
+ * The card name gap is bounded to keep matching linear on long lines.
+ */
+export const EXTRACT_TAP_FOR = /tap(s|ped)?\s+.{0,80}?\s+for/i;
+
+/**
+ * Pattern: A player putting a spell on the stack
+ *
+ * Used to: Count the spells each player casts per turn (big turns). Only
+ * the stack line counts, so "Whenever you cast ..." trigger text isn't
+ * mistaken for a cast. Case-sensitive, as Forge writes it, so a card named
+ * "Cast Out" in a trigger line isn't read as the verb.
+ * Capturing group:
+ *   - Group 1: The casting player
+ *
+ * Forge examples:
+ *   - "Add to stack: Ai(1)-Alpha cast Dark Ritual (12)"
+ *   - "Stack: Ai-Alpha cast Sol Ring (3)"
+ *   - "Add to stack: Ai(2)-Beta triggered Cast Out (184) ..." -> no match
+ *   - "Add to stack: Ai(2)-Beta triggered Guttersnipe (58) - Whenever you
+ *     cast ..." -> no match (a triggered or activated ability's text)
+ */
+export const EXTRACT_CAST_BY = /^\s*(?:Add\s+to\s+stack|Stack):\s+((?:(?!\s(?:triggered|activated)\s).)+?)\s+casts?\s+\S/;
+
+/**
+ * Pattern: Mana added by a mana ability or ritual
+ *
+ * Used to: Count the mana made available per turn (big turns). Only the
+ * first option of a choice counts ("Add {R} or {G}" is one mana).
+ * Capturing groups:
+ *   - Group 1: The mana symbols added, e.g. "{B}{B}{B}"
+ *   - Group 2: A spelled-out amount ("one" ... "five")
+ *
+ * Forge examples:
+ *   - "Mana: Forest (287) - {T}: Add {G}." -> 1
+ *   - "Resolve stack: Dark Ritual (12) - Add {B}{B}{B}." -> 3
+ *   - "Mana: Command Tower (4) - {T}: Add one mana of any color ..." -> 1
  */
-export const EXTRACT_TAP_FOR = /tap(s|ped)?\s+.*?\s+for/i;
+export const EXTRACT_MANA_ADDED = /\badds?\s+((?:\{[^}\s]{1,3}\})+|(one|two|three|four|five)\s+mana\b)/i;
 
 /**
  * Pattern: Card draw events
@@ -304,9 +653,41 @@ export const EXTRACT_TAP_FOR = /tap(s|ped)?\s+.*?\s+for/i;
  * Forge examples:
  *   - "draws a card" -> 1 card
  *   - "draws 3 cards" -> 3 cards
+ *   - "that player draws an additional card" (Howling Mine) -> 1 card
  */
 export const EXTRACT_DRAW_MULTIPLE = /draws?\s+(\d+)\s+cards?/i;
-export const EXTRACT_DRAW_SINGLE = /draws?\s+(?:a\s+)?card(?!s)/i;
+export const EXTRACT_DRAW_SINGLE = /draws?\s+(?:an?\s+(?:additional\s+|extra\s+)?)?card(?!s)/i;
+
+/**
+ * Pattern: Symmetric draw
+ *
+ * Used to: Credit a draw effect to every player it reaches, not just its
+ * controller (see draws.ts).
+ * Capturing groups:
+ *   - Group 1: "player" or "opponent" for "each player/opponent draws"
+ *   - Undefined group 1: a per-player draw-step trigger (Howling Mine),
+ *     drawn by the player whose draw step it is
+ *
+ * Forge examples:
+ *   - "Resolve stack: Temple Bell (5) - Each player draws a card."
+ *   - "Resolve stack: At the beginning of each player's draw step, if Howling Mine is untapped, that player draws an additional card. [Phase: Ai(2)-Beta]"
+ *
+ * Wheels ("Each player discards their hand, then draws seven cards") are
+ * not matched: they replace hands rather than add to them.
+ */
+export const DETECT_SYMMETRIC_DRAW = /\beach\s+(?:other\s+)?(player|opponent)\s+(?:may\s+)?draws?\b|\beach\s+player's\s+draw\s+step\b.{0,80}?\bthat\s+player\s+draws?\b/i;
+
+/**
+ * Pattern: Activator of a resolving ability
+ *
+ * Used to: Tell whose trigger or spell a resolve line belongs to.
+ * Capturing group:
+ *   - Group 1: The player
+ *
+ * Forge example:
+ *   - "... draw a card. [Card: Omen of the Hunt (117), Activator: Ai(2)-Enduring Enchantments, SpellAbility: ...]"
+ */
+export const EXTRACT_ACTIVATOR = /\bActivator:\s*([^,\]]{1,120}?)\s*(?:,|\])/;
 
 /**
  * Pattern: CMC extraction from cast lines
@@ -321,6 +702,21 @@ export const EXTRACT_DRAW_SINGLE = /draws?\s+(?:a\s+)?card(?!s)/i;
  */
 export const EXTRACT_CMC = /\((?:CMC\s*)?(\d+)\)/i;
 
+/**
+ * Pattern: CMC stated outright on a cast line
+ *
+ * Used to: Build the cast CMC histogram. Unlike EXTRACT_CMC, a bare "(71)"
+ * doesn't count: current Forge logs put the card id there, and a curve of
+ * card ids is noise.
+ * Capturing group:
+ *   - Group 1: The CMC number
+ *
+ * Forge examples:
+ *   - "cast Consecrated Sphinx (CMC 6)" -> CMC 6
+ *   - "cast Arboreal Grazer (71)" -> no match
+ */
+export const EXTRACT_STATED_CMC = /\bCMC\s*(\d+)/i;
+
 /**
  * Pattern: Winner extraction
  *
@@ -335,6 +731,254 @@ export const EXTRACT_CMC = /\((?:CMC\s*)?(\d+)\)/i;
  */
 export const EXTRACT_WINNER = /(.+?)\s+(?:wins\s+the\s+game|has\s+won!?)(?:\s|$|!|\.)/i;
 
+/**
+ * Cheap pre-check for lines that might carry a win phrase. EXTRACT_WINNER
+ * never spans lines, so it only runs on lines containing one; its lazy
+ * prefix is quadratic on long lines without one.
+ */
+export const DETECT_WIN_PHRASE = /wins\s+the\s+game|has\s+won/i;
+
+/**
+ * Pattern: Decoration before the winner in a win line
+ *
+ * Used to: Trim EXTRACT_WINNER's capture, whose lazy `.+?` still starts at
+ *          the beginning of the line, down to the name.
+ *
+ * Forge examples:
+ *   - "Game outcome: Ai(1)-Alpha" -> "Ai(1)-Alpha"
+ *   - "Game Result: Game 1 ended in 600 ms. Ai(1)-Alpha" -> "Ai(1)-Alpha"
+ *   - "Turn 7: Alice attacks, Alice" -> "Alice attacks, Alice"
+ *     (the clause is trimmed against the log's players; see extractWinner)
+ */
+export const WIN_LINE_PREFIX =
+  /^(?:Game\s+outcome:\s*|Game\s+Result:.*?\bended\s+in\s+\d+\s*ms\.\s*|Turn:?\s+(?:Turn\s+)?\d+(?:\s*\([^)]*\))?\s*:?\s*)/i;
+
+/**
+ * Environment variable choosing how EXTRACT_WINNER's capture is trimmed:
+ *   - strict (default): WIN_LINE_PREFIX is stripped, and a capture ending in
+ *     a player from the log's turn markers is cut to that player
+ *   - legacy: only a leading "Game outcome:" is stripped
+ */
+export const WINNER_CAPTURE_ENV = 'WINNER_CAPTURE';
+
+/**
+ * Environment variable holding an operator-supplied win line pattern.
+ *
+ * Some simulator builds phrase the end of a game differently from
+ * EXTRACT_WINNER. WIN_LINE_PATTERN is a regex (case-insensitive) whose
+ * first capture group is the winner, e.g.
+ *   WIN_LINE_PATTERN='^Victory: (.+?) is the last player standing'
+ *
+ * It is tried on each line before the default pattern; when unset, or when
+ * no line matches, the default is used.
+ */
+export const WIN_LINE_PATTERN_ENV = 'WIN_LINE_PATTERN';
+
+/**
+ * Compiles and validates a WIN_LINE_PATTERN value.
+ *
+ * @param source - The regex source
+ * @returns The compiled, case-insensitive pattern
+ * @throws If the regex is invalid or has no capture group for the winner
+ */
+export function compileWinLinePattern(source: string): RegExp {
+  let pattern: RegExp;
+  try {
+    pattern = new RegExp(source, 'i');
+  } catch (err) {
+    throw new Error(`Invalid ${WIN_LINE_PATTERN_ENV}: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  // Alternating with an empty pattern always matches, exposing the group count
+  const groups = new RegExp(`${source}|`).exec('')!.length - 1;
+  if (groups < 1) {
+    throw new Error(`Invalid ${WIN_LINE_PATTERN_ENV}: needs a capture group for the winner`);
+  }
+  return pattern;
+}
+
+/**
+ * Pattern: Permanent entering the battlefield
+ *
+ * Used to: Count permanents each player adds to the board per round.
+ * Capturing groups:
+ *   - Group 1: The permanent (e.g., "Sol Ring (12)", "Elephant Token (422)")
+ *   - Group 2: The controller, when the line names one ("under X's control")
+ *
+ * Forge examples:
+ *   - "Zone Change: Sol Ring (12) enters the battlefield."
+ *   - "Zone Change: Wood Elves (6) enters the battlefield tapped."
+ *   - "Zone Change: Clone (7) enters the battlefield under Ai(1)-Alpha's control."
+ *   - "Zone Change: Clone (7) enters the battlefield as a copy of Craterhoof Behemoth (9)."
+ *
+ * Rules text ("When X enters the battlefield, ...") is not an ETB and is
+ * excluded; so are lines that don't end after the ETB clause.
+ */
+export const EXTRACT_ETB = /^(?!.*\bwhen(?:ever)?\b)(?:[A-Za-z ]{1,30}:\s*)?(.{1,120}?)\s+enters?\s+the\s+battlefield(?:\s+tapped)?(?:\s+under\s+(.{1,80}?)['’]s?\s+control)?(?:\s+as\s+a\s+copy\s+of\s+.{1,120}?)?\s*\.?\s*$/i;
+
+/**
+ * Pattern: Token in a permanent name
+ *
+ * Used to: Tell token ETBs from real permanents.
+ */
+export const DETECT_TOKEN = /\btoken\b/i;
+
+/**
+ * Pattern: Damage dealt by a source
+ *
+ * Used to: Find the killing blow before an elimination, and total the
+ * early damage each deck dealt (see aggression.ts).
+ * Capturing groups:
+ *   - Group 1: The source (e.g., "Elephant Token (422)")
+ *   - Group 2: The amount of damage
+ *   - Group 3: "combat " when it was combat damage
+ *   - Group 4: What was damaged (a player or a permanent)
+ *
+ * Forge examples:
+ *   - "Damage: Elephant Token (422) deals 3 combat damage to Ai(2)-Enduring Enchantments."
+ *   - "Damage: Lightning Bolt (5) deals 3 damage to Ai(2)-Beta."
+ */
+export const EXTRACT_DAMAGE = /^Damage:\s*(.{1,120}?)\s+deals\s+(\d+)\s+(combat\s+)?damage\s+to\s+(.{1,120}?)\.?\s*$/i;
+
+/**
+ * Pattern: Player eliminated
+ *
+ * Used to: Find who lost, and where the end-of-game lines start.
+ * Capturing groups:
+ *   - Group 1: The player, in the current format
+ *   - Group 2: The player, in the older format
+ *
+ * Forge formats:
+ *   - "Game outcome: Ai(2)-Beta has lost because life total reached 0" (current)
+ *   - "Player B loses the game." (older)
+ */
+export const EXTRACT_ELIMINATED_PLAYER = /^Game outcome:\s*(.{1,120}?)\s+has\s+lost\b|^(.{1,120}?)\s+loses\s+the\s+game\b/i;
+
+/**
+ * Pattern: Player conceded
+ *
+ * Used to: Treat a concession as an elimination when inferring the last
+ * player standing.
+ * Capturing groups:
+ *   - Group 1: The player
+ *
+ * Forge example: "Game outcome: Ai(3)-Gamma has conceded"
+ */
+export const EXTRACT_CONCEDED_PLAYER = /^Game outcome:\s*(.{1,120}?)\s+has\s+conceded\b/i;
+
+/**
+ * Pattern: Why a player lost
+ *
+ * Used to: Tell how a game ended (see win-reason.ts).
+ * Capturing groups:
+ *   - Group 1: The rest of the line after "has lost"
+ *
+ * Forge examples:
+ *   - "Game outcome: Ai(2)-Beta has lost because life total reached 0" -> "because life total reached 0"
+ *   - "Game outcome: Ai(2)-Beta has lost because of obtaining 10 poison counters"
+ *   - "Game outcome: Ai(2)-Beta has lost due to accumulation of 21 damage from generals"
+ *   - "Game outcome: Ai(2)-Beta has lost trying to draw cards from an empty library"
+ */
+export const EXTRACT_LOSS_REASON = /^Game outcome:\s*.{1,120}?\s+has\s+lost\b\s*(.{0,160}?)\s*$/i;
+
+/**
+ * Pattern: AI profile
+ *
+ * Used to: Record which Forge AI profile (difficulty / personality) played
+ * each seat, since win rates aren't comparable across AI skill levels.
+ * Case-sensitive so ordinary lines mentioning "Ai(1)-..." don't match.
+ * Capturing groups:
+ *   - Group 1 or 2: The player
+ *   - Group 3: The profile, e.g. "Reckless" or "Default.ai"
+ *
+ * Forge examples:
+ *   - "Ai(1)-Alpha AI: Default"
+ *   - "Ai(2)-Beta Personality: Reckless"
+ *   - "AI profile for Ai(3)-Gamma: Cautious.ai"
+ */
+export const EXTRACT_AI_PROFILE = /^[ \t]*(?:AI\s+profile\s+for\s+(.{1,120}?)|(.{1,120}?)\s+(?:AI(?:\s+[Pp]rofile)?|Personality)):[ \t]*(\S.{0,60}?)[ \t]*$/m;
+
+/**
+ * Pattern: Card put on the stack or played as a land, with its id
+ *
+ * Used to: Learn which player owns a card id, so interaction aimed at the
+ * card can be credited to them.
+ * Capturing groups:
+ *   - Group 1 or 3: The player
+ *   - Group 2 or 4: The card id
+ *
+ * Forge examples:
+ *   - "Add to stack: Ai(1)-Alpha cast Thassa's Oracle (5)" -> Ai(1)-Alpha, 5
+ *   - "Land: Ai(2)-Beta played Island (21)" -> Ai(2)-Beta, 21
+ */
+export const EXTRACT_CARD_OWNER = /^\s*(?:Add\s+to\s+stack|Stack):\s+(.+?)\s+casts?\s+[^\[]{1,120}?\((\d+)\)|^\s*Land:\s*(.+?)\s+played\s+[^\[]{1,120}?\((\d+)\)/;
+
+/**
+ * Pattern: Resolving spell or ability and its source card
+ *
+ * Used to: Find the card behind an interaction line, so a player's
+ * interaction with their own cards (flicker, self-bounce) isn't counted.
+ * Capturing groups:
+ *   - Group 1: The source card id
+ *
+ * Forge example:
+ *   - "Resolve stack: Murder (3) - Destroy target creature. (Targeting: Serra Angel (33))" -> 3
+ */
+export const EXTRACT_RESOLVE_SOURCE = /^\s*Resolve\s+stack:\s*.{1,120}?\((\d+)\)\s+-\s/;
+
+/**
+ * Pattern: Interaction clause
+ *
+ * Used to: Tell which clauses of a resolve line counter, remove or strip
+ * cards from another player. Each clause is followed by its own
+ * "(Targeting: ...)" section.
+ *
+ * Forge examples:
+ *   - "Counter target spell."
+ *   - "Destroy target creature." / "Exile target nonland permanent."
+ *   - "Return target creature or planeswalker to its owner's hand."
+ *   - "Target player discards two cards."
+ */
+export const DETECT_INTERACTION_CLAUSE = /\b(?:counter|destroy|exile)\s+(?:up\s+to\s+\w+\s+)?target\b|\breturn\s+(?:up\s+to\s+\w+\s+)?target\b[^.]{0,80}?\bto\s+(?:its|their)\s+owner['’]?s?['’]?\s+hands?\b|\btarget\s+(?:player|opponent)\s+discards\b/i;
+
+/**
+ * Pattern: Mulligan
+ *
+ * Used to: Record each player's mulligan sequence.
+ * Capturing groups:
+ *   - Group 1: The player
+ *   - Group 2: The hand size mulliganed down to
+ *
+ * Forge example: "Mulligan: Ai(1)-Alpha has mulliganed down to 6 cards."
+ */
+export const EXTRACT_MULLIGAN = /^\s*Mulligan:\s*(.{1,120}?)\s+has\s+mulliganed\s+down\s+to\s+(\d+)\s+cards?/i;
+
+/**
+ * Pattern: Kept opening hand
+ *
+ * Used to: Record the hand size each player kept.
+ * Capturing groups:
+ *   - Group 1: The player
+ *   - Group 2: The hand size kept
+ *
+ * Forge example: "Mulligan: Ai(1)-Alpha has kept a hand of 7 cards"
+ */
+export const EXTRACT_MULLIGAN_KEEP = /^\s*Mulligan:\s*(.{1,120}?)\s+has\s+kept\s+a\s+hand\s+of\s+(\d+)\s+cards?/i;
+
+/**
+ * Pattern: Player whose library was milled
+ *
+ * Used to: Tell self-mill from opponent-mill by comparing the milled player
+ * to the active player.
+ * Capturing group:
+ *   - Group 1: The milled player (e.g., "Ai(2)-Enduring Enchantments")
+ *
+ * Forge examples:
+ *   - "Zone Change: Ai(2)-Enduring Enchantments milled Calix, ..." -> "Ai(2)-Enduring Enchantments"
+ *   - "Player A mills 3 cards." -> "Player A"
+ *   - "Player A puts the top 4 cards of their library into their graveyard." -> "Player A"
+ */
+export const EXTRACT_MILLED_PLAYER = /^(?:Zone Change:\s*)?(.{1,80}?)\s+(?:mill(?:s|ed)\s|puts?\s+the\s+top\s+\S+\s+cards?\s+of\s+(?:their|his|her|its)\s+(?:own\s+)?library)/i;
+
 /**
  * Pattern: Player identifier from turn line
  *
@@ -350,7 +994,127 @@ export const EXTRACT_WINNER = /(.+?)\s+(?:wins\s+the\s+game|has\s+won!?)(?:\s|$|
  *
  * The caller should check both capture groups and use the first non-undefined.
  */
-export const EXTRACT_ACTIVE_PLAYER = /^Turn\s+\d+:\s*(.+?)\s*$|^Turn:\s*Turn\s+\d+\s*\((.+)\)\s*$/im;
+export const EXTRACT_ACTIVE_PLAYER = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?(?:Turn\s+\d+:\s*(.+?)\s*|Turn:\s*Turn\s+\d+\s*\((.+)\)\s*)$/im;
+
+/**
+ * Pattern: Triggered ability put on the stack, and its source card
+ *
+ * Used to: Name the permanent behind an upkeep or end-step trigger, so a
+ * source that fires turn after turn is found (see engines.ts).
+ * Capturing group:
+ *   - Group 1: The source card, without its id
+ *
+ * Forge example:
+ *   - "Add to stack: Ai(1)-Doran Big Butts triggered Nyx-Fleece Ram (6)" -> "Nyx-Fleece Ram"
+ */
+export const EXTRACT_TRIGGER_SOURCE = /^\s*(?:Add\s+to\s+stack|Stack):\s+.{1,120}?\s+triggered\s+(.{1,120}?)\s*\(\d+\)/;
+
+/**
+ * Pattern: Phase marker
+ *
+ * Used to: Track which step of the turn the following lines belong to.
+ * Capturing group:
+ *   - Group 1: The step (e.g., "Upkeep step", "Main phase, precombat")
+ *
+ * Forge example: "Phase: Ai(1)-Doran Big Butts' Upkeep step" -> "Upkeep step"
+ */
+export const EXTRACT_PHASE = /^\s*Phase:\s*.{1,120}['’]s?\s+(\S.{0,60}?)\s*$/;
+
+/**
+ * Steps whose triggers make up a recurring engine (Phyrexian Arena, Bitterblossom,
+ * "at the beginning of your end step" value engines). "End of Combat Step" is
+ * not an end step.
+ */
+export const DETECT_UPKEEP_OR_END_STEP = /^(?:upkeep|end)\s+step$/i;
+
+/**
+ * Trigger text that names the upkeep or end step, for logs without phase
+ * markers: "At the beginning of your upkeep, you draw a card and you lose 1 life."
+ */
+export const DETECT_BEGINNING_TRIGGER = /\bat\s+the\s+beginning\s+of\s+[^.]{0,40}?\b(?:upkeep|end\s+step)\b/i;
+
+/**
+ * Pattern: Spell cast, with its caster and card name
+ *
+ * Used to: Profile the card types each deck casts (see card-types.ts).
+ * Capturing groups:
+ *   - Group 1: The caster
+ *   - Group 2: The card name, without its id or stated CMC
+ *
+ * Forge examples:
+ *   - "Add to stack: Ai(1)-Alpha cast Phyrexian Arena (3)" -> "Ai(1)-Alpha", "Phyrexian Arena"
+ *   - "Player B casts Lightning Bolt (CMC 1)." -> "Player B", "Lightning Bolt"
+ *   - "Add to stack: Ai(2)-Beta triggered Guttersnipe (58) - Whenever you
+ *     cast ..." -> no match (a triggered or activated ability's text)
+ */
+export const EXTRACT_CAST_CARD = /^\s*(?:(?:Add\s+to\s+stack|Stack):\s+)?((?:(?!\s(?:triggered|activated)\s).){1,120}?)\s+casts?\s+([^(\[\n]{1,120}?)\s*(?:\(|\[|\.?\s*$)/;
+
+/**
+ * Pattern: Permanent spell resolving with its type line
+ *
+ * Used to: Learn a cast card's types from the log when no card resolver is
+ * given. Forge prints the type line when a permanent spell resolves
+ * (always for creatures, sometimes for other permanents).
+ * Capturing groups:
+ *   - Group 1: The card name
+ *   - Group 2: The type words (e.g., "Creature", "Legendary Artifact Creature")
+ *
+ * Forge examples:
+ *   - "Resolve stack: Ripjaw Raptor - Creature 4 / 5" -> "Ripjaw Raptor", "Creature"
+ *   - "Resolve stack: Phyrexian Arena - Enchantment" -> "Phyrexian Arena", "Enchantment"
+ */
+export const EXTRACT_RESOLVED_TYPES = /^\s*Resolve\s+stack:\s*([^(\[\n]{1,120}?)\s+-\s+((?:[A-Z][a-z]+\s+){0,3}[A-Z][a-z]+)(?:\s+\d+\s*\/\s*\d+)?\s*$/;
+
+/**
+ * Pattern: Attackers assigned to attack a player
+ *
+ * Used to: Count the attacks each player received, for the pod's
+ * archenemy (see archenemy.ts). An attack on a planeswalker or battle
+ * names the card, not a player, and is not credited to anyone.
+ * Capturing groups:
+ *   - Group 1: The attacking player
+ *   - Group 2: The attacking creatures, each with its id
+ *   - Group 3: The defender
+ *
+ * Forge example:
+ *   - "Combat: Ai(3)-Marchesa assigned Warren Soultrader (258) and Skyclave Shadowcat (264) to attack Ai(4)-Explorers of the Deep."
+ *     -> "Ai(3)-Marchesa", "Warren Soultrader (258) and Skyclave Shadowcat (264)", "Ai(4)-Explorers of the Deep"
+ */
+export const EXTRACT_ATTACK = /^\s*Combat:\s+(.{1,120}?)\s+assigned\s+(.{1,600}?)\s+to\s+attack\s+(.{1,120}?)\.?\s*$/;
+
+/**
+ * Pattern: Life a player paid or dealt themselves for mana or a cost
+ *
+ * Used to: Tell self-inflicted life loss from damage dealt by opponents
+ * (see life-payments.ts). Rules text offering a payment ("you may pay 2
+ * life") doesn't match; only a cost that was paid does.
+ * Capturing groups (one is set):
+ *   - Group 1: Life paid while casting a spell (Phyrexian mana, alternate costs)
+ *   - Group 2: Life paid in an activated ability's cost (fetchlands)
+ *   - Group 3: Damage a mana source dealt its controller (painlands, Ancient Tomb)
+ *   - Group 4: Damage a permanent dealt its controller on becoming tapped (City of Brass)
+ *   - Group 5: Life a player pays outright
+ *
+ * Forge examples:
+ *   - "Add to stack: Ai(1)-Alpha cast Dismember (7) (by paying 4 life for {B/P}{B/P})" -> group 1 "4"
+ *   - "Add to stack: Ai(1)-Alpha activated Polluted Delta (5) - {T}, Pay 1 life, Sacrifice Polluted Delta: Search your library ..." -> group 2 "1"
+ *   - "Mana: Llanowar Wastes (12) - {T}: Add {B}. Llanowar Wastes deals 1 damage to you." -> group 3 "1"
+ *   - "Resolve stack: Whenever City of Brass becomes tapped, it deals 1 damage to you. [Card: City of Brass (9)]" -> group 4 "1"
+ */
+export const EXTRACT_LIFE_PAYMENT = /\bby\s+paying\s+(\d+)\s+life\b|\bactivated\s+[^\n]{1,120}?\(\d+\)\s+-\s+[^:\n]{0,120}?\bpay\s+(\d+)\s+life\b[^:\n]{0,120}:|^\s*Mana:[^\n]{1,200}?\bdeals\s+(\d+)\s+damage\s+to\s+you\b|^\s*Resolve\s+stack:[^\n]{1,120}?\bbecomes\s+tapped,\s+it\s+deals\s+(\d+)\s+damage\s+to\s+you\b|\bpays\s+(\d+)\s+life\b/i;
+
+/**
+ * Pattern: Fetchland-style effect (sacrifice it to search for a land)
+ *
+ * Forge example:
+ *   - "... Sacrifice Polluted Delta: Search your library for an Island or Swamp card, ..."
+ */
+export const DETECT_FETCH = /\bsacrifice\s[^:\n]{1,120}:\s*search\s+your\s+library\b/i;
+
+/**
+ * Pattern: Phyrexian mana symbol, e.g. "{B/P}"
+ */
+export const DETECT_PHYREXIAN_MANA = /\{[WUBRGC](?:\/[WUBRG])?\/P\}/i;
 
 // -----------------------------------------------------------------------------
 // SECTION 4: GAME SPLITTING
@@ -366,16 +1130,51 @@ export const EXTRACT_ACTIVE_PLAYER = /^Turn\s+\d+:\s*(.+?)\s*$|^Turn:\s*Turn\s+\
  */
 export const GAME_RESULT_PATTERN = /^Game Result: Game \d+ ended/im;
 
+/**
+ * How splitConcatenatedGames finds game boundaries.
+ *
+ *   - 'result-line': split after each "Game Result: Game N ended..." line
+ *     (the default; what Forge writes for multi-game runs)
+ *   - 'turn-reset': also split where the turn counter goes back to 1 after
+ *     higher turns, for logs that hold several games with no result line
+ *     between them
+ */
+export type SplitStrategy = 'result-line' | 'turn-reset';
+
+export interface SplitOptions {
+  /** Boundary detection strategy (default 'result-line') */
+  strategy?: SplitStrategy;
+}
+
 /**
  * Split a concatenated multi-game log into individual game logs.
  *
  * When running 4 games per container, the stdout is a single concatenated
  * blob. This function splits it by "Game Result: Game N ended..." markers.
  *
+ * A game that still holds a win line followed by a "Turn 1" line (a
+ * best-of-N match with no result lines) is split again at that turn line,
+ * so each game keeps its own winner.
+ *
+ * With the 'turn-reset' strategy, each of those games is split again
+ * wherever a "Turn 1" line follows a higher turn, win line or not.
+ *
+ * Either way the new game starts at the turn line, so anything logged
+ * before it (pregame lines of the next game) stays with the previous game.
+ *
  * @param rawLog - The concatenated raw log text
+ * @param options - Boundary detection strategy
  * @returns Array of individual game log strings (1 per game)
  */
-export function splitConcatenatedGames(rawLog: string): string[] {
+export function splitConcatenatedGames(rawLog: string, options: SplitOptions = {}): string[] {
+  const games = splitOnResultLines(rawLog).flatMap(splitOnWinLines);
+  if (options.strategy === 'turn-reset') {
+    return games.flatMap(splitOnTurnReset);
+  }
+  return games;
+}
+
+function splitOnResultLines(rawLog: string): string[] {
   const trimmed = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n').trim();
   if (!trimmed) return [];
 
@@ -396,3 +1195,58 @@ export function splitConcatenatedGames(rawLog: string): string[] {
   }
   return games.length > 0 ? games : [trimmed];
 }
+
+/**
+ * Splits one log at each "Turn 1" line that comes after a win line.
+ * Uses the built-in win phrases only; a WIN_LINE_PATTERN override doesn't
+ * move game boundaries.
+ */
+function splitOnWinLines(game: string): string[] {
+  const turnPattern = new RegExp(EXTRACT_TURN_NUMBER.source, 'i');
+  const lines = game.split('\n');
+  const games: string[] = [];
+  let start = 0;
+  let won = false;
+
+  lines.forEach((line, i) => {
+    const match = turnPattern.exec(line);
+    if (match) {
+      if (won && parseInt(match[1], 10) === 1) {
+        games.push(lines.slice(start, i).join('\n').trim());
+        start = i;
+      }
+      won = false;
+    } else if (DETECT_WIN_PHRASE.test(line) && EXTRACT_WINNER.test(line)) {
+      won = true;
+    }
+  });
+  if (start === 0) return [game];
+  games.push(lines.slice(start).join('\n').trim());
+
+  return games.filter((g) => g.length > 0);
+}
+
+/**
+ * Splits one log wherever the turn counter resets to 1 after higher turns.
+ */
+function splitOnTurnReset(game: string): string[] {
+  const turnPattern = new RegExp(EXTRACT_TURN_NUMBER.source, 'i');
+  const lines = game.split('\n');
+  const games: string[] = [];
+  let start = 0;
+  let highestTurn = 0;
+
+  lines.forEach((line, i) => {
+    const match = turnPattern.exec(line);
+    if (!match) return;
+    const turn = parseInt(match[1], 10);
+    if (turn === 1 && highestTurn > 1) {
+      games.push(lines.slice(start, i).join('\n').trim());
+      start = i;
+    }
+    highestTurn = turn === 1 ? 1 : Math.max(highestTurn, turn);
+  });
+  games.push(lines.slice(start).join('\n').trim());
+
+  return games.filter((g) => g.length > 0);
+}
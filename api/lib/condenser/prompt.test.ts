@@ -0,0 +1,197 @@
+/**
+ * Tests for the analysis prompt builder.
+ *
+ * Run with: npx tsx lib/condenser/prompt.test.ts
+ */
+
+import type { CondensedGame, JobResults } from '../types';
+import { buildAnalysisPrompt, DEFAULT_PROMPT_OPTIONS } from './prompt';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const DECK_NAMES = ['Beta', 'Alpha'];
+
+function makeGame(overrides: Partial<CondensedGame>): CondensedGame {
+  return {
+    keptEvents: [],
+    manaPerTurn: {},
+    cardsDrawnPerTurn: {},
+    turnCount: 0,
+    ...overrides,
+  };
+}
+
+const GAMES: CondensedGame[] = [
+  makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 6, turnCount: 6, protectedCombo: true }),
+  makeGame({ winner: 'Ai(2)-Beta', winningTurn: 9, turnCount: 9 }),
+  makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 7, turnCount: 7 }),
+  makeGame({ turnCount: 20, lockStallDetected: true }),
+];
+
+const RESULTS: JobResults = {
+  wins: { Alpha: 2, Beta: 1 },
+  avgWinTurn: { Alpha: 6.5, Beta: 9 },
+  gamesPlayed: 4,
+  confidence: 'low (<20 games)',
+  explosiveness: { Alpha: 72, Beta: 31 },
+  ritualsPerGame: { Alpha: 1.5, Beta: 0 },
+  turnCountPercentiles: { p50: 7, p90: 8.6, p99: 8.96, min: 6, max: 9 },
+  avgFirstBloodTurn: 5.5,
+};
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running prompt tests...\n');
+
+  await test('buildAnalysisPrompt: includes the key stats', () => {
+    const prompt = buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES);
+    assert(prompt.startsWith(DEFAULT_PROMPT_OPTIONS.instructions), 'opens with the instructions');
+    assert(prompt.includes('- Games played: 4'), 'game count');
+    assert(prompt.includes('- Decisive games: 3'), 'decisive games');
+    assert(prompt.includes('- Confidence: low (<20 games)'), 'confidence');
+    assert(prompt.includes('- Alpha: 2 wins (50.0%), average winning turn 6.5'), 'Alpha win rate');
+    assert(prompt.includes('- Beta: 1 wins (25.0%), average winning turn 9'), 'Beta win rate');
+    assert(prompt.indexOf('- Alpha: 2 wins') < prompt.indexOf('- Beta: 1 wins'), 'decks sorted by name');
+    assert(prompt.includes('- Alpha: explosiveness 72/100, 1.5 rituals per game'), 'Alpha profile');
+    assert(prompt.includes('median 7'), 'game length');
+    assert(prompt.includes('round 5.5'), 'first blood');
+    assert(prompt.includes('- Fastest win: turn 6 by Alpha (Game 1)'), 'fastest win');
+    assert(prompt.includes('counter backup: Game 1'), 'protected combo');
+    assert(prompt.includes('- Lock stalls: Game 4'), 'lock stall');
+    assert(prompt.includes('- No winner: Game 4'), 'no winner');
+  });
+
+  await test('buildAnalysisPrompt: deck profiles include the card types cast', () => {
+    const cast = makeGame({
+      keptEvents: [
+        { type: 'spell_cast', line: 'Add to stack: Ai(2)-Beta cast Counterspell (3)', player: 'Ai(2)-Beta' },
+        { type: 'spell_cast', line: 'Add to stack: Ai(2)-Beta cast Ripjaw Raptor (4)', player: 'Ai(2)-Beta', repeat: 2 },
+      ],
+      cardTypeHints: { 'Ripjaw Raptor': ['creature'] },
+    });
+    const prompt = buildAnalysisPrompt([...GAMES, cast], RESULTS, DECK_NAMES);
+    assert(prompt.includes('- Beta: explosiveness 31/100, 0 rituals per game, casts 2 creature, 1 unknown'), 'Beta card types');
+  });
+
+  await test('buildAnalysisPrompt: deck profiles include games as the archenemy', () => {
+    const prompt = buildAnalysisPrompt(GAMES, { ...RESULTS, archenemyCounts: { Alpha: 0, Beta: 3 } }, DECK_NAMES);
+    assert(prompt.includes('- Beta: explosiveness 31/100, 0 rituals per game, archenemy in 3 games'), 'Beta archenemy');
+    assert(!prompt.includes('- Alpha: explosiveness 72/100, 1.5 rituals per game, archenemy'), 'never the archenemy is omitted');
+  });
+
+  await test('buildAnalysisPrompt: deck profiles include the aggression index', () => {
+    const prompt = buildAnalysisPrompt(GAMES, { ...RESULTS, aggressionIndex: { Alpha: 64, Beta: 0 } }, DECK_NAMES);
+    assert(prompt.includes('- Alpha: explosiveness 72/100, aggression 64/100, 1.5 rituals per game'), 'Alpha aggression');
+    assert(prompt.includes('- Beta: explosiveness 31/100, aggression 0/100, 0 rituals per game'), 'Beta aggression');
+  });
+
+  await test('buildAnalysisPrompt: deck profiles include extra-turn combo wins', () => {
+    const combo = makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 5, turnCount: 5, extraTurnCombo: true });
+    const prompt = buildAnalysisPrompt([...GAMES, combo, { ...combo, lowSignal: true }], RESULTS, DECK_NAMES);
+    assert(prompt.includes('- Alpha: explosiveness 72/100, 1.5 rituals per game, 1 extra-turn combo wins'), 'Alpha extra-turn combos');
+    assert(!prompt.includes('- Beta: explosiveness 31/100, 0 rituals per game, 0 extra-turn'), 'decks without one are omitted');
+  });
+
+  await test('buildAnalysisPrompt: deterministic', () => {
+    assertEqual(buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES), buildAnalysisPrompt(GAMES, RESULTS, [...DECK_NAMES].reverse()), 'same prompt');
+  });
+
+  await test('buildAnalysisPrompt: drops the least important sections to fit the length cap', () => {
+    const full = buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES);
+    const cap = full.indexOf('## Notable Games') - 1;
+    const trimmed = buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES, { ...DEFAULT_PROMPT_OPTIONS, maxLength: cap });
+    assert(trimmed.length <= cap, `within ${cap} characters (got ${trimmed.length})`);
+    assert(!trimmed.includes('## Notable Games'), 'notable games dropped first');
+    assert(trimmed.includes('## Game Pace') && trimmed.includes('## Deck Profiles'), 'more important sections kept');
+
+    const tight = buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES, {
+      ...DEFAULT_PROMPT_OPTIONS,
+      maxLength: full.indexOf('## Deck Profiles') - 1,
+    });
+    assert(tight.includes('## Deck Win Rates') && !tight.includes('## Game Pace') && !tight.includes('## Deck Profiles'), 'only required sections left');
+  });
+
+  await test('buildAnalysisPrompt: cuts the required sections at the cap when they alone are too long', () => {
+    const prompt = buildAnalysisPrompt(GAMES, RESULTS, DECK_NAMES, { ...DEFAULT_PROMPT_OPTIONS, maxLength: 50 });
+    assertEqual(prompt.length, 50, 'exactly the cap');
+    assertEqual(prompt, DEFAULT_PROMPT_OPTIONS.instructions.slice(0, 50), 'instructions first');
+  });
+
+  await test('buildAnalysisPrompt: lists a limited number of notable games', () => {
+    const draws = Array.from({ length: 8 }, () => makeGame({ turnCount: 12 }));
+    const prompt = buildAnalysisPrompt(draws, { wins: {}, avgWinTurn: {}, gamesPlayed: 8 }, DECK_NAMES, {
+      ...DEFAULT_PROMPT_OPTIONS,
+      maxNotableGames: 2,
+    });
+    assert(prompt.includes('- No winner: Game 1, Game 2 (+6 more)'), 'capped list');
+    assert(prompt.includes('- Confidence: low (<20 games)'), 'confidence derived from games played');
+    assert(!prompt.includes('## Deck Profiles') && !prompt.includes('## Game Pace'), 'empty sections omitted');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,227 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Analysis Prompt
+ * =============================================================================
+ *
+ * Assembles the prompt sent to the analysis model for a job, from the
+ * condensed games (the analysis payload) and the job's aggregated results,
+ * so the prompt is built and tested in one place.
+ *
+ * ## Sections
+ *
+ * In order of importance:
+ *
+ *   1. Instructions
+ *   2. Sample: games played, decisive games and sample-size confidence
+ *   3. Deck win rates: wins, win rate and average winning turn per deck
+ *   4. Deck profiles: explosiveness, aggression, rituals per game, comeback
+ *      wins, games as the archenemy and kept hand size, where the results
+ *      have them,
+ *      the card types each deck cast (card-types.ts) and its extra-turn
+ *      combo wins (extra-turns.ts)
+ *   5. Game pace: game length percentiles and average first-blood round
+ *   6. Notable games: fastest wins, protected combos, lock stalls and games
+ *      with no winner
+ *
+ * ## Length budget
+ *
+ * The prompt is at most `maxLength` characters. When it doesn't fit, whole
+ * sections are dropped from the least important up; instructions, sample
+ * and win rates are always kept, and are cut at the limit only if they
+ * alone don't fit. Output is deterministic: decks are sorted by name and
+ * games are referenced by their 1-based position in the job.
+ *
+ * Low-signal games (see low-signal.ts) are left out of every section.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame, JobResults } from '../types';
+import { sampleConfidence } from './confidence';
+import { resolveWinnerName } from './deck-match';
+import { cardTypeProfile, CARD_TYPES, CARD_TYPE_UNKNOWN } from './card-types';
+import { extraTurnComboCounts } from './extra-turns';
+
+/**
+ * Options for buildAnalysisPrompt.
+ */
+export interface PromptOptions {
+  /** Longest prompt, in characters */
+  maxLength: number;
+  /** Games listed per notable-game category */
+  maxNotableGames: number;
+  /** Instructions opening the prompt */
+  instructions: string;
+}
+
+export const DEFAULT_PROMPT_INSTRUCTIONS = [
+  'You are assessing Magic: The Gathering Commander decks from simulated games.',
+  'Using the statistics below, estimate each deck\'s power bracket (1-5) and explain the evidence.',
+  'Treat results from a low-confidence sample with caution.',
+].join('\n');
+
+export const DEFAULT_PROMPT_OPTIONS: PromptOptions = {
+  maxLength: 8000,
+  maxNotableGames: 5,
+  instructions: DEFAULT_PROMPT_INSTRUCTIONS,
+};
+
+interface PromptSection {
+  lines: string[];
+  /** Kept (and cut to fit if need be) rather than dropped */
+  required: boolean;
+}
+
+function formatPercent(numerator: number, denominator: number): string {
+  if (denominator === 0) return '0.0%';
+  return `${((numerator / denominator) * 100).toFixed(1)}%`;
+}
+
+function gameList(indices: number[], max: number): string {
+  const listed = indices.slice(0, max).map((i) => `Game ${i + 1}`).join(', ');
+  return indices.length > max ? `${listed} (+${indices.length - max} more)` : listed;
+}
+
+function formatTypeMix(counts: Record<string, number> | undefined): string | undefined {
+  if (!counts) return undefined;
+  const parts = [...CARD_TYPES, CARD_TYPE_UNKNOWN]
+    .filter((type) => counts[type])
+    .map((type) => `${counts[type]} ${type}`);
+  return parts.length > 0 ? `casts ${parts.join(', ')}` : undefined;
+}
+
+function deckSections(
+  games: CondensedGame[],
+  results: JobResults,
+  deckNames: string[],
+  gamesPlayed: number
+): PromptSection[] {
+  const decks = [...new Set([...deckNames, ...Object.keys(results.wins)])].sort((a, b) => a.localeCompare(b));
+
+  const winRates = ['## Deck Win Rates'];
+  for (const deck of decks) {
+    const wins = results.wins[deck] ?? 0;
+    const avgTurn = results.avgWinTurn[deck];
+    winRates.push(
+      `- ${deck}: ${wins} wins (${formatPercent(wins, gamesPlayed)})` +
+        (wins > 0 && avgTurn ? `, average winning turn ${avgTurn}` : '')
+    );
+  }
+
+  const counted = games.filter((g) => !g.lowSignal);
+  const typeProfile = cardTypeProfile(counted, deckNames);
+  const extraTurnCombos = extraTurnComboCounts(counted, deckNames);
+  const profiles = ['## Deck Profiles'];
+  for (const deck of decks) {
+    const traits: string[] = [];
+    if (results.explosiveness?.[deck] !== undefined) traits.push(`explosiveness ${results.explosiveness[deck]}/100`);
+    if (results.aggressionIndex?.[deck] !== undefined) traits.push(`aggression ${results.aggressionIndex[deck]}/100`);
+    if (results.ritualsPerGame?.[deck] !== undefined) traits.push(`${results.ritualsPerGame[deck]} rituals per game`);
+    if (results.comebackWins?.[deck]) traits.push(`${results.comebackWins[deck]} comeback wins`);
+    if (results.archenemyCounts?.[deck]) traits.push(`archenemy in ${results.archenemyCounts[deck]} games`);
+    if (results.avgKeptHandSize?.[deck] !== undefined) traits.push(`average kept hand ${results.avgKeptHandSize[deck]}`);
+    const typeMix = formatTypeMix(typeProfile[deck]);
+    if (typeMix) traits.push(typeMix);
+    if (extraTurnCombos[deck]) traits.push(`${extraTurnCombos[deck]} extra-turn combo wins`);
+    if (traits.length > 0) profiles.push(`- ${deck}: ${traits.join(', ')}`);
+  }
+
+  return [
+    { lines: winRates, required: true },
+    { lines: profiles.length > 1 ? profiles : [], required: false },
+  ];
+}
+
+function paceSection(results: JobResults): PromptSection {
+  const lines = ['## Game Pace'];
+  const p = results.turnCountPercentiles;
+  if (p) {
+    lines.push(`- Game length (turns): median ${p.p50}, p90 ${p.p90}, fastest ${p.min}, slowest ${p.max}`);
+  }
+  if (results.avgFirstBloodTurn) {
+    lines.push(`- First player eliminated on round ${results.avgFirstBloodTurn} on average`);
+  }
+  return { lines: lines.length > 1 ? lines : [], required: false };
+}
+
+function notableSection(games: CondensedGame[], deckNames: string[], maxGames: number): PromptSection {
+  const winningTurns = games.filter((g) => !g.lowSignal && g.winner && g.winningTurn).map((g) => g.winningTurn!);
+  const fastestTurn = winningTurns.length > 0 ? Math.min(...winningTurns) : undefined;
+
+  const fastest: number[] = [];
+  const protectedCombos: number[] = [];
+  const lockStalls: number[] = [];
+  const noWinner: number[] = [];
+  games.forEach((game, i) => {
+    if (game.lowSignal) return;
+    if (game.winner && game.winningTurn === fastestTurn) fastest.push(i);
+    if (game.protectedCombo) protectedCombos.push(i);
+    if (game.lockStallDetected) lockStalls.push(i);
+    if (!game.winner) noWinner.push(i);
+  });
+
+  const lines = ['## Notable Games'];
+  if (fastest.length > 0) {
+    const winners = [...new Set(fastest.map((i) => resolveWinnerName(games[i].winner!, deckNames)))].sort();
+    lines.push(`- Fastest win: turn ${fastestTurn} by ${winners.join(', ')} (${gameList(fastest, maxGames)})`);
+  }
+  if (protectedCombos.length > 0) {
+    lines.push(`- Winning or big turn protected by hexproof/indestructible or counter backup: ${gameList(protectedCombos, maxGames)}`);
+  }
+  if (lockStalls.length > 0) {
+    lines.push(`- Lock stalls: ${gameList(lockStalls, maxGames)}`);
+  }
+  if (noWinner.length > 0) {
+    lines.push(`- No winner: ${gameList(noWinner, maxGames)}`);
+  }
+  return { lines: lines.length > 1 ? lines : [], required: false };
+}
+
+function render(sections: PromptSection[]): string {
+  return sections
+    .filter((s) => s.lines.length > 0)
+    .map((s) => s.lines.join('\n'))
+    .join('\n\n');
+}
+
+/**
+ * Builds the analysis prompt for a job.
+ *
+ * @param games - Condensed games for the job (in job order)
+ * @param results - The job's aggregated results
+ * @param deckNames - Deck names; winners are resolved against these
+ * @param options - Length cap, notable-game count and instructions
+ * @returns The prompt, at most `options.maxLength` characters
+ */
+export function buildAnalysisPrompt(
+  games: CondensedGame[],
+  results: JobResults,
+  deckNames: string[],
+  options: PromptOptions = DEFAULT_PROMPT_OPTIONS
+): string {
+  const gamesPlayed = results.gamesPlayed;
+  const decisive = games.filter((g) => !g.lowSignal && g.winner).length;
+  const sample = [
+    '## Sample',
+    `- Games played: ${gamesPlayed}`,
+    `- Decisive games: ${decisive}`,
+    `- Confidence: ${results.confidence ?? sampleConfidence(gamesPlayed).label}`,
+  ];
+
+  const sections: PromptSection[] = [
+    { lines: [options.instructions], required: true },
+    { lines: sample, required: true },
+    ...deckSections(games, results, deckNames, gamesPlayed),
+    paceSection(results),
+    notableSection(games, deckNames, options.maxNotableGames),
+  ];
+
+  // Drop optional sections, least important (last) first, until it fits
+  let prompt = render(sections);
+  for (let i = sections.length - 1; i >= 0 && prompt.length > options.maxLength; i--) {
+    if (sections[i].required) continue;
+    sections[i] = { ...sections[i], lines: [] };
+    prompt = render(sections);
+  }
+  return prompt.length > options.maxLength ? prompt.slice(0, options.maxLength) : prompt;
+}
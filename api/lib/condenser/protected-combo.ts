@@ -0,0 +1,124 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Protected Combo
+ * =============================================================================
+ *
+ * Flags games where the win (or a big combo turn) came with protection or
+ * counter backup in play: Heroic Intervention, Teferi's Protection, Silence,
+ * a held-up Counterspell. A deck that shields its combo turn is a prepared
+ * combo deck, a stronger bracket signal than the combo alone.
+ *
+ * ## Heuristic
+ *
+ * protectionPerRound counts, per round, the lines that grant protection (see
+ * KEEP_PROTECTION, built from `keywords`) and, with `includeCounters`, the
+ * counter and silence lines (DETECT_COUNTER_BACKUP). The condensed game keeps
+ * these as `protectionPerTurn`.
+ *
+ * protectedCombo then looks at the key rounds: the round of the winner's
+ * last turn and, with `includeBigTurns`, every big turn (see big-turns.ts).
+ * A game is a protected combo when a key round, or one of the
+ * `windowRounds` rounds before it, has a protection line.
+ *
+ * Protection lines don't reliably name whose permanent they protect, so any
+ * player's protection in a key round counts.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { buildProtectionPattern, DETECT_COUNTER_BACKUP, PROTECTION_KEYWORDS } from './patterns';
+import { indexTurns, sliceByTurn, getNumPlayers, segmentToRound, type TurnIndex } from './turns';
+import { matchesDeckName } from './deck-match';
+import { shouldIgnoreLine } from './filter';
+
+/**
+ * Keywords and thresholds for protectedCombo.
+ */
+export interface ProtectedComboOptions {
+  /** Keywords a permanent gains to count as protected */
+  keywords: readonly string[];
+  /** Count counterspells and Silence effects as backup too */
+  includeCounters: boolean;
+  /** Big turns are key rounds as well as the winning round */
+  includeBigTurns: boolean;
+  /** Rounds before a key round whose protection still counts (0 = same round only) */
+  windowRounds: number;
+}
+
+export const DEFAULT_PROTECTED_COMBO: ProtectedComboOptions = {
+  keywords: PROTECTION_KEYWORDS,
+  includeCounters: true,
+  includeBigTurns: true,
+  windowRounds: 0,
+};
+
+/**
+ * Counts protection and counter-backup lines per round.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param options - Keywords and whether counters count
+ * @param turns - The game's turn index (built if not provided)
+ * @returns Map of round number -> protection lines. Rounds without any are
+ *          omitted.
+ */
+export function protectionPerRound(
+  rawLog: string,
+  options: ProtectedComboOptions = DEFAULT_PROTECTED_COMBO,
+  turns: TurnIndex = indexTurns(rawLog)
+): Record<number, number> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const protection = buildProtectionPattern(options.keywords);
+  const result: Record<number, number> = {};
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      if (shouldIgnoreLine(line)) continue;
+      if (protection.test(line) || (options.includeCounters && DETECT_COUNTER_BACKUP.test(line))) {
+        result[round] = (result[round] ?? 0) + 1;
+      }
+    }
+  }
+
+  return result;
+}
+
+/**
+ * Round of the winner's last turn, from perDeckTurns.
+ */
+function winningRound(game: CondensedGame): number | undefined {
+  if (!game.winner || !game.perDeckTurns) return undefined;
+  const players = Object.keys(game.perDeckTurns);
+  const winnerKey = players.find((k) => matchesDeckName(k, game.winner!));
+  if (!winnerKey) return undefined;
+  return segmentToRound(game.perDeckTurns[winnerKey].lastSegment, players.length);
+}
+
+/**
+ * True when the winning round (or a big turn) had protection or counter
+ * backup.
+ *
+ * @param game - A condensed game with protectionPerTurn
+ * @param options - Which rounds count; keywords and counters were applied
+ *   when protectionPerTurn was built
+ */
+export function protectedCombo(
+  game: CondensedGame,
+  options: ProtectedComboOptions = DEFAULT_PROTECTED_COMBO
+): boolean {
+  const protection = game.protectionPerTurn;
+  if (!protection || Object.keys(protection).length === 0) return false;
+
+  const keyRounds = new Set<number>(options.includeBigTurns ? game.bigTurns ?? [] : []);
+  const win = winningRound(game);
+  if (win !== undefined) keyRounds.add(win);
+
+  for (const round of keyRounds) {
+    for (let r = round - options.windowRounds; r <= round; r++) {
+      if ((protection[r] ?? 0) > 0) return true;
+    }
+  }
+  return false;
+}
@@ -0,0 +1,153 @@
+/**
+ * Tests for representative game selection.
+ *
+ * Run with: npx tsx lib/condenser/representative.test.ts
+ */
+
+import type { CondensedGame, StructuredGame } from '../types';
+import { selectRepresentativeGames } from './representative';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function makeGame(totalTurns: number, winner?: string): StructuredGame {
+  return {
+    totalTurns,
+    players: ['Ai(1)-A', 'Ai(2)-B'],
+    turns: [],
+    decks: [],
+    ...(winner && { winner }),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running representative game tests...\n');
+
+  await test('selectRepresentativeGames: picks one game per bucket', () => {
+    const games = [
+      makeGame(9, 'Ai(1)-A'), // 0
+      makeGame(5, 'Ai(2)-B'), // 1: fastest win
+      makeGame(30), //           2: draw, and the stalled game
+      makeGame(8, 'Ai(3)-C'), // 3: median win (5, 8, 9, 12)
+      makeGame(12, 'Ai(1)-A'), // 4
+      makeGame(10), //           5: second draw
+    ];
+    const reps = selectRepresentativeGames(games);
+    assertEqual(reps.fastestWin, 1, 'fastest win');
+    assertEqual(reps.medianWin, 3, 'median win');
+    assertEqual(reps.stalled, 2, 'stalled');
+    assertEqual(reps.draw, 2, 'first draw');
+  });
+
+  await test('selectRepresentativeGames: a long win can be the stalled game', () => {
+    const games = [makeGame(6, 'Ai(1)-A'), makeGame(7, 'Ai(2)-B'), makeGame(20, 'Ai(3)-C')];
+    const reps = selectRepresentativeGames(games);
+    assertEqual(reps.stalled, 2, '20 turns vs median 7');
+    assertEqual(reps.draw, undefined, 'no draw');
+  });
+
+  await test('selectRepresentativeGames: no stalled game when lengths are close', () => {
+    const games = [makeGame(6, 'Ai(1)-A'), makeGame(7, 'Ai(2)-B'), makeGame(8, 'Ai(3)-C')];
+    const reps = selectRepresentativeGames(games);
+    assertEqual(reps.stalled, undefined, '8 turns is under 1.5x the median');
+  });
+
+  await test('selectRepresentativeGames: ties go to the earliest game', () => {
+    const games = [makeGame(20), makeGame(7, 'Ai(1)-A'), makeGame(7, 'Ai(2)-B'), makeGame(20)];
+    const reps = selectRepresentativeGames(games);
+    assertEqual(reps.fastestWin, 1, 'fastest');
+    assertEqual(reps.medianWin, 1, 'lower median');
+    assertEqual(reps.stalled, 0, 'first of the longest');
+    assertEqual(reps.draw, 0, 'first draw');
+    assertEqual(
+      JSON.stringify(selectRepresentativeGames(games)),
+      JSON.stringify(reps),
+      'repeat calls agree'
+    );
+  });
+
+  await test('selectRepresentativeGames: empty and no-winner inputs', () => {
+    assertEqual(JSON.stringify(selectRepresentativeGames([])), '{}', 'empty');
+    const reps = selectRepresentativeGames([makeGame(4), makeGame(15)]);
+    assertEqual(reps.medianWin, undefined, 'no median win');
+    assertEqual(reps.stalled, 1, 'longest game stalls when nobody won');
+    assertEqual(reps.draw, 0, 'draw');
+  });
+
+  await test('selectRepresentativeGames: accepts condensed games', () => {
+    const games = [
+      { turnCount: 4, winner: 'Ai(1)-A' },
+      { turnCount: 12, winner: 'Ai(2)-B' },
+    ] as CondensedGame[];
+    const reps = selectRepresentativeGames(games);
+    assert(reps.fastestWin === 0 && reps.medianWin === 0, 'wins from turnCount');
+    assertEqual(reps.stalled, 1, '12 turns vs median 4');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,76 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Representative Games
+ * =============================================================================
+ *
+ * Aggregates say how often each deck won; a few concrete games show how.
+ * This picks one illustrative game per outcome bucket so the frontend (or
+ * anything summarizing a job) can link to it:
+ *
+ *   - medianWin:  a win of typical length (the lower median by turn count)
+ *   - fastestWin: the win with the fewest turns
+ *   - stalled:    the longest game, when it ran at least STALLED_LENGTH_FACTOR
+ *                 times the median win length (any length if nobody won)
+ *   - draw:       the first game with no winner
+ *
+ * Buckets with no matching game are omitted, and one game may represent
+ * more than one bucket (e.g. a single-game job). Selection is deterministic:
+ * ties go to the earliest game.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame, StructuredGame } from '../types';
+import { gameLength } from './turn-stats';
+
+/** How much longer than the median win a game must run to count as stalled. */
+export const STALLED_LENGTH_FACTOR = 1.5;
+
+/**
+ * 0-based indices into the job's game list, one per outcome bucket.
+ */
+export interface Representatives {
+  medianWin?: number;
+  fastestWin?: number;
+  stalled?: number;
+  draw?: number;
+}
+
+/**
+ * Picks a representative game for each outcome bucket.
+ *
+ * @param games - Condensed or structured games, in job order
+ * @returns Indices of the chosen games
+ */
+export function selectRepresentativeGames(
+  games: Array<CondensedGame | StructuredGame>
+): Representatives {
+  const reps: Representatives = {};
+
+  // Wins sorted by length, then by position, so ties resolve to the earliest game
+  const wins = games
+    .map((game, index) => ({ index, turns: gameLength(game), winner: game.winner }))
+    .filter((g) => g.winner)
+    .sort((a, b) => a.turns - b.turns || a.index - b.index);
+
+  let medianTurns = 0;
+  if (wins.length > 0) {
+    const median = wins[Math.floor((wins.length - 1) / 2)];
+    reps.medianWin = median.index;
+    reps.fastestWin = wins[0].index;
+    medianTurns = median.turns;
+  }
+
+  let longest = -1;
+  games.forEach((game, index) => {
+    if (longest < 0 || gameLength(game) > gameLength(games[longest])) longest = index;
+  });
+  if (longest >= 0 && gameLength(games[longest]) >= medianTurns * STALLED_LENGTH_FACTOR) {
+    reps.stalled = longest;
+  }
+
+  const draw = games.findIndex((game) => !game.winner);
+  if (draw >= 0) reps.draw = draw;
+
+  return reps;
+}
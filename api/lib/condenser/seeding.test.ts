@@ -0,0 +1,138 @@
+/**
+ * Tests for bracket pod seeding.
+ *
+ * Run with: npx tsx lib/condenser/seeding.test.ts
+ */
+
+import { seedPods } from './seeding';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const DECKS = ['A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J'];
+
+function appearanceCounts(pods: string[][]): Map<string, number> {
+  const counts = new Map<string, number>();
+  for (const pod of pods) {
+    for (const deck of pod) counts.set(deck, (counts.get(deck) ?? 0) + 1);
+  }
+  return counts;
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running pod seeding tests...\n');
+
+  await test('seedPods: every pod has podSize distinct decks', () => {
+    const pods = seedPods(DECKS, 8, 4, 42);
+    assertEqual(pods.length, 20, '10 decks x 8 games / 4 seats');
+    for (const pod of pods) {
+      assertEqual(pod.length, 4, 'pod size');
+      assertEqual(new Set(pod).size, 4, 'no deck twice in a pod');
+    }
+  });
+
+  await test('seedPods: appearances are balanced', () => {
+    const counts = appearanceCounts(seedPods(DECKS, 8, 4, 42));
+    assertEqual(counts.size, DECKS.length, 'every deck plays');
+    for (const [deck, count] of counts) assertEqual(count, 8, `${deck} appearances`);
+
+    // 7 decks x 5 games = 35 seats -> 9 pods of 4; one deck per extra seat plays 6
+    const uneven = [...appearanceCounts(seedPods(DECKS.slice(0, 7), 5, 4, 3)).values()];
+    assert(Math.max(...uneven) - Math.min(...uneven) <= 1, `uneven counts within one: ${uneven}`);
+    assert(Math.min(...uneven) >= 5, 'every deck plays at least gamesPerDeck');
+  });
+
+  await test('seedPods: compositions vary', () => {
+    const pods = seedPods(DECKS, 8, 4, 42);
+    const compositions = new Set(pods.map((pod) => [...pod].sort().join(',')));
+    assertEqual(compositions.size, pods.length, 'no pod repeats the same four');
+
+    // With only five decks, repeats can't all be avoided but every pairing happens
+    const small = seedPods(DECKS.slice(0, 5), 4, 4, 7);
+    const pairs = new Set<string>();
+    for (const pod of small) {
+      for (const a of pod) for (const b of pod) if (a < b) pairs.add(`${a}${b}`);
+    }
+    assertEqual(pairs.size, 10, 'all 10 pairs of 5 decks meet');
+  });
+
+  await test('seedPods: deterministic for a seed', () => {
+    const first = JSON.stringify(seedPods(DECKS, 4, 4, 99));
+    assertEqual(JSON.stringify(seedPods(DECKS, 4, 4, 99)), first, 'same seed');
+    assert(JSON.stringify(seedPods(DECKS, 4, 4, 100)) !== first, 'different seed differs');
+  });
+
+  await test('seedPods: degenerate inputs give no pods', () => {
+    assertEqual(seedPods(['A', 'B', 'C'], 2, 4).length, 0, 'fewer decks than seats');
+    assertEqual(seedPods(DECKS, 0, 4).length, 0, 'no games');
+    assertEqual(seedPods(['A', 'A', 'B', 'C'], 1, 4).length, 0, 'duplicates collapse');
+    assertEqual(seedPods(['A', 'B'], 3, 2).length, 3, 'two-player pods');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,114 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Pod Seeding
+ * =============================================================================
+ *
+ * Schedules which decks play together when running a bracket, so each job
+ * can be configured with one pod.
+ *
+ * ## Balance
+ *
+ * Pods are filled greedily. Each seat goes to the deck with the fewest
+ * appearances so far, so appearance counts never differ by more than one.
+ * When decks × gamesPerDeck isn't a multiple of podSize the last pod is
+ * topped up, and those decks play one extra game.
+ *
+ * ## Variety
+ *
+ * Among decks tied on appearances, the one that has shared the fewest pods
+ * with the decks already seated wins, and a pod that would repeat an
+ * earlier composition is avoided when another deck can take the last seat.
+ * Remaining ties are broken by a seeded shuffle, so the same inputs and
+ * seed always give the same schedule.
+ *
+ * =============================================================================
+ */
+
+/** Pod size for Commander. */
+export const DEFAULT_POD_SIZE = 4;
+
+/** mulberry32: a small, fast, seedable PRNG returning floats in [0, 1). */
+function mulberry32(seed: number): () => number {
+  let state = seed >>> 0;
+  return () => {
+    state = (state + 0x6d2b79f5) >>> 0;
+    let t = state;
+    t = Math.imul(t ^ (t >>> 15), t | 1);
+    t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+    return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+  };
+}
+
+function pairKey(a: number, b: number): string {
+  return a < b ? `${a}:${b}` : `${b}:${a}`;
+}
+
+/** Compares score tuples lexicographically; lower is better. */
+function compareScores(a: number[], b: number[]): number {
+  for (let i = 0; i < a.length; i++) {
+    if (a[i] !== b[i]) return a[i] - b[i];
+  }
+  return 0;
+}
+
+/**
+ * Generates a balanced pod schedule.
+ *
+ * @param deckNames - Decks in the bracket; duplicates are ignored
+ * @param gamesPerDeck - Games each deck should play
+ * @param podSize - Decks per pod (default 4)
+ * @param seed - Seed for tie-breaking; same seed, same schedule
+ * @returns One array of deck names per pod, or [] when there are fewer
+ *   decks than seats or nothing to schedule
+ */
+export function seedPods(
+  deckNames: string[],
+  gamesPerDeck: number,
+  podSize: number = DEFAULT_POD_SIZE,
+  seed = 1
+): string[][] {
+  const decks = [...new Set(deckNames)];
+  if (podSize < 1 || gamesPerDeck < 1 || decks.length < podSize) return [];
+
+  const random = mulberry32(seed);
+  const appearances = new Array<number>(decks.length).fill(0);
+  const pairs = new Map<string, number>();
+  const compositions = new Set<string>();
+  const podCount = Math.ceil((decks.length * gamesPerDeck) / podSize);
+  const pods: string[][] = [];
+
+  for (let p = 0; p < podCount; p++) {
+    // Fresh random ranks per pod so ties don't always break the same way
+    const rank = decks.map(() => random());
+    const pod: number[] = [];
+
+    while (pod.length < podSize) {
+      const lastSeat = pod.length === podSize - 1;
+      let best = -1;
+      let bestScore: number[] = [];
+      for (let d = 0; d < decks.length; d++) {
+        if (pod.includes(d)) continue;
+        const repeats = lastSeat && compositions.has([...pod, d].sort((a, b) => a - b).join(',')) ? 1 : 0;
+        const shared = pod.reduce((sum, other) => sum + (pairs.get(pairKey(d, other)) ?? 0), 0);
+        const score = [appearances[d], repeats, shared, rank[d]];
+        if (best < 0 || compareScores(score, bestScore) < 0) {
+          best = d;
+          bestScore = score;
+        }
+      }
+      pod.push(best);
+    }
+
+    for (const d of pod) appearances[d]++;
+    for (let i = 0; i < pod.length; i++) {
+      for (let j = i + 1; j < pod.length; j++) {
+        const key = pairKey(pod[i], pod[j]);
+        pairs.set(key, (pairs.get(key) ?? 0) + 1);
+      }
+    }
+    compositions.add([...pod].sort((a, b) => a - b).join(','));
+    pods.push(pod.map((d) => decks[d]));
+  }
+
+  return pods;
+}
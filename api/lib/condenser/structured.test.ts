@@ -12,7 +12,9 @@ import * as path from 'path';
 import { buildStructuredGame, attributeLines, filterStructuredToSignificant } from './structured';
 import { structureGames } from './index';
 import { splitConcatenatedGames } from './patterns';
-import { extractWinner, calculateLifePerTurn } from './turns';
+import { extractWinner, calculateLifePerTurn, calculateCardsDrawnPerTurn, creatureDeathsPerRound, STARTING_LIFE, extractStartingLife, startingLifeOf } from './turns';
+import { resolveWinnerName } from './deck-match';
+import { boardDevelopmentPerTurn } from './board';
 
 // ---------------------------------------------------------------------------
 // Test Utilities (same pattern as condenser.test.ts)
@@ -108,6 +110,27 @@ async function runTests() {
     }
   });
 
+  await test('buildStructuredGame: infers the last player standing', () => {
+    const log = fs.readFileSync(path.join(__dirname, 'fixtures', 'last-standing-log.txt'), 'utf-8');
+    const result = buildStructuredGame(log);
+    assertEqual(result.winner, 'Ai(1)-Alpha', 'survivor credited');
+    assertEqual(result.winReason, 'last_standing', 'winReason');
+  });
+
+  await test('buildStructuredGame: carries per-player AI profiles', () => {
+    const log = fs.readFileSync(path.join(__dirname, 'fixtures', 'ai-profile-log.txt'), 'utf-8');
+    const result = buildStructuredGame(log);
+    assertEqual(result.aiProfiles?.['Ai(2)-Beta'], 'Reckless', 'Beta profile');
+    assertEqual(buildStructuredGame(games[0]).aiProfiles, undefined, 'omitted without profile lines');
+  });
+
+  await test('buildStructuredGame: carries per-player mulligan details', () => {
+    const log = fs.readFileSync(path.join(__dirname, 'fixtures', 'mulligan-log.txt'), 'utf-8');
+    const result = buildStructuredGame(log);
+    assertEqual(result.mulliganDetails?.['Ai(1)-Alpha']?.keptHandSize, 5, 'Alpha kept 5');
+    assertEqual(result.mulliganDetails?.['Ai(2)-Beta']?.mulligans, 0, 'Beta kept 7');
+  });
+
   await test('buildStructuredGame: winner matches extractWinner output', () => {
     const result = buildStructuredGame(games[0]);
     const expectedWinner = extractWinner(games[0]);
@@ -451,6 +474,165 @@ async function runTests() {
     assertEqual(round2[PD], 0, 'PD round 2 (dead)');
   });
 
+  // =========================================================================
+  // Starting life overrides
+  // =========================================================================
+
+  const startingLifeLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'starting-life-log.txt'), 'utf-8');
+  const SA = 'Ai(1)-Alpha';
+  const SB = 'Ai(2)-Beta';
+  const SG = 'Ai(3)-Gamma';
+
+  await test('extractStartingLife: reads "starts at N life" lines', () => {
+    const startingLife = extractStartingLife(startingLifeLog);
+    assertEqual(JSON.stringify(startingLife), JSON.stringify({ [SA]: 30, [SB]: 50 }), 'stated lives');
+    assertEqual(startingLifeOf(SG, startingLife), STARTING_LIFE, 'Gamma falls back to the default');
+    assertEqual(
+      JSON.stringify(extractStartingLife('Ai(1)-Alpha starts the game with 20 life.')),
+      JSON.stringify({ [SA]: 20 }),
+      'unprefixed "starts the game with" form'
+    );
+  });
+
+  await test('calculateLifePerTurn: uses per-player starting life as the baseline', () => {
+    const life = calculateLifePerTurn(startingLifeLog, [SA, SB, SG], 3);
+    assertEqual(JSON.stringify(life[1]), JSON.stringify({ [SA]: 30, [SB]: 50, [SG]: 40 }), 'round 1 baselines');
+    assertEqual(JSON.stringify(life[2]), JSON.stringify({ [SA]: 28, [SB]: 48, [SG]: 42 }), 'round 2');
+  });
+
+  await test('buildStructuredGame: turn deltas start from the stated starting life', () => {
+    const result = buildStructuredGame(startingLifeLog);
+    assertEqual(result.turns[0].delta, undefined, 'no change in round 1');
+    assertEqual(
+      JSON.stringify(result.turns[1].delta?.life),
+      JSON.stringify({ [SA]: -2, [SB]: -2, [SG]: 2 }),
+      'round 2 deltas against 30 / 50 / 40'
+    );
+  });
+
+  // =========================================================================
+  // Board development
+  // =========================================================================
+
+  const boardLog = fs.readFileSync(path.join(__dirname, 'fixtures', 'board-development-log.txt'), 'utf-8');
+  const A = 'Ai(1)-Alpha';
+  const B = 'Ai(2)-Beta';
+
+  await test('boardDevelopmentPerTurn: counts non-token ETBs per player per round', () => {
+    const board = boardDevelopmentPerTurn(boardLog);
+    assertEqual(board[1]?.[A], 2, 'Alpha round 1 (Sol Ring, Llanowar Elves)');
+    assertEqual(board[1]?.[B], 1, 'Beta round 1 (Arcane Signet; trigger text ignored)');
+    assertEqual(board[2]?.[A], 2, 'Alpha round 2 (Wood Elves, Clone under Alpha control)');
+    assertEqual(board[2]?.[B], 1, 'Beta round 2 (Sakura-Tribe Elder)');
+  });
+
+  await test('boardDevelopmentPerTurn: includeTokens adds token ETBs', () => {
+    const board = boardDevelopmentPerTurn(boardLog, { includeTokens: true });
+    assertEqual(board[2]?.[A], 4, 'Alpha round 2 with two Elephant tokens');
+    assertEqual(board[1]?.[A], 2, 'round 1 unchanged');
+  });
+
+  await test('buildStructuredGame: surfaces boardDevelopmentPerTurn when present', () => {
+    const result = buildStructuredGame(boardLog);
+    assertEqual(result.boardDevelopmentPerTurn?.[1]?.[A], 2, 'structured Alpha round 1');
+    assertEqual(
+      buildStructuredGame('Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (11)').boardDevelopmentPerTurn,
+      undefined,
+      'omitted without ETBs'
+    );
+  });
+
+  // =========================================================================
+  // Turn deltas
+  // =========================================================================
+
+  await test('buildStructuredGame: turn deltas sum to the cumulative totals', () => {
+    for (const name of ['comeback-log.txt', 'board-development-log.txt', 'real-4game-log.txt']) {
+      const log = fs.readFileSync(path.join(__dirname, 'fixtures', name), 'utf-8');
+      const result = buildStructuredGame(log);
+      const life: Record<string, number> = Object.fromEntries(result.players.map((p) => [p, STARTING_LIFE]));
+      const added: Record<string, number> = {};
+      let removed = 0;
+      let drawn = 0;
+
+      for (const turn of result.turns) {
+        for (const [player, change] of Object.entries(turn.delta?.life ?? {})) {
+          life[player] += change;
+        }
+        for (const [player, count] of Object.entries(turn.delta?.permanentsAdded ?? {})) {
+          added[player] = (added[player] ?? 0) + count;
+        }
+        removed += turn.delta?.permanentsRemoved ?? 0;
+        drawn += turn.delta?.cardsDrawn ?? 0;
+
+        const snapshot = result.lifePerTurn?.[turn.turnNumber];
+        if (snapshot) {
+          assertEqual(JSON.stringify(life), JSON.stringify(snapshot), `${name} life after round ${turn.turnNumber}`);
+        }
+      }
+
+      const expectedAdded: Record<string, number> = {};
+      for (const round of Object.values(result.boardDevelopmentPerTurn ?? {})) {
+        for (const [player, count] of Object.entries(round)) {
+          expectedAdded[player] = (expectedAdded[player] ?? 0) + count;
+        }
+      }
+      const sum = (values: Record<number, number>) => Object.values(values).reduce((a, b) => a + b, 0);
+      assertEqual(JSON.stringify(added), JSON.stringify(expectedAdded), `${name} permanents added`);
+      assertEqual(removed, sum(creatureDeathsPerRound(log)), `${name} permanents removed`);
+      assertEqual(drawn, sum(calculateCardsDrawnPerTurn(log)), `${name} cards drawn`);
+    }
+  });
+
+  await test('buildStructuredGame: first round life delta is against starting life', () => {
+    const result = buildStructuredGame(
+      'Turn: Turn 1 (Ai(1)-Alpha)\n[LIFE] Life: Ai(1)-Alpha 40 -> 38\nTurn: Turn 2 (Ai(2)-Beta)\nLand: Ai(2)-Beta played Island (12)'
+    );
+    assertEqual(JSON.stringify(result.turns[0].delta), JSON.stringify({ life: { 'Ai(1)-Alpha': -2 } }), 'round 1 delta');
+  });
+
+  await test('buildStructuredGame: omits the delta for a round where nothing changed', () => {
+    const result = buildStructuredGame('Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (11)');
+    assertEqual(result.turns[0].delta, undefined, 'no delta');
+  });
+
+  // =========================================================================
+  // Seat label mapping
+  // =========================================================================
+
+  const seatLog = [
+    'Turn: Turn 1 (Player 1)',
+    'Land: Player 1 played Forest (41)',
+    'Turn: Turn 2 (Player 2)',
+    'Land: Player 2 played Island (42)',
+    'Turn: Turn 3 (Player 1)',
+    'Land: Player 1 played Forest (43)',
+    'Turn: Turn 4 (Player 2)',
+    'Land: Player 2 played Island (44)',
+    'Player 1 loses the game.',
+    'Player 2 wins the game.',
+  ].join('\n');
+
+  await test('buildStructuredGame: seat labels map to deck names by deck order', () => {
+    const result = buildStructuredGame(seatLog, ['Alpha', 'Beta']);
+    assertEqual(result.decks.length, 2, 'should have 2 decks');
+    assertEqual(result.decks[0].deckLabel, 'Alpha', 'seat 1 label');
+    assertEqual(result.decks[1].deckLabel, 'Beta', 'seat 2 label');
+    assert(result.decks[0].turns.length > 0, 'Alpha should have the Player 1 turns');
+    assert(result.decks[1].turns.length > 0, 'Beta should have the Player 2 turns');
+    assertEqual(resolveWinnerName(result.winner!, ['Alpha', 'Beta']), 'Beta', 'winner resolves to seat 2 deck');
+  });
+
+  await test('buildStructuredGame: explicit seat map overrides deck order', () => {
+    const seatMap = { 'Player 1': 'Beta', 'Player 2': 'Alpha' };
+    const result = buildStructuredGame(seatLog, ['Alpha', 'Beta'], seatMap);
+    const alpha = result.decks.find((d) => d.deckLabel === 'Alpha')!;
+    const beta = result.decks.find((d) => d.deckLabel === 'Beta')!;
+    assert(alpha.turns[0].actions.some((a) => a.line.includes('Island (42)')), 'Alpha is seat 2');
+    assert(beta.turns[0].actions.some((a) => a.line.includes('Forest (41)')), 'Beta is seat 1');
+    assertEqual(resolveWinnerName(result.winner!, ['Alpha', 'Beta'], seatMap), 'Alpha', 'winner via explicit map');
+  });
+
   // =========================================================================
   // Summary
   // =========================================================================
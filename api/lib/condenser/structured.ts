@@ -29,10 +29,16 @@
  * =============================================================================
  */
 
-import type { StructuredGame, DeckHistory, DeckTurnActions, DeckAction, EventType } from '../types';
-import { extractTurnRanges, sliceByTurn, getMaxRound, getNumPlayers, segmentToRound, calculateLifePerTurn, calculatePerDeckTurns, extractWinner } from './turns';
+import type { StructuredGame, DeckHistory, DeckTurnActions, DeckAction, EventType, TurnDelta } from '../types';
+import { extractTurnRanges, sliceByTurn, getMaxRound, getNumPlayers, segmentToRound, calculateLifePerTurn, calculatePerDeckTurns, resolveWinner, calculateCardsDrawnPerTurn, creatureDeathsPerRound, firstBloodRound, extractStartingLife, startingLifeOf } from './turns';
 import { classifyLine } from './classify';
-import { matchesDeckName } from './deck-match';
+import { boardDevelopmentPerTurn } from './board';
+import { wasComebackWin } from './comeback';
+import { classifyGameEnd } from './win-reason';
+import { isLowSignal } from './low-signal';
+import { extractAiProfiles } from './ai-profile';
+import { extractMulliganDetails } from './mulligan';
+import { matchesDeckName, resolveSeatLabel, seatMapFromDeckNames, type SeatMap } from './deck-match';
 
 // -----------------------------------------------------------------------------
 // Player Attribution
@@ -122,11 +128,13 @@ export function attributeLines(rawLog: string): AttributedLine[] {
  *
  * @param rawLog - The complete raw log text
  * @param deckNames - Optional array of deck names [hero, opp1, opp2, opp3]
+ * @param seatMap - Optional seat label map; defaults to deck order when deckNames is given
  * @returns StructuredGame object for frontend consumption
  */
 export function buildStructuredGame(
   rawLog: string,
-  deckNames?: string[]
+  deckNames?: string[],
+  seatMap: SeatMap = deckNames ? seatMapFromDeckNames(deckNames) : {}
 ): StructuredGame {
   const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
   const ranges = extractTurnRanges(normalized);
@@ -220,17 +228,20 @@ export function buildStructuredGame(
   const decks: DeckHistory[] = [];
   const deckLabels = deckNames ?? Array.from(deckMap.keys());
   const logPlayerKeys = Array.from(deckMap.keys());
+  const assignedKeys = new Set<string>();
 
   for (let i = 0; i < deckLabels.length; i++) {
-    // When deckNames is provided, match by name (log uses "Ai(N)-DeckName") so Hero column is correct
+    // When deckNames is provided, match by name (log uses "Ai(N)-DeckName") so Hero column is correct.
+    // Seat labels ("Player 1") are resolved through the seat map first.
     const playerKey =
       (deckNames &&
         logPlayerKeys.find(
-          (k) => matchesDeckName(k, deckNames[i])
+          (k) => matchesDeckName(resolveSeatLabel(k, seatMap), deckNames[i])
         )) ??
       players[i] ??
       deckLabels[i];
     const label = deckNames?.[i] ?? playerKey;
+    assignedKeys.add(playerKey);
     const deckTurns = deckMap.get(playerKey) ?? [];
 
     // Sort turns by round number
@@ -244,7 +255,10 @@ export function buildStructuredGame(
 
   // Handle case where we have more players in the log than deck names provided
   for (const player of players) {
-    if (!decks.find((d) => d.deckLabel === player || deckLabels.includes(player))) {
+    if (
+      !assignedKeys.has(player) &&
+      !decks.find((d) => d.deckLabel === player || deckLabels.includes(player))
+    ) {
       const deckTurns = deckMap.get(player) ?? [];
       deckTurns.sort((a, b) => a.turnNumber - b.turnNumber);
       decks.push({
@@ -255,15 +269,25 @@ export function buildStructuredGame(
   }
 
   // -------------------------------------------------------------------------
-  // Step 4: Calculate life totals per round
+  // Step 4: Calculate life totals and board development per round
   // -------------------------------------------------------------------------
   const lifePerTurn = calculateLifePerTurn(normalized, players, numPlayers);
+  const boardDevelopment = boardDevelopmentPerTurn(normalized);
+  attachTurnDeltas(turns, players, {
+    startingLife: extractStartingLife(normalized),
+    lifePerTurn,
+    boardDevelopment,
+    deaths: creatureDeathsPerRound(normalized),
+    cardsDrawn: calculateCardsDrawnPerTurn(normalized, numPlayers),
+  });
 
   // -------------------------------------------------------------------------
   // Step 5: Per-deck turns, winner, and winning turn
   // -------------------------------------------------------------------------
   const perDeckTurns = calculatePerDeckTurns(ranges);
-  const winner = extractWinner(rawLog);
+  const { winner, winReason, simultaneousWinners } = resolveWinner(rawLog);
+  const aiProfiles = extractAiProfiles(rawLog);
+  const mulliganDetails = extractMulliganDetails(rawLog);
 
   // Use winner's personal turn count for totalTurns (accurate with eliminations)
   let accurateTotalTurns = totalTurns;
@@ -283,16 +307,84 @@ export function buildStructuredGame(
 
   const winningTurn = accurateTotalTurns > 0 ? accurateTotalTurns : undefined;
 
-  return {
+  const game: StructuredGame = {
     totalTurns: accurateTotalTurns,
     players,
     turns,
     decks,
     lifePerTurn,
+    ...(Object.keys(boardDevelopment).length > 0 && { boardDevelopmentPerTurn: boardDevelopment }),
     ...(Object.keys(perDeckTurns).length > 0 && { perDeckTurns }),
     ...(winner && { winner }),
+    ...(winReason && { winReason }),
+    ...(simultaneousWinners && { simultaneousWin: true, simultaneousWinners }),
+    endReason: classifyGameEnd(rawLog),
     ...(winningTurn !== undefined && { winningTurn }),
+    firstBloodTurn: firstBloodRound(normalized),
+    ...(Object.keys(aiProfiles).length > 0 && { aiProfiles }),
+    ...(Object.keys(mulliganDetails).length > 0 && { mulliganDetails }),
   };
+  if (wasComebackWin(game)) {
+    game.comebackWin = true;
+  }
+  const eventCount = attributedLines.filter((attr) => attr.eventType).length;
+  if (isLowSignal(eventCount, accurateTotalTurns)) {
+    game.lowSignal = true;
+  }
+  return game;
+}
+
+// -----------------------------------------------------------------------------
+// Turn Deltas
+// -----------------------------------------------------------------------------
+
+interface RoundMetrics {
+  /** Stated starting lives; other players start at STARTING_LIFE */
+  startingLife: Record<string, number>;
+  lifePerTurn: Record<number, Record<string, number>>;
+  boardDevelopment: Record<number, Record<string, number>>;
+  deaths: Record<number, number>;
+  cardsDrawn: Record<number, number>;
+}
+
+/**
+ * Sets each round's delta from the per-round metrics, so a replay can step
+ * forward without recomputing totals. Life is diffed against the previous
+ * round's snapshot (starting life for round 1); the other metrics are
+ * already per round.
+ */
+function attachTurnDeltas(
+  turns: StructuredGame['turns'],
+  players: string[],
+  metrics: RoundMetrics
+): void {
+  const hasLife = Object.keys(metrics.lifePerTurn).length > 0;
+  let previousLife: Record<string, number> = Object.fromEntries(
+    players.map((p) => [p, startingLifeOf(p, metrics.startingLife)])
+  );
+
+  for (const turn of turns) {
+    const round = turn.turnNumber;
+    const delta: TurnDelta = {};
+
+    const life = metrics.lifePerTurn[round];
+    if (hasLife && life) {
+      const changes: Record<string, number> = {};
+      for (const [player, total] of Object.entries(life)) {
+        const change = total - (previousLife[player] ?? startingLifeOf(player, metrics.startingLife));
+        if (change !== 0) changes[player] = change;
+      }
+      if (Object.keys(changes).length > 0) delta.life = changes;
+      previousLife = life;
+    }
+
+    const added = metrics.boardDevelopment[round];
+    if (added && Object.keys(added).length > 0) delta.permanentsAdded = { ...added };
+    if (metrics.deaths[round]) delta.permanentsRemoved = metrics.deaths[round];
+    if (metrics.cardsDrawn[round]) delta.cardsDrawn = metrics.cardsDrawn[round];
+
+    if (Object.keys(delta).length > 0) turn.delta = delta;
+  }
 }
 
 /**
@@ -0,0 +1,246 @@
+/**
+ * Tests for the Markdown job summary.
+ *
+ * Run with: npx tsx lib/condenser/summary.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { CondensedGame } from '../types';
+import { buildMarkdownSummary } from './summary';
+import { sampleConfidence, parseConfidenceThresholds, getConfidenceThresholds, DEFAULT_CONFIDENCE_THRESHOLDS, CONFIDENCE_THRESHOLDS_ENV } from './confidence';
+import { condenseGames } from './index';
+import { splitConcatenatedGames } from './patterns';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const FIXTURE_PATH = path.join(__dirname, 'fixtures', 'real-4game-log.txt');
+
+const DECK_NAMES = ['Doran Big Butts', 'Enduring Enchantments', 'Explorers of the Deep', 'Veloci-RAMP-Tor'];
+
+function makeGame(overrides: Partial<CondensedGame>): CondensedGame {
+  return {
+    keptEvents: [],
+    manaPerTurn: {},
+    cardsDrawnPerTurn: {},
+    turnCount: 0,
+    ...overrides,
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running summary tests...\n');
+
+  await test('buildMarkdownSummary: real fixture reports per-deck wins', () => {
+    const rawLog = fs.readFileSync(FIXTURE_PATH, 'utf-8');
+    const condensed = condenseGames(splitConcatenatedGames(rawLog));
+    const summary = buildMarkdownSummary(condensed, DECK_NAMES);
+    assert(summary.includes('- Games: 4'), 'should report game count');
+    assert(summary.includes('- Decisive games: 4'), 'should report decisive games');
+    assert(summary.includes('| Enduring Enchantments | 2 | 50.0% |'), 'Enduring Enchantments won 2 of 4');
+    assert(summary.includes('| Veloci-RAMP-Tor | 0 | 0.0% | - |'), 'decks with no wins are still listed');
+  });
+
+  await test('buildMarkdownSummary: decks are sorted by name', () => {
+    const games = [
+      makeGame({ winner: 'Ai(2)-Zeta', winningTurn: 8, turnCount: 8 }),
+      makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 6, turnCount: 6 }),
+    ];
+    const summary = buildMarkdownSummary(games, ['Zeta', 'Alpha']);
+    assert(summary.indexOf('| Alpha |') < summary.indexOf('| Zeta |'), 'Alpha should be listed before Zeta');
+  });
+
+  await test('buildMarkdownSummary: fastest win and longest game are reported', () => {
+    const games = [
+      makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 9, turnCount: 9 }),
+      makeGame({ winner: 'Ai(2)-Beta', winningTurn: 5, turnCount: 5 }),
+      makeGame({ turnCount: 14 }),
+    ];
+    const summary = buildMarkdownSummary(games, ['Alpha', 'Beta']);
+    assert(summary.includes('- Fastest win: Game 2 — Beta on turn 5'), 'fastest win should be game 2');
+    assert(summary.includes('- Longest game: Game 3 — 14 turns'), 'longest game should be game 3');
+    assert(summary.includes('- No winner detected: Game 3'), 'game 3 has no winner');
+    assert(summary.includes('- Decisive games: 2'), 'two decisive games');
+  });
+
+  await test('buildMarkdownSummary: unmatched winners are listed under their log name', () => {
+    const games = [makeGame({ winner: 'Ai(3)-Unknown Deck', winningTurn: 7, turnCount: 7 })];
+    const summary = buildMarkdownSummary(games, ['Alpha']);
+    assert(summary.includes('| Ai(3)-Unknown Deck | 1 |'), 'unmatched winner should keep its raw name');
+  });
+
+  await test('buildMarkdownSummary: output is deterministic', () => {
+    const games = [
+      makeGame({ winner: 'Ai(1)-Alpha', winningTurn: 6, turnCount: 6 }),
+      makeGame({ winner: 'Ai(2)-Beta', winningTurn: 7, turnCount: 7 }),
+    ];
+    assertEqual(
+      buildMarkdownSummary(games, ['Beta', 'Alpha']),
+      buildMarkdownSummary(games, ['Alpha', 'Beta']),
+      'deck order in input should not change output'
+    );
+  });
+
+  await test('sampleConfidence: game counts map to confidence labels', () => {
+    const cases: Array<[number, string]> = [
+      [0, 'low (<20 games)'],
+      [19, 'low (<20 games)'],
+      [20, 'medium (20-199 games)'],
+      [199, 'medium (20-199 games)'],
+      [200, 'high (>=200 games)'],
+      [1000, 'high (>=200 games)'],
+    ];
+    for (const [games, label] of cases) {
+      assertEqual(sampleConfidence(games).label, label, `${games} games`);
+    }
+    assertEqual(sampleConfidence(50).sampleSize, 50, 'sampleSize');
+  });
+
+  await test('sampleConfidence: thresholds are configurable', () => {
+    const thresholds = { low: 10, high: 50 };
+    assertEqual(sampleConfidence(9, thresholds).level, 'low', '9 games');
+    assertEqual(sampleConfidence(10, thresholds).label, 'medium (10-49 games)', '10 games');
+    assertEqual(sampleConfidence(50, thresholds).level, 'high', '50 games');
+  });
+
+  await test('parseConfidenceThresholds: parses keys and rejects bad values', () => {
+    assertEqual(JSON.stringify(parseConfidenceThresholds('High=300, low=30')), JSON.stringify({ low: 30, high: 300 }), 'both keys');
+    assertEqual(JSON.stringify(parseConfidenceThresholds('low=50')), JSON.stringify({ low: 50, high: 200 }), 'missing key keeps its default');
+    const errorOf = (source: string) => {
+      try { parseConfidenceThresholds(source); } catch (err) { return (err as Error).message; }
+      return '';
+    };
+    assert(errorOf('medium=30').includes('unknown key "medium"'), 'unknown key');
+    assert(errorOf('low=0').startsWith('Invalid CONFIDENCE_THRESHOLDS'), 'zero');
+    assert(errorOf('high=abc').startsWith('Invalid CONFIDENCE_THRESHOLDS'), 'not a number');
+    assert(errorOf('low=300').includes('must be below high'), 'low above the default high');
+  });
+
+  await test('getConfidenceThresholds: reads CONFIDENCE_THRESHOLDS', () => {
+    const saved = process.env[CONFIDENCE_THRESHOLDS_ENV];
+    try {
+      delete process.env[CONFIDENCE_THRESHOLDS_ENV];
+      assertEqual(getConfidenceThresholds(), DEFAULT_CONFIDENCE_THRESHOLDS, 'default when unset');
+      process.env[CONFIDENCE_THRESHOLDS_ENV] = 'low=2,high=4';
+      assertEqual(getConfidenceThresholds().high, 4, 'override');
+      assertEqual(sampleConfidence(4).level, 'high', 'override applies to sampleConfidence');
+    } finally {
+      if (saved === undefined) delete process.env[CONFIDENCE_THRESHOLDS_ENV];
+      else process.env[CONFIDENCE_THRESHOLDS_ENV] = saved;
+    }
+  });
+
+  await test('buildMarkdownSummary: a 1-turn game is kept but not counted', () => {
+    const rawLog = fs.readFileSync(FIXTURE_PATH, 'utf-8');
+    const trivialGame = [
+      'Turn 1: Ai(1)-Doran Big Butts',
+      'Land: Ai(1)-Doran Big Butts played Forest (1)',
+      'Game outcome: Ai(1)-Doran Big Butts has won because all opponents have lost',
+      'Game outcome: Ai(2)-Enduring Enchantments has conceded',
+    ].join('\n');
+    const realGames = condenseGames(splitConcatenatedGames(rawLog));
+    const condensed = condenseGames([...splitConcatenatedGames(rawLog), trivialGame]);
+    assertEqual(condensed.length, 5, 'the 1-turn game is still condensed');
+    assertEqual(condensed[4].lowSignal, true, 'the 1-turn game is flagged');
+    assertEqual(condensed.filter((g) => g.lowSignal).length, 1, 'real games are not flagged');
+
+    const summary = buildMarkdownSummary(condensed, DECK_NAMES);
+    const winRates = (s: string) => s.slice(s.indexOf('## Deck Win Rates'), s.indexOf('## Notable Games'));
+    assert(summary.includes('- Games: 4'), 'game count should exclude the 1-turn game');
+    assert(summary.includes('- Low-signal games (not counted): 1'), 'should report the low-signal game');
+    assertEqual(winRates(summary), winRates(buildMarkdownSummary(realGames, DECK_NAMES)), 'win rates over the 4 real games');
+    assert(!summary.includes('Game 5'), 'the 1-turn game is not a notable game');
+  });
+
+  await test('buildMarkdownSummary: a shared simultaneous win credits every winner', () => {
+    const games = [
+      makeGame({
+        winner: 'Ai(1)-Alpha',
+        winningTurn: 6,
+        turnCount: 6,
+        simultaneousWin: true,
+        simultaneousWinners: ['Ai(1)-Alpha', 'Ai(2)-Beta'],
+      }),
+      makeGame({ winner: 'Ai(2)-Beta', winningTurn: 8, turnCount: 8 }),
+    ];
+    const summary = buildMarkdownSummary(games, ['Alpha', 'Beta']);
+    assert(summary.includes('| Alpha | 1 | 50.0% | 6.0 |'), 'Alpha credited');
+    assert(summary.includes('| Beta | 2 | 100.0% | 7.0 |'), 'Beta credited for both games');
+    assert(summary.includes('- Simultaneous wins: Game 1 (Ai(1)-Alpha, Ai(2)-Beta)'), 'simultaneous game listed');
+  });
+
+  await test('buildMarkdownSummary: empty job renders without throwing', () => {
+    const summary = buildMarkdownSummary([]);
+    assert(summary.includes('- Games: 0'), 'should report zero games');
+    assert(summary.includes('- Confidence: low (<20 games)'), 'zero games is low confidence');
+    assert(summary.includes('- No games'), 'should note there are no games');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,151 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Markdown Summary
+ * =============================================================================
+ *
+ * Renders a human-readable `summary.md` for a job from its condensed games,
+ * for quick eyeballing without the frontend.
+ *
+ * This is purely a rendering of data the pipeline already computes (winner,
+ * winningTurn, turnCount), so it stays in sync with the condensed output.
+ * Low-signal games (see low-signal.ts) are counted in the overview but left
+ * out of every statistic.
+ * Output is deterministic: decks are sorted by name and games are referenced
+ * by their 1-based position in the job.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame } from '../types';
+import { resolveWinnerName } from './deck-match';
+import { sampleConfidence } from './confidence';
+
+/**
+ * Formats a ratio as a percentage with one decimal place.
+ */
+function formatPercent(numerator: number, denominator: number): string {
+  if (denominator === 0) return '0.0%';
+  return `${((numerator / denominator) * 100).toFixed(1)}%`;
+}
+
+/**
+ * Formats an average with one decimal place, or "-" when there is no data.
+ */
+function formatAverage(values: number[]): string {
+  if (values.length === 0) return '-';
+  const avg = values.reduce((a, b) => a + b, 0) / values.length;
+  return avg.toFixed(1);
+}
+
+/**
+ * Builds a Markdown report for a job's condensed games.
+ *
+ * Sections:
+ *   - Overview: game count, low-signal games, sample-size confidence,
+ *     decisive games, average game length
+ *   - Deck win rates: wins, win rate, and average winning turn per deck
+ *   - Notable games: fastest win, longest game, games with no winner
+ *
+ * @param games - Condensed games for the job (in job order)
+ * @param deckNames - Optional deck names; winners are resolved against these
+ *                    and decks with zero wins are still listed
+ * @returns Markdown text
+ */
+export function buildMarkdownSummary(
+  games: CondensedGame[],
+  deckNames?: string[]
+): string {
+  const names = deckNames ?? [];
+  const wins: Record<string, number> = {};
+  const winTurns: Record<string, number[]> = {};
+  for (const name of names) {
+    wins[name] = 0;
+    winTurns[name] = [];
+  }
+
+  let fastest: { index: number; deck: string; turn: number } | undefined;
+  let longest: { index: number; turns: number } | undefined;
+  const noWinner: number[] = [];
+  const counted = games.filter((g) => !g.lowSignal);
+
+  games.forEach((game, index) => {
+    if (game.lowSignal) return;
+    if (!longest || game.turnCount > longest.turns) {
+      longest = { index, turns: game.turnCount };
+    }
+
+    if (!game.winner) {
+      noWinner.push(index);
+      return;
+    }
+
+    // A shared simultaneous win credits every winner
+    for (const winner of game.simultaneousWinners ?? [game.winner]) {
+      const deck = resolveWinnerName(winner, names);
+      wins[deck] = (wins[deck] ?? 0) + 1;
+      if (!winTurns[deck]) winTurns[deck] = [];
+      if (game.winningTurn !== undefined) {
+        winTurns[deck].push(game.winningTurn);
+        if (!fastest || game.winningTurn < fastest.turn) {
+          fastest = { index, deck, turn: game.winningTurn };
+        }
+      }
+    }
+  });
+
+  const decisive = counted.length - noWinner.length;
+  const lines: string[] = [];
+
+  // ---------------------------------------------------------------------------
+  // Overview
+  // ---------------------------------------------------------------------------
+  lines.push('# Job Summary', '');
+  lines.push(`- Games: ${counted.length}`);
+  if (counted.length < games.length) {
+    lines.push(`- Low-signal games (not counted): ${games.length - counted.length}`);
+  }
+  lines.push(`- Confidence: ${sampleConfidence(counted.length).label}`);
+  lines.push(`- Decisive games: ${decisive}`);
+  lines.push(`- Average game length: ${formatAverage(counted.map((g) => g.turnCount))} turns`);
+  lines.push('');
+
+  // ---------------------------------------------------------------------------
+  // Deck win rates (sorted by deck name for stable diffs)
+  // ---------------------------------------------------------------------------
+  lines.push('## Deck Win Rates', '');
+  lines.push('| Deck | Wins | Win Rate | Avg Win Turn |');
+  lines.push('| --- | ---: | ---: | ---: |');
+  const decks = Object.keys(wins).sort((a, b) => a.localeCompare(b));
+  for (const deck of decks) {
+    lines.push(
+      `| ${deck} | ${wins[deck]} | ${formatPercent(wins[deck], counted.length)} | ${formatAverage(winTurns[deck] ?? [])} |`
+    );
+  }
+  lines.push('');
+
+  // ---------------------------------------------------------------------------
+  // Notable games
+  // ---------------------------------------------------------------------------
+  lines.push('## Notable Games', '');
+  if (fastest) {
+    lines.push(`- Fastest win: Game ${fastest.index + 1} — ${fastest.deck} on turn ${fastest.turn}`);
+  }
+  if (longest) {
+    lines.push(`- Longest game: Game ${longest.index + 1} — ${longest.turns} turns`);
+  }
+  if (noWinner.length > 0) {
+    lines.push(`- No winner detected: ${noWinner.map((i) => `Game ${i + 1}`).join(', ')}`);
+  }
+  const simultaneous = games.flatMap((game, index) =>
+    !game.lowSignal && game.simultaneousWinners ? [`Game ${index + 1} (${game.simultaneousWinners.join(', ')})`] : []
+  );
+  if (simultaneous.length > 0) {
+    lines.push(`- Simultaneous wins: ${simultaneous.join(', ')}`);
+  }
+  if (!fastest && !longest) {
+    lines.push('- No games');
+  }
+  lines.push('');
+
+  return lines.join('\n');
+}
@@ -0,0 +1,141 @@
+/**
+ * Tests for the job tempo curve.
+ *
+ * Run with: npx tsx lib/condenser/tempo.test.ts
+ */
+
+import type { CondensedGame } from '../types';
+import { jobTempoCurve, DEFAULT_TEMPO_CURVE } from './tempo';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function makeGame(overrides: Partial<CondensedGame>): CondensedGame {
+  return {
+    keptEvents: [],
+    manaPerTurn: {},
+    cardsDrawnPerTurn: {},
+    turnCount: 0,
+    ...overrides,
+  };
+}
+
+/** Two players, three rounds */
+const LONG_GAME = makeGame({
+  manaPerTurn: { 1: { manaEvents: 2 }, 2: { manaEvents: 4 }, 3: { manaEvents: 6 } },
+  castsPerTurn: {
+    1: [{ player: 'A', casts: 1, manaAdded: 0 }],
+    2: [{ player: 'A', casts: 2, manaAdded: 0 }, { player: 'B', casts: 1, manaAdded: 0 }],
+    3: [{ player: 'A', casts: 3, manaAdded: 1 }],
+  },
+  cardsDrawnPerTurn: { 1: 1, 2: 2, 3: 2 },
+  perDeckTurns: { A: { turnsTaken: 3, lastSegment: 5 }, B: { turnsTaken: 3, lastSegment: 6 } },
+});
+
+/** Two players, over after round 2 */
+const SHORT_GAME = makeGame({
+  manaPerTurn: { 1: { manaEvents: 2 }, 2: { manaEvents: 2 } },
+  castsPerTurn: { 1: [{ player: 'B', casts: 1, manaAdded: 0 }] },
+  cardsDrawnPerTurn: { 1: 1, 2: 2 },
+  perDeckTurns: { A: { turnsTaken: 2, lastSegment: 3 }, B: { turnsTaken: 2, lastSegment: 4 } },
+});
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running tempo curve tests...\n');
+
+  await test('jobTempoCurve: averages over the games still going, in round order', () => {
+    const curve = jobTempoCurve([SHORT_GAME, LONG_GAME]);
+    assertEqual(
+      JSON.stringify(curve),
+      JSON.stringify([
+        { round: 1, games: 2, mana: 2, spells: 1, cardsDrawn: 1, tempo: 0.39 },
+        { round: 2, games: 2, mana: 3, spells: 1.5, cardsDrawn: 2, tempo: 0.67 },
+        { round: 3, games: 1, mana: 6, spells: 3, cardsDrawn: 2, tempo: 1 },
+      ]),
+      'curve'
+    );
+  });
+
+  await test('jobTempoCurve: games without perDeckTurns count in every round', () => {
+    const noLength = { ...SHORT_GAME, perDeckTurns: undefined };
+    const curve = jobTempoCurve([noLength, LONG_GAME]);
+    assertEqual(curve[2].games, 2, 'both games in round 3');
+    assertEqual(curve[2].mana, 3, 'round 3 mana averaged over both');
+  });
+
+  await test('jobTempoCurve: weights shift the blend', () => {
+    const manaOnly = jobTempoCurve([SHORT_GAME, LONG_GAME], { ...DEFAULT_TEMPO_CURVE, spells: 0, cardsDrawn: 0 });
+    assertEqual(manaOnly[0].tempo, 0.33, 'round 1 mana is a third of the peak');
+    assertEqual(manaOnly[1].tempo, 0.5, 'round 2 mana is half the peak');
+  });
+
+  await test('jobTempoCurve: empty without per-round metrics', () => {
+    assertEqual(jobTempoCurve([]).length, 0, 'no games');
+    assertEqual(jobTempoCurve([makeGame({})]).length, 0, 'no metrics');
+  });
+
+  // -------------------------------------------------------------------------
+  // Summary
+  // -------------------------------------------------------------------------
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,112 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Tempo Curve
+ * =============================================================================
+ *
+ * Blends the per-round metrics the condenser already computes (mana events,
+ * spells cast, cards drawn) into one series the frontend can plot: the
+ * pod's typical arc from a slow start through its peak turns.
+ *
+ * ## Averaging
+ *
+ * Each metric is averaged per round over the games still going in that
+ * round. A game's last round comes from perDeckTurns (the last segment any
+ * player took); a game without it is counted in every round, so jobs from
+ * older artifacts average over all games.
+ *
+ * ## Blending
+ *
+ * Each metric is scaled by its peak over the curve (so the busiest round
+ * scores 1), then the scaled metrics are combined as a weighted average.
+ * `tempo` is 0-1; a metric that is 0 everywhere is left out of the blend.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame, TempoPoint } from '../types';
+import { segmentToRound } from './turns';
+
+/**
+ * Weights for blending the metrics into `tempo`.
+ */
+export interface TempoCurveOptions {
+  mana: number;
+  spells: number;
+  cardsDrawn: number;
+}
+
+export const DEFAULT_TEMPO_CURVE: TempoCurveOptions = {
+  mana: 1,
+  spells: 1,
+  cardsDrawn: 1,
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+/**
+ * Last round of a game, or undefined when the game has no perDeckTurns.
+ */
+function lastRound(game: CondensedGame): number | undefined {
+  const decks = Object.values(game.perDeckTurns ?? {});
+  if (decks.length === 0) return undefined;
+  return segmentToRound(Math.max(...decks.map((d) => d.lastSegment)), decks.length);
+}
+
+/**
+ * Builds the job's tempo curve.
+ *
+ * @param games - Condensed games for the job
+ * @param options - Metric weights (default DEFAULT_TEMPO_CURVE)
+ * @returns One point per round from 1 to the last round with data, in
+ *          order; empty when no game has per-round metrics
+ */
+export function jobTempoCurve(
+  games: CondensedGame[],
+  options: TempoCurveOptions = DEFAULT_TEMPO_CURVE
+): TempoPoint[] {
+  const totals: Record<number, { mana: number; spells: number; cardsDrawn: number }> = {};
+  const bump = (round: number, metric: 'mana' | 'spells' | 'cardsDrawn', value: number) => {
+    const entry = (totals[round] ??= { mana: 0, spells: 0, cardsDrawn: 0 });
+    entry[metric] += value;
+  };
+  for (const game of games) {
+    for (const [round, info] of Object.entries(game.manaPerTurn)) bump(Number(round), 'mana', info.manaEvents);
+    for (const [round, turns] of Object.entries(game.castsPerTurn ?? {})) {
+      bump(Number(round), 'spells', turns.reduce((sum, t) => sum + t.casts, 0));
+    }
+    for (const [round, drawn] of Object.entries(game.cardsDrawnPerTurn)) bump(Number(round), 'cardsDrawn', drawn);
+  }
+
+  const rounds = Object.keys(totals).map(Number).filter((r) => r > 0);
+  if (rounds.length === 0) return [];
+  const maxRound = Math.max(...rounds);
+  const lastRounds = games.map(lastRound);
+
+  const points: TempoPoint[] = [];
+  for (let round = 1; round <= maxRound; round++) {
+    const alive = lastRounds.filter((last) => last === undefined || last >= round).length;
+    const t = totals[round] ?? { mana: 0, spells: 0, cardsDrawn: 0 };
+    const per = (value: number) => (alive > 0 ? value / alive : 0);
+    points.push({ round, games: alive, mana: per(t.mana), spells: per(t.spells), cardsDrawn: per(t.cardsDrawn), tempo: 0 });
+  }
+
+  const metrics = (['mana', 'spells', 'cardsDrawn'] as const).map((metric) => ({
+    metric,
+    weight: options[metric],
+    peak: Math.max(...points.map((p) => p[metric])),
+  })).filter((m) => m.peak > 0 && m.weight > 0);
+  const totalWeight = metrics.reduce((sum, m) => sum + m.weight, 0);
+
+  for (const point of points) {
+    if (totalWeight > 0) {
+      point.tempo = metrics.reduce((sum, m) => sum + (m.weight * point[m.metric]) / m.peak, 0) / totalWeight;
+    }
+    point.mana = round2(point.mana);
+    point.spells = round2(point.spells);
+    point.cardsDrawn = round2(point.cardsDrawn);
+    point.tempo = round2(point.tempo);
+  }
+  return points;
+}
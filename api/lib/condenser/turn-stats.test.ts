@@ -0,0 +1,145 @@
+/**
+ * Tests for game length percentiles.
+ *
+ * Run with: npx tsx lib/condenser/turn-stats.test.ts
+ */
+
+import type { CondensedGame, StructuredGame } from '../types';
+import { turnCountPercentiles, percentile, averageFirstBloodTurn } from './turn-stats';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function makeGame(totalTurns: number, winner?: string): StructuredGame {
+  return {
+    totalTurns,
+    players: ['Ai(1)-A', 'Ai(2)-B'],
+    turns: [],
+    decks: [],
+    ...(winner && { winner }),
+  };
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running turn-stats tests...\n');
+
+  await test('percentile: interpolates between closest ranks', () => {
+    assertEqual(percentile([1, 2, 3, 4], 50), 2.5, 'p50 of 1..4');
+    assertEqual(percentile([10, 20], 90), 19, 'p90 of [10, 20]');
+    assertEqual(percentile([7], 99), 7, 'single value');
+  });
+
+  await test('turnCountPercentiles: known inputs', () => {
+    const games = [3, 9, 1, 7, 5, 10, 2, 8, 4, 6].map((n) => makeGame(n, 'Ai(1)-A'));
+    const p = turnCountPercentiles(games)!;
+    assertEqual(p.p50, 5.5, 'p50');
+    assertEqual(p.p90, 9.1, 'p90');
+    assertEqual(p.p99, 9.9, 'p99');
+    assertEqual(p.min, 1, 'min');
+    assertEqual(p.max, 10, 'max');
+  });
+
+  await test('turnCountPercentiles: stalls are excluded', () => {
+    const games = [makeGame(6, 'Ai(1)-A'), makeGame(8, 'Ai(2)-B'), makeGame(40)];
+    const p = turnCountPercentiles(games)!;
+    assertEqual(p.max, 8, 'stalled 40-turn game ignored');
+    assertEqual(p.p50, 7, 'p50 of [6, 8]');
+  });
+
+  await test('turnCountPercentiles: single game gives that length everywhere', () => {
+    const p = turnCountPercentiles([makeGame(9, 'Ai(1)-A')])!;
+    assertEqual(p.p50, 9, 'p50');
+    assertEqual(p.p99, 9, 'p99');
+    assertEqual(p.min, 9, 'min');
+    assertEqual(p.max, 9, 'max');
+  });
+
+  await test('turnCountPercentiles: no winners returns null', () => {
+    assertEqual(turnCountPercentiles([]), null, 'empty');
+    assertEqual(turnCountPercentiles([makeGame(12)]), null, 'only stalls');
+  });
+
+  await test('turnCountPercentiles: accepts condensed games', () => {
+    const games = [
+      { turnCount: 4, winner: 'Ai(1)-A' },
+      { turnCount: 12, winner: 'Ai(2)-B' },
+    ] as CondensedGame[];
+    const p = turnCountPercentiles(games)!;
+    assertEqual(p.p50, 8, 'p50');
+    assert(p.min === 4 && p.max === 12, 'min/max from turnCount');
+  });
+
+  await test('averageFirstBloodTurn: skips games without an elimination', () => {
+    const games = [3, 6, 0, 4].map((n) => ({ ...makeGame(8, 'Ai(1)-A'), firstBloodTurn: n }));
+    assertEqual(averageFirstBloodTurn([...games, makeGame(10)]), 4.3, 'average of 3, 6 and 4');
+    assertEqual(averageFirstBloodTurn([makeGame(10), { ...makeGame(5), firstBloodTurn: 0 }]), 0, 'no eliminations');
+    assertEqual(averageFirstBloodTurn([]), 0, 'no games');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
@@ -0,0 +1,90 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Game Length Percentiles
+ * =============================================================================
+ *
+ * The mean game length is pulled around by stalled games and combo
+ * blowouts, so this reports the p50/p90/p99 game length plus min/max.
+ *
+ * Percentiles use linear interpolation between closest ranks (the same
+ * method as numpy's default and Excel's PERCENTILE.INC), so small samples
+ * still give sensible values: with one game every percentile is that
+ * game's length.
+ *
+ * Games without a winner are stalls (draws, timeouts) and are excluded.
+ *
+ * Also averages the "first blood" round (the first elimination) across a
+ * job, for telling early-kill pods from ones where everyone survives.
+ *
+ * =============================================================================
+ */
+
+import type { CondensedGame, StructuredGame } from '../types';
+
+/**
+ * Game length percentiles, in turns.
+ */
+export interface TurnCountPercentiles {
+  p50: number;
+  p90: number;
+  p99: number;
+  min: number;
+  max: number;
+}
+
+/**
+ * Linear-interpolation percentile of an ascending-sorted array.
+ *
+ * @param sorted - Non-empty values sorted ascending
+ * @param p - Percentile in the range 0-100
+ */
+export function percentile(sorted: number[], p: number): number {
+  const rank = (Math.min(Math.max(p, 0), 100) / 100) * (sorted.length - 1);
+  const lo = Math.floor(rank);
+  const hi = Math.ceil(rank);
+  return sorted[lo] + (sorted[hi] - sorted[lo]) * (rank - lo);
+}
+
+/** A game's length in turns, for condensed or structured games. */
+export function gameLength(game: CondensedGame | StructuredGame): number {
+  return 'turnCount' in game ? game.turnCount : game.totalTurns;
+}
+
+/**
+ * Computes game length percentiles across games, excluding stalls.
+ *
+ * @param games - Condensed or structured games
+ * @returns The percentiles, or null when no game has a winner
+ */
+export function turnCountPercentiles(
+  games: Array<CondensedGame | StructuredGame>
+): TurnCountPercentiles | null {
+  const lengths = games
+    .filter((g) => g.winner)
+    .map(gameLength)
+    .filter((n) => n > 0)
+    .sort((a, b) => a - b);
+  if (lengths.length === 0) return null;
+
+  const round = (n: number) => Math.round(n * 10) / 10;
+  return {
+    p50: round(percentile(lengths, 50)),
+    p90: round(percentile(lengths, 90)),
+    p99: round(percentile(lengths, 99)),
+    min: lengths[0],
+    max: lengths[lengths.length - 1],
+  };
+}
+
+/**
+ * Averages the first-blood round across games, skipping games where nobody
+ * was eliminated (firstBloodTurn 0 or absent).
+ *
+ * @param games - Condensed or structured games
+ * @returns The average round to one decimal place; 0 when no game had an elimination
+ */
+export function averageFirstBloodTurn(games: Array<CondensedGame | StructuredGame>): number {
+  const rounds = games.map((g) => g.firstBloodTurn ?? 0).filter((n) => n > 0);
+  if (rounds.length === 0) return 0;
+  return Math.round((rounds.reduce((a, b) => a + b, 0) / rounds.length) * 10) / 10;
+}
@@ -24,20 +24,42 @@
  * In Commander (4-player), each game turn has one player turn per player.
  * We track both the turn NUMBER and the active PLAYER.
  *
+ * detectFormat() sniffs the format from the first few turn markers, and
+ * extractTurnRanges() then applies only that format's pattern, so a stray
+ * line that looks like the other format's marker can't split a turn. A log
+ * with no markers, or with both kinds among the first few, is parsed with
+ * the permissive pattern that accepts either. (JSONL logs aren't produced
+ * yet and have no parser.)
+ *
  * =============================================================================
  */
 
-import type { TurnManaInfo, DeckTurnInfo } from '../types';
+import type { TurnManaInfo, TurnCastInfo, DeckTurnInfo, WinReason } from '../types';
 import {
   EXTRACT_TURN_NUMBER,
+  EXTRACT_TURN_NUMBER_CURRENT,
+  EXTRACT_TURN_NUMBER_LEGACY,
   EXTRACT_MANA_PRODUCED,
   EXTRACT_TAP_FOR,
+  EXTRACT_CAST_BY,
+  EXTRACT_STATED_CMC,
+  EXTRACT_MANA_ADDED,
   EXTRACT_DRAW_MULTIPLE,
   EXTRACT_DRAW_SINGLE,
   EXTRACT_WINNER,
+  DETECT_WIN_PHRASE,
+  WIN_LINE_PATTERN_ENV,
+  WIN_LINE_PREFIX,
+  WINNER_CAPTURE_ENV,
+  compileWinLinePattern,
   EXTRACT_ACTIVE_PLAYER,
+  DETECT_LOCK_EFFECT,
+  EXTRACT_ELIMINATED_PLAYER,
+  EXTRACT_CONCEDED_PLAYER,
 } from './patterns';
 import { matchesDeckName } from './deck-match';
+import { shouldIgnoreLine } from './filter';
+import { classifyLine, withClassificationRules, type ClassifyOptions } from './classify';
 
 // -----------------------------------------------------------------------------
 // Turn Boundary Types
@@ -56,6 +78,53 @@ export interface TurnRange {
   player?: string;
 }
 
+// -----------------------------------------------------------------------------
+// Format Detection
+// -----------------------------------------------------------------------------
+
+/**
+ * Turn marker format of a log: 'current' ("Turn: Turn N (Player)"),
+ * 'legacy' ("Turn N: Player"), or 'unknown' when there are no markers or
+ * both kinds appear.
+ */
+export type LogFormat = 'current' | 'legacy' | 'unknown';
+
+/** Turn markers read before deciding a log's format. */
+export const FORMAT_SNIFF_MARKERS = 4;
+
+const CURRENT_TURN_MARKER = new RegExp(EXTRACT_TURN_NUMBER_CURRENT.source, 'i');
+const LEGACY_TURN_MARKER = new RegExp(EXTRACT_TURN_NUMBER_LEGACY.source, 'i');
+
+/**
+ * Sniffs a log's turn marker format from its first FORMAT_SNIFF_MARKERS
+ * turn markers.
+ *
+ * @param rawLog - The complete raw log text
+ * @returns The format, or 'unknown' when detection is ambiguous
+ *
+ * @example
+ * detectFormat("Turn: Turn 1 (Ai(1)-Alpha)\n...")  // 'current'
+ * detectFormat("Turn 1: Player A\n...")            // 'legacy'
+ */
+export function detectFormat(rawLog: string): LogFormat {
+  const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
+  const pattern = new RegExp(EXTRACT_TURN_NUMBER.source, 'gim');
+  let current = 0;
+  let legacy = 0;
+
+  let match: RegExpExecArray | null;
+  while (current + legacy < FORMAT_SNIFF_MARKERS && (match = pattern.exec(normalized)) !== null) {
+    const lineEnd = normalized.indexOf('\n', match.index);
+    const line = normalized.slice(match.index, lineEnd === -1 ? undefined : lineEnd);
+    if (CURRENT_TURN_MARKER.test(line)) current++;
+    else if (LEGACY_TURN_MARKER.test(line)) legacy++;
+  }
+
+  if (current > 0 && legacy === 0) return 'current';
+  if (legacy > 0 && current === 0) return 'legacy';
+  return 'unknown';
+}
+
 // -----------------------------------------------------------------------------
 // Turn Extraction
 // -----------------------------------------------------------------------------
@@ -67,6 +136,7 @@ export interface TurnRange {
  * (or player turn within a game turn) begins.
  *
  * @param rawLog - The complete raw log text
+ * @param format - Turn marker format (detected if not provided)
  * @returns Array of turn ranges, sorted by position in the log
  *
  * @example
@@ -86,7 +156,7 @@ export interface TurnRange {
  * //   { turnNumber: 2, startOffset: 30, player: "Ai(1)-Doran" }
  * // ]
  */
-export function extractTurnRanges(rawLog: string): TurnRange[] {
+export function extractTurnRanges(rawLog: string, format: LogFormat = detectFormat(rawLog)): TurnRange[] {
   const ranges: TurnRange[] = [];
 
   // Normalize line endings so ^ in multiline mode matches at start of each line.
@@ -99,7 +169,13 @@ export function extractTurnRanges(rawLog: string): TurnRange[] {
   // We use the global flag (g) to find all matches, not just the first.
   // The exec() method in a loop gives us match positions.
 
-  const pattern = new RegExp(EXTRACT_TURN_NUMBER.source, 'gim');
+  const source =
+    format === 'current'
+      ? EXTRACT_TURN_NUMBER_CURRENT.source
+      : format === 'legacy'
+      ? EXTRACT_TURN_NUMBER_LEGACY.source
+      : EXTRACT_TURN_NUMBER.source;
+  const pattern = new RegExp(source, 'gim');
 
   let match: RegExpExecArray | null;
   while ((match = pattern.exec(normalized)) !== null) {
@@ -123,7 +199,13 @@ export function extractTurnRanges(rawLog: string): TurnRange[] {
     // Format 1: "Turn N: Player X" → group 1
     // Format 2: "Turn: Turn N (PlayerName)" → group 2
     const playerMatch = EXTRACT_ACTIVE_PLAYER.exec(fullLine);
-    const player = (playerMatch?.[1] ?? playerMatch?.[2])?.trim();
+    const player = (
+      format === 'current'
+        ? playerMatch?.[2]
+        : format === 'legacy'
+        ? playerMatch?.[1]
+        : playerMatch?.[1] ?? playerMatch?.[2]
+    )?.trim();
 
     ranges.push({
       turnNumber,
@@ -135,6 +217,34 @@ export function extractTurnRanges(rawLog: string): TurnRange[] {
   return ranges;
 }
 
+/**
+ * A log with normalized line endings and its turn ranges. condenseGame
+ * builds one per game and passes it to the per-metric helpers so the
+ * format is sniffed and the turns found once, not once per metric.
+ */
+export interface TurnIndex {
+  /** The log with \r\n and \r normalized to \n; range offsets index into it */
+  log: string;
+  /** Turn ranges from extractTurnRanges() */
+  ranges: TurnRange[];
+}
+
+/** Normalizes \r\n and \r line endings to \n. */
+export function normalizeLineEndings(rawLog: string): string {
+  return rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
+}
+
+/**
+ * Normalizes a log's line endings, detects its format and finds its turns.
+ *
+ * @param rawLog - The complete raw log text
+ * @returns The normalized log and its turn ranges
+ */
+export function indexTurns(rawLog: string): TurnIndex {
+  const log = normalizeLineEndings(rawLog);
+  return { log, ranges: extractTurnRanges(log, detectFormat(log)) };
+}
+
 /**
  * Gets the maximum turn number from a list of turn ranges.
  *
@@ -313,6 +423,7 @@ export function countManaEvents(chunk: string): number {
  *
  * @param rawLog - The complete raw log text
  * @param numPlayers - Optional number of players (auto-detected if not provided)
+ * @param turns - The log's turn index (built if not provided)
  * @returns Object mapping round number -> mana info
  *
  * @example
@@ -321,10 +432,10 @@ export function countManaEvents(chunk: string): number {
  */
 export function calculateManaPerTurn(
   rawLog: string,
-  numPlayers?: number
+  numPlayers?: number,
+  turns: TurnIndex = indexTurns(rawLog)
 ): Record<number, TurnManaInfo> {
-  const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
-  const ranges = extractTurnRanges(normalized);
+  const { log: normalized, ranges } = turns;
   const chunks = sliceByTurn(normalized, ranges);
   const playerCount = numPlayers ?? getNumPlayers(ranges);
   const result: Record<number, TurnManaInfo> = {};
@@ -402,14 +513,15 @@ export function countCardsDrawn(chunk: string): number {
  *
  * @param rawLog - The complete raw log text
  * @param numPlayers - Optional number of players (auto-detected if not provided)
+ * @param turns - The log's turn index (built if not provided)
  * @returns Object mapping round number -> cards drawn
  */
 export function calculateCardsDrawnPerTurn(
   rawLog: string,
-  numPlayers?: number
+  numPlayers?: number,
+  turns: TurnIndex = indexTurns(rawLog)
 ): Record<number, number> {
-  const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
-  const ranges = extractTurnRanges(normalized);
+  const { log: normalized, ranges } = turns;
   const chunks = sliceByTurn(normalized, ranges);
   const playerCount = numPlayers ?? getNumPlayers(ranges);
   const result: Record<number, number> = {};
@@ -428,10 +540,174 @@ export function calculateCardsDrawnPerTurn(
   return result;
 }
 
+// -----------------------------------------------------------------------------
+// Cast Metrics
+// -----------------------------------------------------------------------------
+
+const SPELLED_MANA: Record<string, number> = { one: 1, two: 2, three: 3, four: 4, five: 5 };
+
+/**
+ * Counts spells cast and mana added in a text chunk.
+ *
+ * Casts are stack lines ("Add to stack: X cast Y"); mana is the symbols
+ * added by mana abilities and rituals ("Add {B}{B}{B}"), so a turn's casts
+ * can be weighed against the mana that paid for them.
+ *
+ * @param chunk - A portion of the log (typically one player turn)
+ * @returns Casts and mana added
+ */
+export function countCastsAndMana(chunk: string): { casts: number; manaAdded: number } {
+  let casts = 0;
+  let manaAdded = 0;
+  for (const line of chunk.split('\n')) {
+    if (EXTRACT_CAST_BY.test(line)) casts++;
+    const added = EXTRACT_MANA_ADDED.exec(line);
+    if (added) {
+      manaAdded += added[2]
+        ? SPELLED_MANA[added[2].toLowerCase()]
+        : (added[1].match(/\{/g)?.length ?? 0);
+    }
+  }
+  return { casts, manaAdded };
+}
+
+/**
+ * Calculates casts per player turn, grouped by round.
+ *
+ * Each player turn (Forge segment) counts every spell cast during it,
+ * including instants from other players; mana lines don't name a player,
+ * so the turn is credited to its active player.
+ *
+ * @param rawLog - The complete raw log text
+ * @param numPlayers - Optional number of players (auto-detected if not provided)
+ * @param turns - The log's turn index (built if not provided)
+ * @returns Map of round number -> player turns with at least one cast.
+ *          Rounds with no casts are omitted.
+ *
+ * @example
+ * const casts = calculateCastsPerTurn(log);
+ * // Result: { 2: [{ player: "Ai(1)-Alpha", casts: 4, manaAdded: 5 }], ... }
+ */
+export function calculateCastsPerTurn(
+  rawLog: string,
+  numPlayers?: number,
+  turns: TurnIndex = indexTurns(rawLog)
+): Record<number, TurnCastInfo[]> {
+  const { log: normalized, ranges } = turns;
+  const playerCount = numPlayers ?? getNumPlayers(ranges);
+  const result: Record<number, TurnCastInfo[]> = {};
+
+  for (const { turnNumber, player, chunk } of sliceByTurn(normalized, ranges)) {
+    const { casts, manaAdded } = countCastsAndMana(chunk);
+    if (casts === 0) continue;
+    const round = segmentToRound(turnNumber, playerCount);
+    (result[round] ??= []).push(player ? { player, casts, manaAdded } : { casts, manaAdded });
+  }
+
+  return result;
+}
+
+/** Histogram key for casts whose line doesn't state a CMC. */
+export const CMC_UNKNOWN = 'unknown';
+
+/**
+ * Tallies the CMC of every spell cast, to show a deck's real curve in play
+ * rather than just whether it cast something big.
+ *
+ * Only a CMC the line states ("(CMC 6)") is read; casts without one (current
+ * Forge logs only give the card id) go under CMC_UNKNOWN.
+ *
+ * @param rawLog - The complete raw log text
+ * @returns Map of CMC (or "unknown") -> casts. Empty when nothing was cast.
+ */
+export function castCmcHistogram(rawLog: string): Record<string, number> {
+  const histogram: Record<string, number> = {};
+  for (const line of rawLog.split(/\r?\n|\r/)) {
+    if (!EXTRACT_CAST_BY.test(line)) continue;
+    const stated = EXTRACT_STATED_CMC.exec(line);
+    const key = stated ? String(parseInt(stated[1], 10)) : CMC_UNKNOWN;
+    histogram[key] = (histogram[key] ?? 0) + 1;
+  }
+  return histogram;
+}
+
 // -----------------------------------------------------------------------------
 // Winner Detection
 // -----------------------------------------------------------------------------
 
+let winLineOverride: { source: string; pattern: RegExp } | undefined;
+
+/**
+ * Returns the compiled WIN_LINE_PATTERN override, or undefined when unset.
+ * Recompiles only when the environment value changes.
+ *
+ * Call once at startup to fail fast on a bad pattern.
+ *
+ * @throws If WIN_LINE_PATTERN is set but invalid
+ */
+export function getWinLinePattern(): RegExp | undefined {
+  const source = process.env[WIN_LINE_PATTERN_ENV]?.trim();
+  if (!source) return undefined;
+  if (winLineOverride?.source !== source) {
+    winLineOverride = { source, pattern: compileWinLinePattern(source) };
+  }
+  return winLineOverride.pattern;
+}
+
+/** How EXTRACT_WINNER's capture is trimmed; see WINNER_CAPTURE_ENV. */
+export type WinnerCapture = 'strict' | 'legacy';
+
+const WINNER_CAPTURES: readonly WinnerCapture[] = ['strict', 'legacy'];
+
+/**
+ * Reads WINNER_CAPTURE; 'strict' when unset.
+ *
+ * Call once at startup to fail fast on a bad value.
+ *
+ * @throws If WINNER_CAPTURE isn't strict or legacy
+ */
+export function getWinnerCapture(): WinnerCapture {
+  const source = process.env[WINNER_CAPTURE_ENV]?.trim().toLowerCase();
+  if (!source) return 'strict';
+  if (!(WINNER_CAPTURES as readonly string[]).includes(source)) {
+    throw new Error(`Invalid ${WINNER_CAPTURE_ENV}: unknown mode "${source}" (expected ${WINNER_CAPTURES.join(', ')})`);
+  }
+  return source as WinnerCapture;
+}
+
+/**
+ * Trims EXTRACT_WINNER's capture down to the winner's name. The lazy
+ * capture starts at the beginning of the line, so a decorated line like
+ * "Turn 7: Alice attacks, Alice wins the game" captures everything before
+ * the win phrase.
+ *
+ * In strict mode known prefixes (WIN_LINE_PREFIX) are stripped, then a
+ * capture ending in one of `players` (after a space or punctuation) is cut
+ * to the longest such player. Anything else is kept whole, so a commander
+ * name with a comma ("Atraxa, Praetors' Voice") survives.
+ *
+ * @param captured - EXTRACT_WINNER's group 1
+ * @param players - Players from the log's turn markers
+ * @param mode - Trimming mode (default from WINNER_CAPTURE)
+ *
+ * @example
+ * trimWinnerCapture('Turn 7: Alice attacks, Alice', ['Alice', 'Bob']) // "Alice"
+ */
+export function trimWinnerCapture(
+  captured: string,
+  players: readonly string[],
+  mode: WinnerCapture = getWinnerCapture()
+): string {
+  const legacy = captured.trim().replace(/^Game outcome:\s*/i, '');
+  if (mode === 'legacy') return legacy;
+  const stripped = captured.trim().replace(WIN_LINE_PREFIX, '').trim();
+  if (!stripped) return legacy;
+  const player = players
+    .filter((p) => stripped === p || (stripped.endsWith(p) && /[\s,.;:]$/.test(stripped.slice(0, -p.length))))
+    .sort((a, b) => b.length - a.length)[0];
+  return player ?? stripped;
+}
+
 /**
  * Attempts to extract the game winner from the log.
  *
@@ -439,12 +715,257 @@ export function calculateCardsDrawnPerTurn(
  *   - "Player A wins the game."
  *   - "Game Over. Player B wins."
  *
+ * A WIN_LINE_PATTERN override, if configured, is tried first. The built-in
+ * capture is trimmed to the name with trimWinnerCapture.
+ *
  * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
  * @returns The winner's identifier, or undefined if not found
  */
-export function extractWinner(rawLog: string): string | undefined {
-  const match = EXTRACT_WINNER.exec(rawLog);
-  return match?.[1]?.trim().replace(/^Game outcome:\s*/i, '');
+export function extractWinner(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): string | undefined {
+  return winLines(turns.log.split('\n'), turnPlayers(turns.ranges))[0]?.winner;
+}
+
+/** Distinct players named by the turn markers. */
+function turnPlayers(ranges: readonly TurnRange[]): string[] {
+  return [...new Set(ranges.map((r) => r.player).filter((p): p is string => !!p))];
+}
+
+/**
+ * The winner named by each win line, in order. Lines matching the
+ * WIN_LINE_PATTERN override are used when there are any; otherwise the
+ * built-in win phrases. Loss lines are skipped: "Ai(2)-Beta has lost
+ * because an opponent has won by spell ..." names the loser.
+ */
+function winLines(lines: string[], players: readonly string[]): Array<{ index: number; winner: string }> {
+  const override = getWinLinePattern();
+  if (override) {
+    const found = lines.flatMap((line, index) => {
+      const winner = override.exec(line)?.[1]?.trim();
+      return winner ? [{ index, winner }] : [];
+    });
+    if (found.length > 0) return found;
+  }
+
+  return lines.flatMap((line, index) => {
+    if (!DETECT_WIN_PHRASE.test(line) || eliminatedPlayerOf(line)) return [];
+    const match = EXTRACT_WINNER.exec(line);
+    return match ? [{ index, winner: trimWinnerCapture(match[1], players) }] : [];
+  });
+}
+
+/**
+ * True when two win lines name the same player. Forge follows "Game
+ * outcome: X has won" with "Game Result: Game 1 ended in 600 ms. X has
+ * won!", whose capture keeps the prefix with WINNER_CAPTURE=legacy, so a
+ * name ending in the other counts as the same.
+ */
+function sameWinner(a: string, b: string): boolean {
+  return a === b || a.endsWith(` ${b}`) || b.endsWith(` ${a}`) || matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+/**
+ * Lists the distinct players named by win lines, in order of their first
+ * win line, with the turn segment that line fell in.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @returns One entry per distinct winner; `segment` is the 0-based index of
+ *          the turn marker before the line (-1 before the first marker)
+ */
+export function extractWinners(
+  rawLog: string,
+  turns: TurnIndex = indexTurns(rawLog)
+): Array<{ winner: string; segment: number }> {
+  const { log: normalized, ranges } = turns;
+  const lines = normalized.split('\n');
+
+  // Turn segment of each line, from the markers' character offsets
+  const segments: number[] = [];
+  let offset = 0;
+  let segment = -1;
+  for (const line of lines) {
+    while (segment + 1 < ranges.length && ranges[segment + 1].startOffset <= offset) segment++;
+    segments.push(segment);
+    offset += line.length + 1;
+  }
+
+  const winners: Array<{ winner: string; segment: number }> = [];
+  for (const { index, winner } of winLines(lines, turnPlayers(ranges))) {
+    if (winners.some((w) => sameWinner(w.winner, winner))) continue;
+    winners.push({ winner, segment: segments[index] });
+  }
+  return winners;
+}
+
+/**
+ * Finds players who won in the same turn: two distinct winners whose win
+ * lines fall between the same pair of turn markers (two combos resolving
+ * off one trigger, a shared alternate win).
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @returns The simultaneous winners in log order, or [] when the game
+ *          didn't end that way
+ */
+export function extractSimultaneousWinners(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): string[] {
+  const winners = extractWinners(rawLog, turns);
+  if (winners.length < 2) return [];
+  const first = winners[0];
+  const together = winners.filter((w) => w.segment === first.segment);
+  return together.length > 1 ? together.map((w) => w.winner) : [];
+}
+
+/** Environment variable choosing how simultaneous wins are scored. */
+export const SIMULTANEOUS_WIN_SCORING_ENV = 'SIMULTANEOUS_WIN_SCORING';
+
+/**
+ * How a game with simultaneous winners is scored:
+ *   - draw: nobody wins; the game counts as a draw
+ *   - shared: every simultaneous winner is credited with the win
+ */
+export type SimultaneousWinScoring = 'draw' | 'shared';
+
+const SIMULTANEOUS_WIN_SCORINGS: readonly SimultaneousWinScoring[] = ['draw', 'shared'];
+
+/**
+ * Reads SIMULTANEOUS_WIN_SCORING; 'draw' when unset.
+ *
+ * Call once at startup to fail fast on a bad value.
+ *
+ * @throws If SIMULTANEOUS_WIN_SCORING isn't draw or shared
+ */
+export function getSimultaneousWinScoring(): SimultaneousWinScoring {
+  const source = process.env[SIMULTANEOUS_WIN_SCORING_ENV]?.trim().toLowerCase();
+  if (!source) return 'draw';
+  if (!(SIMULTANEOUS_WIN_SCORINGS as readonly string[]).includes(source)) {
+    throw new Error(
+      `Invalid ${SIMULTANEOUS_WIN_SCORING_ENV}: unknown scoring "${source}" (expected ${SIMULTANEOUS_WIN_SCORINGS.join(', ')})`
+    );
+  }
+  return source as SimultaneousWinScoring;
+}
+
+/**
+ * Names the player a loss or concession line takes out of the game.
+ *
+ * @param line - One log line
+ * @returns The eliminated player (as logged), or undefined for other lines
+ */
+export function eliminatedPlayerOf(line: string): string | undefined {
+  const trimmed = line.trim();
+  const lost = EXTRACT_ELIMINATED_PLAYER.exec(trimmed);
+  return (lost?.[1] ?? lost?.[2] ?? EXTRACT_CONCEDED_PLAYER.exec(trimmed)?.[1])?.trim() || undefined;
+}
+
+/**
+ * True when `player` is one of the eliminated players. Per-player metrics
+ * walk the log in order and use this to drop lines credited to a player
+ * after they're out: their delayed triggers and permanents leaving are
+ * noise, not actions.
+ *
+ * @param player - A player name as logged
+ * @param eliminated - Players eliminated so far (see eliminatedPlayerOf)
+ */
+export function isEliminated(player: string, eliminated: readonly string[]): boolean {
+  return eliminated.some((e) => matchesDeckName(player, e) || matchesDeckName(e, player));
+}
+
+/**
+ * Infers the winner when eliminations leave exactly one player standing but
+ * no win line names them.
+ *
+ * Players are everyone who took a turn; a player is out once a loss or
+ * concession line names them.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @returns The last player standing, or undefined if zero or several remain
+ */
+export function extractLastStanding(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): string | undefined {
+  const players = turnPlayers(turns.ranges);
+  if (players.length < 2) return undefined;
+
+  const eliminated: string[] = [];
+  for (const line of turns.log.split('\n')) {
+    const name = eliminatedPlayerOf(line);
+    if (name) eliminated.push(name);
+  }
+
+  const standing = players.filter((p) => !isEliminated(p, eliminated));
+  return standing.length === 1 ? standing[0] : undefined;
+}
+
+/**
+ * Finds the winner from a win line, falling back to the last player
+ * standing (with winReason 'last_standing').
+ *
+ * When several players won in the same turn they are returned as
+ * `simultaneousWinners`, and `scoring` decides the rest: 'draw' leaves
+ * `winner` unset, 'shared' sets it to the first of them.
+ *
+ * @param rawLog - The complete raw log text
+ * @param scoring - How simultaneous wins are scored (default from
+ *   SIMULTANEOUS_WIN_SCORING)
+ * @param turns - The log's turn index (built if not provided)
+ */
+export function resolveWinner(
+  rawLog: string,
+  scoring: SimultaneousWinScoring = getSimultaneousWinScoring(),
+  turns: TurnIndex = indexTurns(rawLog)
+): { winner?: string; winReason?: WinReason; simultaneousWinners?: string[] } {
+  const simultaneousWinners = extractSimultaneousWinners(rawLog, turns);
+  if (simultaneousWinners.length > 0) {
+    return scoring === 'shared' ? { winner: simultaneousWinners[0], simultaneousWinners } : { simultaneousWinners };
+  }
+  const winner = extractWinner(rawLog, turns);
+  if (winner) return { winner };
+  const survivor = extractLastStanding(rawLog, turns);
+  return survivor ? { winner: survivor, winReason: 'last_standing' } : {};
+}
+
+/**
+ * Game wins tallied across the games of one match (e.g. a best-of-3 file).
+ */
+export interface MatchResult {
+  /** Games won per player (as logged) */
+  wins: Record<string, number>;
+  /** The player with the most game wins; unset on a tie or with no winners */
+  winner?: string;
+}
+
+/**
+ * Declares a match winner from its games' winners. Split the file with
+ * splitConcatenatedGames first so each game reports its own winner.
+ *
+ * @param games - The match's games (condensed or structured), in order
+ * @returns Wins per player and the match winner, if one player has the most
+ */
+export function matchWinner(games: Array<{ winner?: string }>): MatchResult {
+  const wins: Record<string, number> = {};
+  for (const { winner } of games) {
+    if (winner) wins[winner] = (wins[winner] ?? 0) + 1;
+  }
+
+  const ranked = Object.entries(wins).sort((a, b) => b[1] - a[1]);
+  const result: MatchResult = { wins };
+  if (ranked.length > 0 && (ranked.length === 1 || ranked[0][1] > ranked[1][1])) {
+    result.winner = ranked[0][0];
+  }
+  return result;
+}
+
+/**
+ * Detects "can't lose / can't win" style lock effects anywhere in the log.
+ *
+ * These effects can prevent a win line from ever being logged, so the flag
+ * is a likely explanation for games with no detected winner.
+ *
+ * @param rawLog - The complete raw log text
+ * @returns true if any line describes a lock effect
+ */
+export function detectLockEffect(rawLog: string): boolean {
+  return DETECT_LOCK_EFFECT.test(rawLog);
 }
 
 /**
@@ -492,6 +1013,53 @@ export function extractWinningTurn(rawLog: string): number | undefined {
 // Life Total Tracking
 // -----------------------------------------------------------------------------
 
+/** Commander starting life total, used for any player whose log doesn't state one. */
+export const STARTING_LIFE = 40;
+
+/**
+ * Pattern for a stated starting life total (Vanguard, handicap games).
+ *
+ * Examples:
+ *   Ai(1)-Alpha starts at 30 life
+ *   Game: Ai(2)-Beta starts the game with 45 life.
+ *
+ * Capture groups:
+ *   1: Player name
+ *   2: Starting life
+ */
+const STARTING_LIFE_PATTERN = /^\s*(?:[A-Za-z]+:\s+)?(.{1,120}?)\s+starts\s+(?:the\s+game\s+)?(?:at|with)\s+(\d+)\s+life\b/i;
+
+/**
+ * Reads each player's stated starting life.
+ *
+ * @param rawLog - The complete raw log text
+ * @returns Map of player name (as logged) -> starting life. Players without
+ *          a "starts at N life" line are absent; use startingLifeOf for them.
+ */
+export function extractStartingLife(rawLog: string): Record<string, number> {
+  const startingLife: Record<string, number> = {};
+  for (const line of rawLog.split(/\r?\n|\r/)) {
+    const match = STARTING_LIFE_PATTERN.exec(line);
+    if (match && !(match[1].trim() in startingLife)) {
+      startingLife[match[1].trim()] = parseInt(match[2], 10);
+    }
+  }
+  return startingLife;
+}
+
+/**
+ * A player's starting life: the stated value, or STARTING_LIFE.
+ *
+ * @param player - Player identifier
+ * @param startingLife - Stated starting lives from extractStartingLife
+ */
+export function startingLifeOf(player: string, startingLife: Record<string, number>): number {
+  const stated = Object.keys(startingLife).find(
+    (name) => matchesDeckName(player, name) || matchesDeckName(name, player)
+  );
+  return stated !== undefined ? startingLife[stated] : STARTING_LIFE;
+}
+
 /**
  * Pattern for Forge's native life change log entries.
  *
@@ -522,6 +1090,9 @@ const LIFE_LOG_PATTERN = /^\[LIFE\] Life: (.+)\s+(-?\d+)\s*->\s*(-?\d+)$/;
  * A "round" is one full rotation where each player takes a turn.
  * In a 4-player Commander game, round 1 = segments 1-4, round 2 = segments 5-8, etc.
  *
+ * Players start at their stated starting life ("X starts at N life"), or
+ * STARTING_LIFE, until their first `[LIFE]` entry.
+ *
  * Returns an empty object when no `[LIFE]` entries are found (e.g., logs from
  * Forge versions before the life tracking feature). This lets the frontend
  * detect that life data is unavailable rather than showing misleading defaults.
@@ -542,10 +1113,10 @@ export function calculateLifePerTurn(
   const chunks = sliceByTurn(normalized, ranges);
   const playerCount = numPlayers ?? getNumPlayers(ranges);
 
-  // Initialize life totals (Commander starts at 40)
+  const startingLife = extractStartingLife(normalized);
   const currentLife: Record<string, number> = {};
   for (const player of players) {
-    currentLife[player] = 40;
+    currentLife[player] = startingLifeOf(player, startingLife);
   }
 
   const lifePerRound: Record<number, Record<string, number>> = {};
@@ -599,3 +1170,219 @@ export function calculateLifePerTurn(
 
   return lifePerRound;
 }
+
+/**
+ * Totals life lost across all players per round, from `[LIFE]` entries.
+ * Life gain is ignored, so a lifelink swing doesn't hide the damage dealt.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @returns Map of round number -> total life lost that round. Rounds with
+ *          no life loss are omitted; empty `{}` without `[LIFE]` entries.
+ */
+export function lifeLossRatePerTurn(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): Record<number, number> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const lossPerRound: Record<number, number> = {};
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      const match = LIFE_LOG_PATTERN.exec(line);
+      if (!match) continue;
+      const lost = parseInt(match[2], 10) - parseInt(match[3], 10);
+      if (lost > 0) lossPerRound[round] = (lossPerRound[round] ?? 0) + lost;
+    }
+  }
+
+  return lossPerRound;
+}
+
+/**
+ * Thresholds for flagging an "aggro clock": a run of rounds where the table
+ * as a whole loses life fast.
+ */
+export interface FastClockOptions {
+  /** Average life lost per round across the table that counts as fast */
+  threshold: number;
+  /** Number of consecutive rounds averaged (the sliding window) */
+  window: number;
+}
+
+/**
+ * Defaults for a 4-player, 40-life Commander pod: 30 life a round is the
+ * table losing nearly a full player's life total each round.
+ */
+export const DEFAULT_FAST_CLOCK: FastClockOptions = {
+  threshold: 30,
+  window: 2,
+};
+
+/**
+ * Returns true when any `window` consecutive rounds average at least
+ * `threshold` life lost. Rounds missing from the map count as 0. A game
+ * shorter than the window is averaged over the rounds it has.
+ *
+ * @param lossPerRound - Output of lifeLossRatePerTurn
+ * @param options - Threshold and window size
+ */
+export function isFastClock(
+  lossPerRound: Record<number, number>,
+  options: FastClockOptions = DEFAULT_FAST_CLOCK
+): boolean {
+  const rounds = Object.keys(lossPerRound).map(Number);
+  if (rounds.length === 0) return false;
+  const lastRound = Math.max(...rounds);
+  const window = Math.max(1, Math.min(options.window, lastRound));
+
+  for (let start = 1; start + window - 1 <= lastRound; start++) {
+    let total = 0;
+    for (let round = start; round < start + window; round++) total += lossPerRound[round] ?? 0;
+    if (total / window >= options.threshold) return true;
+  }
+  return false;
+}
+
+/**
+ * Counts classified events per round: the event density used to spot
+ * locks. Lines are filtered and classified as in the condense pipeline,
+ * without compaction.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @param classify - The pipeline's classification options, so the counts
+ *   match its events
+ * @returns Map of round number -> events that round. Rounds with no
+ *          events are omitted.
+ */
+export function eventsPerRound(
+  rawLog: string,
+  turns: TurnIndex = indexTurns(rawLog),
+  classify?: ClassifyOptions
+): Record<number, number> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const prepared = withClassificationRules(classify);
+  const events: Record<number, number> = {};
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      if (shouldIgnoreLine(line) || classifyLine(line, prepared) === null) continue;
+      events[round] = (events[round] ?? 0) + 1;
+    }
+  }
+
+  return events;
+}
+
+/**
+ * Counts creature deaths per round, classified as in the condense
+ * pipeline so higher-priority types (recursion, land destruction) win.
+ * Many deaths per round mark an attrition game rather than a combo kill.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @param classify - The pipeline's classification options, so the counts
+ *   match its creature_death events
+ * @returns Map of round number -> creature deaths that round. Rounds with
+ *          no deaths are omitted.
+ */
+export function creatureDeathsPerRound(
+  rawLog: string,
+  turns: TurnIndex = indexTurns(rawLog),
+  classify?: ClassifyOptions
+): Record<number, number> {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const prepared = withClassificationRules(classify);
+  const deaths: Record<number, number> = {};
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    const round = segmentToRound(turnNumber, numPlayers);
+    for (const line of chunk.split('\n')) {
+      if (shouldIgnoreLine(line) || classifyLine(line, prepared) !== 'creature_death') continue;
+      deaths[round] = (deaths[round] ?? 0) + 1;
+    }
+  }
+
+  return deaths;
+}
+
+/**
+ * Finds the "first blood" round: when the first player lost or conceded.
+ * An early first blood marks an aggressive or targeted pod; a late one, a
+ * table where everyone survives to the end.
+ *
+ * @param rawLog - The complete raw log text
+ * @param turns - The log's turn index (built if not provided)
+ * @returns The earliest round with an elimination, or 0 when nobody was
+ *          eliminated
+ */
+export function firstBloodRound(rawLog: string, turns: TurnIndex = indexTurns(rawLog)): number {
+  const { log: normalized, ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+
+  for (const { turnNumber, chunk } of sliceByTurn(normalized, ranges)) {
+    if (chunk.split('\n').some((line) => eliminatedPlayerOf(line) !== undefined)) {
+      return segmentToRound(turnNumber, numPlayers);
+    }
+  }
+  return 0;
+}
+
+/**
+ * Thresholds for flagging a lock stall: a run of rounds where nobody does
+ * anything (Stasis, "skip your untap step", Winter Orb).
+ */
+export interface LockStallOptions {
+  /** Events per player turn at or below which a round counts as quiet */
+  maxDensity: number;
+  /** Consecutive quiet rounds needed to flag a stall */
+  minRounds: number;
+}
+
+/**
+ * A normal slow game still plays a land or casts something most turns
+ * (density around 1 or more); a locked table averages under one event
+ * every two player turns for four rounds running.
+ */
+export const DEFAULT_LOCK_STALL: LockStallOptions = {
+  maxDensity: 0.5,
+  minRounds: 4,
+};
+
+/**
+ * Finds the first run of at least `minRounds` consecutive quiet rounds,
+ * where a round is quiet when its events per player turn are at most
+ * `maxDensity`. Rounds with no events count as quiet.
+ *
+ * @param rawLog - The complete raw log text
+ * @param options - Density threshold and run length
+ * @param turns - The log's turn index (built if not provided)
+ * @param classify - Classification options for eventsPerRound
+ * @returns The round the stall started, or undefined
+ */
+export function detectLockStall(
+  rawLog: string,
+  options: LockStallOptions = DEFAULT_LOCK_STALL,
+  turns: TurnIndex = indexTurns(rawLog),
+  classify?: ClassifyOptions
+): number | undefined {
+  const { ranges } = turns;
+  const numPlayers = getNumPlayers(ranges);
+  const lastRound = getMaxRound(ranges, numPlayers);
+  const events = eventsPerRound(rawLog, turns, classify);
+
+  let runStart: number | undefined;
+  for (let round = 1; round <= lastRound; round++) {
+    const quiet = (events[round] ?? 0) / numPlayers <= options.maxDensity;
+    if (!quiet) {
+      runStart = undefined;
+      continue;
+    }
+    runStart ??= round;
+    if (round - runStart + 1 >= options.minRounds) return runStart;
+  }
+  return undefined;
+}
@@ -29,7 +29,17 @@ export type EventType =
   | 'win_condition'         // Game ending event (player wins/loses)
   | 'commander_cast'        // Commander was cast (important for Commander format)
   | 'combat'                // Combat-related action
-  | 'draw_extra';           // Extra card draw beyond normal draw step
+  | 'draw_extra'            // Extra card draw beyond normal draw step
+  | 'mill'                  // Cards milled from a library into a graveyard
+  | 'library_manip'         // Scry / surveil
+  | 'free_cast'             // Cascade, suspend, "without paying its mana cost"
+  | 'alt_cost_cast'         // Flashback, escape, disturb, jump-start, retrace
+  | 'clone'                 // Clone / token copy ("enters as a copy of", "token copy of")
+  | 'goad'                  // Goad / "must attack if able" (forced attacks)
+  | 'protection'            // Gains hexproof/shroud/protection/indestructible, or can't be countered
+  | 'land_destruction'      // Land destruction or forced land sacrifice
+  | 'creature_death'        // A creature dies, is destroyed or goes from battlefield to graveyard
+  | 'ritual';               // A spell that adds mana when it resolves (Dark Ritual)
 
 /**
  * A single event extracted from the game log.
@@ -44,6 +54,14 @@ export interface GameEvent {
   turn?: number;
   /** Which player performed this action (if determinable) */
   player?: string;
+  /** How many identical consecutive events this one stands for (set by compaction, >= 2) */
+  repeat?: number;
+  /** The acting player's color identity (e.g. ["B", "R"]), when a color map was supplied */
+  playerColors?: string[];
+  /** 1-based line number in the game's raw log, when line numbers were requested */
+  lineNo?: number;
+  /** Set on combat events that are an attack forced by goad */
+  goaded?: boolean;
 }
 
 // -----------------------------------------------------------------------------
@@ -169,6 +187,12 @@ export interface StructuredGame {
    */
   lifePerTurn?: Record<number, Record<string, number>>;
 
+  /**
+   * Non-token permanents entering the battlefield per round per player.
+   * Key is round number, value is map of player name to permanents entered.
+   */
+  boardDevelopmentPerTurn?: Record<number, Record<string, number>>;
+
   /** Per-deck turn counts (accurate even with mid-game eliminations) */
   perDeckTurns?: Record<string, DeckTurnInfo>;
 
@@ -0,0 +1,112 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Unmatched Line Sampling
+ * =============================================================================
+ *
+ * Tallies the lines that survive the noise filter but match no
+ * classification rule, so patterns can be tuned from production logs. A
+ * job uploads the most common ones as `unmatched-sample.json`.
+ *
+ * ## Normalization
+ *
+ * Lines are tallied by a normalized form so the same kind of line from
+ * different cards and turns adds up: card ids become "(#)", other numbers
+ * become "#", and whitespace is collapsed. Player names are kept; they're
+ * a small set per job.
+ *
+ * =============================================================================
+ */
+
+import { splitAndFilter } from './filter';
+import { classifyLine } from './classify';
+import { EXTRACT_ACTIVE_PLAYER } from './patterns';
+
+/** Distinct unmatched lines kept in a job's sample. */
+export const DEFAULT_UNMATCHED_SAMPLE_SIZE = 50;
+
+/** Forge phase announcements ("Phase: Ai(1)-Alpha' Upkeep step"): structure, not actions */
+const PHASE_MARKER = /^\s*Phase:/i;
+
+/** Longest normalized line kept, matching the event line cap. */
+const MAX_SAMPLE_LINE_LENGTH = 200;
+
+/**
+ * One normalized unmatched line and how often it appeared.
+ */
+export interface UnmatchedLineCount {
+  line: string;
+  count: number;
+}
+
+/**
+ * The `unmatched-sample.json` artifact.
+ */
+export interface UnmatchedSample {
+  /** Unmatched lines across all games */
+  totalUnmatched: number;
+  /** Distinct normalized unmatched lines */
+  distinct: number;
+  /** The most common normalized lines, most frequent first */
+  top: UnmatchedLineCount[];
+}
+
+/**
+ * Normalizes a line for tallying: card ids and numbers are masked and
+ * whitespace collapsed.
+ *
+ * @example
+ * normalizeUnmatchedLine('Mana: Forest (287) - {T}: Add {G}.')
+ * // "Mana: Forest (#) - {T}: Add {G}."
+ */
+export function normalizeUnmatchedLine(line: string): string {
+  return line
+    .replace(/(?<!\bAi)\(\d+\)/g, '(#)')
+    .replace(/(?<![\w(])\d+(?!\))/g, '#')
+    .replace(/\s+/g, ' ')
+    .trim()
+    .slice(0, MAX_SAMPLE_LINE_LENGTH);
+}
+
+/**
+ * Adds one game's unmatched lines to a running tally. Turn and phase
+ * markers aren't events and are skipped.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @param tally - Normalized line -> count, updated in place
+ * @returns The same tally
+ */
+export function tallyUnmatchedLines(rawLog: string, tally: Map<string, number> = new Map()): Map<string, number> {
+  for (const line of splitAndFilter(rawLog)) {
+    if (EXTRACT_ACTIVE_PLAYER.test(line) || PHASE_MARKER.test(line)) continue;
+    if (classifyLine(line) !== null) continue;
+    const key = normalizeUnmatchedLine(line);
+    if (key.length === 0) continue;
+    tally.set(key, (tally.get(key) ?? 0) + 1);
+  }
+  return tally;
+}
+
+/**
+ * Builds the unmatched-line sample for a set of games.
+ *
+ * @param rawLogs - One raw log per game
+ * @param topN - Distinct lines to keep (default DEFAULT_UNMATCHED_SAMPLE_SIZE)
+ * @returns Totals and the top lines by count (ties in line order)
+ */
+export function buildUnmatchedSample(
+  rawLogs: string[],
+  topN: number = DEFAULT_UNMATCHED_SAMPLE_SIZE
+): UnmatchedSample {
+  const tally = new Map<string, number>();
+  for (const rawLog of rawLogs) tallyUnmatchedLines(rawLog, tally);
+
+  let totalUnmatched = 0;
+  for (const count of tally.values()) totalUnmatched += count;
+
+  const top = [...tally.entries()]
+    .map(([line, count]) => ({ line, count }))
+    .sort((a, b) => b.count - a.count || (a.line < b.line ? -1 : a.line > b.line ? 1 : 0))
+    .slice(0, Math.max(0, topN));
+
+  return { totalUnmatched, distinct: tally.size, top };
+}
@@ -0,0 +1,161 @@
+/**
+ * Tests for win reasons.
+ *
+ * Run with: npx tsx lib/condenser/win-reason.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { StructuredGame } from '../types';
+import { classifyGameEnd, winReasonCounts } from './win-reason';
+import { structureGame } from './index';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function readFixture(name: string): string {
+  return fs.readFileSync(path.join(__dirname, 'fixtures', name), 'utf-8');
+}
+
+/** A two-player game Beta loses with the given outcome line. */
+function endedWith(outcome: string): string {
+  return [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Land: Ai(1)-Alpha played Forest (1)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Land: Ai(2)-Beta played Island (2)',
+    `Game outcome: ${outcome}`,
+    'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+  ].join('\n');
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running win reason tests...\n');
+
+  await test('classifyGameEnd: combat and non-combat killing blows', () => {
+    assertEqual(classifyGameEnd(readFixture('killing-blow-combat-log.txt')), 'combat', 'Craterhoof attack');
+    assertEqual(classifyGameEnd(readFixture('killing-blow-burn-log.txt')), 'combo', 'non-combat damage');
+  });
+
+  await test('classifyGameEnd: reasons from the loss line', () => {
+    assertEqual(classifyGameEnd(endedWith('Ai(2)-Beta has lost because of obtaining 10 poison counters')), 'poison', 'poison');
+    assertEqual(
+      classifyGameEnd(endedWith('Ai(2)-Beta has lost due to accumulation of 21 damage from generals')),
+      'commander_damage',
+      'commander damage'
+    );
+    assertEqual(classifyGameEnd(endedWith('Ai(2)-Beta has lost trying to draw cards from an empty library')), 'mill', 'mill');
+    assertEqual(
+      classifyGameEnd(endedWith("Ai(2)-Beta has lost because an opponent has won by spell Thassa's Oracle")),
+      'combo',
+      'alternate win'
+    );
+    assertEqual(classifyGameEnd(endedWith('Ai(2)-Beta has conceded')), 'concession', 'concession');
+    assertEqual(classifyGameEnd(endedWith('Ai(2)-Beta has lost because life total reached 0')), 'last_standing', 'no damage line');
+  });
+
+  await test('classifyGameEnd: a concession earlier in the game does not hide the kill', () => {
+    // Forge lists Gamma's concession after Beta's combat loss
+    const log = readFixture('killing-blow-combat-log.txt');
+    assert(log.indexOf('Gamma has conceded') > log.indexOf('Beta has lost'), 'fixture lists the concession last');
+    assertEqual(classifyGameEnd(log), 'combat', 'combat');
+  });
+
+  await test('classifyGameEnd: no winner is a draw', () => {
+    assertEqual(classifyGameEnd('Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (1)'), 'draw', 'draw');
+  });
+
+  await test('winReasonCounts: every decisive game contributes exactly one reason', () => {
+    const logs = [
+      readFixture('killing-blow-combat-log.txt'),
+      readFixture('killing-blow-burn-log.txt'),
+      endedWith('Ai(2)-Beta has lost because of obtaining 10 poison counters'),
+      endedWith('Ai(2)-Beta has conceded'),
+      endedWith('Ai(2)-Beta has lost trying to draw cards from an empty library'),
+      readFixture('real-4game-log.txt').split(/^Game Result:.*$/m)[0],
+      'Turn: Turn 1 (Ai(1)-Alpha)\nLand: Ai(1)-Alpha played Forest (1)',
+    ];
+    const games = logs.map((log) => structureGame(log));
+    const counts = winReasonCounts(games);
+    assertEqual(
+      JSON.stringify(counts),
+      JSON.stringify({ combat: 2, combo: 1, mill: 1, poison: 1, concession: 1, draw: 1 }),
+      'counts'
+    );
+    const decisive = games.filter((g) => g.winner).length;
+    const { draw = 0, ...wins } = counts;
+    assertEqual(Object.values(wins).reduce((a, b) => a + b, 0), decisive, 'reasons sum to decisive games');
+    assertEqual(draw, games.length - decisive, 'games without a winner are draws');
+  });
+
+  await test('winReasonCounts: games stored without endReason count as last_standing', () => {
+    const game: StructuredGame = { totalTurns: 3, players: ['Ai(1)-A', 'Ai(2)-B'], turns: [], decks: [], winner: 'Ai(1)-A' };
+    assertEqual(JSON.stringify(winReasonCounts([game])), JSON.stringify({ last_standing: 1 }), 'fallback');
+    assertEqual(JSON.stringify(winReasonCounts([])), '{}', 'empty');
+  });
+
+  // -------------------------------------------------------------------------
+  // Summary
+  // -------------------------------------------------------------------------
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
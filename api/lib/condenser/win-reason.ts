@@ -0,0 +1,119 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Win Reasons
+ * =============================================================================
+ *
+ * Labels how each game ended (combat, commander damage, combo, mill, poison,
+ * concession), so a job can report how its pod tends to close games out. A
+ * pod that mostly ends by combo is a higher-powered pod than one that ends
+ * in combat.
+ *
+ * ## Heuristic
+ *
+ * A game with no winner is a draw. Otherwise the reason comes from the
+ * "Game outcome: X has lost ..." lines. Forge lists every player's outcome
+ * at the end of the game in seat order, not in the order they were
+ * eliminated, so the line for the player who was knocked out last is found
+ * in this order:
+ *
+ *   1. A loss to an alternate win ("an opponent has won by spell",
+ *      "effect of spell") ends the game outright: combo.
+ *   2. The killing blow's victim (kill.ts): combat when the blow was combat
+ *      damage, combo otherwise (Walking Ballista, a drain loop).
+ *   3. The last other non-concession loss line:
+ *        "... poison counters"        -> poison
+ *        "... damage from generals"   -> commander_damage
+ *        "... empty library"          -> mill
+ *   4. Every loss was a concession: concession.
+ *
+ * A win with no loss or concession lines at all is an alternate win that
+ * left nobody listed: combo. Anything else, including life reaching 0 with
+ * no damage line to blame, is last_standing: the winner outlasted the table
+ * but the log doesn't say how.
+ *
+ * =============================================================================
+ */
+
+import type { StructuredGame, GameEndReason } from '../types';
+import { EXTRACT_CONCEDED_PLAYER, EXTRACT_LOSS_REASON } from './patterns';
+import { eliminatedPlayerOf, resolveWinner } from './turns';
+import { extractKillingBlow } from './kill';
+import { matchesDeckName } from './deck-match';
+
+/** Loss to another player's alternate win (Thassa's Oracle, Approach of the Second Sun) */
+const ALTERNATE_WIN = /\bhas\s+won\b|\beffect\s+of\b/i;
+
+/** Every reason, in the order winReasonCounts lists them */
+export const GAME_END_REASONS: readonly GameEndReason[] = [
+  'combat',
+  'commander_damage',
+  'combo',
+  'mill',
+  'poison',
+  'concession',
+  'last_standing',
+  'draw',
+];
+
+/**
+ * Labels how one game ended.
+ *
+ * @param rawLog - The complete raw log text for one game
+ * @returns 'draw' when the game has no winner; otherwise exactly one of the
+ *          win reasons
+ */
+export function classifyGameEnd(rawLog: string): GameEndReason {
+  const normalized = rawLog.replace(/\r\n/g, '\n').replace(/\r/g, '\n');
+  if (!resolveWinner(normalized).winner) return 'draw';
+
+  // Player -> why they lost ('' when the line doesn't say), in the order of
+  // each player's last loss line
+  const losses = new Map<string, string>();
+  const concessions = new Set<string>();
+  for (const raw of normalized.split('\n')) {
+    const line = raw.trim();
+    const player = eliminatedPlayerOf(line);
+    if (player === undefined) continue;
+    if (EXTRACT_CONCEDED_PLAYER.test(line)) {
+      concessions.add(player);
+      losses.delete(player);
+    } else {
+      losses.delete(player);
+      losses.set(player, EXTRACT_LOSS_REASON.exec(line)?.[1] ?? '');
+      concessions.delete(player);
+    }
+  }
+  if (losses.size === 0) return concessions.size > 0 ? 'concession' : 'combo';
+
+  const reasons = [...losses.values()];
+  if (reasons.some((reason) => ALTERNATE_WIN.test(reason))) return 'combo';
+
+  const blow = extractKillingBlow(normalized);
+  if (blow && [...losses.keys()].some((player) => matchesDeckName(player, blow.victim))) {
+    return blow.type === 'combat' ? 'combat' : 'combo';
+  }
+
+  const reason = reasons[reasons.length - 1];
+  if (/\bpoison\b/i.test(reason)) return 'poison';
+  if (/\b(?:generals?|commanders?)\b/i.test(reason)) return 'commander_damage';
+  if (/\blibrary\b/i.test(reason)) return 'mill';
+  return 'last_standing';
+}
+
+/**
+ * Counts games by how they ended. Every game counts exactly once: decisive
+ * games under their win reason and games without a winner under 'draw'.
+ *
+ * @param games - Structured games with endReason
+ * @returns Reason -> games, listing only reasons that occurred
+ */
+export function winReasonCounts(games: StructuredGame[]): Partial<Record<GameEndReason, number>> {
+  const counts: Partial<Record<GameEndReason, number>> = {};
+  for (const game of games) {
+    const reason = game.winner ? game.endReason ?? 'last_standing' : 'draw';
+    counts[reason] = (counts[reason] ?? 0) + 1;
+  }
+  return Object.fromEntries(
+    GAME_END_REASONS.filter((reason) => counts[reason]).map((reason) => [reason, counts[reason]])
+  );
+}
@@ -11,7 +11,8 @@
 import * as fs from 'fs';
 import * as path from 'path';
 import { splitConcatenatedGames } from './patterns';
-import { structureGames } from './index';
+import { condenseGames, structureGames } from './index';
+import { tallyWins } from './win-tally';
 import { resolveWinnerName } from './deck-match';
 import type { SimulationStatus, SimulationState } from '../types';
 import { GAMES_PER_CONTAINER } from '../types';
@@ -41,6 +42,10 @@ async function test(name: string, fn: () => void | Promise<void>) {
   }
 }
 
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
 function assertEqual<T>(actual: T, expected: T, message: string) {
   if (actual !== expected) {
     throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
@@ -163,6 +168,33 @@ async function runTests() {
     assertEqual(totalWins, gamesWithWinners, 'total wins should equal games with winners');
   });
 
+  await test('tallyWins: wins, games played and average winning turn', () => {
+    const structured = structureGames(splitConcatenatedGames(loadFixture()), deckNames);
+    const tally = tallyWins(structured, deckNames);
+    assertEqual(tally.gamesPlayed, 4, 'gamesPlayed');
+    assertEqual(tally.wins['Enduring Enchantments'], 2, 'Enduring wins');
+    assertEqual(tally.wins['Graveyard Shift'], 0, 'winless decks are listed');
+    assertEqual(tally.avgWinTurn['Graveyard Shift'], 0, 'no winning turn without wins');
+    assert(tally.avgWinTurn['Enduring Enchantments'] > 0, 'Enduring average winning turn');
+  });
+
+  await test('tallyWins: condensed and structured games tally the same', () => {
+    const games = splitConcatenatedGames(loadFixture());
+    const fromStructured = tallyWins(structureGames(games, deckNames), deckNames);
+    const fromCondensed = tallyWins(condenseGames(games), deckNames);
+    assertEqual(JSON.stringify(fromCondensed), JSON.stringify(fromStructured), 'same tally');
+  });
+
+  await test('tallyWins: a shared simultaneous win credits every winner', () => {
+    const tally = tallyWins(
+      [{ winner: 'Ai(1)-Doran Big Butts', simultaneousWinners: ['Ai(1)-Doran Big Butts', 'Ai(2)-Enduring Enchantments'], winningTurn: 6 }],
+      deckNames
+    );
+    assertEqual(tally.wins['Doran Big Butts'], 1, 'first winner');
+    assertEqual(tally.wins['Enduring Enchantments'], 1, 'second winner');
+    assertEqual(tally.avgWinTurn['Enduring Enchantments'], 6, 'winning turn for both');
+  });
+
   // =========================================================================
   // gamesCompleted derivation
   // =========================================================================
@@ -0,0 +1,66 @@
+/**
+ * =============================================================================
+ * Forge Log Analyzer - Win Tally
+ * =============================================================================
+ *
+ * Counts a job's games and each deck's wins and average winning turn. This
+ * is the one tally behind JobResults.wins / gamesPlayed / avgWinTurn and the
+ * win-rates aggregator (aggregators.ts), so the two can't disagree.
+ *
+ * Every deck in a job plays every game, so gamesPlayed is the game count.
+ * Winners are resolved against the deck names with resolveWinnerName; a
+ * shared simultaneous win (SIMULTANEOUS_WIN_SCORING=shared) credits every
+ * winner.
+ *
+ * =============================================================================
+ */
+
+import type { StructuredGame } from '../types';
+import { resolveWinnerName } from './deck-match';
+
+/** The fields a tally reads; condensed and structured games both have them. */
+export type TalliedGame = Pick<StructuredGame, 'winner' | 'simultaneousWinners' | 'winningTurn'>;
+
+export interface WinTally {
+  /** Games counted */
+  gamesPlayed: number;
+  /** Deck -> games won, with every deck listed */
+  wins: Record<string, number>;
+  /** Deck -> average winning turn to one decimal (0 without wins) */
+  avgWinTurn: Record<string, number>;
+}
+
+/**
+ * Tallies wins per deck.
+ *
+ * @param games - The job's counted games (low-signal games left out)
+ * @param deckNames - Deck names; winners are resolved against these
+ */
+export function tallyWins(games: readonly TalliedGame[], deckNames: string[]): WinTally {
+  const wins: Record<string, number> = {};
+  const turnSums: Record<string, number[]> = {};
+  for (const name of deckNames) {
+    wins[name] = 0;
+    turnSums[name] = [];
+  }
+
+  for (const game of games) {
+    if (!game.winner) continue;
+    for (const winner of game.simultaneousWinners ?? [game.winner]) {
+      const matched = resolveWinnerName(winner, deckNames);
+      wins[matched] = (wins[matched] ?? 0) + 1;
+      if (game.winningTurn) {
+        (turnSums[matched] ??= []).push(game.winningTurn);
+      }
+    }
+  }
+
+  const avgWinTurn: Record<string, number> = {};
+  for (const [name, turns] of Object.entries(turnSums)) {
+    avgWinTurn[name] = turns.length > 0
+      ? Math.round((turns.reduce((a, b) => a + b, 0) / turns.length) * 10) / 10
+      : 0;
+  }
+
+  return { gamesPlayed: games.length, wins, avgWinTurn };
+}
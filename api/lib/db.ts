@@ -218,6 +218,11 @@ export function getDb(): Database.Database {
   } catch {
     // Column already exists
   }
+  try {
+    db.exec(`ALTER TABLE simulations ADD COLUMN stage_durations_json TEXT`);
+  } catch {
+    // Column already exists
+  }
 
   // Worker heartbeat tracking table
   db.exec(`
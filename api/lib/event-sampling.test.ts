@@ -0,0 +1,162 @@
+/**
+ * Tests for per-deck sampling of the analysis payload's kept events.
+ *
+ * Run with: npx tsx lib/event-sampling.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import type { CondensedGame, GameEvent } from './types';
+import { condenseGames, splitConcatenatedGames } from './condenser/index';
+import { EVENT_SAMPLE_UNATTRIBUTED, resolveEventSampleOptions, sampleEventsPerDeck } from './event-sampling';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser/condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const DECK_NAMES = ['Alpha', 'Beta'];
+
+function makeGame(keptEvents: GameEvent[]): CondensedGame {
+  return { keptEvents, manaPerTurn: {}, cardsDrawnPerTurn: {}, turnCount: 5 };
+}
+
+const GAMES: CondensedGame[] = [
+  makeGame([
+    { type: 'land_played', line: 'Land: Ai(1)-Alpha played Forest (1)' },
+    { type: 'spell_cast', line: 'Add to stack: Ai(1)-Alpha cast Llanowar Elves (2)' },
+    { type: 'spell_cast', line: 'Add to stack: Ai(2)-Beta cast Wrath of God (20)' },
+    { type: 'creature_death', line: 'Resolve stack: Wrath of God (20) - Destroy all creatures. They can\'t be regenerated.' },
+    { type: 'spell_cast_high_cmc', line: 'Add to stack: Ai(1)-Alpha cast Craterhoof Behemoth (3)' },
+  ]),
+  makeGame([
+    { type: 'life_change', line: 'Ai(2)-Beta lost 10 life', player: 'Ai(2)-Beta' },
+    { type: 'win_condition', line: 'Game outcome: Ai(1)-Alpha has won because all opponents have lost' },
+  ]),
+];
+
+function lines(game: CondensedGame): string[] {
+  return game.keptEvents.map((e) => e.line);
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running event sampling tests...\n');
+
+  await test('sampleEventsPerDeck: keeps wins and high-CMC casts over lesser events', () => {
+    const [first, second] = sampleEventsPerDeck(GAMES, DECK_NAMES, { maxEventsPerDeck: 2 });
+    assertEqual(
+      JSON.stringify(lines(first)),
+      JSON.stringify([
+        'Add to stack: Ai(2)-Beta cast Wrath of God (20)',
+        'Resolve stack: Wrath of God (20) - Destroy all creatures. They can\'t be regenerated.',
+        'Add to stack: Ai(1)-Alpha cast Craterhoof Behemoth (3)',
+      ]),
+      "Alpha's land and Elves dropped; kept events stay in log order"
+    );
+    assertEqual(first.omittedEvents, 2, 'omitted in game 1');
+    assertEqual(second, GAMES[1], 'nothing dropped from game 2');
+  });
+
+  await test('sampleEventsPerDeck: respects the cap per deck, wipes before other events', () => {
+    const sampled = sampleEventsPerDeck(GAMES, DECK_NAMES, { maxEventsPerDeck: 1 });
+    assertEqual(
+      JSON.stringify(lines(sampled[1])),
+      JSON.stringify(['Ai(2)-Beta lost 10 life', 'Game outcome: Ai(1)-Alpha has won because all opponents have lost']),
+      'Alpha keeps its win, Beta its life change over its cast'
+    );
+    assertEqual(
+      JSON.stringify(lines(sampled[0])),
+      JSON.stringify(['Resolve stack: Wrath of God (20) - Destroy all creatures. They can\'t be regenerated.']),
+      'the unattributed board wipe is kept under its own cap; Beta already used its one event'
+    );
+    assertEqual(sampled[0].omittedEvents, 4, 'omitted in game 1');
+    assertEqual(EVENT_SAMPLE_UNATTRIBUTED, 'unknown', 'bucket name');
+  });
+
+  await test('sampleEventsPerDeck: real games stay under the cap and are deterministic', () => {
+    const rawLog = fs.readFileSync(path.join(__dirname, 'condenser', 'fixtures', 'real-4game-log.txt'), 'utf-8');
+    const games = condenseGames(splitConcatenatedGames(rawLog));
+    const deckNames = ['Doran Big Butts', 'Enduring Enchantments', 'Explorers of the Deep', 'Veloci-RAMP-Tor'];
+    const sampled = sampleEventsPerDeck(games, deckNames, { maxEventsPerDeck: 10 });
+    const kept = sampled.reduce((sum, g) => sum + g.keptEvents.length, 0);
+    const omitted = sampled.reduce((sum, g) => sum + (g.omittedEvents ?? 0), 0);
+    const total = games.reduce((sum, g) => sum + g.keptEvents.length, 0);
+    assertEqual(kept + omitted, total, 'every event kept or counted as omitted');
+    assert(kept <= 10 * (deckNames.length + 1), `at most 10 per deck plus unattributed, got ${kept}`);
+    const types = (gs: CondensedGame[]) => new Set(gs.flatMap((g) => g.keptEvents.map((e) => e.type)));
+    assert(types(games).has('land_played') && !types(sampled).has('land_played'), 'low-priority land drops are dropped');
+    assertEqual(JSON.stringify(sampleEventsPerDeck(games, deckNames, { maxEventsPerDeck: 10 })), JSON.stringify(sampled), 'deterministic');
+    assertEqual(games[0].omittedEvents, undefined, 'input is not mutated');
+  });
+
+  await test('resolveEventSampleOptions: reads MAX_EVENTS_PER_DECK', () => {
+    assertEqual(resolveEventSampleOptions({}), undefined, 'unset keeps every event');
+    assertEqual(resolveEventSampleOptions({ MAX_EVENTS_PER_DECK: '0' }), undefined, 'below 1 keeps every event');
+    assertEqual(resolveEventSampleOptions({ MAX_EVENTS_PER_DECK: '50' })?.maxEventsPerDeck, 50, 'cap');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
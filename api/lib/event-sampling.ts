@@ -0,0 +1,112 @@
+/**
+ * Per-deck sampling of the kept events in the analysis payload.
+ *
+ * Every kept event of a large job can blow the analysis model's token
+ * budget, while metrics alone (metrics-only) say nothing concrete about
+ * how a deck plays. With a cap set, the payload (`analyze-payload.json`)
+ * keeps at most N events per deck across the job's games, the deck's most
+ * significant ones:
+ *
+ *   1. win_condition events
+ *   2. board wipes (DETECT_BOARD_WIPE), whatever they classified as
+ *   3. spell_cast_high_cmc events
+ *   4. the rest, in classification priority order
+ *
+ * Ties go to the earlier game, then the earlier event, so the same games
+ * and cap always yield the same sample. Kept events stay in log order, and
+ * each game records how many of its events were dropped as
+ * `omittedEvents`.
+ *
+ * An event belongs to the deck of its `player`, or of the player named at
+ * the start of its line; events naming no deck share one more cap under
+ * EVENT_SAMPLE_UNATTRIBUTED. Repeated events (`repeat`) count once.
+ *
+ * Configure with MAX_EVENTS_PER_DECK=N. Unset, empty, or values below 1
+ * keep every event.
+ */
+
+import type { CondensedGame, EventType, GameEvent } from './types';
+import { DEFAULT_CLASSIFICATION_PRIORITY } from './condenser/classify';
+import { DETECT_BOARD_WIPE } from './condenser/patterns';
+import { linePlayerKey } from './condenser/colors';
+import { resolveWinnerName } from './condenser/deck-match';
+
+/** Cap bucket for events that name no deck. */
+export const EVENT_SAMPLE_UNATTRIBUTED = 'unknown';
+
+export interface EventSampleOptions {
+  /** Most events kept per deck across the job */
+  maxEventsPerDeck: number;
+}
+
+/**
+ * Reads the per-deck event cap from the environment.
+ *
+ * @returns The options, or undefined when every event is kept
+ */
+export function resolveEventSampleOptions(env: NodeJS.ProcessEnv = process.env): EventSampleOptions | undefined {
+  const parsed = parseInt(env.MAX_EVENTS_PER_DECK ?? '', 10);
+  return Number.isFinite(parsed) && parsed >= 1 ? { maxEventsPerDeck: parsed } : undefined;
+}
+
+const SAMPLE_PRIORITY: EventType[] = [
+  'spell_cast_high_cmc',
+  ...DEFAULT_CLASSIFICATION_PRIORITY.filter((t) => t !== 'win_condition' && t !== 'spell_cast_high_cmc'),
+];
+
+/** Lower ranks are kept first. */
+function eventRank(event: GameEvent): number {
+  if (event.type === 'win_condition') return 0;
+  if (DETECT_BOARD_WIPE.test(event.line)) return 1;
+  const index = SAMPLE_PRIORITY.indexOf(event.type);
+  return 2 + (index === -1 ? SAMPLE_PRIORITY.length : index);
+}
+
+/**
+ * Keeps each deck's most significant events, up to the cap.
+ *
+ * @param games - Condensed games, in job order
+ * @param deckNames - Deck names; event players are resolved against these
+ * @param options - The per-deck cap
+ * @returns New games with the sampled keptEvents and, where events were
+ *          dropped, `omittedEvents`. Games with nothing dropped are
+ *          returned as is.
+ */
+export function sampleEventsPerDeck(
+  games: CondensedGame[],
+  deckNames: string[],
+  options: EventSampleOptions
+): CondensedGame[] {
+  const deckKeys = Object.fromEntries(deckNames.map((name) => [name, [] as string[]]));
+  const deckOf = (event: GameEvent) =>
+    event.player
+      ? resolveWinnerName(event.player, deckNames)
+      : linePlayerKey(event.line, deckKeys) ?? EVENT_SAMPLE_UNATTRIBUTED;
+
+  const byDeck = new Map<string, { game: number; index: number; rank: number }[]>();
+  games.forEach((game, gameIndex) => {
+    game.keptEvents.forEach((event, index) => {
+      const deck = deckOf(event);
+      if (!byDeck.has(deck)) byDeck.set(deck, []);
+      byDeck.get(deck)!.push({ game: gameIndex, index, rank: eventRank(event) });
+    });
+  });
+
+  const kept = games.map(() => new Set<number>());
+  for (const events of byDeck.values()) {
+    events
+      .sort((a, b) => a.rank - b.rank || a.game - b.game || a.index - b.index)
+      .slice(0, options.maxEventsPerDeck)
+      .forEach(({ game, index }) => kept[game].add(index));
+  }
+
+  return games.map((game, i) => {
+    const omitted = game.keptEvents.length - kept[i].size;
+    if (omitted === 0) return game;
+    return {
+      ...game,
+      keptEvents: game.keptEvents.filter((_, index) => kept[i].has(index)),
+      omittedEvents: omitted,
+    };
+  });
+}
@@ -492,10 +492,12 @@ export async function claimJob(id: string, workerId?: string, workerName?: strin
  * uses a transaction to flip that sim to RUNNING (and promote the job from
  * QUEUED to RUNNING if needed). Transaction conflicts cause the loop to try
  * the next candidate, so concurrent workers do not collide on the same sim.
+ * Jobs in excludeJobIds are skipped.
  */
 export async function claimNextSim(
   workerId: string,
   workerName: string,
+  excludeJobIds: string[] = [],
 ): Promise<{ jobId: string; simId: string; simIndex: number } | null> {
   const candidates = await jobsCollection
     .where('status', 'in', ['QUEUED', 'RUNNING'])
@@ -506,6 +508,7 @@ export async function claimNextSim(
   for (const jobDoc of candidates.docs) {
     const jobData = jobDoc.data();
     if (jobData.source === 'flutter-local') continue;
+    if (excludeJobIds.includes(jobDoc.id)) continue;
     const completed = jobData.completedSimCount ?? 0;
     const total = jobData.totalSimCount ?? 0;
     if (total > 0 && completed >= total) continue;
@@ -658,6 +661,7 @@ export async function updateSimulationStatus(
   if (update.winningTurn !== undefined) updateData.winningTurn = update.winningTurn;
   if (update.winners !== undefined) updateData.winners = update.winners;
   if (update.winningTurns !== undefined) updateData.winningTurns = update.winningTurns;
+  if (update.stageDurationsMs !== undefined) updateData.stageDurationsMs = update.stageDurationsMs;
 
   await simulationsCollection(jobId).doc(simId).update(updateData);
 }
@@ -697,6 +701,7 @@ export async function conditionalUpdateSimulationStatus(
     if (update.winningTurn !== undefined) updateData.winningTurn = update.winningTurn;
     if (update.winners !== undefined) updateData.winners = update.winners;
     if (update.winningTurns !== undefined) updateData.winningTurns = update.winningTurns;
+    if (update.stageDurationsMs !== undefined) updateData.stageDurationsMs = update.stageDurationsMs;
 
     transaction.update(simRef, updateData);
     return true;
@@ -766,6 +771,7 @@ export async function getSimulationStatus(
     ...(data.winningTurn != null && { winningTurn: data.winningTurn }),
     ...(data.winners?.length > 0 && { winners: data.winners }),
     ...(data.winningTurns?.length > 0 && { winningTurns: data.winningTurns }),
+    ...(data.stageDurationsMs && { stageDurationsMs: data.stageDurationsMs }),
   } as SimulationStatus;
 }
 
@@ -795,6 +801,7 @@ export async function getSimulationStatuses(
       ...(data.winningTurn != null && { winningTurn: data.winningTurn }),
       ...(data.winners?.length > 0 && { winners: data.winners }),
       ...(data.winningTurns?.length > 0 && { winningTurns: data.winningTurns }),
+      ...(data.stageDurationsMs && { stageDurationsMs: data.stageDurationsMs }),
     } as SimulationStatus;
   });
 }
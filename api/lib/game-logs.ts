@@ -11,6 +11,9 @@
 
 import * as fs from 'fs';
 import * as path from 'path';
+import { createLogger } from './logger';
+
+const log = createLogger('GameLogs');
 
 /**
  * Parsed game log filename components.
@@ -115,28 +118,113 @@ export function countGameLogFiles(logsDir: string, jobId: string): number {
   return findGameLogFiles(logsDir, jobId).length;
 }
 
+/**
+ * Default number of log files read concurrently by readGameLogs().
+ * Bounded so a job with hundreds of files on a network volume doesn't
+ * exhaust file descriptors.
+ */
+export const DEFAULT_READ_CONCURRENCY = 8;
+
+/**
+ * Files read so far out of those found, reported while readGameLogs runs.
+ */
+export interface ReadProgress {
+  jobId: string;
+  read: number;
+  total: number;
+}
+
+/**
+ * How often readGameLogs reports progress. A report is made every
+ * `everyFiles` files or when `everyMs` has passed since the last one,
+ * whichever comes first, and once more when all files are read.
+ */
+export interface ReadProgressOptions {
+  everyFiles: number;
+  everyMs: number;
+  /**
+   * Called with each report, after it is logged (e.g. to update the job's
+   * progress). Errors are logged and don't stop the read.
+   */
+  onProgress?: (progress: ReadProgress) => void | Promise<void>;
+}
+
+export const DEFAULT_READ_PROGRESS: ReadProgressOptions = {
+  everyFiles: 500,
+  everyMs: 5000,
+};
+
+function reportProgress(progress: ReadProgress, options: ReadProgressOptions): void {
+  log.info('Reading game logs', { ...progress });
+  if (!options.onProgress) return;
+  const onError = (error: unknown) =>
+    log.warn('Read progress callback failed', {
+      jobId: progress.jobId,
+      error: error instanceof Error ? error.message : String(error),
+    });
+  try {
+    const pending = options.onProgress(progress);
+    if (pending) pending.catch(onError);
+  } catch (error) {
+    onError(error);
+  }
+}
+
 /**
  * Reads all game log files for a job.
  *
+ * Files are read in parallel by a bounded pool of workers, but the returned
+ * array always follows the sorted (runIndex, gameNumber) file order. Files
+ * that fail to read are logged and skipped; empty files are skipped.
+ *
+ * On a slow volume a job with thousands of files can take a while to read,
+ * so progress (files read / found) is logged periodically; see
+ * ReadProgressOptions.
+ *
  * @param logsDir - Directory containing log files
  * @param jobId - The job ID to filter by
+ * @param concurrency - Max files read at once (default: DEFAULT_READ_CONCURRENCY)
+ * @param progress - Progress interval and callback (default: DEFAULT_READ_PROGRESS)
  * @returns Array of log contents, sorted by (runIndex, gameNumber)
  */
-export function readGameLogs(logsDir: string, jobId: string): string[] {
-  const logs: string[] = [];
-
-  try {
-    const logFiles = findGameLogFiles(logsDir, jobId);
+export async function readGameLogs(
+  logsDir: string,
+  jobId: string,
+  concurrency: number = DEFAULT_READ_CONCURRENCY,
+  progress: ReadProgressOptions = DEFAULT_READ_PROGRESS
+): Promise<string[]> {
+  const logFiles = findGameLogFiles(logsDir, jobId);
+  const contents: (string | null)[] = new Array(logFiles.length).fill(null);
+  const total = logFiles.length;
+  let read = 0;
+  let lastReportAt = Date.now();
 
-    for (const file of logFiles) {
-      const content = fs.readFileSync(path.join(logsDir, file), 'utf-8');
-      if (content.trim()) {
-        logs.push(content);
+  // Each worker pulls the next unread index, so slots are filled in place
+  // and the output order never depends on which read finishes first.
+  let next = 0;
+  const worker = async () => {
+    while (next < logFiles.length) {
+      const index = next++;
+      const file = logFiles[index];
+      try {
+        contents[index] = await fs.promises.readFile(path.join(logsDir, file), 'utf-8');
+      } catch (error) {
+        console.error(`[GameLogs] Error reading ${file}:`, error);
+      }
+      read++;
+      const now = Date.now();
+      if (read < total && (read % progress.everyFiles === 0 || now - lastReportAt >= progress.everyMs)) {
+        lastReportAt = now;
+        reportProgress({ jobId, read, total }, progress);
       }
     }
-  } catch (error) {
-    console.error(`[GameLogs] Error reading logs:`, error);
+  };
+
+  const poolSize = Math.max(1, Math.min(concurrency, logFiles.length));
+  await Promise.all(Array.from({ length: poolSize }, worker));
+  if (total > 0) {
+    reportProgress({ jobId, read, total }, progress);
   }
 
-  return logs;
+  return contents.filter((c): c is string => c !== null && c.trim() !== '');
 }
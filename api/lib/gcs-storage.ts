@@ -1,6 +1,12 @@
 import { Storage } from '@google-cloud/storage';
 import { isRetryableGcsError } from './gcs-retry';
 import { withRetry } from './retry';
+import { describeArtifact, type UploadedArtifact } from './artifact-manifest';
+import { ARTIFACT_WRITE_RETRY, artifactMetadataFromEnv, resolveStorageClassPolicy, writeArtifact } from './artifact-write';
+import { readArtifact, isArtifactNotFound } from './artifact-read';
+import { createJobPrefixResolver, resolvePathLayout } from './artifact-path';
+
+export { ArtifactNotFoundError, isArtifactNotFound } from './artifact-read';
 
 // Initialize Cloud Storage client
 const storage = new Storage({
@@ -10,63 +16,75 @@ const storage = new Storage({
 const BUCKET_NAME = process.env.GCS_BUCKET || 'magic-bracket-simulator-artifacts';
 const bucket = storage.bucket(BUCKET_NAME);
 
+// jobs/<id>/ or, with PATH_LAYOUT=dated, jobs/YYYY/MM/DD/<id>/ (see artifact-path.ts)
+const jobPrefix = createJobPrefixResolver(resolvePathLayout(), async (jobId) => {
+  const { getJob } = await import('./job-store-factory');
+  return (await getJob(jobId))?.createdAt;
+});
+
 /**
  * Upload a job artifact to GCS
  * @param jobId The job ID
  * @param filename The filename (e.g., 'condensed.json', 'raw/game_001.txt')
  * @param data The data to upload (string or Buffer)
- * @returns The GCS URI of the uploaded file
+ * @param metadata Extra object metadata, merged over the SIM_VERSION / ENV /
+ *   GIT_SHA tags from the environment. `jobId` is always the job's ID.
+ *   The storage class comes from ARTIFACT_STORAGE_CLASSES.
+ * @returns The uploaded artifact (GCS URI, content type, size, sha256)
  */
 export async function uploadJobArtifact(
   jobId: string,
   filename: string,
-  data: string | Buffer
-): Promise<string> {
-  const objectPath = `jobs/${jobId}/${filename}`;
-
-  const contentType = filename.endsWith('.json')
-    ? 'application/json'
-    : filename.endsWith('.txt')
-    ? 'text/plain'
-    : 'application/octet-stream';
-
-  await withRetry(
-    async () => {
-      const file = bucket.file(objectPath);
-      await file.save(data, {
-        contentType,
-        metadata: { jobId },
-      });
-    },
-    { maxAttempts: 3, delayMs: 1000, backoffMultiplier: 2 },
-    `GCS upload ${filename}`,
-    isRetryableGcsError
+  data: string | Buffer,
+  metadata?: Record<string, string>
+): Promise<UploadedArtifact> {
+  const objectPath = `${await jobPrefix(jobId)}${filename}`;
+  await writeArtifact(
+    bucket.file(objectPath),
+    filename,
+    data,
+    jobId,
+    { ...artifactMetadataFromEnv(), ...metadata },
+    ARTIFACT_WRITE_RETRY,
+    resolveStorageClassPolicy()
   );
 
-  return `gs://${BUCKET_NAME}/${objectPath}`;
+  return describeArtifact(filename, `gs://${BUCKET_NAME}/${objectPath}`, data);
+}
+
+/**
+ * Read a job artifact from GCS, retrying transient errors
+ * @param jobId The job ID
+ * @param filename The filename (e.g., 'condensed.json')
+ * @returns The file contents as a string (may be empty)
+ * @throws ArtifactNotFoundError if the artifact does not exist
+ */
+export async function readJobArtifact(
+  jobId: string,
+  filename: string
+): Promise<string> {
+  const objectPath = `${await jobPrefix(jobId)}${filename}`;
+  return readArtifact(bucket.file(objectPath), objectPath);
 }
 
 /**
  * Get a job artifact from GCS
+ *
+ * Compatibility wrapper around readJobArtifact for callers that treat a
+ * missing or unreadable artifact the same way.
  * @param jobId The job ID
  * @param filename The filename (e.g., 'condensed.json')
- * @returns The file contents as a string, or null if not found
+ * @returns The file contents as a string, or null if not found or unreadable
  */
 export async function getJobArtifact(
   jobId: string,
   filename: string
 ): Promise<string | null> {
-  const objectPath = `jobs/${jobId}/${filename}`;
-  const file = bucket.file(objectPath);
-
   try {
-    const [exists] = await file.exists();
-    if (!exists) return null;
-
-    const [contents] = await file.download();
-    return contents.toString('utf-8');
+    return await readJobArtifact(jobId, filename);
   } catch (error) {
-    console.error(`Error downloading ${objectPath}:`, error);
+    if (isArtifactNotFound(error)) return null;
+    console.error(`Error downloading ${filename} for job ${jobId}:`, error);
     return null;
   }
 }
@@ -82,7 +100,7 @@ export async function getJobArtifactJson<T>(
   filename: string
 ): Promise<T | null> {
   const contents = await getJobArtifact(jobId, filename);
-  if (!contents) return null;
+  if (contents === null) return null;
 
   try {
     return JSON.parse(contents) as T;
@@ -98,7 +116,7 @@ export async function getJobArtifactJson<T>(
  * @returns Array of filenames
  */
 export async function listJobArtifacts(jobId: string): Promise<string[]> {
-  const prefix = `jobs/${jobId}/`;
+  const prefix = await jobPrefix(jobId);
   const [files] = await bucket.getFiles({ prefix });
   
   return files.map(file => file.name.replace(prefix, ''));
@@ -107,9 +125,11 @@ export async function listJobArtifacts(jobId: string): Promise<string[]> {
 /**
  * Delete all artifacts for a job
  * @param jobId The job ID
+ * @param createdAt The job's creation date, for PATH_LAYOUT=dated when the
+ *   job itself may already be deleted
  */
-export async function deleteJobArtifacts(jobId: string): Promise<void> {
-  const prefix = `jobs/${jobId}/`;
+export async function deleteJobArtifacts(jobId: string, createdAt?: Date): Promise<void> {
+  const prefix = await jobPrefix(jobId, createdAt);
   
   try {
     await bucket.deleteFiles({ prefix, force: true });
@@ -123,12 +143,12 @@ export async function deleteJobArtifacts(jobId: string): Promise<void> {
  * Upload multiple raw game logs
  * @param jobId The job ID
  * @param logs Array of raw game log contents
- * @returns Array of GCS URIs
+ * @returns The uploaded artifacts, in log order
  */
 export async function uploadRawLogs(
   jobId: string,
   logs: string[]
-): Promise<string[]> {
+): Promise<UploadedArtifact[]> {
   const uploads = logs.map((log, index) => {
     const filename = `raw/game_${String(index + 1).padStart(3, '0')}.txt`;
     return uploadJobArtifact(jobId, filename, log);
@@ -167,7 +187,7 @@ export async function getSignedUrl(
   filename: string,
   expiresInMinutes: number = 15
 ): Promise<string> {
-  const objectPath = `jobs/${jobId}/${filename}`;
+  const objectPath = `${await jobPrefix(jobId)}${filename}`;
   const file = bucket.file(objectPath);
 
   const [url] = await file.getSignedUrl({
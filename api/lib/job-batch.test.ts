@@ -0,0 +1,97 @@
+/**
+ * Tests for batch job processing (job IDs file, bounded pool, failure summary).
+ * Run with: npx tsx lib/job-batch.test.ts
+ */
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { parseJobIds, readJobIdsFile, runJobBatch, formatBatchSummary } from './job-batch';
+
+interface TestResult { name: string; passed: boolean; error?: string; }
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+async function runTests() {
+  console.log('Running job-batch tests...\n');
+
+  await test('parseJobIds skips blanks and comments and drops repeats', () => {
+    const ids = parseJobIds('job-a\n\n# header\njob-b  # retry\r\njob-a\n  job-c  \n');
+    assert(JSON.stringify(ids) === '["job-a","job-b","job-c"]', `got ${JSON.stringify(ids)}`);
+  });
+
+  await test('one failing job does not stop the other two', async () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'job-batch-'));
+    const file = path.join(dir, 'jobs.txt');
+    fs.writeFileSync(file, 'job-1\njob-2\njob-3\n');
+    try {
+      const processed: string[] = [];
+      const summary = await runJobBatch(readJobIdsFile(file), async (jobId) => {
+        if (jobId === 'job-2') throw new Error('no raw logs stored');
+        processed.push(jobId);
+      });
+      assert(JSON.stringify(processed) === '["job-1","job-3"]', `processed ${JSON.stringify(processed)}`);
+      assert(JSON.stringify(summary.succeeded) === '["job-1","job-3"]', `succeeded ${JSON.stringify(summary.succeeded)}`);
+      assert(summary.failed.length === 1, `expected 1 failure, got ${summary.failed.length}`);
+      assert(summary.failed[0].jobId === 'job-2', `failed ${summary.failed[0].jobId}`);
+      assert(summary.failed[0].error === 'no raw logs stored', `error ${summary.failed[0].error}`);
+
+      const text = formatBatchSummary(summary);
+      assert(text.includes('3 job(s): 2 succeeded, 1 failed'), `summary: ${text}`);
+      assert(text.includes('FAILED job-2: no raw logs stored'), `summary: ${text}`);
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  await test('concurrency bounds jobs in flight and keeps input order', async () => {
+    let inFlight = 0;
+    let peak = 0;
+    const ids = ['a', 'b', 'c', 'd', 'e'];
+    const summary = await runJobBatch(ids, async (jobId) => {
+      inFlight++;
+      peak = Math.max(peak, inFlight);
+      await new Promise((resolve) => setTimeout(resolve, jobId === 'a' ? 20 : 5));
+      inFlight--;
+      if (jobId === 'd') throw 'plain string';
+    }, 2);
+    assert(peak === 2, `peak ${peak}`);
+    assert(JSON.stringify(summary.succeeded) === '["a","b","c","e"]', `succeeded ${JSON.stringify(summary.succeeded)}`);
+    assert(summary.failed[0].error === 'plain string', `error ${summary.failed[0]?.error}`);
+  });
+
+  await test('default runs jobs one at a time', async () => {
+    let inFlight = 0;
+    let peak = 0;
+    await runJobBatch(['x', 'y', 'z'], async () => {
+      inFlight++;
+      peak = Math.max(peak, inFlight);
+      await new Promise((resolve) => setTimeout(resolve, 1));
+      inFlight--;
+    });
+    assert(peak === 1, `peak ${peak}`);
+  });
+
+  // ── Summary ───────────────────────────────────────────────
+  const passed = results.filter(r => r.passed).length;
+  const failed = results.filter(r => !r.passed).length;
+  console.log(`\n${passed} passed, ${failed} failed`);
+  if (failed > 0) process.exit(1);
+}
+
+runTests();
@@ -0,0 +1,91 @@
+/**
+ * Runs one operation over many jobs, for backfills and batch reprocessing.
+ *
+ * Job IDs come from a file (one per line). Jobs are processed by a bounded
+ * pool, and a failing job is recorded instead of aborting the batch, so one
+ * bad job doesn't stop a backfill of hundreds.
+ */
+import * as fs from 'fs';
+
+/** Jobs processed at once unless overridden (sequential). */
+export const DEFAULT_BATCH_CONCURRENCY = 1;
+
+export interface JobBatchFailure {
+  jobId: string;
+  error: string;
+}
+
+export interface JobBatchSummary {
+  /** Jobs that completed, in input order */
+  succeeded: string[];
+  /** Jobs that threw, in input order */
+  failed: JobBatchFailure[];
+}
+
+/**
+ * Parses a job IDs file: one ID per line. Blank lines and `#` comments are
+ * skipped, and repeated IDs are kept once.
+ */
+export function parseJobIds(text: string): string[] {
+  const ids = text
+    .split(/\r?\n/)
+    .map((line) => line.replace(/#.*$/, '').trim())
+    .filter((line) => line.length > 0);
+  return [...new Set(ids)];
+}
+
+/** Reads and parses a job IDs file (see parseJobIds). */
+export function readJobIdsFile(filePath: string): string[] {
+  return parseJobIds(fs.readFileSync(filePath, 'utf-8'));
+}
+
+/**
+ * Runs `processJob` for every job, at most `concurrency` at a time.
+ * Never rejects: each job's error is recorded in the summary.
+ *
+ * @param jobIds - Jobs to process
+ * @param processJob - The per-job operation
+ * @param concurrency - Max jobs in flight (default: DEFAULT_BATCH_CONCURRENCY)
+ * @returns Which jobs succeeded and which failed, each in input order
+ */
+export async function runJobBatch(
+  jobIds: string[],
+  processJob: (jobId: string) => Promise<void>,
+  concurrency: number = DEFAULT_BATCH_CONCURRENCY
+): Promise<JobBatchSummary> {
+  const outcomes: (JobBatchFailure | null)[] = new Array(jobIds.length).fill(null);
+
+  // Same pool shape as readGameLogs: each worker pulls the next index
+  let next = 0;
+  const worker = async () => {
+    while (next < jobIds.length) {
+      const index = next++;
+      try {
+        await processJob(jobIds[index]);
+      } catch (error) {
+        outcomes[index] = {
+          jobId: jobIds[index],
+          error: error instanceof Error ? error.message : String(error),
+        };
+      }
+    }
+  };
+
+  const poolSize = Math.max(1, Math.min(concurrency, jobIds.length));
+  await Promise.all(Array.from({ length: poolSize }, worker));
+
+  return {
+    succeeded: jobIds.filter((_, i) => outcomes[i] === null),
+    failed: outcomes.filter((o): o is JobBatchFailure => o !== null),
+  };
+}
+
+/** One-line-per-failure summary for the end of a batch run. */
+export function formatBatchSummary(summary: JobBatchSummary): string {
+  const total = summary.succeeded.length + summary.failed.length;
+  const lines = [`Processed ${total} job(s): ${summary.succeeded.length} succeeded, ${summary.failed.length} failed.`];
+  for (const { jobId, error } of summary.failed) {
+    lines.push(`  FAILED ${jobId}: ${error}`);
+  }
+  return lines.join('\n');
+}
@@ -174,6 +174,70 @@ async function runTests() {
       }
     });
 
+    await test('job stats come from the stored games, not the raw logs', async () => {
+      const jobId = createTestJob(1);
+      try {
+        jobStore.updateJobStatus(jobId, 'RUNNING');
+        jobStore.initializeSimulations(jobId, 1);
+        jobStore.updateSimulationStatus(jobId, 'sim_000', { state: 'COMPLETED' });
+        await uploadRawLogs(jobId, 4);
+        await aggregateJobResults(jobId);
+        const first = jobStore.getJob(jobId)!.results!;
+        assert((first.tempoCurve?.length ?? 0) > 0, 'tempoCurve should be set');
+        assert(first.aggressionIndex !== undefined, 'aggressionIndex should be set');
+
+        // Re-aggregate with the raw logs gone: the stats must not go empty
+        const jobDir = path.join(tempDir, jobId);
+        for (const f of fs.readdirSync(jobDir).filter((f) => /^game_\d+\.txt$/.test(f))) {
+          fs.unlinkSync(path.join(jobDir, f));
+        }
+        jobStore.updateJobStatus(jobId, 'RUNNING');
+        await aggregateJobResults(jobId);
+        const again = jobStore.getJob(jobId)!.results!;
+        assertEqual(JSON.stringify(again.tempoCurve), JSON.stringify(first.tempoCurve), 'tempoCurve');
+        assertEqual(JSON.stringify(again.archenemyCounts), JSON.stringify(first.archenemyCounts), 'archenemyCounts');
+        assertEqual(JSON.stringify(again.aggressionIndex), JSON.stringify(first.aggressionIndex), 'aggressionIndex');
+      } finally {
+        cleanup(jobId);
+      }
+    });
+
+    await test('low-signal game is stored but left out of the win-rate denominator', async () => {
+      const jobId = createTestJob(1);
+      try {
+        jobStore.updateJobStatus(jobId, 'RUNNING');
+        jobStore.initializeSimulations(jobId, 1);
+        jobStore.updateSimulationStatus(jobId, 'sim_000', { state: 'COMPLETED' });
+        await uploadRawLogs(jobId, 4);
+        const trivialGame = [
+          'Turn 1: Ai(1)-Deck A',
+          'Land: Ai(1)-Deck A played Forest (1)',
+          'Game outcome: Ai(1)-Deck A has won because all opponents have lost',
+          'Game outcome: Ai(2)-Deck B has conceded',
+          'Game outcome: Ai(3)-Deck C has conceded',
+          'Game outcome: Ai(4)-Deck D has conceded',
+        ].join('\n');
+        await logStore.uploadSingleSimulationLog(jobId, 'raw/game_005.txt', trivialGame);
+        await aggregateJobResults(jobId);
+
+        const job = jobStore.getJob(jobId);
+        assertEqual(job!.status, 'COMPLETED', 'job should be COMPLETED');
+        assertEqual(job!.results?.gamesPlayed, 4, 'gamesPlayed should exclude the 1-turn game');
+        assertEqual(job!.results?.lowSignalGames, 1, 'lowSignalGames');
+        assertEqual(job!.results?.wins['Deck A'], 0, 'the 1-turn win should not count');
+        const totalWins = Object.values(job!.results!.wins).reduce((a, b) => a + b, 0);
+        assert(totalWins <= 4, `wins should come from the 4 counted games, got ${totalWins}`);
+
+        const meta = JSON.parse(fs.readFileSync(path.join(tempDir, jobId, 'meta.json'), 'utf-8'));
+        assertEqual(meta.condensed.length, 5, 'condensed should still have every game');
+        assertEqual(meta.condensed[4].lowSignal, true, 'the 1-turn game should be flagged');
+        assertEqual(meta.condensed.filter((g: { lowSignal?: boolean }) => g.lowSignal).length, 1, 'only the 1-turn game is flagged');
+        assertEqual(meta.structured[4].lowSignal, true, 'the structured game should be flagged too');
+      } finally {
+        cleanup(jobId);
+      }
+    });
+
     await test('CANCELLED job with completed sims → logs ingested but status stays CANCELLED', async () => {
       const jobId = createTestJob(2);
       try {
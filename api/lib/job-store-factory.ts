@@ -9,6 +9,8 @@ import * as workerStore from './worker-store-factory';
 import { cancelRecoveryCheck } from './cloud-tasks';
 import * as Sentry from '@sentry/nextjs';
 import { createLogger } from './logger';
+import { ArtifactSchemaError, validateJobResults } from './artifact-schema';
+import { clearLiveEvents } from './live-events';
 
 import { USE_FIRESTORE, isGcpMode } from './env';
 
@@ -188,17 +190,18 @@ export interface ClaimedSim {
 /**
  * Atomically claim the next PENDING simulation across all active jobs.
  * Flips the sim to RUNNING with the caller's workerId, and flips the job
- * from QUEUED to RUNNING if this is the first claim against it. Returns
- * null when no work is available.
+ * from QUEUED to RUNNING if this is the first claim against it. Sims of
+ * jobs in excludeJobIds are skipped. Returns null when no work is available.
  */
 export async function claimNextSim(
   workerId: string,
   workerName: string,
+  excludeJobIds: string[] = [],
 ): Promise<ClaimedSim | null> {
   if (USE_FIRESTORE) {
-    return firestoreStore.claimNextSim(workerId, workerName);
+    return firestoreStore.claimNextSim(workerId, workerName, excludeJobIds);
   }
-  return (await sqliteStore()).claimNextSim(workerId, workerName) ?? null;
+  return (await sqliteStore()).claimNextSim(workerId, workerName, excludeJobIds) ?? null;
 }
 
 export async function cancelJob(id: string): Promise<boolean> {
@@ -558,11 +561,49 @@ export function deriveJobStatus(simulations: SimulationStatus[]): JobStatus | nu
   return 'RUNNING';
 }
 
+/**
+ * Looks up each deck's color identity by deck ID, keyed by deck name, so
+ * condensed events can be tagged with player colors. Lookup failures are
+ * non-fatal; returns undefined when no colors are known.
+ */
+async function resolveDeckColors(
+  deckIds: string[] | undefined,
+  deckNames: string[]
+): Promise<Record<string, string[]> | undefined> {
+  if (!Array.isArray(deckIds) || deckIds.length !== deckNames.length) return undefined;
+  const { getDeckById } = await import('./deck-store-factory');
+  const colors: Record<string, string[]> = {};
+  await Promise.all(
+    deckIds.map(async (id, i) => {
+      try {
+        const deck = await getDeckById(id);
+        if (deck?.colorIdentity?.length) colors[deckNames[i]] = deck.colorIdentity;
+      } catch {
+        // Colors are cosmetic; aggregate without them
+      }
+    })
+  );
+  return Object.keys(colors).length > 0 ? colors : undefined;
+}
+
+/**
+ * Fails the job when `err` is an artifact schema violation, since retrying
+ * would only produce the same bad output. Returns false for other errors.
+ */
+async function failOnSchemaViolation(jobId: string, err: unknown): Promise<boolean> {
+  if (!(err instanceof ArtifactSchemaError)) return false;
+  log.error('Artifact schema violation', { jobId, artifact: err.artifact, issues: err.issues });
+  await setJobFailed(jobId, err.message);
+  await setNeedsAggregation(jobId, false);
+  return true;
+}
+
 /**
  * Aggregate results when all simulations are COMPLETED or CANCELLED.
  * FAILED sims are NOT considered terminal — they will be retried by the scanner.
  * Reads incrementally uploaded raw logs, runs ingestion (condense + structure),
- * and sets the job to COMPLETED or CANCELLED.
+ * and sets the job to COMPLETED or CANCELLED, or FAILED when the condensed
+ * artifact or the results don't match their schema (see artifact-schema.ts).
  */
 export async function aggregateJobResults(jobId: string): Promise<void> {
   const sims = await getSimulationStatuses(jobId);
@@ -580,52 +621,134 @@ export async function aggregateJobResults(jobId: string): Promise<void> {
   await setNeedsAggregation(jobId, true);
 
   // Read raw logs uploaded incrementally by workers
-  const { getRawLogs, ingestLogs, getStructuredLogs } = await import('./log-store');
+  const { getRawLogs, ingestLogs, getStructuredLogs, getCondensedLogs } = await import('./log-store');
   const rawLogs = await getRawLogs(jobId);
 
   const deckNames = job.decks.map(d => d.name);
+  let deadLetterCount = 0;
+  let duplicateCount = 0;
   if (rawLogs && rawLogs.length > 0) {
     const deckLists = job.decks.map(d => d.dck ?? '');
-    await ingestLogs(jobId, rawLogs, deckNames, deckLists);
+    const playerColors = await resolveDeckColors(job.deckIds, deckNames);
+    try {
+      ({ deadLetterCount, duplicateCount } = await ingestLogs(jobId, rawLogs, deckNames, deckLists, playerColors));
+    } catch (err) {
+      if (await failOnSchemaViolation(jobId, err)) return;
+      throw err;
+    }
   }
 
-  // Load structured games for results computation and per-deck win stats
+  // The condensed artifact now covers what workers streamed live
+  clearLiveEvents(jobId);
+
+  // Load structured games for results computation and per-deck win stats,
+  // and condensed games for the per-round and targeting stats
   const structuredData = await getStructuredLogs(jobId);
+  const condensedGames = (await getCondensedLogs(jobId))?.filter((g) => !g.lowSignal) ?? [];
 
   // Compute aggregated results from structured games
   if (structuredData?.games?.length) {
-    const { matchesDeckName } = await import('./condenser/deck-match');
-    const results: JobResults = { wins: {}, avgWinTurn: {}, gamesPlayed: structuredData.games.length };
-    const turnSums: Record<string, number[]> = {};
+    const { resolveWinnerName } = await import('./condenser/deck-match');
+    const { sampleConfidence } = await import('./condenser/confidence');
+    const { tallyWins } = await import('./condenser/win-tally');
+    // Low-signal games stay in the artifacts but don't count toward any statistic
+    const counted = structuredData.games.flatMap((g, i) => (g.lowSignal ? [] : [i]));
+    const games = counted.map((i) => structuredData.games[i]);
+    const { wins, avgWinTurn, gamesPlayed } = tallyWins(games, deckNames);
+    const results: JobResults = {
+      wins,
+      avgWinTurn,
+      gamesPlayed,
+      confidence: sampleConfidence(gamesPlayed).label,
+    };
+    if (counted.length < structuredData.games.length) {
+      results.lowSignalGames = structuredData.games.length - counted.length;
+    }
+    if (deadLetterCount > 0) results.deadLetterCount = deadLetterCount;
+    if (duplicateCount > 0) results.duplicateGames = duplicateCount;
+
+    const { explosivenessScore, ritualsPerGame } = await import('./condenser/explosiveness');
+    results.explosiveness = {};
+    results.ritualsPerGame = {};
     for (const name of deckNames) {
-      results.wins[name] = 0;
-      results.avgWinTurn[name] = 0;
-      turnSums[name] = [];
+      results.explosiveness[name] = explosivenessScore(name, games);
+      results.ritualsPerGame[name] = ritualsPerGame(name, games);
     }
 
-    for (const game of structuredData.games) {
-      if (game.winner) {
-        const matched = deckNames.find(n => matchesDeckName(game.winner!, n)) ?? game.winner;
-        results.wins[matched] = (results.wins[matched] ?? 0) + 1;
-        if (game.winningTurn) {
-          if (!turnSums[matched]) turnSums[matched] = [];
-          turnSums[matched].push(game.winningTurn);
-        }
+    const { winReasonCounts } = await import('./condenser/win-reason');
+    results.winReasonCounts = winReasonCounts(games);
+
+    const { turnCountPercentiles, averageFirstBloodTurn } = await import('./condenser/turn-stats');
+    const percentiles = turnCountPercentiles(games);
+    if (percentiles) results.turnCountPercentiles = percentiles;
+    results.avgFirstBloodTurn = averageFirstBloodTurn(games);
+    const { jobTempoCurve } = await import('./condenser/tempo');
+    const tempoCurve = jobTempoCurve(condensedGames);
+    if (tempoCurve.length > 0) results.tempoCurve = tempoCurve;
+
+    const { selectRepresentativeGames } = await import('./condenser/representative');
+    // Indices refer to the job's games, not just the counted ones
+    const representative = selectRepresentativeGames(games);
+    results.representativeGames = Object.fromEntries(
+      Object.entries(representative).map(([bucket, i]) => [bucket, counted[i]])
+    ) as typeof representative;
+
+    results.comebackWins = Object.fromEntries(deckNames.map((name) => [name, 0]));
+    for (const game of games) {
+      if (!game.comebackWin || !game.winner) continue;
+      const matched = resolveWinnerName(game.winner, deckNames);
+      results.comebackWins[matched] = (results.comebackWins[matched] ?? 0) + 1;
+    }
+
+    const profiles: Record<string, Set<string>> = {};
+    for (const game of games) {
+      for (const [player, profile] of Object.entries(game.aiProfiles ?? {})) {
+        const matched = resolveWinnerName(player, deckNames);
+        (profiles[matched] ??= new Set()).add(profile);
       }
     }
-    for (const [name, turns] of Object.entries(turnSums)) {
-      results.avgWinTurn[name] = turns.length > 0
-        ? Math.round((turns.reduce((a, b) => a + b, 0) / turns.length) * 10) / 10
-        : 0;
+    if (Object.keys(profiles).length > 0) {
+      results.aiProfiles = Object.fromEntries(
+        Object.entries(profiles).map(([name, set]) => [name, [...set].sort()])
+      );
     }
 
+    const { archenemyCounts } = await import('./condenser/archenemy');
+    const { aggressionIndex } = await import('./condenser/aggression');
+    const archenemies = archenemyCounts(condensedGames, deckNames);
+    if (Object.values(archenemies).some((n) => n > 0)) results.archenemyCounts = archenemies;
+    if (condensedGames.length > 0) results.aggressionIndex = aggressionIndex(condensedGames, deckNames);
+
+    const keptSizes: Record<string, number[]> = {};
+    for (const game of games) {
+      for (const [player, info] of Object.entries(game.mulliganDetails ?? {})) {
+        const matched = resolveWinnerName(player, deckNames);
+        (keptSizes[matched] ??= []).push(info.keptHandSize);
+      }
+    }
+    if (Object.keys(keptSizes).length > 0) {
+      results.avgKeptHandSize = Object.fromEntries(
+        Object.entries(keptSizes).map(([name, sizes]) => [
+          name,
+          Math.round((sizes.reduce((a, b) => a + b, 0) / sizes.length) * 10) / 10,
+        ])
+      );
+    }
+
+    try {
+      validateJobResults(results);
+    } catch (err) {
+      if (await failOnSchemaViolation(jobId, err)) return;
+      throw err;
+    }
     await setJobResults(jobId, results);
   }
 
   // Update per-deck win/game counters for jobs with 4 resolved deck IDs
-  if (Array.isArray(job.deckIds) && job.deckIds.length === 4 && structuredData?.games?.length) {
+  const ratedGames = structuredData?.games?.filter((g) => !g.lowSignal) ?? [];
+  if (Array.isArray(job.deckIds) && job.deckIds.length === 4 && ratedGames.length > 0) {
     const { processJobForRatings } = await import('./trueskill-service');
-    processJobForRatings(jobId, job.deckIds, structuredData.games).catch((err) => {
+    processJobForRatings(jobId, job.deckIds, ratedGames).catch((err) => {
       log.error('Rating stats update failed (non-fatal)', { jobId, error: err instanceof Error ? err.message : String(err) });
       Sentry.captureException(err, { tags: { component: 'rating-stats', jobId } });
     });
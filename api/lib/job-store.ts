@@ -243,16 +243,21 @@ export function getNextQueuedJob(): Job | undefined {
  * Atomically claim the next PENDING simulation across any active (QUEUED or
  * RUNNING) job, ordered oldest-job-first then lowest-sim-index-first. Flips
  * the sim to RUNNING with workerId/workerName/startedAt, and promotes the
- * job to RUNNING if this is its first claim. Returns undefined when no work
- * is available. Entire operation runs in a single SQLite transaction, so
- * concurrent workers cannot claim the same sim.
+ * job to RUNNING if this is its first claim. Sims of jobs in excludeJobIds
+ * are skipped. Returns undefined when no work is available. Entire operation
+ * runs in a single SQLite transaction, so concurrent workers cannot claim
+ * the same sim.
  */
 export function claimNextSim(
   workerId: string,
   workerName: string,
+  excludeJobIds: string[] = [],
 ): { jobId: string; simId: string; simIndex: number } | undefined {
   const db = getDb();
   const nowIso = new Date().toISOString();
+  const excludeClause = excludeJobIds.length > 0
+    ? ` AND s.job_id NOT IN (${excludeJobIds.map(() => '?').join(', ')})`
+    : '';
 
   const tx = db.transaction(() => {
     const row = db
@@ -260,11 +265,11 @@ export function claimNextSim(
         `SELECT s.job_id AS job_id, s.sim_id AS sim_id, s.idx AS idx, j.status AS job_status
          FROM simulations s
          JOIN jobs j ON j.id = s.job_id
-         WHERE s.state = 'PENDING' AND j.status IN ('QUEUED', 'RUNNING') AND (j.source IS NULL OR j.source != 'flutter-local')
+         WHERE s.state = 'PENDING' AND j.status IN ('QUEUED', 'RUNNING') AND (j.source IS NULL OR j.source != 'flutter-local')${excludeClause}
          ORDER BY j.created_at ASC, s.idx ASC
          LIMIT 1`,
       )
-      .get() as { job_id: string; sim_id: string; idx: number; job_status: string } | undefined;
+      .get(...excludeJobIds) as { job_id: string; sim_id: string; idx: number; job_status: string } | undefined;
 
     if (!row) return undefined;
 
@@ -422,6 +427,7 @@ interface SimRow {
   winning_turn: number | null;
   winners_json: string | null;
   winning_turns_json: string | null;
+  stage_durations_json: string | null;
 }
 
 function simRowToStatus(row: SimRow): SimulationStatus {
@@ -439,6 +445,9 @@ function simRowToStatus(row: SimRow): SimulationStatus {
     ...(row.winning_turn != null && { winningTurn: row.winning_turn }),
     ...(row.winners_json != null && { winners: JSON.parse(row.winners_json) as string[] }),
     ...(row.winning_turns_json != null && { winningTurns: JSON.parse(row.winning_turns_json) as number[] }),
+    ...(row.stage_durations_json != null && {
+      stageDurationsMs: JSON.parse(row.stage_durations_json) as SimulationStatus['stageDurationsMs'],
+    }),
   };
 }
 
@@ -508,6 +517,10 @@ function buildSimulationUpdateClause(update: Partial<SimulationStatus>): { sets:
     sets.push('winning_turns_json = ?');
     values.push(JSON.stringify(update.winningTurns));
   }
+  if (update.stageDurationsMs !== undefined) {
+    sets.push('stage_durations_json = ?');
+    values.push(JSON.stringify(update.stageDurationsMs));
+  }
 
   return { sets, values };
 }
@@ -0,0 +1,58 @@
+/**
+ * In-memory buffer of condensed events streamed by workers while a job runs
+ * (POST /api/jobs/[id]/events), for the live timeline.
+ *
+ * Best-effort and per-instance: events live only in this process, the
+ * oldest are dropped past MAX_LIVE_EVENTS_PER_JOB, and the least recently
+ * written jobs are evicted past MAX_LIVE_JOBS. The authoritative timeline is
+ * still the condensed artifact written at aggregation.
+ */
+import type { GameEvent } from './types';
+import { lruEvictIfFull, lruTouch } from './lru';
+
+export const MAX_LIVE_JOBS = 100;
+export const MAX_LIVE_EVENTS_PER_JOB = 5000;
+/** Keep in sync with MAX_EVENTS_PER_POST in worker/src/event-stream.ts. */
+export const MAX_LIVE_EVENTS_PER_POST = 1000;
+
+interface LiveBuffer {
+  /** Cursor of events[0]: how many events were dropped before it */
+  offset: number;
+  events: GameEvent[];
+}
+
+const buffers = new Map<string, LiveBuffer>();
+
+/** Appends events to a job's buffer. Returns the job's total event count. */
+export function appendLiveEvents(jobId: string, events: GameEvent[]): number {
+  let buffer = lruTouch(buffers, jobId);
+  if (!buffer) {
+    lruEvictIfFull(buffers, MAX_LIVE_JOBS);
+    buffer = { offset: 0, events: [] };
+    buffers.set(jobId, buffer);
+  }
+  buffer.events.push(...events);
+  const overflow = buffer.events.length - MAX_LIVE_EVENTS_PER_JOB;
+  if (overflow > 0) {
+    buffer.events.splice(0, overflow);
+    buffer.offset += overflow;
+  }
+  return buffer.offset + buffer.events.length;
+}
+
+/**
+ * Events after cursor `since` (0 = from the start). `next` is the cursor to
+ * pass on the following poll; events dropped before the client caught up are
+ * skipped.
+ */
+export function getLiveEvents(jobId: string, since = 0): { events: GameEvent[]; next: number } {
+  const buffer = buffers.get(jobId);
+  if (!buffer) return { events: [], next: since };
+  const start = Math.max(0, since - buffer.offset);
+  return { events: buffer.events.slice(start), next: buffer.offset + buffer.events.length };
+}
+
+/** Drops a job's buffer (e.g. once its results are aggregated). */
+export function clearLiveEvents(jobId: string): void {
+  buffers.delete(jobId);
+}
@@ -0,0 +1,140 @@
+/**
+ * Tests for log sampling in large jobs.
+ *
+ * Run with: npx tsx lib/log-sampling.test.ts
+ */
+
+import type { StructuredGame } from './types';
+import { resolveLogSampleOptions, sampleStride, selectSampledGames } from './log-sampling';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser/condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+function makeGame(totalTurns: number, winner?: string): StructuredGame {
+  return {
+    totalTurns,
+    players: ['Ai(1)-A', 'Ai(2)-B'],
+    turns: [],
+    decks: [],
+    ...(winner && { winner }),
+  };
+}
+
+// Ten games: game 3 is the fastest win, game 1 the median win, and game 5 a
+// long draw (the stalled, draw, and longest game).
+const GAMES = [
+  makeGame(9, 'Ai(1)-A'),
+  makeGame(10, 'Ai(2)-B'),
+  makeGame(11, 'Ai(1)-A'),
+  makeGame(3, 'Ai(2)-B'),
+  makeGame(9, 'Ai(1)-A'),
+  makeGame(40),
+  makeGame(8, 'Ai(2)-B'),
+  makeGame(12, 'Ai(1)-A'),
+  makeGame(10, 'Ai(2)-B'),
+  makeGame(10, 'Ai(1)-A'),
+];
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running log sampling tests...\n');
+
+  await test('resolveLogSampleOptions: reads LOG_SAMPLE_RATE, then LOG_SAMPLE_N', () => {
+    assertEqual(resolveLogSampleOptions({}), undefined, 'unset');
+    assertEqual(resolveLogSampleOptions({ LOG_SAMPLE_RATE: '0' }), undefined, 'zero disables');
+    assertEqual(resolveLogSampleOptions({ LOG_SAMPLE_RATE: 'abc' }), undefined, 'garbage disables');
+    assertEqual(resolveLogSampleOptions({ LOG_SAMPLE_RATE: '10', LOG_SAMPLE_N: '5' })?.every, 10, 'rate wins');
+    assertEqual(resolveLogSampleOptions({ LOG_SAMPLE_N: '5' })?.count, 5, 'target count');
+  });
+
+  await test('sampleStride: a target count becomes a stride', () => {
+    assertEqual(sampleStride(10000, { every: 25 }), 25, 'explicit stride');
+    assertEqual(sampleStride(10000, { count: 100 }), 100, '10000 games / 100');
+    assertEqual(sampleStride(10, { count: 3 }), 4, 'rounds up');
+    assertEqual(sampleStride(10, { count: 50 }), 1, 'more than the job keeps everything');
+  });
+
+  await test('selectSampledGames: every Kth game plus the representative games', () => {
+    const sample = selectSampledGames(GAMES, { every: 4 });
+    // 0, 4, 8 by stride; 3 fastest, 1 median, 5 stalled/draw/longest
+    assertEqual(sample.join(','), '0,1,3,4,5,8', 'sampled indices');
+  });
+
+  await test('selectSampledGames: deterministic across calls and option styles', () => {
+    const first = selectSampledGames(GAMES, { every: 4 });
+    assertEqual(selectSampledGames(GAMES, { every: 4 }).join(','), first.join(','), 'repeat call');
+    assertEqual(selectSampledGames(GAMES, { count: 3 }).join(','), first.join(','), 'same stride via count');
+  });
+
+  await test('selectSampledGames: keeps every game when sampling is off', () => {
+    assertEqual(selectSampledGames(GAMES, undefined).length, 10, 'no options');
+    assertEqual(selectSampledGames(GAMES, { every: 1 }).length, 10, 'stride 1');
+    assert(selectSampledGames([], { every: 4 }).length === 0, 'empty job');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
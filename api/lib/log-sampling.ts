@@ -0,0 +1,86 @@
+/**
+ * Deterministic sampling of per-game artifacts for very large jobs.
+ *
+ * A 10,000-game job doesn't need a per-game log for every game. With
+ * sampling on, ingest still condenses and structures every game (so the
+ * condensed analysis payload, aggregate stats and summary.md cover the
+ * whole job) but only writes per-game raw logs, under `sample/`, for:
+ *
+ *   - every Kth game (game 1, K+1, 2K+1, ...)
+ *   - the representative games (fastest / median win, stalled, draw)
+ *   - the longest game
+ *
+ * Configure with either env var (LOG_SAMPLE_RATE wins if both are set):
+ *   - LOG_SAMPLE_RATE=K  keep every Kth game
+ *   - LOG_SAMPLE_N=N     keep about N games (K = ceil(total / N))
+ *
+ * The raw logs uploaded by workers are never removed, since they are the
+ * only copy of the games left out. Unset, empty, or values below 1 disable
+ * sampling. The same games and the same options always yield the same
+ * sample.
+ */
+
+import type { CondensedGame, StructuredGame } from './types';
+import { selectRepresentativeGames } from './condenser/representative';
+import { gameLength } from './condenser/turn-stats';
+
+export interface LogSampleOptions {
+  /** Keep every Kth game. */
+  every?: number;
+  /** Keep about this many games. Ignored when `every` is set. */
+  count?: number;
+}
+
+function positiveInt(value: string | undefined): number | undefined {
+  const parsed = parseInt(value ?? '', 10);
+  return Number.isFinite(parsed) && parsed >= 1 ? parsed : undefined;
+}
+
+/**
+ * Reads sampling options from the environment.
+ *
+ * @returns The options, or undefined when sampling is off
+ */
+export function resolveLogSampleOptions(env: NodeJS.ProcessEnv = process.env): LogSampleOptions | undefined {
+  const every = positiveInt(env.LOG_SAMPLE_RATE);
+  if (every) return { every };
+  const count = positiveInt(env.LOG_SAMPLE_N);
+  if (count) return { count };
+  return undefined;
+}
+
+/** The stride K for a job of `total` games. */
+export function sampleStride(total: number, options: LogSampleOptions): number {
+  if (options.every) return Math.max(1, Math.floor(options.every));
+  if (options.count) return Math.max(1, Math.ceil(total / options.count));
+  return 1;
+}
+
+/**
+ * Picks which games keep their per-game artifacts.
+ *
+ * @param games - Condensed or structured games, in job order
+ * @param options - Sampling options; undefined keeps every game
+ * @returns Sorted 0-based game indices
+ */
+export function selectSampledGames(
+  games: Array<CondensedGame | StructuredGame>,
+  options: LogSampleOptions | undefined
+): number[] {
+  const all = games.map((_, index) => index);
+  if (!options) return all;
+  const stride = sampleStride(games.length, options);
+  if (stride <= 1) return all;
+
+  const sample = new Set(all.filter((index) => index % stride === 0));
+  for (const index of Object.values(selectRepresentativeGames(games))) {
+    if (index !== undefined) sample.add(index);
+  }
+  let longest = -1;
+  games.forEach((game, index) => {
+    if (longest < 0 || gameLength(game) > gameLength(games[longest])) longest = index;
+  });
+  if (longest >= 0) sample.add(longest);
+
+  return [...sample].sort((a, b) => a - b);
+}
@@ -10,7 +10,9 @@
 import * as fs from 'fs';
 import * as path from 'path';
 import * as os from 'os';
+import * as crypto from 'crypto';
 import { splitConcatenatedGames } from './condenser/index';
+import type { CondensedGame, StructuredGame } from './types';
 
 // ---------------------------------------------------------------------------
 // Test Utilities
@@ -211,6 +213,35 @@ async function runTests() {
       assert(Array.isArray(meta.deckLists), 'meta should have deckLists');
     });
 
+    await test('ingestLogs: writes summary.md alongside meta.json', async () => {
+      const jobId = 'job-ingest-summary';
+      await logStore.ingestLogs(jobId, games, ['Doran Big Butts', 'Enduring Enchantments', 'Explorers of the Deep', 'Veloci-RAMP-Tor']);
+      const summaryPath = path.join(tempDir, jobId, 'summary.md');
+      assert(fs.existsSync(summaryPath), 'summary.md should exist');
+      const summary = fs.readFileSync(summaryPath, 'utf-8');
+      assert(summary.includes('# Job Summary'), 'summary should have a title');
+      assert(summary.includes('| Enduring Enchantments | 2 |'), 'summary should tally wins per deck');
+    });
+
+    await test('ingestLogs: writes unmatched-sample.json with the most common unclassified lines', async () => {
+      const jobId = 'job-ingest-unmatched';
+      await logStore.ingestLogs(jobId, games, ['A', 'B', 'C', 'D']);
+      const sample = JSON.parse(fs.readFileSync(path.join(tempDir, jobId, 'unmatched-sample.json'), 'utf-8'));
+      assert(sample.totalUnmatched > 0, 'real games have unclassified lines');
+      assert(sample.top.length > 0 && sample.top.length <= 50, `top length ${sample.top.length}`);
+      for (let i = 1; i < sample.top.length; i++) {
+        assert(sample.top[i - 1].count >= sample.top[i].count, 'top is sorted by count');
+      }
+    });
+
+    await test('ingestLogs: writes highlights.json for every game', async () => {
+      const jobId = 'job-ingest-highlights';
+      await logStore.ingestLogs(jobId, games, ['A', 'B', 'C', 'D']);
+      const feed = JSON.parse(fs.readFileSync(path.join(tempDir, jobId, 'highlights.json'), 'utf-8'));
+      assertEqual(feed.games, games.length, 'feed covers every game');
+      assertEqual(feed.highlights.filter((h: { kind: string }) => h.kind === 'win').length, games.length, 'one win per game');
+    });
+
     await test('ingestLogs: handles concatenated logs (splits internally)', async () => {
       // Pass the raw log as a single element — ingestLogs should split it
       const result = await logStore.ingestLogs('job-ingest-concat', [rawLog], ['A', 'B', 'C', 'D']);
@@ -229,6 +260,210 @@ async function runTests() {
       assert(!fs.existsSync(oldFile), 'game_005.txt should be cleaned');
     });
 
+    await test('ingestLogs: routes unparseable files to deadletter/', async () => {
+      const jobId = 'job-ingest-deadletter';
+      const garbage = 'Segmentation fault (core dumped)\n\x00\x01 not a forge log\n';
+      const result = await logStore.ingestLogs(jobId, [games[0], garbage, games[1]], ['A', 'B', 'C', 'D']);
+      assertEqual(result.gameCount, 2, 'garbage file should not count as a game');
+      assertEqual(result.deadLetterCount, 1, 'deadLetterCount');
+      assertEqual(result.emptyCount, 0, 'emptyCount');
+      const deadLetterPath = path.join(tempDir, jobId, 'deadletter', 'log_002.txt');
+      assert(fs.existsSync(deadLetterPath), 'dead letter should be named after its input position');
+      assertEqual(fs.readFileSync(deadLetterPath, 'utf-8'), garbage, 'dead letter should keep original content');
+      const meta = JSON.parse(fs.readFileSync(path.join(tempDir, jobId, 'meta.json'), 'utf-8'));
+      assertEqual(meta.condensed.length, 2, 'garbage file should not be condensed');
+      assertEqual(meta.structured.length, 2, 'garbage file should not be structured');
+    });
+
+    // One tiny "game" per win line: a runaway file that splits absurdly
+    const runawayGame = 'Turn: Turn 1 (Ai(1)-A)\nAi(1)-A wins the game.\n';
+
+    await test('ingestLogs: a file splitting past the default cap is dead-lettered', async () => {
+      const jobId = 'job-ingest-runaway';
+      const runaway = runawayGame.repeat(logStore.DEFAULT_MAX_GAMES_PER_FILE + 500);
+      assertEqual(splitConcatenatedGames(runaway).length, logStore.DEFAULT_MAX_GAMES_PER_FILE + 500, 'runaway split');
+      const result = await logStore.ingestLogs(jobId, [games[0], runaway], ['A', 'B', 'C', 'D']);
+      assertEqual(result.gameCount, 1, 'runaway games not ingested');
+      assertEqual(result.deadLetterCount, 1, 'deadLetterCount');
+      const deadLetterPath = path.join(tempDir, jobId, 'deadletter', 'log_002.txt');
+      assertEqual(fs.readFileSync(deadLetterPath, 'utf-8'), runaway, 'dead letter keeps the whole file');
+    });
+
+    await test('ingestLogs: MAX_GAMES_PER_FILE sets the cap', async () => {
+      const jobId = 'job-ingest-max-games';
+      process.env.MAX_GAMES_PER_FILE = '3';
+      // The repeated games are identical, so keep dedup out of the count
+      process.env.DEDUPE_GAMES = 'false';
+      try {
+        const result = await logStore.ingestLogs(jobId, [runawayGame.repeat(3), runawayGame.repeat(4)], ['A', 'B', 'C', 'D']);
+        assertEqual(result.gameCount, 3, 'file at the cap ingested');
+        assertEqual(result.deadLetterCount, 1, 'file over the cap dead-lettered');
+      } finally {
+        delete process.env.MAX_GAMES_PER_FILE;
+        delete process.env.DEDUPE_GAMES;
+      }
+      assertEqual(logStore.resolveMaxGamesPerFile({ MAX_GAMES_PER_FILE: '0' }), logStore.DEFAULT_MAX_GAMES_PER_FILE, 'invalid uses default');
+    });
+
+    await test('ingestLogs: empty files are counted but not dead-lettered', async () => {
+      const jobId = 'job-ingest-empty';
+      const result = await logStore.ingestLogs(jobId, [games[0], '', '  \n'], ['A', 'B', 'C', 'D']);
+      assertEqual(result.gameCount, 1, 'gameCount');
+      assertEqual(result.emptyCount, 2, 'emptyCount');
+      assertEqual(result.deadLetterCount, 0, 'deadLetterCount');
+      assert(!fs.existsSync(path.join(tempDir, jobId, 'deadletter')), 'no deadletter directory');
+    });
+
+    await test('ingestLogs: a game uploaded twice is counted once', async () => {
+      const jobId = 'job-ingest-duplicate';
+      const originalWarn = console.warn;
+      const warnings: string[] = [];
+      console.warn = (line: string) => warnings.push(line);
+      let result;
+      try {
+        result = await logStore.ingestLogs(jobId, [games[0], games[1], games[0].replace(/\n/g, '\r\n')], ['A', 'B', 'C', 'D']);
+      } finally {
+        console.warn = originalWarn;
+      }
+      assertEqual(result.gameCount, 2, 'gameCount');
+      assertEqual(result.duplicateCount, 1, 'duplicateCount');
+      const meta = JSON.parse(fs.readFileSync(path.join(tempDir, jobId, 'meta.json'), 'utf-8'));
+      assertEqual(meta.condensed.length, 2, 'duplicate not condensed');
+      assertEqual(meta.structured.length, 2, 'duplicate not structured');
+      const dropped = warnings.find((w) => w.startsWith('Dropping duplicate game'));
+      assert(dropped !== undefined, 'drop is logged');
+      assert(dropped!.includes('log file 3, game 1') && dropped!.includes('first seen at log file 1, game 1'), `both positions noted: ${dropped}`);
+    });
+
+    await test('ingestLogs: DEDUPE_GAMES=false keeps repeated games', async () => {
+      const jobId = 'job-ingest-duplicate-kept';
+      process.env.DEDUPE_GAMES = 'false';
+      try {
+        const result = await logStore.ingestLogs(jobId, [games[0], games[0]], ['A', 'B', 'C', 'D']);
+        assertEqual(result.gameCount, 2, 'gameCount');
+        assertEqual(result.duplicateCount, 0, 'duplicateCount');
+      } finally {
+        delete process.env.DEDUPE_GAMES;
+      }
+      assertEqual(logStore.resolveDedupeGames({}), true, 'on by default');
+      assertEqual(logStore.resolveDedupeGames({ DEDUPE_GAMES: 'OFF' }), false, 'off');
+    });
+
+    await test('ingestLogs: re-ingesting clears previous dead letters', async () => {
+      const jobId = 'job-ingest-deadletter-clean';
+      await logStore.ingestLogs(jobId, [games[0], 'garbage'], ['A', 'B', 'C', 'D']);
+      assert(fs.existsSync(path.join(tempDir, jobId, 'deadletter', 'log_002.txt')), 'first ingest writes dead letter');
+      await logStore.ingestLogs(jobId, [games[0]], ['A', 'B', 'C', 'D']);
+      assert(!fs.existsSync(path.join(tempDir, jobId, 'deadletter')), 'dead letters should be cleared');
+    });
+
+    await test('ingestLogs: manifest.json lists exactly the artifacts written', async () => {
+      const jobId = 'job-ingest-manifest';
+      await logStore.ingestLogs(jobId, [games[0], 'garbage', games[1]], ['A', 'B', 'C', 'D']);
+      const jobDir = path.join(tempDir, jobId);
+      const manifest = JSON.parse(fs.readFileSync(path.join(jobDir, 'manifest.json'), 'utf-8'));
+      assertEqual(manifest.schemaVersion, 1, 'schemaVersion');
+      assertEqual(manifest.jobId, jobId, 'jobId');
+      const names = manifest.artifacts.map((a: { name: string }) => a.name);
+      assertEqual(
+        names.join(','),
+        'game_001.txt,game_002.txt,meta.json,summary.md,unmatched-sample.json,highlights.json,deadletter/log_002.txt',
+        'artifact names'
+      );
+      for (const artifact of manifest.artifacts) {
+        const contents = fs.readFileSync(path.join(jobDir, artifact.name));
+        assertEqual(artifact.size, contents.length, `${artifact.name} size`);
+        assertEqual(
+          artifact.sha256,
+          crypto.createHash('sha256').update(contents).digest('hex'),
+          `${artifact.name} sha256`
+        );
+        assertEqual(artifact.uri, `file://${path.join(jobDir, artifact.name)}`, `${artifact.name} uri`);
+      }
+      assertEqual(manifest.artifacts[2].contentType, 'application/json', 'meta.json content type');
+      assertEqual(manifest.artifacts[3].contentType, 'text/markdown', 'summary.md content type');
+    });
+
+    await test('ingestLogs: LOG_SAMPLE_RATE limits per-game raw files only', async () => {
+      const jobId = 'job-ingest-sampled';
+      process.env.LOG_SAMPLE_RATE = '3';
+      try {
+        // The worker's upload: one file holding all four games
+        await logStore.uploadSingleSimulationLog(jobId, 'raw/game_001.txt', rawLog);
+        const result = await logStore.ingestLogs(jobId, (await logStore.getRawLogs(jobId))!, ['A', 'B', 'C', 'D']);
+        assertEqual(result.gameCount, 4, 'every game counted');
+        const jobDir = path.join(tempDir, jobId);
+        const sample = JSON.parse(fs.readFileSync(path.join(jobDir, 'sample.json'), 'utf-8'));
+        assertEqual(sample.total, 4, 'sample total');
+        assertEqual(result.sampledCount, sample.indices.length, 'sampledCount');
+        const sampleFiles = fs.readdirSync(path.join(jobDir, 'sample'));
+        assertEqual(sampleFiles.length, sample.indices.length, 'per-game files only for the sample');
+        assert(sampleFiles.includes('game_001.txt'), 'stride keeps game 1');
+        assertEqual(fs.readFileSync(path.join(jobDir, 'game_001.txt'), 'utf-8'), rawLog, 'uploaded log untouched');
+        const meta = JSON.parse(fs.readFileSync(path.join(jobDir, 'meta.json'), 'utf-8'));
+        assertEqual(meta.condensed.length, 4, 'condensed covers every game');
+        assertEqual(meta.structured.length, 4, 'structured covers every game');
+
+        // Re-ingesting (e.g. reprocessing) still sees the whole job
+        const again = await logStore.ingestLogs(jobId, (await logStore.getRawLogs(jobId))!, ['A', 'B', 'C', 'D']);
+        assertEqual(again.gameCount, 4, 're-ingest keeps every game');
+      } finally {
+        delete process.env.LOG_SAMPLE_RATE;
+      }
+    });
+
+    await test('ingestLogs: PAYLOAD_TRANSFORM applies to the analysis payload only', async () => {
+      const jobId = 'job-ingest-transformed';
+      process.env.PAYLOAD_TRANSFORM = 'metrics-only';
+      process.env.MAX_EVENTS_PER_DECK = '2';
+      try {
+        await logStore.ingestLogs(jobId, games, ['A', 'B', 'C', 'D']);
+        const jobDir = path.join(tempDir, jobId);
+        const payload: CondensedGame[] = JSON.parse(
+          fs.readFileSync(path.join(jobDir, logStore.ANALYZE_PAYLOAD_FILENAME), 'utf-8')
+        );
+        assert(payload.every((g) => g.keptEvents.length === 0), 'payload events dropped');
+        assert(payload.every((g) => g.turnCount > 0), 'payload metrics kept');
+        const meta = JSON.parse(fs.readFileSync(path.join(jobDir, 'meta.json'), 'utf-8'));
+        assert(meta.condensed.every((g: CondensedGame) => g.keptEvents.length > 0), 'condensed (logs UI) untouched');
+        assert(meta.condensed.every((g: CondensedGame) => g.omittedEvents === undefined), 'condensed not capped');
+        assert(meta.structured.every((g: StructuredGame) => g.decks.length > 0), 'structured untouched');
+        const manifest = JSON.parse(fs.readFileSync(path.join(jobDir, 'manifest.json'), 'utf-8'));
+        assert(
+          manifest.artifacts.some((a: { name: string }) => a.name === logStore.ANALYZE_PAYLOAD_FILENAME),
+          'payload listed in the manifest'
+        );
+      } finally {
+        delete process.env.PAYLOAD_TRANSFORM;
+        delete process.env.MAX_EVENTS_PER_DECK;
+      }
+
+      // Without a transform or cap the condensed games are the payload
+      await logStore.ingestLogs(jobId, games, ['A', 'B', 'C', 'D']);
+      assert(!fs.existsSync(path.join(tempDir, jobId, logStore.ANALYZE_PAYLOAD_FILENAME)), 'stale payload removed');
+    });
+
+    await test('ingestLogs: AGGREGATORS uploads each enabled aggregator as agg-<name>.json', async () => {
+      const jobId = 'job-ingest-aggregated';
+      const { registerAggregator } = await import('./aggregators');
+      registerAggregator({ name: 'game-count', aggregate: (condensed) => ({ games: condensed.length }) });
+      process.env.AGGREGATORS = 'game-count,win-rates';
+      try {
+        await logStore.ingestLogs(jobId, games, ['Doran Big Butts', 'Enduring Enchantments', 'Explorers of the Deep', 'Veloci-RAMP-Tor']);
+        const jobDir = path.join(tempDir, jobId);
+        const custom = JSON.parse(fs.readFileSync(path.join(jobDir, 'agg-game-count.json'), 'utf-8'));
+        assertEqual(custom.games, 4, 'custom aggregator saw every game');
+        const rates = JSON.parse(fs.readFileSync(path.join(jobDir, 'agg-win-rates.json'), 'utf-8'));
+        assertEqual(rates['Enduring Enchantments'].wins, 2, 'built-in win rates');
+        assert(!fs.existsSync(path.join(jobDir, 'agg-elo.json')), 'only enabled aggregators run');
+        const manifest = JSON.parse(fs.readFileSync(path.join(jobDir, 'manifest.json'), 'utf-8'));
+        const names = manifest.artifacts.map((a: { name: string }) => a.name);
+        assert(names.includes('agg-game-count.json') && names.includes('agg-win-rates.json'), `manifest lists aggregates: ${names}`);
+      } finally {
+        delete process.env.AGGREGATORS;
+      }
+    });
+
     // =========================================================================
     // getCondensedLogs
     // =========================================================================
@@ -247,6 +482,32 @@ async function runTests() {
       assert(result![0].turnCount > 0, 'first game should have turns');
     });
 
+    await test('backfillCondensedGameIds: adds missing IDs, updates the manifest, and is idempotent', async () => {
+      const jobId = 'job-backfill-ids';
+      await logStore.ingestLogs(jobId, games, ['A', 'B', 'C', 'D']);
+      const jobDir = path.join(tempDir, jobId);
+      const metaPath = path.join(jobDir, 'meta.json');
+      // Simulate a job ingested before game IDs existed
+      const meta = JSON.parse(fs.readFileSync(metaPath, 'utf-8'));
+      const ids = meta.condensed.map((g: { gameId: string }) => g.gameId);
+      meta.condensed = meta.condensed.map(({ gameId: _gameId, ...game }: { gameId: string }) => game);
+      fs.writeFileSync(metaPath, JSON.stringify(meta, null, 2), 'utf-8');
+
+      const result = await logStore.backfillCondensedGameIds(jobId);
+      assertEqual(JSON.stringify(result), JSON.stringify({ total: 4, added: 4 }), 'result');
+      const backfilled = fs.readFileSync(metaPath, 'utf-8');
+      const condensed = (await logStore.getCondensedLogs(jobId))!;
+      assertEqual(JSON.stringify(condensed.map((g) => g.gameId)), JSON.stringify(ids), 'same IDs as a fresh ingest');
+      const manifest = JSON.parse(fs.readFileSync(path.join(jobDir, 'manifest.json'), 'utf-8'));
+      const entry = manifest.artifacts.find((a: { name: string }) => a.name === 'meta.json');
+      assertEqual(entry.sha256, crypto.createHash('sha256').update(backfilled).digest('hex'), 'manifest sha256 updated');
+
+      const again = await logStore.backfillCondensedGameIds(jobId);
+      assertEqual(JSON.stringify(again), JSON.stringify({ total: 4, added: 0 }), 're-run adds nothing');
+      assertEqual(fs.readFileSync(metaPath, 'utf-8'), backfilled, 're-run leaves meta.json unchanged');
+      assertEqual(await logStore.backfillCondensedGameIds('nonexistent-backfill'), null, 'no artifact');
+    });
+
     await test('getCondensedLogs: recomputes from raw files when meta.json missing', async () => {
       const jobId = 'job-condensed-fallback';
       // Write raw game files directly without meta.json
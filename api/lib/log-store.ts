@@ -9,8 +9,22 @@ import * as fs from 'fs';
 import * as path from 'path';
 import { isGcpMode } from './env';
 import * as gcs from './gcs-storage';
-import { condenseGames, structureGames, splitConcatenatedGames } from './condenser/index';
+import { backfillGameIds } from './condenser/game-id';
+import { condenseGames, structureGames, splitConcatenatedGames, buildMarkdownSummary, buildUnmatchedSample, buildHighlights, getHighlightKinds, type CondenseOptions, type PlayerColorMap } from './condenser/index';
 import type { CondensedGame, StructuredGame } from './types';
+import {
+  ARTIFACT_MANIFEST_FILENAME,
+  buildArtifactManifest,
+  describeArtifact,
+  replaceManifestArtifact,
+  type ArtifactManifest,
+  type UploadedArtifact,
+} from './artifact-manifest';
+import { resolveLogSampleOptions, selectSampledGames } from './log-sampling';
+import { resolveEventSampleOptions, sampleEventsPerDeck } from './event-sampling';
+import { resolvePayloadTransform } from './payload-transform';
+import { validateCondensed } from './artifact-schema';
+import { resolveAggregators, runAggregators } from './aggregators';
 
 // Local filesystem storage directory
 const LOGS_DATA_DIR = process.env.LOGS_DATA_DIR ?? path.join(process.cwd(), 'logs-data');
@@ -22,6 +36,12 @@ const LOGS_DATA_DIR = process.env.LOGS_DATA_DIR ?? path.join(process.cwd(), 'log
  */
 export const MAX_LOG_BYTES = 10 * 1024 * 1024;
 
+/**
+ * The AI analysis payload, when MAX_EVENTS_PER_DECK or PAYLOAD_TRANSFORM
+ * reshaped it. Without it, the condensed games are the payload.
+ */
+export const ANALYZE_PAYLOAD_FILENAME = 'analyze-payload.json';
+
 // Ensure local data directory exists in LOCAL mode
 if (!isGcpMode() && !fs.existsSync(LOGS_DATA_DIR)) {
   fs.mkdirSync(LOGS_DATA_DIR, { recursive: true });
@@ -46,42 +66,255 @@ interface StoredMeta {
 
 // ─── Ingest (POST) ──────────────────────────────────────────────────────────
 
+/**
+ * A non-empty input file that could not be parsed into any recognizable game.
+ * `index` is the file's 0-based position in the ingested `gameLogs` array.
+ */
+export interface DeadLetterLog {
+  index: number;
+  content: string;
+}
+
+/**
+ * Default cap on the games split from one input file. Real files hold a
+ * handful; a file past this is more likely corrupt (every line read as a
+ * new game) than a huge run.
+ */
+export const DEFAULT_MAX_GAMES_PER_FILE = 1000;
+
+/**
+ * Reads MAX_GAMES_PER_FILE from the environment.
+ *
+ * @returns The cap; DEFAULT_MAX_GAMES_PER_FILE when unset or below 1
+ */
+export function resolveMaxGamesPerFile(env: NodeJS.ProcessEnv = process.env): number {
+  const parsed = parseInt(env.MAX_GAMES_PER_FILE ?? '', 10);
+  return Number.isFinite(parsed) && parsed >= 1 ? parsed : DEFAULT_MAX_GAMES_PER_FILE;
+}
+
+/**
+ * Whether ingest drops repeated games (see partitionGameLogs). On unless
+ * DEDUPE_GAMES is "false", "0" or "off".
+ */
+export function resolveDedupeGames(env: NodeJS.ProcessEnv = process.env): boolean {
+  const value = env.DEDUPE_GAMES?.trim().toLowerCase();
+  return value !== 'false' && value !== '0' && value !== 'off';
+}
+
+/**
+ * A game is recognizable if the condenser found at least a turn, a result
+ * line, or a kept event in it.
+ */
+function isRecognizableGame(game: CondensedGame): boolean {
+  return game.turnCount > 0 || game.winner !== undefined || game.keptEvents.length > 0;
+}
+
+/**
+ * Split input files into games, routing files that yield no recognizable
+ * game to a dead-letter set so they can't corrupt aggregate stats.
+ * A file that splits into more than `maxGamesPerFile` games is treated as
+ * suspicious and dead-lettered whole, without condensing its games.
+ * Whitespace-only files are counted separately and otherwise dropped.
+ * With `dedupe`, a game whose ID (see condenser/game-id.ts) was already seen
+ * is dropped, so a log uploaded twice by a retry or an overlapping glob
+ * isn't counted twice; each drop is logged with both positions.
+ */
+function partitionGameLogs(
+  gameLogs: string[],
+  maxGamesPerFile: number,
+  dedupe: boolean,
+  options?: CondenseOptions
+): {
+  games: string[];
+  condensed: CondensedGame[];
+  deadLetters: DeadLetterLog[];
+  emptyCount: number;
+  duplicateCount: number;
+} {
+  const games: string[] = [];
+  const condensed: CondensedGame[] = [];
+  const deadLetters: DeadLetterLog[] = [];
+  let emptyCount = 0;
+  let duplicateCount = 0;
+  // Game ID -> where it was first seen, e.g. "log file 2, game 1"
+  const seen = new Map<string, string>();
+  gameLogs.forEach((content, index) => {
+    if (content.trim() === '') {
+      emptyCount++;
+      return;
+    }
+    const split = splitConcatenatedGames(content);
+    if (split.length > maxGamesPerFile) {
+      console.warn(
+        `Log file ${index + 1} split into ${split.length} games (MAX_GAMES_PER_FILE=${maxGamesPerFile}); moving it to deadletter/`
+      );
+      deadLetters.push({ index, content });
+      return;
+    }
+    const splitCondensed = condenseGames(split, options);
+    if (!splitCondensed.some(isRecognizableGame)) {
+      deadLetters.push({ index, content });
+      return;
+    }
+    splitCondensed.forEach((game, gameIndex) => {
+      const position = `log file ${index + 1}, game ${gameIndex + 1}`;
+      const first = dedupe && game.gameId ? seen.get(game.gameId) : undefined;
+      if (first) {
+        console.warn(`Dropping duplicate game ${game.gameId} at ${position} (first seen at ${first})`);
+        duplicateCount++;
+        return;
+      }
+      if (game.gameId) seen.set(game.gameId, position);
+      games.push(split[gameIndex]);
+      condensed.push(game);
+    });
+  });
+  return { games, condensed, deadLetters, emptyCount, duplicateCount };
+}
+
+function gameFilename(index: number): string {
+  return `game_${String(index + 1).padStart(3, '0')}.txt`;
+}
+
+function deadLetterFilename(entry: DeadLetterLog): string {
+  return `log_${String(entry.index + 1).padStart(3, '0')}.txt`;
+}
+
+/** Writes a file under jobDir and describes it for the manifest. */
+function writeLocalArtifact(jobDir: string, name: string, data: string): UploadedArtifact {
+  const filePath = path.join(jobDir, name);
+  fs.writeFileSync(filePath, data, 'utf-8');
+  return describeArtifact(name, `file://${filePath}`, data);
+}
+
 /**
  * Ingest raw game logs for a job. Pre-computes condensed and structured data.
+ * Unparseable files, and files that split into more than MAX_GAMES_PER_FILE
+ * games, are stored under `deadletter/` instead of being ingested.
+ * Repeats of a game already ingested are dropped and counted as
+ * `duplicateCount` unless DEDUPE_GAMES=false.
+ * A `manifest.json` listing every artifact written is stored last.
+ * When log sampling is configured (see log-sampling.ts), per-game raw logs
+ * are written under `sample/` only for the sampled games and `sample.json`
+ * records their indices. The uploaded raw logs are left as they are (they
+ * are the job's only copy), and condensed and structured data and the
+ * summary cover every game.
+ * With `playerColors` (deck name -> color identity), condensed events are
+ * tagged with their acting player's colors.
+ * `unmatched-sample.json` records the most common lines no pattern
+ * classified (see condenser/unmatched.ts), for pattern tuning.
+ * `highlights.json` collects every game's wins, eliminations, board wipes
+ * and big turns (see condenser/highlights.ts) for a lightweight feed.
+ * With MAX_EVENTS_PER_DECK set, the analysis payload keeps only each deck's
+ * most significant events (see event-sampling.ts). It then passes through
+ * the PAYLOAD_TRANSFORM transforms (see payload-transform.ts). When either
+ * changed it, it is stored as `analyze-payload.json` for outside analysis
+ * tools (nothing in the API reads it back); condensed output (what the logs
+ * UI shows) is never reshaped. Both are schema-checked before
+ * anything is written; a violation throws ArtifactSchemaError.
+ * Each aggregator enabled by AGGREGATORS (see aggregators.ts) stores its
+ * output as `agg-<name>.json`.
+ * Job statistics are not computed here; aggregateJobResults derives them
+ * from the stored games.
  */
 export async function ingestLogs(
   jobId: string,
   gameLogs: string[],
   deckNames?: string[],
-  deckLists?: string[]
-): Promise<{ gameCount: number }> {
-  const expandedLogs = gameLogs.flatMap(splitConcatenatedGames);
-  const condensed = condenseGames(expandedLogs);
+  deckLists?: string[],
+  playerColors?: PlayerColorMap
+): Promise<{ gameCount: number; sampledCount: number; deadLetterCount: number; emptyCount: number; duplicateCount: number }> {
+  const { games: expandedLogs, condensed, deadLetters, emptyCount, duplicateCount } = partitionGameLogs(
+    gameLogs,
+    resolveMaxGamesPerFile(),
+    resolveDedupeGames(),
+    playerColors && { playerColors }
+  );
   const structured = structureGames(expandedLogs, deckNames);
+  const summary = buildMarkdownSummary(condensed, deckNames);
+  const unmatchedSample = JSON.stringify(buildUnmatchedSample(expandedLogs), null, 2);
+  const highlights = JSON.stringify(buildHighlights(expandedLogs, condensed, getHighlightKinds()), null, 2);
+
+  const sampleOptions = resolveLogSampleOptions();
+  const sampled = selectSampledGames(condensed, sampleOptions);
+  const eventSample = resolveEventSampleOptions();
+  const payload = resolvePayloadTransform()(
+    eventSample ? sampleEventsPerDeck(condensed, deckNames ?? [], eventSample) : condensed
+  );
+  const sampleRecord = sampleOptions && JSON.stringify({ total: expandedLogs.length, indices: sampled });
+  const condensedJson = JSON.stringify(condensed);
+  validateCondensed(condensedJson);
+  // Only stored separately when a cap or transform reshaped it
+  const payloadJson = payload !== condensed ? JSON.stringify(payload) : undefined;
+  if (payloadJson) validateCondensed(payloadJson);
+  const counted = condensed.filter((g) => !g.lowSignal);
+  const aggregated = runAggregators(resolveAggregators(), counted, deckNames ?? []);
+
+  const artifacts: UploadedArtifact[] = [];
 
   if (isGcpMode()) {
-    // Upload raw logs
-    await gcs.uploadRawLogs(jobId, expandedLogs);
+    // Upload raw logs. A sample goes next to the uploaded logs, not over them.
+    if (sampleOptions) {
+      for (const i of sampled) {
+        artifacts.push(await gcs.uploadJobArtifact(jobId, `sample/${gameFilename(i)}`, expandedLogs[i]));
+      }
+      artifacts.push(await gcs.uploadJobArtifact(jobId, 'sample.json', sampleRecord!));
+    } else {
+      artifacts.push(...(await gcs.uploadRawLogs(jobId, expandedLogs)));
+    }
     // Upload pre-computed JSON
-    await gcs.uploadJobArtifact(jobId, 'condensed.json', JSON.stringify(condensed));
-    await gcs.uploadJobArtifact(jobId, 'structured.json', JSON.stringify({ games: structured, deckNames }));
+    artifacts.push(await gcs.uploadJobArtifact(jobId, 'condensed.json', condensedJson));
+    if (payloadJson) {
+      artifacts.push(await gcs.uploadJobArtifact(jobId, ANALYZE_PAYLOAD_FILENAME, payloadJson));
+    }
+    artifacts.push(await gcs.uploadJobArtifact(jobId, 'structured.json', JSON.stringify({ games: structured, deckNames })));
+    artifacts.push(await gcs.uploadJobArtifact(jobId, 'summary.md', summary));
+    artifacts.push(await gcs.uploadJobArtifact(jobId, 'unmatched-sample.json', unmatchedSample));
+    artifacts.push(await gcs.uploadJobArtifact(jobId, 'highlights.json', highlights));
+    for (const { name, content } of aggregated) {
+      artifacts.push(await gcs.uploadJobArtifact(jobId, name, content));
+    }
+    for (const entry of deadLetters) {
+      artifacts.push(await gcs.uploadJobArtifact(jobId, `deadletter/${deadLetterFilename(entry)}`, entry.content));
+    }
+    // Last, so its presence signals the job is fully written
+    const manifest = buildArtifactManifest(jobId, artifacts);
+    await gcs.uploadJobArtifact(jobId, ARTIFACT_MANIFEST_FILENAME, JSON.stringify(manifest, null, 2));
   } else {
     // Local filesystem
     const jobDir = getJobDir(jobId);
     if (fs.existsSync(jobDir)) {
-      // Clean old game files
+      // Clean old game files. A sampled ingest keeps them: they're the
+      // only copy of the games left out of the sample.
       for (const f of fs.readdirSync(jobDir)) {
-        if (/^game_\d+\.txt$/.test(f)) fs.unlinkSync(path.join(jobDir, f));
+        if (
+          (!sampleOptions && /^game_\d+\.txt$/.test(f)) ||
+          /^agg-.+\.json$/.test(f) ||
+          f === ARTIFACT_MANIFEST_FILENAME ||
+          f === ANALYZE_PAYLOAD_FILENAME ||
+          f === 'sample.json'
+        ) {
+          fs.unlinkSync(path.join(jobDir, f));
+        }
       }
     } else {
       fs.mkdirSync(jobDir, { recursive: true });
     }
 
-    // Write raw game files
-    expandedLogs.forEach((log, i) => {
-      const filename = `game_${String(i + 1).padStart(3, '0')}.txt`;
-      fs.writeFileSync(path.join(jobDir, filename), log, 'utf-8');
-    });
+    // Write raw game files, or just the sample's under sample/
+    const sampleDir = path.join(jobDir, 'sample');
+    fs.rmSync(sampleDir, { recursive: true, force: true });
+    if (sampleRecord) {
+      fs.mkdirSync(sampleDir, { recursive: true });
+      for (const i of sampled) {
+        artifacts.push(writeLocalArtifact(jobDir, `sample/${gameFilename(i)}`, expandedLogs[i]));
+      }
+      artifacts.push(writeLocalArtifact(jobDir, 'sample.json', sampleRecord));
+    } else {
+      for (const i of sampled) {
+        artifacts.push(writeLocalArtifact(jobDir, gameFilename(i), expandedLogs[i]));
+      }
+    }
 
     // Write metadata with pre-computed data
     const meta: StoredMeta = {
@@ -91,10 +324,46 @@ export async function ingestLogs(
       condensed,
       structured,
     };
-    fs.writeFileSync(getMetaPath(jobId), JSON.stringify(meta, null, 2), 'utf-8');
+    artifacts.push(writeLocalArtifact(jobDir, path.basename(getMetaPath(jobId)), JSON.stringify(meta, null, 2)));
+    if (payloadJson) {
+      artifacts.push(writeLocalArtifact(jobDir, ANALYZE_PAYLOAD_FILENAME, payloadJson));
+    }
+    artifacts.push(writeLocalArtifact(jobDir, 'summary.md', summary));
+    artifacts.push(writeLocalArtifact(jobDir, 'unmatched-sample.json', unmatchedSample));
+    artifacts.push(writeLocalArtifact(jobDir, 'highlights.json', highlights));
+    for (const { name, content } of aggregated) {
+      artifacts.push(writeLocalArtifact(jobDir, name, content));
+    }
+
+    // Replace any dead letters from a previous ingest
+    const deadLetterDir = path.join(jobDir, 'deadletter');
+    fs.rmSync(deadLetterDir, { recursive: true, force: true });
+    if (deadLetters.length > 0) {
+      fs.mkdirSync(deadLetterDir, { recursive: true });
+      for (const entry of deadLetters) {
+        artifacts.push(writeLocalArtifact(jobDir, `deadletter/${deadLetterFilename(entry)}`, entry.content));
+      }
+    }
+
+    // Last, so its presence signals the job is fully written
+    const manifest = buildArtifactManifest(jobId, artifacts);
+    fs.writeFileSync(path.join(jobDir, ARTIFACT_MANIFEST_FILENAME), JSON.stringify(manifest, null, 2), 'utf-8');
+  }
+
+  if (deadLetters.length > 0) {
+    console.warn(`Job ${jobId}: ${deadLetters.length} unparseable log file(s) moved to deadletter/`);
+  }
+  if (duplicateCount > 0) {
+    console.warn(`Job ${jobId}: ${duplicateCount} duplicate game(s) dropped`);
   }
 
-  return { gameCount: expandedLogs.length };
+  return {
+    gameCount: expandedLogs.length,
+    sampledCount: sampled.length,
+    deadLetterCount: deadLetters.length,
+    emptyCount,
+    duplicateCount,
+  };
 }
 
 // ─── Single simulation log upload (incremental) ──────────────────────────────
@@ -175,6 +444,50 @@ export async function getCondensedLogs(jobId: string): Promise<CondensedGame[] |
   return condenseGames(raw);
 }
 
+/**
+ * Adds game IDs to a job's stored condensed games that predate them, and
+ * writes the condensed artifact back when any were added. IDs come from the
+ * raw logs when they're still stored, one per game; see backfillGameIds.
+ * The manifest entry for the rewritten artifact is updated to match.
+ * Re-running changes nothing.
+ *
+ * @returns Games in the artifact and how many got an ID, or null when the
+ *          job has no stored condensed artifact
+ */
+export async function backfillCondensedGameIds(jobId: string): Promise<{ total: number; added: number } | null> {
+  if (isGcpMode()) {
+    const stored = await gcs.getJobArtifactJson<CondensedGame[]>(jobId, 'condensed.json');
+    if (!stored) return null;
+    const { games, added } = backfillGameIds(stored, await gcs.getRawLogs(jobId));
+    if (added > 0) {
+      const condensedJson = JSON.stringify(games);
+      validateCondensed(condensedJson);
+      const uploaded = await gcs.uploadJobArtifact(jobId, 'condensed.json', condensedJson);
+      const manifest = await gcs.getJobArtifactJson<ArtifactManifest>(jobId, ARTIFACT_MANIFEST_FILENAME);
+      if (manifest) {
+        const updated = replaceManifestArtifact(manifest, uploaded);
+        await gcs.uploadJobArtifact(jobId, ARTIFACT_MANIFEST_FILENAME, JSON.stringify(updated, null, 2));
+      }
+    }
+    return { total: games.length, added };
+  }
+  const meta = readLocalMeta(jobId);
+  if (!meta?.condensed) return null;
+  const { games, added } = backfillGameIds(meta.condensed, readLocalRawLogs(jobId));
+  if (added > 0) {
+    validateCondensed(JSON.stringify(games));
+    const jobDir = getJobDir(jobId);
+    const metaJson = JSON.stringify({ ...meta, condensed: games }, null, 2);
+    const written = writeLocalArtifact(jobDir, path.basename(getMetaPath(jobId)), metaJson);
+    const manifestPath = path.join(jobDir, ARTIFACT_MANIFEST_FILENAME);
+    if (fs.existsSync(manifestPath)) {
+      const manifest = JSON.parse(fs.readFileSync(manifestPath, 'utf-8')) as ArtifactManifest;
+      fs.writeFileSync(manifestPath, JSON.stringify(replaceManifestArtifact(manifest, written), null, 2), 'utf-8');
+    }
+  }
+  return { total: games.length, added };
+}
+
 export async function getStructuredLogs(
   jobId: string,
   deckNamesHint?: string[]
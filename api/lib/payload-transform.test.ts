@@ -0,0 +1,162 @@
+/**
+ * Tests for the built-in analysis payload transforms.
+ *
+ * Run with: npx tsx lib/payload-transform.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+import { condenseGames, splitConcatenatedGames } from './condenser/index';
+import { PAYLOAD_TRANSFORMS, parsePayloadTransform, resolvePayloadTransform } from './payload-transform';
+
+// ---------------------------------------------------------------------------
+// Test Utilities (same pattern as condenser/condenser.test.ts)
+// ---------------------------------------------------------------------------
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => void | Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+const rawLog = fs.readFileSync(path.join(__dirname, 'condenser', 'fixtures', 'real-4game-log.txt'), 'utf-8');
+const splitGames = splitConcatenatedGames(rawLog);
+
+/** Fresh condensed games, with events tagged by player colors. */
+function makeGames() {
+  return condenseGames(splitGames, {
+    playerColors: {
+      'Explorers of the Deep': ['U', 'G'],
+      'Doran Big Butts': ['W', 'B', 'G'],
+      'Enduring Enchantments': ['W'],
+      'Graveyard Shift': ['B', 'G'],
+    },
+  });
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+async function runTests() {
+  console.log('Running payload transform tests...\n');
+
+  await test('identity: returns the games unchanged', () => {
+    const games = makeGames();
+    assertEqual(PAYLOAD_TRANSFORMS.identity(games), games, 'same array');
+    assertEqual(resolvePayloadTransform({})(games), games, 'default when unset');
+  });
+
+  await test('anonymize-players: no player name survives, metrics keep their shape', () => {
+    const games = makeGames();
+    const before = JSON.stringify(games);
+    const anonymized = PAYLOAD_TRANSFORMS['anonymize-players'](games);
+    assertEqual(JSON.stringify(games), before, 'input not mutated');
+    games.forEach((game, i) => {
+      const out = anonymized[i];
+      const json = JSON.stringify(out);
+      for (const player of Object.keys(game.perDeckTurns ?? {})) {
+        assert(!json.includes(player), `game ${i + 1} still names ${player}`);
+      }
+      assertEqual(
+        Object.keys(out.perDeckTurns ?? {}).join(','),
+        Object.keys(game.perDeckTurns ?? {}).map((_, n) => `Player ${n + 1}`).join(','),
+        `game ${i + 1} players numbered in turn order`
+      );
+      assert(out.winner !== undefined && /^Player \d$/.test(out.winner), `game ${i + 1} winner aliased, got ${out.winner}`);
+      assertEqual(out.keptEvents.length, game.keptEvents.length, `game ${i + 1} events kept`);
+      assertEqual(out.turnCount, game.turnCount, `game ${i + 1} turnCount`);
+    });
+  });
+
+  await test('strip-player-colors: drops playerColors from every event', () => {
+    const games = makeGames();
+    assert(games.some((g) => g.keptEvents.some((e) => e.playerColors)), 'fixture has tagged events');
+    const stripped = PAYLOAD_TRANSFORMS['strip-player-colors'](games);
+    assert(stripped.every((g) => g.keptEvents.every((e) => !('playerColors' in e))), 'no playerColors left');
+    assert(games.some((g) => g.keptEvents.some((e) => e.playerColors)), 'input not mutated');
+    assertEqual(stripped[0].keptEvents[0].line, games[0].keptEvents[0].line, 'event lines kept');
+  });
+
+  await test('metrics-only: drops event lines and keeps metrics', () => {
+    const games = makeGames();
+    const trimmed = PAYLOAD_TRANSFORMS['metrics-only'](games);
+    assert(trimmed.every((g) => g.keptEvents.length === 0), 'no events');
+    assert(games.every((g) => g.keptEvents.length > 0), 'input not mutated');
+    assertEqual(JSON.stringify(trimmed[0].manaPerTurn), JSON.stringify(games[0].manaPerTurn), 'manaPerTurn kept');
+    assertEqual(trimmed[0].winner, games[0].winner, 'winner kept');
+  });
+
+  await test('parsePayloadTransform: chains transforms in order', () => {
+    const games = makeGames();
+    const chained = parsePayloadTransform(' Anonymize-Players, metrics-only ')(games);
+    assert(chained.every((g) => g.keptEvents.length === 0), 'metrics-only applied');
+    assert(chained.every((g) => /^Player \d$/.test(g.winner ?? '')), 'anonymize-players applied');
+    assertEqual(resolvePayloadTransform({ PAYLOAD_TRANSFORM: 'metrics-only' })(games)[0].keptEvents.length, 0, 'read from env');
+  });
+
+  await test('parsePayloadTransform: rejects unknown transforms', () => {
+    let message = '';
+    try { parsePayloadTransform('identity,strip-decklists'); } catch (err) { message = (err as Error).message; }
+    assert(message.includes('unknown transform "strip-decklists"'), `unknown transform error, got "${message}"`);
+    let proto = '';
+    try { parsePayloadTransform('toString'); } catch (err) { proto = (err as Error).message; }
+    assert(proto.startsWith('Invalid PAYLOAD_TRANSFORM'), 'prototype keys are not transforms');
+  });
+
+  // =========================================================================
+  // Summary
+  // =========================================================================
+
+  console.log('\n--- Test Summary ---');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}/${results.length}`);
+  console.log(`Failed: ${failed}/${results.length}`);
+
+  if (failed > 0) {
+    console.log('\nFailed tests:');
+    results
+      .filter((r) => !r.passed)
+      .forEach((r) => {
+        console.log(`  - ${r.name}: ${r.error}`);
+      });
+    process.exit(1);
+  }
+
+  console.log('\nAll tests passed!');
+}
+
+runTests().catch((error) => {
+  console.error('Test runner error:', error);
+  process.exit(1);
+});
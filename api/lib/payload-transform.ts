@@ -0,0 +1,102 @@
+/**
+ * Built-in transforms applied to the AI analysis payload, the condensed
+ * games stored as `analyze-payload.json`.
+ *
+ * Some deployments want to redact or trim what the analysis model sees
+ * without forking the condenser. Select transforms by name with
+ * PAYLOAD_TRANSFORM; a comma-separated list runs in order:
+ *
+ *   - identity:            no change (the default)
+ *   - anonymize-players:   replace player names with "Player 1", "Player 2",
+ *                          ... in turn order, everywhere they appear
+ *   - strip-player-colors: drop the color identity tagged on events
+ *   - metrics-only:        drop the event lines and keep the per-turn metrics
+ *
+ * Only the analysis payload is transformed; `condensed.json` (what the logs
+ * UI shows), structured data, summary.md and highlights.json are built from
+ * the untouched games. Transforms never mutate their input.
+ */
+
+import type { CondensedGame } from './types';
+
+export type PayloadTransform = (games: CondensedGame[]) => CondensedGame[];
+
+/** Environment variable naming the transforms to apply. */
+export const PAYLOAD_TRANSFORM_ENV = 'PAYLOAD_TRANSFORM';
+
+const identity: PayloadTransform = (games) => games;
+
+/**
+ * Players in turn order: perDeckTurns keys (inserted as turns were seen),
+ * then any winner or event player not among them.
+ */
+function playersOf(game: CondensedGame): string[] {
+  const players = new Set(Object.keys(game.perDeckTurns ?? {}));
+  if (game.winner) players.add(game.winner);
+  for (const event of game.keptEvents) {
+    if (event.player) players.add(event.player);
+  }
+  return [...players];
+}
+
+function anonymizeGame(game: CondensedGame): CondensedGame {
+  const aliases = new Map(playersOf(game).map((player, i) => [player, `Player ${i + 1}`]));
+  // Longest first, so "Ai(1)-Goblins" is replaced before a name it contains
+  const names = [...aliases.keys()].sort((a, b) => b.length - a.length);
+  let json = JSON.stringify(game);
+  for (const name of names) {
+    const escaped = JSON.stringify(name).slice(1, -1);
+    json = json.split(escaped).join(aliases.get(name)!);
+  }
+  return JSON.parse(json) as CondensedGame;
+}
+
+const anonymizePlayers: PayloadTransform = (games) => games.map(anonymizeGame);
+
+const stripPlayerColors: PayloadTransform = (games) =>
+  games.map((game) => ({
+    ...game,
+    keptEvents: game.keptEvents.map(({ playerColors: _colors, ...event }) => event),
+  }));
+
+const metricsOnly: PayloadTransform = (games) => games.map((game) => ({ ...game, keptEvents: [] }));
+
+export const PAYLOAD_TRANSFORMS: Record<string, PayloadTransform> = {
+  identity,
+  'anonymize-players': anonymizePlayers,
+  'strip-player-colors': stripPlayerColors,
+  'metrics-only': metricsOnly,
+};
+
+/**
+ * Builds the transform for a PAYLOAD_TRANSFORM value.
+ *
+ * @param source - Comma-separated transform names
+ * @throws Error when a name isn't a built-in transform
+ */
+export function parsePayloadTransform(source: string): PayloadTransform {
+  const steps: PayloadTransform[] = [];
+  for (const part of source.split(',')) {
+    const name = part.trim().toLowerCase();
+    if (name.length === 0) continue;
+    if (!Object.prototype.hasOwnProperty.call(PAYLOAD_TRANSFORMS, name)) {
+      throw new Error(
+        `Invalid ${PAYLOAD_TRANSFORM_ENV}: unknown transform "${name}" (expected ${Object.keys(PAYLOAD_TRANSFORMS).join(', ')})`
+      );
+    }
+    steps.push(PAYLOAD_TRANSFORMS[name]);
+  }
+  if (steps.length === 0) return identity;
+  return (games) => steps.reduce((current, step) => step(current), games);
+}
+
+/**
+ * Reads the payload transform from the environment.
+ *
+ * @returns The transform; identity when PAYLOAD_TRANSFORM is unset
+ * @throws If PAYLOAD_TRANSFORM names an unknown transform
+ */
+export function resolvePayloadTransform(env: NodeJS.ProcessEnv = process.env): PayloadTransform {
+  const source = env[PAYLOAD_TRANSFORM_ENV]?.trim();
+  return source ? parsePayloadTransform(source) : identity;
+}
@@ -0,0 +1,129 @@
+/**
+ * Tests for saved-decks.ts command-zone parsing.
+ *
+ * Run with: npx tsx lib/saved-decks.test.ts
+ */
+
+import * as fs from 'fs';
+import * as path from 'path';
+
+import {
+  listSavedDecks,
+  parseCommandZoneFromContent,
+  parseCommanderFromContent,
+} from './saved-decks';
+
+// getDecksDir() reads FORGE_ENGINE_PATH at call time, so point it at the fixtures.
+const FIXTURE_ENGINE_PATH = path.join(__dirname, 'fixtures', 'command-zone');
+process.env.FORGE_ENGINE_PATH = FIXTURE_ENGINE_PATH;
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+function test(name: string, fn: () => void) {
+  try {
+    fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assert(condition: boolean, message: string) {
+  if (!condition) throw new Error(message);
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+function readFixture(filename: string): string {
+  return fs.readFileSync(path.join(FIXTURE_ENGINE_PATH, 'decks', filename), 'utf-8');
+}
+
+// ---------------------------------------------------------------------------
+// parseCommandZoneFromContent
+// ---------------------------------------------------------------------------
+
+test('parseCommandZoneFromContent: single commander', () => {
+  const zone = parseCommandZoneFromContent(readFixture('single-commander.dck'));
+  assertEqual(zone.commanders.join('; '), 'Doran, the Siege Tower', 'commanders');
+  assertEqual(zone.companion, undefined, 'no companion');
+});
+
+test('parseCommandZoneFromContent: partner pair', () => {
+  const zone = parseCommandZoneFromContent(readFixture('partner-pair.dck'));
+  assertEqual(zone.commanders.join('; '), 'Tymna the Weaver; Thrasios, Triton Hero', 'both partners');
+});
+
+test('parseCommandZoneFromContent: background plus creature', () => {
+  const zone = parseCommandZoneFromContent(readFixture('background-creature.dck'));
+  assertEqual(zone.commanders.join('; '), 'Wilson, Refined Grizzly; Raised by Giants', 'creature and background');
+});
+
+test('parseCommandZoneFromContent: companion from sideboard', () => {
+  const zone = parseCommandZoneFromContent(readFixture('companion.dck'));
+  assertEqual(zone.commanders.join('; '), 'Urza, Lord High Artificer', 'commander');
+  assertEqual(zone.companion, 'Lurrus of the Dream-Den', 'companion');
+});
+
+test('parseCommandZoneFromContent: non-companion sideboard cards are ignored', () => {
+  const zone = parseCommandZoneFromContent('[Commander]\n1 Krenko, Mob Boss\n[Sideboard]\n1 Sol Ring\n');
+  assertEqual(zone.companion, undefined, 'no companion');
+  assert(!('companion' in zone), 'companion key omitted');
+});
+
+test('parseCommandZoneFromContent: no commander section', () => {
+  const zone = parseCommandZoneFromContent('[metadata]\nName=Test\n[Main]\n1 Forest\n');
+  assertEqual(zone.commanders.length, 0, 'no commanders');
+});
+
+test('parseCommanderFromContent: returns first commander of a pair', () => {
+  assertEqual(parseCommanderFromContent(readFixture('partner-pair.dck')), 'Tymna the Weaver', 'first partner');
+  assertEqual(parseCommanderFromContent('[Main]\n1 Forest\n'), undefined, 'no commander');
+});
+
+// ---------------------------------------------------------------------------
+// listSavedDecks
+// ---------------------------------------------------------------------------
+
+test('listSavedDecks: surfaces commanders and companion', () => {
+  const decks = listSavedDecks();
+  assertEqual(decks.length, 4, 'fixture deck count');
+  const partners = decks.find((d) => d.filename === 'partner-pair.dck');
+  assertEqual(partners?.commanders?.length, 2, 'partner deck lists two commanders');
+  assertEqual(partners?.companion, undefined, 'partner deck has no companion');
+  const lurrus = decks.find((d) => d.filename === 'companion.dck');
+  assertEqual(lurrus?.companion, 'Lurrus of the Dream-Den', 'companion deck');
+});
+
+// ---------------------------------------------------------------------------
+// Summary
+// ---------------------------------------------------------------------------
+
+const passed = results.filter((r) => r.passed).length;
+const failed = results.filter((r) => !r.passed).length;
+console.log('\n--- Test Summary ---');
+console.log(`Passed: ${passed}/${results.length}`);
+console.log(`Failed: ${failed}/${results.length}`);
+
+if (failed > 0) {
+  console.log('\nFailed tests:');
+  results
+    .filter((r) => !r.passed)
+    .forEach((r) => {
+      console.log(`  - ${r.name}: ${r.error}`);
+    });
+  process.exit(1);
+}
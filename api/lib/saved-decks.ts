@@ -5,6 +5,8 @@ export interface SavedDeck {
   id: string;
   name: string;
   filename: string;
+  commanders?: string[];
+  companion?: string;
 }
 
 function getDecksDir(): string {
@@ -13,32 +15,77 @@ function getDecksDir(): string {
 }
 
 /**
- * Parse commander card name from .dck file content.
- * Looks for [Commander] section and first card line (e.g. "1 Card Name|SET|1" or "1 Card Name").
- * Returns the card name (part before first |) or undefined if no Commander section.
+ * Command-zone contents of a deck: one commander, a partner pair, or a
+ * commander plus background, and an optional companion.
  */
-export function parseCommanderFromContent(content: string): string | undefined {
-  const lines = content.split(/\r?\n/);
-  let inCommander = false;
-  for (const line of lines) {
+export interface DeckCommandZone {
+  commanders: string[];
+  companion?: string;
+}
+
+/**
+ * Cards with the companion mechanic. Forge keeps companions in the
+ * [Sideboard] section, so they are recognized by name.
+ */
+const COMPANION_NAMES = new Set([
+  'gyruda, doom of depths',
+  'jegantha, the wellspring',
+  'kaheera, the orphanguard',
+  'keruga, the macrosage',
+  'lurrus of the dream-den',
+  'lutri, the spellchaser',
+  'obosh, the preypiercer',
+  'umori, the collector',
+  'yorion, sky nomad',
+  'zirda, the dawnwaker',
+]);
+
+/**
+ * Extract the card name from a .dck card line (e.g. "1 Card Name|SET|1" or "1 Card Name").
+ * Returns undefined for lines that are not card entries.
+ */
+function parseCardLine(line: string): string | undefined {
+  const match = line.match(/^\d+\s*(?:x\s*)?(.+)$/);
+  if (!match) return undefined;
+  const rest = match[1].trim();
+  const pipeIndex = rest.indexOf('|');
+  return pipeIndex >= 0 ? rest.substring(0, pipeIndex).trim() : rest;
+}
+
+/**
+ * Parse the command zone from .dck file content.
+ * Every card in the [Commander] section is a commander (partners and
+ * backgrounds list two entries). A companion is a known companion card found
+ * in the [Sideboard] section.
+ */
+export function parseCommandZoneFromContent(content: string): DeckCommandZone {
+  const commanders: string[] = [];
+  let companion: string | undefined;
+  let section = '';
+  for (const line of content.split(/\r?\n/)) {
     const trimmed = line.trim();
-    if (trimmed.toLowerCase() === '[commander]') {
-      inCommander = true;
+    if (!trimmed) continue;
+    if (trimmed.startsWith('[')) {
+      section = trimmed.toLowerCase();
       continue;
     }
-    if (inCommander && trimmed) {
-      if (trimmed.startsWith('[')) {
-        break;
-      }
-      const match = trimmed.match(/^\d+\s*(?:x\s*)?(.+)$/);
-      if (match) {
-        const rest = match[1].trim();
-        const pipeIndex = rest.indexOf('|');
-        return pipeIndex >= 0 ? rest.substring(0, pipeIndex).trim() : rest;
-      }
+    const card = parseCardLine(trimmed);
+    if (!card) continue;
+    if (section === '[commander]') {
+      commanders.push(card);
+    } else if (section === '[sideboard]' && !companion && COMPANION_NAMES.has(card.toLowerCase())) {
+      companion = card;
     }
   }
-  return undefined;
+  return companion ? { commanders, companion } : { commanders };
+}
+
+/**
+ * Parse commander card name from .dck file content.
+ * Returns the first card in the [Commander] section or undefined if there is none.
+ */
+export function parseCommanderFromContent(content: string): string | undefined {
+  return parseCommandZoneFromContent(content).commanders[0];
 }
 
 /**
@@ -111,11 +158,14 @@ export function listSavedDecks(): SavedDeck[] {
         const content = fs.readFileSync(filePath, 'utf-8');
         const fallbackName = file.replace(/\.dck$/, '');
         const name = parseDeckName(content, fallbackName);
+        const { commanders, companion } = parseCommandZoneFromContent(content);
         
         decks.push({
           id: file, // Use filename as ID
           name,
           filename: file,
+          ...(commanders.length > 0 && { commanders }),
+          ...(companion && { companion }),
         });
       } catch (err) {
         console.error(`Failed to read deck file ${file}:`, err);
@@ -11,7 +11,7 @@
  * Firestore docs remain valid; new writes leave mu/sigma at neutral defaults.
  */
 import type { DeckRating, MatchResult, StructuredGame } from './types';
-import { matchesDeckName } from './condenser/deck-match';
+import { matchesDeckName, resolveSeatLabel, seatMapFromDeckNames } from './condenser/deck-match';
 import { getDeckById } from './deck-store-factory';
 import { getRatingStore } from './rating-store-factory';
 import { addWinTurn, emptyWinTurnAggregate } from './win-turn-aggregate';
@@ -77,6 +77,7 @@ export async function processJobForRatings(
     };
   });
 
+  const seatMap = seatMapFromDeckNames(deckInfos.map(d => d.name ?? ''));
   const matchResults: MatchResult[] = [];
   let resolvedGames = 0;
   const jobTimestamp = new Date().toISOString();
@@ -87,8 +88,9 @@ export async function processJobForRatings(
 
     let winnerDeckId: string | null = null;
     if (winner) {
+      const seated = resolveSeatLabel(winner, seatMap);
       for (const { id, name } of deckInfos) {
-        if (name && matchesDeckName(winner, name)) {
+        if (name && matchesDeckName(seated, name)) {
           winnerDeckId = id;
           break;
         }
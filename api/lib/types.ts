@@ -24,12 +24,19 @@ export type { SimulationState, SimulationStatus } from '@shared/types/simulation
 export type {
   EventType,
   GameEvent,
+  KillInfo,
+  WinReason,
+  GameEndReason,
   TurnManaInfo,
+  TurnCastInfo,
+  TempoPoint,
+  MulliganInfo,
   DeckTurnInfo,
   CondensedGame,
   DeckAction,
   DeckTurnActions,
   DeckHistory,
+  TurnDelta,
   StructuredGame,
 } from '@shared/types/log';
 
@@ -3,7 +3,9 @@
  * Run with: npx tsx lib/validation.test.ts
  */
 
-import { createJobSchema, updateSimulationSchema, updateJobSchema, parseBody } from './validation';
+import { createJobSchema, updateSimulationSchema, updateJobSchema, postEventsSchema, parseBody } from './validation';
+import { appendLiveEvents, getLiveEvents, clearLiveEvents, MAX_LIVE_EVENTS_PER_JOB } from './live-events';
+import { MAX_EVENTS_PER_POST } from '../../worker/src/event-stream';
 
 interface TestResult { name: string; passed: boolean; error?: string; }
 const results: TestResult[] = [];
@@ -132,6 +134,22 @@ async function runTests() {
     assertEqual(result.success, true, 'should succeed');
   });
 
+  await test('updateSimulationSchema: accepts per-stage durations', () => {
+    const result = parseBody(updateSimulationSchema, {
+      state: 'COMPLETED',
+      durationMs: 5000,
+      stageDurationsMs: { fetch: 12, run: 4900, condense: 3, upload: 80 },
+    });
+    assertEqual(result.success, true, 'should succeed');
+  });
+
+  await test('updateSimulationSchema: rejects negative stage duration', () => {
+    const result = parseBody(updateSimulationSchema, {
+      stageDurationsMs: { run: -1 },
+    });
+    assertEqual(result.success, false, 'should fail');
+  });
+
   await test('updateSimulationSchema: rejects invalid state', () => {
     const result = parseBody(updateSimulationSchema, {
       state: 'INVALID_STATE',
@@ -178,6 +196,48 @@ async function runTests() {
     assertEqual(result.success, true, 'should succeed');
   });
 
+  // ── postEventsSchema / live events ──────────────────────────────────
+
+  await test('postEventsSchema: valid batch passes', () => {
+    const result = parseBody(postEventsSchema, {
+      events: [{ type: 'land_played', line: 'Land: Ai(1)-Alpha played Forest (1)' }],
+    });
+    assertEqual(result.success, true, 'should succeed');
+  });
+
+  await test('postEventsSchema: rejects empty batches and unknown event types', () => {
+    assertEqual(parseBody(postEventsSchema, { events: [] }).success, false, 'empty');
+    const bad = parseBody(postEventsSchema, { events: [{ type: 'teleport', line: 'x' }] });
+    assertEqual(bad.success, false, 'unknown type');
+    if (!bad.success) assertEqual(bad.error.startsWith('events.0.type'), true, `error: ${bad.error}`);
+  });
+
+  await test('postEventsSchema: accepts the worker\'s largest batch and no more', () => {
+    const event = { type: 'land_played', line: 'Land: Ai(1)-Alpha played Forest (1)' };
+    const full = Array.from({ length: MAX_EVENTS_PER_POST }, () => event);
+    assertEqual(parseBody(postEventsSchema, { events: full }).success, true, 'full worker batch');
+    assertEqual(parseBody(postEventsSchema, { events: [...full, event] }).success, false, 'one over');
+  });
+
+  await test('live events: cursor reads, and the oldest are dropped past the cap', () => {
+    const line = (n: number) => ({ type: 'land_played' as const, line: `Land ${n}` });
+    assertEqual(appendLiveEvents('job-live', [line(1), line(2)]), 2, 'total');
+    const first = getLiveEvents('job-live');
+    assertEqual(first.events.length, 2, 'all events from the start');
+    assertEqual(getLiveEvents('job-live', first.next).events.length, 0, 'nothing new');
+
+    const many = Array.from({ length: MAX_LIVE_EVENTS_PER_JOB }, (_, i) => line(i + 3));
+    const total = appendLiveEvents('job-live', many);
+    assertEqual(total, MAX_LIVE_EVENTS_PER_JOB + 2, 'total counts dropped events');
+    const caughtUp = getLiveEvents('job-live', first.next);
+    assertEqual(caughtUp.events.length, MAX_LIVE_EVENTS_PER_JOB, 'buffer capped');
+    assertEqual(caughtUp.events[0].line, 'Land 3', 'oldest dropped');
+    assertEqual(caughtUp.next, total, 'next cursor');
+
+    clearLiveEvents('job-live');
+    assertEqual(getLiveEvents('job-live', 7).next, 7, 'cleared job echoes the cursor');
+  });
+
   // ── Summary ─────────────────────────────────────────────────────────
 
   console.log('\n--- Test Summary ---');
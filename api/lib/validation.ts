@@ -1,5 +1,7 @@
 import { z } from 'zod';
 import { SIMULATIONS_MIN, SIMULATIONS_MAX, PARALLELISM_MIN, PARALLELISM_MAX } from './types';
+import { gameEventSchema } from './artifact-schema';
+import { MAX_LIVE_EVENTS_PER_POST } from './live-events';
 
 // ---------------------------------------------------------------------------
 // POST /api/jobs — Create job
@@ -30,6 +32,12 @@ export const updateSimulationSchema = z.object({
   winningTurn: z.number().optional(),
   winners: z.array(z.string()).optional(),
   winningTurns: z.array(z.number()).optional(),
+  stageDurationsMs: z.object({
+    fetch: z.number().nonnegative().optional(),
+    run: z.number().nonnegative().optional(),
+    condense: z.number().nonnegative().optional(),
+    upload: z.number().nonnegative().optional(),
+  }).optional(),
 });
 
 export type UpdateSimulationInput = z.infer<typeof updateSimulationSchema>;
@@ -50,6 +58,16 @@ export const updateJobSchema = z.object({
 
 export type UpdateJobInput = z.infer<typeof updateJobSchema>;
 
+// ---------------------------------------------------------------------------
+// POST /api/jobs/[id]/events — Stream condensed events (worker)
+// ---------------------------------------------------------------------------
+
+export const postEventsSchema = z.object({
+  events: z.array(gameEventSchema).min(1).max(MAX_LIVE_EVENTS_PER_POST),
+});
+
+export type PostEventsInput = z.infer<typeof postEventsSchema>;
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
@@ -0,0 +1,27 @@
+#!/usr/bin/env npx tsx
+/**
+ * Condense Forge game logs from the command line, without the API, GCS or a
+ * database. Handy for debugging parser changes against a saved log, and
+ * for checking pattern edits against one.
+ *
+ * Usage (from api directory):
+ *   cat game.txt | npx tsx scripts/log-tool.ts condense -
+ *   npx tsx scripts/log-tool.ts condense [-structured] <file>
+ *   npx tsx scripts/log-tool.ts patterns [-file patterns.json] <sample>
+ *   npx tsx scripts/log-tool.ts backfill-ids <jobId>...
+ *
+ * backfill-ids reads and writes job artifacts, so it needs the same env as
+ * the API server (GOOGLE_CLOUD_PROJECT and GCP creds for GCP mode).
+ *
+ * See lib/condenser/cli.ts for details.
+ */
+
+import { runCli, processIO } from '../lib/condenser/cli';
+
+runCli(process.argv.slice(2), {
+  ...processIO(),
+  // Loaded on demand so condense and patterns stay storage-free
+  backfillJob: async (jobId) => (await import('../lib/log-store')).backfillCondensedGameIds(jobId),
+}).then((code) => {
+  process.exitCode = code;
+});
@@ -37,7 +37,7 @@ async function main() {
   }
 
   const logsDir = path.join(JOBS_DIR, jobId, 'logs');
-  const gameLogs = readGameLogs(logsDir, jobId);
+  const gameLogs = await readGameLogs(logsDir, jobId);
 
   if (gameLogs.length === 0) {
     console.error(
@@ -0,0 +1,57 @@
+#!/usr/bin/env npx tsx
+/**
+ * Batch reprocessing: re-ingest the stored raw logs of many jobs so their
+ * condensed and structured artifacts pick up the latest condenser logic.
+ *
+ * Job IDs are read from the file named by JOB_IDS_FILE (or the first
+ * argument), one per line; `#` starts a comment. Jobs run sequentially by
+ * default, or BATCH_CONCURRENCY at a time. The job store and storage
+ * clients are module singletons, so every job shares one client. A job
+ * that fails is reported in the summary and doesn't stop the batch; the
+ * script exits 1 if any job failed.
+ *
+ * Pre-reqs: the same env the API server uses (GOOGLE_CLOUD_PROJECT and GCP
+ * creds for GCP mode; nothing for LOCAL mode).
+ *
+ * Usage (from api directory):
+ *   JOB_IDS_FILE=jobs.txt npx tsx scripts/reprocess-jobs.ts
+ *   BATCH_CONCURRENCY=4 npx tsx scripts/reprocess-jobs.ts jobs.txt
+ */
+
+import { getJob } from '../lib/job-store-factory';
+import { getRawLogs, ingestLogs } from '../lib/log-store';
+import { readJobIdsFile, runJobBatch, formatBatchSummary, DEFAULT_BATCH_CONCURRENCY } from '../lib/job-batch';
+
+async function reprocessJob(jobId: string): Promise<void> {
+  const job = await getJob(jobId);
+  if (!job) throw new Error('job not found');
+  const rawLogs = await getRawLogs(jobId);
+  if (!rawLogs || rawLogs.length === 0) throw new Error('no raw logs stored');
+
+  const deckNames = job.decks.map((d) => d.name);
+  const deckLists = job.decks.map((d) => d.dck ?? '');
+  const { gameCount } = await ingestLogs(jobId, rawLogs, deckNames, deckLists);
+  console.log(`${jobId}: re-ingested ${gameCount} game(s)`);
+}
+
+async function main() {
+  const idsFile = process.env.JOB_IDS_FILE || process.argv[2];
+  if (!idsFile) {
+    console.error('Usage: JOB_IDS_FILE=<file> npx tsx scripts/reprocess-jobs.ts');
+    process.exit(1);
+  }
+
+  const jobIds = readJobIdsFile(idsFile);
+  const parsed = parseInt(process.env.BATCH_CONCURRENCY || '', 10);
+  const concurrency = parsed > 0 ? parsed : DEFAULT_BATCH_CONCURRENCY;
+  console.log(`Reprocessing ${jobIds.length} job(s) from ${idsFile} (concurrency ${concurrency})...`);
+
+  const summary = await runJobBatch(jobIds, reprocessJob, concurrency);
+  console.log(formatBatchSummary(summary));
+  if (summary.failed.length > 0) process.exit(1);
+}
+
+main().catch((err) => {
+  console.error(err);
+  process.exit(1);
+});
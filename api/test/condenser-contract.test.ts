@@ -13,7 +13,9 @@
  *      both sides. If it doesn't, the status update's winners[] array
  *      has a different length than the API's aggregation expects,
  *      breaking per-game attribution.
- *   2. extractWinner must produce the same WINNER for each game index.
+ *   2. The worker's extractWinner must produce the same WINNER for each
+ *      game index as the API's resolveWinner (win line, else the last
+ *      player standing).
  *      If it doesn't, the worker reports one player as the winner and
  *      the API re-aggregates with a different one, and the UI flickers
  *      or the rating math is wrong.
@@ -38,11 +40,15 @@ import {
   splitConcatenatedGames as apiSplit,
   extractWinner as apiExtractWinner,
   extractWinningTurn as apiExtractWinningTurn,
+  condenseGame as apiCondenseGame,
+  resolveWinner as apiResolveWinner,
 } from '../lib/condenser/index';
+import { resolveWinnerName as apiResolveWinnerName } from '../lib/condenser/deck-match';
 import {
   splitConcatenatedGames as workerSplit,
   extractWinner as workerExtractWinner,
   extractWinningTurn as workerExtractWinningTurn,
+  applyWinnerAlias as workerApplyWinnerAlias,
 } from '../../worker/src/condenser';
 
 interface TestResult {
@@ -97,6 +103,11 @@ function normWinner(w: string | undefined): string | undefined {
   return w;
 }
 
+/** The API's condensed winner; unset for a simultaneous-win draw. */
+function apiWinner(log: string): string | undefined {
+  return apiResolveWinner(log).winner;
+}
+
 /** Same normalization for winning turns: 0 or undefined means "no winner". */
 function normTurn(t: number | undefined): number | undefined {
   if (t === undefined || t === 0) return undefined;
@@ -114,6 +125,28 @@ test('splitConcatenatedGames: both implementations produce the same game count',
   assert(apiGames.length > 0, 'fixture must contain at least one game');
 });
 
+test('splitConcatenatedGames: worker and API split match and turn-reset logs alike', () => {
+  const fixtures = path.dirname(FIXTURE_PATH);
+  const bestOfThree = fs.readFileSync(path.join(fixtures, 'best-of-three-log.txt'), 'utf-8');
+  const turnReset = fs.readFileSync(path.join(fixtures, 'turn-reset-log.txt'), 'utf-8');
+  const turnResetNoWin = turnReset.replace('Game outcome: Ai(2)-Beta has won because all opponents have lost\n', '');
+  const cases: Array<[string, string, 'result-line' | 'turn-reset']> = [
+    ['best-of-three', bestOfThree, 'result-line'],
+    ['turn-reset', turnReset, 'result-line'],
+    ['turn-reset without a win line', turnResetNoWin, 'result-line'],
+    ['turn-reset without a win line', turnResetNoWin, 'turn-reset'],
+    ['4-game', RAW_LOG, 'turn-reset'],
+  ];
+  for (const [name, log, strategy] of cases) {
+    const apiGames = apiSplit(log, { strategy });
+    const workerGames = workerSplit(log, { strategy });
+    assertEqual(workerGames.length, apiGames.length, `${name} (${strategy}) game count`);
+    for (let i = 0; i < apiGames.length; i++) {
+      assertEqual(normWinner(workerExtractWinner(workerGames[i])), normWinner(apiWinner(apiGames[i])), `${name} (${strategy}) game ${i} winner`);
+    }
+  }
+});
+
 test('extractWinner: worker and API agree for every split game', () => {
   // Each implementation extracts the winner from its own split output;
   // that's the real flow — the worker reports winners[] to the API and
@@ -122,7 +155,7 @@ test('extractWinner: worker and API agree for every split game', () => {
   const apiGames = apiSplit(RAW_LOG);
   const workerGames = workerSplit(RAW_LOG);
   for (let i = 0; i < apiGames.length; i++) {
-    const apiW = normWinner(apiExtractWinner(apiGames[i]));
+    const apiW = normWinner(apiWinner(apiGames[i]));
     const workerW = normWinner(workerExtractWinner(workerGames[i]));
     assertEqual(workerW, apiW, `game ${i} winner`);
   }
@@ -199,6 +232,96 @@ test('extractWinningTurn: worker and API agree when turn markers lack player att
   assertEqual(workerT, apiT, 'last-resort fallback agrees');
 });
 
+test('extractWinningTurn: worker and API agree on indented and timestamped turn markers', () => {
+  for (const name of ['indented-turns-log.txt', 'timestamped-turns-log.txt']) {
+    const log = fs.readFileSync(path.join(path.dirname(FIXTURE_PATH), name), 'utf-8');
+    const apiT = normTurn(apiExtractWinningTurn(log));
+    const workerT = normTurn(workerExtractWinningTurn(log));
+    assertEqual(workerT, apiT, `${name} winning turn`);
+    assertEqual(apiT, 3, `${name}: Beta took 3 turns`);
+  }
+});
+
+test('extractWinningTurn: worker and API agree with a custom WIN_LINE_PATTERN', () => {
+  const log = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Turn: Turn 3 (Ai(1)-Alpha)',
+    'Turn: Turn 4 (Ai(2)-Beta)',
+    'Turn: Turn 5 (Ai(1)-Alpha)',
+    'Victory: Ai(2)-Beta is the last player standing',
+  ].join('\n');
+  process.env.WIN_LINE_PATTERN = '^Victory: (.+?) is the last player standing';
+  try {
+    const apiW = apiExtractWinner(log);
+    const workerW = workerExtractWinner(log);
+    assertEqual(apiW, 'Ai(2)-Beta', 'api winner from custom line');
+    assertEqual(workerW, apiW, 'winner');
+    const apiT = normTurn(apiExtractWinningTurn(log));
+    assertEqual(normTurn(workerExtractWinningTurn(log)), apiT, 'winning turn');
+    assertEqual(apiT, 2, 'Beta took 2 turns');
+  } finally {
+    delete process.env.WIN_LINE_PATTERN;
+  }
+});
+
+test('extractWinner: worker and API infer the last player standing alike', () => {
+  // No win line: the worker's winner must match the API's condensed winner
+  // (resolveWinner), winning turn included.
+  const log = [
+    'Turn: Turn 1 (Ai(1)-Alpha)', 'stuff',
+    'Turn: Turn 2 (Ai(2)-Beta)', 'stuff',
+    'Turn: Turn 3 (Ai(3)-Gamma)', 'stuff',
+    'Turn: Turn 4 (Ai(1)-Alpha)', 'stuff',
+    'Game outcome: Ai(2)-Beta has lost because life total reached 0',
+    'Game outcome: Ai(3)-Gamma has conceded',
+  ].join('\n');
+  const api = apiCondenseGame(log);
+  assertEqual(api.winner, 'Ai(1)-Alpha', 'api winner');
+  assertEqual(workerExtractWinner(log), api.winner, 'winner');
+  assertEqual(normTurn(workerExtractWinningTurn(log)), normTurn(api.winningTurn), 'winning turn');
+});
+
+test('extractWinner: worker and API score simultaneous wins alike', () => {
+  const log = fs.readFileSync(path.join(path.dirname(FIXTURE_PATH), 'simultaneous-win-log.txt'), 'utf-8');
+  const previous = process.env.SIMULTANEOUS_WIN_SCORING;
+  try {
+    for (const scoring of ['draw', 'shared']) {
+      process.env.SIMULTANEOUS_WIN_SCORING = scoring;
+      assertEqual(normWinner(workerExtractWinner(log)), apiWinner(log), `${scoring} winner`);
+      assertEqual(normTurn(workerExtractWinningTurn(log)), normTurn(apiCondenseGame(log).winningTurn), `${scoring} winning turn`);
+    }
+  } finally {
+    if (previous === undefined) delete process.env.SIMULTANEOUS_WIN_SCORING;
+    else process.env.SIMULTANEOUS_WIN_SCORING = previous;
+  }
+});
+
+test('WINNER_ALIASES: the worker resolves an aliased winner to the API\'s deck', () => {
+  const log = fs.readFileSync(path.join(path.dirname(FIXTURE_PATH), 'commander-winner-log.txt'), 'utf-8');
+  const winner = workerExtractWinner(log);
+  const deckNames = ['Four Color Counters', 'Goblin Swarm'];
+  const previous = process.env.WINNER_ALIASES;
+  process.env.WINNER_ALIASES = JSON.stringify({ [winner]: 'Four Color Counters' });
+  try {
+    const reported = workerApplyWinnerAlias(winner, deckNames);
+    assertEqual(reported, 'Four Color Counters', 'worker reports the deck');
+    assertEqual(apiResolveWinnerName(reported, deckNames), apiResolveWinnerName(winner, deckNames), 'same deck on both sides');
+  } finally {
+    if (previous === undefined) delete process.env.WINNER_ALIASES;
+    else process.env.WINNER_ALIASES = previous;
+  }
+});
+
+test('extractWinner: worker and API trim decorated win lines the same way', () => {
+  const fixtures = ['decorated-win-log.txt', 'game-result-win-log.txt', 'commander-winner-log.txt'];
+  for (const name of fixtures) {
+    const log = fs.readFileSync(path.join(path.dirname(FIXTURE_PATH), name), 'utf-8');
+    assertEqual(workerExtractWinner(log), apiExtractWinner(log) ?? '', `${name} winner`);
+    assertEqual(normTurn(workerExtractWinningTurn(log)), normTurn(apiExtractWinningTurn(log)), `${name} winning turn`);
+  }
+});
+
 // ---------------------------------------------------------------------------
 // Summary
 // ---------------------------------------------------------------------------
@@ -13,6 +13,8 @@ import {
   findGameLogFiles,
   countGameLogFiles,
   readGameLogs,
+  DEFAULT_READ_CONCURRENCY,
+  type ReadProgress,
 } from '../lib/game-logs';
 
 // -----------------------------------------------------------------------------
@@ -219,7 +221,7 @@ async function runTests() {
     }
   });
 
-  await test('readGameLogs: reads all 12 batched game logs in order', () => {
+  await test('readGameLogs: reads all 12 batched game logs in order', async () => {
     const jobId = 'test-job-read';
     const files: { name: string; content: string }[] = [];
 
@@ -235,7 +237,7 @@ async function runTests() {
     const tempDir = createTestLogsDir(jobId, files);
 
     try {
-      const logs = readGameLogs(tempDir, jobId);
+      const logs = await readGameLogs(tempDir, jobId);
       assertEqual(logs.length, 12, 'should read 12 logs');
 
       // Verify content order matches run/game order
@@ -311,7 +313,7 @@ async function runTests() {
     }
   });
 
-  await test('readGameLogs: skips empty files', () => {
+  await test('readGameLogs: skips empty files', async () => {
     const jobId = 'empty-test';
     const files = [
       { name: `job_${jobId}_game_1.txt`, content: 'has content' },
@@ -322,7 +324,7 @@ async function runTests() {
     const tempDir = createTestLogsDir(jobId, files);
 
     try {
-      const logs = readGameLogs(tempDir, jobId);
+      const logs = await readGameLogs(tempDir, jobId);
       assertEqual(logs.length, 1, 'should return only non-empty logs');
       assertEqual(logs[0], 'has content', 'content');
     } finally {
@@ -330,6 +332,118 @@ async function runTests() {
     }
   });
 
+  await test('readGameLogs: parallel reads preserve sorted file order', async () => {
+    const jobId = 'parallel-order';
+    const files: { name: string; content: string }[] = [];
+    for (let game = 1; game <= 40; game++) {
+      // Vary sizes so reads don't complete in submission order
+      files.push({
+        name: `job_${jobId}_game_${game}.txt`,
+        content: `game${game}:` + 'x'.repeat((41 - game) * 1000),
+      });
+    }
+
+    const tempDir = createTestLogsDir(jobId, files);
+
+    try {
+      const expected = files.map((f) => f.content.split(':')[0]);
+      for (const concurrency of [1, 4, 16]) {
+        const logs = await readGameLogs(tempDir, jobId, concurrency);
+        assertArrayEqual(
+          logs.map((l) => l.split(':')[0]),
+          expected,
+          `log order with concurrency ${concurrency}`
+        );
+      }
+    } finally {
+      cleanupDir(tempDir);
+    }
+  });
+
+  await test('readGameLogs: skips files that fail to read', async () => {
+    const jobId = 'read-error';
+    const files = [
+      { name: `job_${jobId}_game_1.txt`, content: 'first' },
+      { name: `job_${jobId}_game_3.txt`, content: 'third' },
+    ];
+
+    const tempDir = createTestLogsDir(jobId, files);
+    // A directory with a log-like name makes readFile fail with EISDIR
+    fs.mkdirSync(path.join(tempDir, `job_${jobId}_game_2.txt`));
+
+    const originalError = console.error;
+    console.error = () => {};
+    try {
+      const logs = await readGameLogs(tempDir, jobId);
+      assertArrayEqual(logs, ['first', 'third'], 'unreadable file should be skipped');
+    } finally {
+      console.error = originalError;
+      cleanupDir(tempDir);
+    }
+  });
+
+  await test('readGameLogs: reports progress every N files and when done', async () => {
+    const jobId = 'progress';
+    const files = Array.from({ length: 250 }, (_, i) => ({
+      name: `job_${jobId}_game_${i + 1}.txt`,
+      content: `game${i + 1}`,
+    }));
+    const tempDir = createTestLogsDir(jobId, files);
+
+    const reports: ReadProgress[] = [];
+    const originalLog = console.log;
+    const logged: string[] = [];
+    console.log = (line: string) => logged.push(line);
+    try {
+      const logs = await readGameLogs(tempDir, jobId, DEFAULT_READ_CONCURRENCY, {
+        everyFiles: 50,
+        everyMs: Number.POSITIVE_INFINITY,
+        onProgress: (p) => {
+          reports.push(p);
+        },
+      });
+      assertEqual(logs.length, 250, 'all logs read');
+    } finally {
+      console.log = originalLog;
+      cleanupDir(tempDir);
+    }
+
+    assertArrayEqual(reports.map((r) => r.read), [50, 100, 150, 200, 250], 'reports');
+    assert(reports.every((r) => r.total === 250 && r.jobId === jobId), 'total and job in every report');
+    assertEqual(logged.length, 5, 'each report is logged');
+    assert(logged[0].startsWith('[GameLogs] Reading game logs {'), `structured log line: ${logged[0]}`);
+  });
+
+  await test('readGameLogs: a failing progress callback does not stop the read', async () => {
+    const jobId = 'progress-error';
+    const files = Array.from({ length: 10 }, (_, i) => ({
+      name: `job_${jobId}_game_${i + 1}.txt`,
+      content: `game${i + 1}`,
+    }));
+    const tempDir = createTestLogsDir(jobId, files);
+
+    const originalLog = console.log;
+    const originalWarn = console.warn;
+    const warnings: string[] = [];
+    console.log = () => {};
+    console.warn = (line: string) => warnings.push(line);
+    try {
+      const logs = await readGameLogs(tempDir, jobId, 2, {
+        everyFiles: 1,
+        everyMs: Number.POSITIVE_INFINITY,
+        onProgress: () => {
+          throw new Error('PATCH failed');
+        },
+      });
+      assertEqual(logs.length, 10, 'all logs read');
+      assertEqual(warnings.length, 10, 'each failure is logged');
+    } finally {
+      console.log = originalLog;
+      console.warn = originalWarn;
+      cleanupDir(tempDir);
+    }
+  });
+
   // -------------------------------------------------------------------------
   // Summary
   // -------------------------------------------------------------------------
@@ -28,6 +28,16 @@ const EVENT_FILTER_OPTIONS = [
   { value: 'zone_change_gy_to_bf', label: 'Reanimate' },
   { value: 'commander_cast', label: 'Commander' },
   { value: 'draw_extra', label: 'Draw' },
+  { value: 'mill', label: 'Mill' },
+  { value: 'library_manip', label: 'Scry/Surveil' },
+  { value: 'free_cast', label: 'Free Cast' },
+  { value: 'alt_cost_cast', label: 'Flashback/Escape' },
+  { value: 'clone', label: 'Clone' },
+  { value: 'goad', label: 'Goad' },
+  { value: 'protection', label: 'Protection' },
+  { value: 'land_destruction', label: 'Land Destruction' },
+  { value: 'creature_death', label: 'Creature Death' },
+  { value: 'ritual', label: 'Ritual' },
 ] as const;
 
 function formatDurationMs(ms: number): string {
@@ -820,6 +830,26 @@ function getEventColor(eventType: string | undefined): string {
       return '#c084fc'; // purple-400
     case 'draw_extra':
       return '#22d3ee'; // cyan-400
+    case 'mill':
+      return '#a3a3a3'; // neutral-400
+    case 'library_manip':
+      return '#818cf8'; // indigo-400
+    case 'free_cast':
+      return '#f472b6'; // pink-400
+    case 'alt_cost_cast':
+      return '#2dd4bf'; // teal-400
+    case 'clone':
+      return '#e879f9'; // fuchsia-400
+    case 'goad':
+      return '#fb7185'; // rose-400
+    case 'protection':
+      return '#e5e7eb'; // gray-200
+    case 'land_destruction':
+      return '#b45309'; // amber-700
+    case 'creature_death':
+      return '#78716c'; // stone-500
+    case 'ritual':
+      return '#facc15'; // yellow-400
     case 'combat':
       return '#fb923c'; // orange-400
     default:
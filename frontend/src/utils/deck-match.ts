@@ -16,6 +16,130 @@ export function matchesDeckName(fullName: string, shortName: string): boolean {
   return false;
 }
 
-export function resolveWinnerName(fullName: string, deckNames: string[]): string {
-  return deckNames.find((name) => matchesDeckName(fullName, name)) ?? fullName;
+/**
+ * Maps seat labels used in a log (e.g. "Player 1") to deck names, for
+ * simulators that don't echo deck names into the log. Labels are compared
+ * ignoring case and whitespace.
+ */
+export type SeatMap = Record<string, string>;
+
+function normalizeSeatLabel(label: string): string {
+  return label.toLowerCase().replace(/\s+/g, '');
+}
+
+/**
+ * Builds the default seat map from deck order: "Player N", "Seat N" and a
+ * bare "Ai(N)" all map to deckNames[N-1].
+ */
+export function seatMapFromDeckNames(deckNames: string[]): SeatMap {
+  const map: SeatMap = {};
+  deckNames.forEach((name, i) => {
+    if (!name) return;
+    map[`Player ${i + 1}`] = name;
+    map[`Seat ${i + 1}`] = name;
+    map[`Ai(${i + 1})`] = name;
+  });
+  return map;
+}
+
+/**
+ * Returns the deck name for a seat label, or `name` unchanged if the map
+ * has no entry for it.
+ */
+export function resolveSeatLabel(name: string, seatMap: SeatMap): string {
+  const key = normalizeSeatLabel(name);
+  for (const [label, deckName] of Object.entries(seatMap)) {
+    if (normalizeSeatLabel(label) === key) return deckName;
+  }
+  return name;
+}
+
+/**
+ * Data-driven rules for normalizing deck labels, so the same deck under
+ * slightly different labels ("Precon: Blood Rites", "blood  rites",
+ * "Blood Rites - The Lost Caverns of Ixalan Commander") gets one key.
+ */
+export interface NormalizeRules {
+  lowercase: boolean;
+  collapseWhitespace: boolean;
+  /** Prefixes removed from the start (case-insensitive) */
+  stripPrefixes: string[];
+  /** Regex sources removed from the end (case-insensitive) */
+  stripSuffixes: string[];
+}
+
+export const DEFAULT_NORMALIZE_RULES: NormalizeRules = {
+  lowercase: true,
+  collapseWhitespace: true,
+  stripPrefixes: ['Precon:', 'Precon -', '[Precon]'],
+  stripSuffixes: [' - .+ Commander(?: Deck)?', ' ?\\((?:precon|upgraded)\\)'],
+};
+
+/**
+ * Normalizes a deck label into a key for grouping.
+ */
+export function normalizeDeckName(name: string, rules: NormalizeRules = DEFAULT_NORMALIZE_RULES): string {
+  let key = name.trim();
+  if (rules.collapseWhitespace) key = key.replace(/\s+/g, ' ');
+  for (const prefix of rules.stripPrefixes) {
+    if (key.toLowerCase().startsWith(prefix.toLowerCase())) {
+      key = key.slice(prefix.length).trim();
+    }
+  }
+  for (const suffix of rules.stripSuffixes) {
+    key = key.replace(new RegExp(`(?:${suffix})$`, 'i'), '').trim();
+  }
+  if (rules.lowercase) key = key.toLowerCase();
+  return key;
+}
+
+/**
+ * Maps names a simulator may report a winner by (usually the commander,
+ * e.g. "Atraxa, Praetors' Voice") to deck names. Both sides are compared
+ * as normalized labels.
+ */
+export type WinnerAliases = Record<string, string>;
+
+/**
+ * Returns the deck an aliased label refers to, or undefined when the label
+ * has no alias or its deck isn't one of `deckNames`.
+ */
+function resolveAlias(
+  label: string,
+  deckNames: string[],
+  aliases: WinnerAliases,
+  rules: NormalizeRules
+): string | undefined {
+  const key = normalizeDeckName(label, rules);
+  for (const [alias, deck] of Object.entries(aliases)) {
+    if (normalizeDeckName(alias, rules) !== key) continue;
+    const target = normalizeDeckName(deck, rules);
+    return deckNames.find((name) => normalizeDeckName(name, rules) === target);
+  }
+  return undefined;
+}
+
+/**
+ * Finds the matching short deck name for a full winner string, or returns
+ * the original string if no match is found.
+ *
+ * Seat labels are resolved first, using `seatMap` or, by default, the deck
+ * order. Then `aliases` (commander name -> deck) are tried. Without
+ * either, falls back to name matching, then to comparing normalized labels.
+ */
+export function resolveWinnerName(
+  fullName: string,
+  deckNames: string[],
+  seatMap: SeatMap = seatMapFromDeckNames(deckNames),
+  rules: NormalizeRules = DEFAULT_NORMALIZE_RULES,
+  aliases: WinnerAliases = {}
+): string {
+  const seated = resolveSeatLabel(fullName, seatMap);
+  const label = seated.replace(/^Ai\(\d+\)-/, '');
+  const aliased = resolveAlias(label, deckNames, aliases, rules);
+  if (aliased) return aliased;
+  const matched = deckNames.find((name) => matchesDeckName(seated, name));
+  if (matched) return matched;
+  const key = normalizeDeckName(label, rules);
+  return deckNames.find((name) => normalizeDeckName(name, rules) === key) ?? fullName;
 }
@@ -1,7 +1,7 @@
 export type { JobStatus, JobResults, WorkersSummary, JobResponse, JobSummary } from './job';
 export { GAMES_PER_CONTAINER } from './job';
 export type { SimulationState, SimulationStatus } from './simulation';
-export type { EventType, GameEvent, TurnManaInfo, DeckTurnInfo, CondensedGame, DeckAction, DeckTurnActions, DeckHistory, StructuredGame } from './log';
+export type { EventType, GameEvent, KillInfo, WinReason, GameEndReason, TurnManaInfo, TurnCastInfo, TempoPoint, MulliganInfo, DeckTurnInfo, CondensedGame, DeckAction, DeckTurnActions, DeckHistory, TurnDelta, StructuredGame } from './log';
 export type { WorkerInfo } from './worker';
 export type { ApiErrorResponse, ApiUpdateResponse } from './api';
 export {
@@ -10,6 +10,8 @@
  * a compile error in both projects.
  */
 
+import type { TempoPoint } from './log';
+
 // ---------------------------------------------------------------------------
 // Job status enum
 // ---------------------------------------------------------------------------
@@ -25,8 +27,40 @@ export interface JobResults {
   wins: Record<string, number>;
   /** Per-deck average winning turn. Key = deck name, value = avg turn */
   avgWinTurn: Record<string, number>;
-  /** Total games actually played (may be < simulations if some failed) */
+  /** Total games actually played (may be < simulations if some failed), excluding low-signal games */
   gamesPlayed: number;
+  /** Games too short or quiet to count (see low-signal.ts); left out of every other statistic */
+  lowSignalGames?: number;
+  /** How much to trust the win rates given gamesPlayed, e.g. "low (<20 games)" */
+  confidence?: string;
+  /** Per-deck heuristic explosiveness score (0-100). Key = deck name */
+  explosiveness?: Record<string, number>;
+  /** Per-deck average rituals (spells that add mana, e.g. Dark Ritual) resolved per game. Key = deck name */
+  ritualsPerGame?: Record<string, number>;
+  /** Games by how they ended (combat, combo, concession, ..., draw); each game counts once */
+  winReasonCounts?: Record<string, number>;
+  /** Average round the first player was eliminated, over games with an elimination; 0 when no game had one */
+  avgFirstBloodTurn?: number;
+  /** Per-round mana, spells and cards drawn averaged over the games still going, with a blended tempo (0-1) */
+  tempoCurve?: TempoPoint[];
+  /** Game length percentiles in turns (p50/p90/p99/min/max), excluding games with no winner */
+  turnCountPercentiles?: { p50: number; p90: number; p99: number; min: number; max: number };
+  /** 0-based game indices illustrating each outcome (median win, fastest win, stalled game, draw) */
+  representativeGames?: { medianWin?: number; fastestWin?: number; stalled?: number; draw?: number };
+  /** Per-deck wins from behind (last on life or board at the midpoint). Key = deck name */
+  comebackWins?: Record<string, number>;
+  /** Distinct AI profiles each deck was played by, sorted. Key = deck name; absent when no game logged profiles */
+  aiProfiles?: Record<string, string[]>;
+  /** Per-deck games as the pod's archenemy (targeted beyond its board presence). Key = deck name */
+  archenemyCounts?: Record<string, number>;
+  /** Per-deck early life pressure (0-100): damage dealt opponents in the first 5 rounds. Key = deck name */
+  aggressionIndex?: Record<string, number>;
+  /** Per-deck average kept hand size (1 decimal), over games that logged mulligan lines. Key = deck name */
+  avgKeptHandSize?: Record<string, number>;
+  /** Non-empty log files that contained no recognizable game (stored under deadletter/) */
+  deadLetterCount?: number;
+  /** Repeats of an already-ingested game dropped so they aren't counted twice (see DEDUPE_GAMES) */
+  duplicateGames?: number;
 }
 
 // ---------------------------------------------------------------------------
@@ -19,7 +19,17 @@ export type EventType =
   | 'win_condition'
   | 'commander_cast'
   | 'combat'
-  | 'draw_extra';
+  | 'draw_extra'
+  | 'mill'
+  | 'library_manip'
+  | 'free_cast'
+  | 'alt_cost_cast'
+  | 'clone'
+  | 'goad'
+  | 'protection'
+  | 'land_destruction'
+  | 'creature_death'
+  | 'ritual';
 
 // ---------------------------------------------------------------------------
 // Condensed game (for AI bracket analysis)
@@ -30,25 +40,193 @@ export interface GameEvent {
   line: string;
   turn?: number;
   player?: string;
+  repeat?: number;
+  /** The acting player's color identity, e.g. ["B", "R"] */
+  playerColors?: string[];
+  /** 1-based line in the game's raw log; set when line numbers are requested */
+  lineNo?: number;
+  /** An attack forced by goad (combat events only) */
+  goaded?: boolean;
 }
 
+/**
+ * What dealt the final damage of a game. Heuristic: read from the damage
+ * lines just before the elimination lines.
+ */
+export interface KillInfo {
+  /** The eliminated player the damage was dealt to */
+  victim: string;
+  /** The card that dealt the damage, e.g. "Lightning Bolt (5)" */
+  source: string;
+  /** Combat damage, a spell that was cast, or any other ability/trigger */
+  type: 'combat' | 'spell' | 'ability';
+  /** The attacking or casting player, when it can be determined */
+  player?: string;
+}
+
+/**
+ * How a winner was determined when no win line named them.
+ *   - last_standing: every other player was eliminated
+ */
+export type WinReason = 'last_standing';
+
+/**
+ * How a game ended; see win-reason.ts. 'draw' is a game with no winner.
+ */
+export type GameEndReason =
+  | 'combat'
+  | 'commander_damage'
+  | 'combo'
+  | 'mill'
+  | 'poison'
+  | 'concession'
+  | 'last_standing'
+  | 'draw';
+
 export interface TurnManaInfo {
   manaEvents: number;
 }
 
+/** Spells one player cast in one turn, against the mana they added */
+export interface TurnCastInfo {
+  player?: string;
+  casts: number;
+  /** Mana added by mana abilities and rituals that turn */
+  manaAdded: number;
+}
+
+/** One round of a job's tempo curve; see tempo.ts */
+export interface TempoPoint {
+  round: number;
+  /** Games still going in this round (the averages' denominator) */
+  games: number;
+  /** Average mana events */
+  mana: number;
+  /** Average spells cast */
+  spells: number;
+  /** Average cards drawn */
+  cardsDrawn: number;
+  /** Blend of the three, each scaled by its peak round (0-1) */
+  tempo: number;
+}
+
+/** One player's mulligans before the game */
+export interface MulliganInfo {
+  mulligans: number;
+  /** Hand size after each mulligan, in order, e.g. [6, 5] */
+  handSizes: number[];
+  /** Hand size kept; 7 without a mulligan */
+  keptHandSize: number;
+}
+
 export interface DeckTurnInfo {
   turnsTaken: number;
   lastSegment: number;
 }
 
 export interface CondensedGame {
+  /** Content hash of the raw log; stable across re-runs and reordering (absent on older artifacts) */
+  gameId?: string;
   keptEvents: GameEvent[];
+  /** Kept events dropped from the analysis payload by MAX_EVENTS_PER_DECK; see event-sampling.ts */
+  omittedEvents?: number;
   manaPerTurn: Record<number, TurnManaInfo>;
   cardsDrawnPerTurn: Record<number, number>;
   turnCount: number;
   winner?: string;
+  /** Set when the winner was inferred rather than read from a win line */
+  winReason?: WinReason;
+  /** Several players won in the same turn; see extractSimultaneousWinners in turns.ts */
+  simultaneousWin?: boolean;
+  /**
+   * The simultaneous winners (as logged). With SIMULTANEOUS_WIN_SCORING=shared
+   * each is credited a win and `winner` is the first; with draw `winner` is unset.
+   */
+  simultaneousWinners?: string[];
   winningTurn?: number;
   perDeckTurns?: Record<string, DeckTurnInfo>;
+  /** Forge AI profile per player (as logged), e.g. { "Ai(2)-Beta": "Reckless" } */
+  aiProfiles?: Record<string, string>;
+  /** Mulligan sequence and kept hand size per player (as logged) */
+  mulliganDetails?: Record<string, MulliganInfo>;
+  /** A "can't lose / can't win" lock effect appeared; explains games with no winner */
+  lockEffectDetected?: boolean;
+  /** Several consecutive rounds with almost no events (Stasis-style lock) */
+  lockStallDetected?: boolean;
+  /** Round the lock stall started; set with lockStallDetected */
+  lockStallStartRound?: number;
+  /** Mill lines (cards put from a library into a graveyard) */
+  millCount?: number;
+  /** Mill lines where the active player milled their own library */
+  selfMillCount?: number;
+  /** Mill lines where a non-active player was milled */
+  opponentMillCount?: number;
+  /** Scry / surveil lines */
+  libraryManipCount?: number;
+  /** Spells cast for free (cascade, suspend, "without paying its mana cost") */
+  freeCastCount?: number;
+  /** Rituals: spells that add mana when they resolve (Dark Ritual), not land or rock taps */
+  ritualCount?: number;
+  /** Spells cast through an alternative cost (flashback, escape, ...), counted in spell totals too */
+  altCostCastCount?: number;
+  /** Clone and copy lines ("enters as a copy of", "token copy of"); a token copy also counts as a token */
+  cloneCount?: number;
+  /** Goad and "must attack if able" lines; the attacks they forced are marked `goaded` */
+  goadCount?: number;
+  /** Protection lines: gains hexproof/shroud/protection/indestructible, or "can't be countered" */
+  protectionCount?: number;
+  /** Land destruction and forced land sacrifice lines, including mass destruction */
+  landDestructionCount?: number;
+  /** Armageddon-style lines destroying or sacrificing all lands (a board-wipe-scale event) */
+  massLandDestructionCount?: number;
+  /** What dealt the final damage, when it can be determined */
+  killingBlow?: KillInfo;
+  /** Total life lost across the table per round (key = round), from [LIFE] entries */
+  lifeLossPerTurn?: Record<number, number>;
+  /** The table lost life fast enough over consecutive rounds to count as an aggro race (self life payments excluded) */
+  fastClock?: boolean;
+  /** Life players paid or dealt themselves per round (key = round): fetchlands, painlands, Phyrexian mana; see life-payments.ts */
+  selfLifePaymentsPerTurn?: Record<number, number>;
+  /** Self life payments by source category (fetch, painland, phyrexian, city, other) */
+  selfLifePaymentsByCategory?: Record<string, number>;
+  /** Round the first player lost or conceded; 0 when nobody was eliminated (absent on older artifacts) */
+  firstBloodTurn?: number;
+  /** Creature deaths per round (key = round), from creature_death lines */
+  creatureDeathsPerTurn?: Record<number, number>;
+  /** Player turns with at least one cast, per round (key = round) */
+  castsPerTurn?: Record<number, TurnCastInfo[]>;
+  /** Spells cast per CMC (key = CMC, or "unknown" when the cast line doesn't state one) */
+  castCmcHistogram?: Record<string, number>;
+  /** Rounds with a player turn that cast far more than normal tempo allows (rituals, free spells) */
+  bigTurns?: number[];
+  /** Spells each player cast during other players' turns (counters, instant-speed removal) */
+  offTurnActions?: Record<string, number>;
+  /** Protection and counter-backup lines per round (key = round); see protected-combo.ts */
+  protectionPerTurn?: Record<number, number>;
+  /** The winning round or a big turn had protection or counter backup (a prepared combo) */
+  protectedCombo?: boolean;
+  /** Sources whose upkeep or end-step trigger fired in several turns (Phyrexian Arena); see engines.ts */
+  recurringEngines?: string[];
+  /** Card name -> types (lowercase) from the type lines Forge printed for cast cards; see card-types.ts */
+  cardTypeHints?: Record<string, string[]>;
+  /** Too short or quiet to count toward the job's statistics; see low-signal.ts */
+  lowSignal?: boolean;
+  /** Times each player's cards or hand were countered, removed, bounced or discarded; "unknown" when the owner can't be told */
+  interactionReceived?: Record<string, number>;
+  /** Attacking creatures each player was attacked by; see archenemy.ts */
+  attacksReceived?: Record<string, number>;
+  /** The player targeted far beyond their board presence (as logged); see archenemy.ts */
+  archenemy?: string;
+  /** Cards each player drew, symmetric draws credited to every player they reach; see draws.ts */
+  cardsDrawnByPlayer?: Record<string, number>;
+  /** Draw effects that reached the whole table (Temple Bell, Howling Mine, "each player draws") */
+  symmetricDrawEvents?: number;
+  /** Combat and direct damage each player dealt opponents in the first 5 rounds; see aggression.ts */
+  earlyDamageDealt?: Record<string, number>;
+  /** Extra turns each player took (a turn right after their own); see extra-turns.ts */
+  extraTurns?: Record<string, number>;
+  /** The winner chained 2+ extra turns through the end of the game (Time Warp loops) */
+  extraTurnCombo?: boolean;
 }
 
 // ---------------------------------------------------------------------------
@@ -70,6 +248,21 @@ export interface DeckHistory {
   turns: DeckTurnActions[];
 }
 
+/**
+ * What changed in one round, so a replay can apply rounds incrementally.
+ * Fields with nothing to report are omitted.
+ */
+export interface TurnDelta {
+  /** Life gained (positive) or lost (negative) per player since the previous round; round 1 is against starting life */
+  life?: Record<string, number>;
+  /** Non-token permanents that entered the battlefield, per player */
+  permanentsAdded?: Record<string, number>;
+  /** Creatures that died (the log doesn't say whose) */
+  permanentsRemoved?: number;
+  /** Cards drawn by all players */
+  cardsDrawn?: number;
+}
+
 export interface StructuredGame {
   totalTurns: number;
   players: string[];
@@ -79,10 +272,35 @@ export interface StructuredGame {
       playerId: string;
       lines: string[];
     }[];
+    /** Changes since the previous round; absent when nothing changed */
+    delta?: TurnDelta;
   }[];
   decks: DeckHistory[];
   lifePerTurn?: Record<number, Record<string, number>>;
+  /** Non-token permanents entering the battlefield, by round then player */
+  boardDevelopmentPerTurn?: Record<number, Record<string, number>>;
   perDeckTurns?: Record<string, DeckTurnInfo>;
   winner?: string;
+  /** Set when the winner was inferred rather than read from a win line */
+  winReason?: WinReason;
+  /** Several players won in the same turn; see extractSimultaneousWinners in turns.ts */
+  simultaneousWin?: boolean;
+  /**
+   * The simultaneous winners (as logged). With SIMULTANEOUS_WIN_SCORING=shared
+   * each is credited a win and `winner` is the first; with draw `winner` is unset.
+   */
+  simultaneousWinners?: string[];
+  /** How the game ended (combat, combo, concession, ...) */
+  endReason?: GameEndReason;
   winningTurn?: number;
+  /** Round the first player lost or conceded; 0 when nobody was eliminated */
+  firstBloodTurn?: number;
+  /** The winner was last on life or board at the game's midpoint (see comeback.ts) */
+  comebackWin?: boolean;
+  /** Too short or quiet to count toward the job's statistics; see low-signal.ts */
+  lowSignal?: boolean;
+  /** Forge AI profile per player (as logged); see ai-profile.ts */
+  aiProfiles?: Record<string, string>;
+  /** Mulligan sequence and kept hand size per player (as logged); see mulligan.ts */
+  mulliganDetails?: Record<string, MulliganInfo>;
 }
@@ -29,4 +29,19 @@ export interface SimulationStatus {
   winners?: string[];
   /** Winning turns for each game in this container batch (multi-game containers) */
   winningTurns?: number[];
+  /**
+   * Wall-clock time spent in each worker pipeline stage, keyed by stage name
+   * (see SIMULATION_STAGES). Best-effort: stages that didn't run are omitted.
+   */
+  stageDurationsMs?: Partial<Record<SimulationStage, number>>;
 }
+
+/**
+ * Named stages of a simulation on the worker, in pipeline order:
+ *   - fetch: load job/deck data from the API
+ *   - run: run the Forge container
+ *   - condense: split the log and extract winners/turns
+ *   - upload: send the raw log to the API (before the COMPLETED status)
+ */
+export const SIMULATION_STAGES = ['fetch', 'run', 'condense', 'upload'] as const;
+export type SimulationStage = (typeof SIMULATION_STAGES)[number];
@@ -0,0 +1,111 @@
+/**
+ * Unit tests for the job attempt budget and the final-attempt partial path.
+ * Run with: npx tsx src/attempts.test.ts
+ */
+
+import {
+  resolveJobMaxAttempts,
+  jobAttempt,
+  isFinalAttempt,
+  partialResult,
+  DEFAULT_JOB_MAX_ATTEMPTS,
+} from './attempts.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+function test(name: string, fn: () => void) {
+  try {
+    fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+const completeGame = [
+  'Turn: Turn 1 (Ai(1)-Alpha)',
+  'Land: Ai(1)-Alpha played Forest (1)',
+  'Turn: Turn 2 (Ai(2)-Beta)',
+  'Turn: Turn 3 (Ai(1)-Alpha)',
+  'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+  'Game Result: Game 1 ended in 120 ms. Ai(1)-Alpha has won!',
+].join('\n');
+
+// The container died mid-way through game 2
+const truncatedGame = [
+  'Turn: Turn 1 (Ai(2)-Beta)',
+  'Land: Ai(2)-Beta played Island (2)',
+].join('\n');
+
+console.log('Running attempt budget tests...\n');
+
+test('jobAttempt: 1-based from retryCount', () => {
+  assertEqual(jobAttempt({}), 1, 'no retryCount');
+  assertEqual(jobAttempt({ retryCount: 0 }), 1, 'first attempt');
+  assertEqual(jobAttempt({ retryCount: 2 }), 3, 'third attempt');
+});
+
+test('isFinalAttempt: true once the budget is reached', () => {
+  assertEqual(isFinalAttempt({ retryCount: 1 }, 3), false, 'attempt 2 of 3');
+  assertEqual(isFinalAttempt({ retryCount: 2 }, 3), true, 'attempt 3 of 3');
+  assertEqual(isFinalAttempt({ retryCount: 5 }, 3), true, 'past the budget');
+  assertEqual(isFinalAttempt({}, 1), true, 'budget of one');
+});
+
+test('resolveJobMaxAttempts: env override with a default', () => {
+  assertEqual(resolveJobMaxAttempts({}), DEFAULT_JOB_MAX_ATTEMPTS, 'unset');
+  assertEqual(resolveJobMaxAttempts({ JOB_MAX_ATTEMPTS: '5' }), 5, 'set');
+  assertEqual(resolveJobMaxAttempts({ JOB_MAX_ATTEMPTS: '0' }), DEFAULT_JOB_MAX_ATTEMPTS, 'invalid');
+});
+
+test('partialResult: keeps only complete games from a failed run', () => {
+  const partial = partialResult(`${completeGame}\n${truncatedGame}`, 'Exit code 137');
+  assertEqual(partial?.winners.join(','), 'Ai(1)-Alpha', 'winners');
+  assertEqual(partial?.winningTurns.join(','), '2', 'winning turns');
+  assertEqual(partial?.logText, completeGame, 'truncated game dropped from the upload');
+  assertEqual(
+    partial?.note,
+    'Partial: 1 complete game(s) kept after final attempt failed (Exit code 137)',
+    'note'
+  );
+});
+
+test('partialResult: a simultaneous-win draw counts as complete', () => {
+  const drawnGame = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Ai(1)-Alpha wins the game.',
+    'Ai(2)-Beta wins the game.',
+  ].join('\n');
+  const partial = partialResult(`${drawnGame}\n${truncatedGame}`, 'Exit code 137');
+  assertEqual(partial?.logText, drawnGame, 'drawn game kept');
+  assertEqual(partial?.winners.length, 0, 'no winner reported for a draw');
+});
+
+test('partialResult: null when no game finished', () => {
+  assertEqual(partialResult(truncatedGame, 'Timeout'), null, 'truncated only');
+  assertEqual(partialResult('', 'Timeout'), null, 'empty log');
+});
+
+console.log('\n-------------------');
+const passed = results.filter((r) => r.passed).length;
+const failed = results.filter((r) => !r.passed).length;
+console.log(`Passed: ${passed}`);
+console.log(`Failed: ${failed}`);
+if (failed > 0) process.exit(1);
@@ -0,0 +1,61 @@
+/**
+ * Job-level attempt budget.
+ *
+ * Each sim is retried locally (worker.ts), and a job whose worker dies is
+ * reset and re-run by the API, bumping its `retryCount`. A sim that keeps
+ * failing would leave a job with no usable output, so on the job's final
+ * allowed attempt a failed sim keeps whatever complete games its log has:
+ * it uploads them and reports COMPLETED with a "Partial" note instead of
+ * FAILED. With no complete game it still fails.
+ */
+
+import type { JobData } from './types.js';
+import { splitConcatenatedGames, extractWinner, extractWinningTurn, extractSimultaneousWinners } from './condenser.js';
+
+export const DEFAULT_JOB_MAX_ATTEMPTS = 3;
+
+/** Reads JOB_MAX_ATTEMPTS; invalid or unset values use the default. */
+export function resolveJobMaxAttempts(env: NodeJS.ProcessEnv = process.env): number {
+  const value = parseInt(env.JOB_MAX_ATTEMPTS ?? '', 10);
+  return Number.isFinite(value) && value > 0 ? value : DEFAULT_JOB_MAX_ATTEMPTS;
+}
+
+/** 1-based attempt number of a job, from the API's retryCount. */
+export function jobAttempt(job: Pick<JobData, 'retryCount'>): number {
+  const retries = Math.floor(Number(job.retryCount));
+  return Number.isFinite(retries) && retries > 0 ? retries + 1 : 1;
+}
+
+export function isFinalAttempt(job: Pick<JobData, 'retryCount'>, maxAttempts: number): boolean {
+  return jobAttempt(job) >= maxAttempts;
+}
+
+export interface PartialResult {
+  /** The complete games only, joined back into one log */
+  logText: string;
+  winners: string[];
+  winningTurns: number[];
+  /** Note recorded as the sim's errorMessage */
+  note: string;
+}
+
+/**
+ * Salvages the complete games (those with a winner, or a simultaneous win
+ * scored as a draw) from a failed run's log.
+ *
+ * @param logText - The failed container's log
+ * @param error - Why the run failed
+ * @returns The partial result, or null when no game finished
+ */
+export function partialResult(logText: string, error: string): PartialResult | null {
+  const games = splitConcatenatedGames(logText)
+    .filter((game) => extractWinner(game) || extractSimultaneousWinners(game).length > 0);
+  if (games.length === 0) return null;
+  const winningTurns = games.map(extractWinningTurn).filter((t) => t > 0);
+  return {
+    logText: games.join('\n'),
+    winners: games.map((game) => extractWinner(game)).filter((w) => w),
+    winningTurns,
+    note: `Partial: ${games.length} complete game(s) kept after final attempt failed (${error})`,
+  };
+}
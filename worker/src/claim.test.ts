@@ -0,0 +1,120 @@
+/**
+ * Unit tests for claimSim against a local HTTP server.
+ * Run with: npx tsx src/claim.test.ts
+ */
+
+import * as http from 'http';
+import type { AddressInfo } from 'net';
+import { claimSim } from './claim.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+/** Starts a server with the given handler and returns its base URL and a close function. */
+async function withServer(
+  handler: http.RequestListener,
+  fn: (apiUrl: string) => Promise<void>
+): Promise<void> {
+  const server = http.createServer(handler);
+  await new Promise<void>((resolve) => server.listen(0, '127.0.0.1', resolve));
+  const { port } = server.address() as AddressInfo;
+  try {
+    await fn(`http://127.0.0.1:${port}`);
+  } finally {
+    await new Promise<void>((resolve) => server.close(() => resolve()));
+  }
+}
+
+const HEADERS = { 'Content-Type': 'application/json', 'X-Worker-Secret': 'shh' };
+
+async function main() {
+  console.log('Running claim-sim client tests...\n');
+
+  await test('200 returns the claimed sim and sends auth + worker identity', async () => {
+    let seenPath = '';
+    let seenSecret: string | undefined;
+    await withServer((req, res) => {
+      seenPath = req.url ?? '';
+      seenSecret = req.headers['x-worker-secret'] as string | undefined;
+      res.writeHead(200, { 'Content-Type': 'application/json', 'X-Max-Concurrent-Override': '3' });
+      res.end(JSON.stringify({ jobId: 'job-1', simId: 'sim_002', simIndex: 2 }));
+    }, async (apiUrl) => {
+      const result = await claimSim(apiUrl, HEADERS, 'worker-1', 'gpu box', 1000);
+      assertEqual(result.status, 200, 'status');
+      assertEqual(result.sim?.jobId, 'job-1', 'jobId');
+      assertEqual(result.sim?.simId, 'sim_002', 'simId');
+      assertEqual(result.sim?.simIndex, 2, 'simIndex');
+      assertEqual(result.overrideHeader, '3', 'override header');
+    });
+    assertEqual(seenPath, '/api/jobs/claim-sim?workerId=worker-1&workerName=gpu+box', 'request path');
+    assertEqual(seenSecret, 'shh', 'X-Worker-Secret header');
+  });
+
+  await test('jobs at their limit are sent as excludeJobs', async () => {
+    let seenPath = '';
+    await withServer((req, res) => {
+      seenPath = req.url ?? '';
+      res.writeHead(204);
+      res.end();
+    }, async (apiUrl) => {
+      await claimSim(apiUrl, HEADERS, 'worker-1', 'w', 1000, ['job-1', 'job-2']);
+    });
+    assertEqual(seenPath, '/api/jobs/claim-sim?workerId=worker-1&workerName=w&excludeJobs=job-1%2Cjob-2', 'request path');
+  });
+
+  await test('204 returns a null sim (no work available)', async () => {
+    await withServer((_req, res) => {
+      res.writeHead(204);
+      res.end();
+    }, async (apiUrl) => {
+      const result = await claimSim(apiUrl, HEADERS, 'worker-1', 'w', 1000);
+      assertEqual(result.status, 204, 'status');
+      assertEqual(result.sim, null, 'sim');
+      assertEqual(result.overrideHeader, null, 'override header');
+    });
+  });
+
+  await test('unexpected status returns a null sim with the status', async () => {
+    await withServer((_req, res) => {
+      res.writeHead(503);
+      res.end('unavailable');
+    }, async (apiUrl) => {
+      const result = await claimSim(apiUrl, HEADERS, 'worker-1', 'w', 1000);
+      assertEqual(result.status, 503, 'status');
+      assertEqual(result.sim, null, 'sim');
+    });
+  });
+
+  console.log('\n-------------------');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}`);
+  console.log(`Failed: ${failed}`);
+  if (failed > 0) process.exit(1);
+}
+
+main();
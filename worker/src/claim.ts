@@ -0,0 +1,48 @@
+/**
+ * Client for the API's `/api/jobs/claim-sim` endpoint, which atomically
+ * leases the next PENDING simulation to this worker. Used by the polling
+ * loop in worker.ts.
+ *
+ * A 204 (no work available) is not an error: it returns `sim: null` so the
+ * caller can fall back to requesting coverage work and sleeping. Jobs in
+ * excludeJobIds (those already at their parallelism on this worker, see
+ * parallelism.ts) are skipped by the API. Network
+ * errors and timeouts are thrown; any other status is returned as-is for
+ * the caller to log.
+ */
+
+export interface ClaimedSim {
+  jobId: string;
+  simId: string;
+  simIndex: number;
+}
+
+export interface ClaimResult {
+  /** The leased simulation, or null when the API has no work (204) */
+  sim: ClaimedSim | null;
+  /** HTTP status of the claim response */
+  status: number;
+  /** Raw X-Max-Concurrent-Override header (see override.ts) */
+  overrideHeader: string | null;
+}
+
+export async function claimSim(
+  apiUrl: string,
+  headers: Record<string, string>,
+  workerId: string,
+  workerName: string,
+  timeoutMs: number,
+  excludeJobIds: string[] = []
+): Promise<ClaimResult> {
+  const claimUrl = new URL(`${apiUrl}/api/jobs/claim-sim`);
+  claimUrl.searchParams.set('workerId', workerId);
+  claimUrl.searchParams.set('workerName', workerName);
+  if (excludeJobIds.length > 0) claimUrl.searchParams.set('excludeJobs', excludeJobIds.join(','));
+  const res = await fetch(claimUrl.toString(), {
+    headers,
+    signal: AbortSignal.timeout(timeoutMs),
+  });
+  const overrideHeader = res.headers.get('X-Max-Concurrent-Override');
+  const sim = res.status === 200 ? ((await res.json()) as ClaimedSim) : null;
+  return { sim, status: res.status, overrideHeader };
+}
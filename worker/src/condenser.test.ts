@@ -14,9 +14,16 @@
  */
 
 import {
+  checkDeckCount,
+  countPlayers,
   extractWinner,
   extractWinningTurn,
+  extractSimultaneousWinners,
+  getSimultaneousWinScoring,
+  getWinnerAliases,
+  applyWinnerAlias,
   splitConcatenatedGames,
+  trimWinnerCapture,
 } from './condenser.js';
 
 // ---------------------------------------------------------------------------
@@ -89,6 +96,114 @@ test('extractWinner: handles Ai-prefixed full deck name', () => {
   assert(winner.includes('Blood Rites'), 'should contain Blood Rites');
 });
 
+test('extractWinner: decorated win lines capture just the name', () => {
+  assertEqual(
+    extractWinner('Turn 1: Alice\nTurn 1: Bob\nTurn 7: Alice attacks, Alice wins the game\n'),
+    'Alice',
+    'clause before the name dropped'
+  );
+  assertEqual(
+    extractWinner('Turn: Turn 1 (Ai(1)-Alpha)\nGame Result: Game 1 ended in 600 ms. Ai(1)-Alpha has won!\n'),
+    'Ai(1)-Alpha',
+    'Game Result prefix stripped'
+  );
+  assertEqual(
+    extractWinner("Turn: Turn 1 (Ai(1)-Alpha)\nAtraxa, Praetors' Voice has won!\n"),
+    "Atraxa, Praetors' Voice",
+    'a name with a comma is kept whole'
+  );
+});
+
+test('extractWinner: falls back to the last player standing', () => {
+  const log = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Turn: Turn 3 (Ai(3)-Gamma)',
+    'Game outcome: Ai(2)-Beta has lost because life total reached 0',
+    'Game outcome: Ai(3)-Gamma has conceded',
+  ].join('\n');
+  assertEqual(extractWinner(log), 'Ai(1)-Alpha', 'only Alpha is left');
+  assertEqual(extractWinningTurn(log), 1, "Alpha's turns");
+  assertEqual(extractWinner(log.split('\n').slice(0, 4).join('\n')), '', 'two players still in');
+});
+
+test('extractWinner: two win lines in one turn follow SIMULTANEOUS_WIN_SCORING', () => {
+  const log = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Turn: Turn 3 (Ai(1)-Alpha)',
+    'Ai(1)-Alpha wins the game.',
+    'Ai(2)-Beta wins the game.',
+    'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+  ].join('\n');
+  const previous = process.env.SIMULTANEOUS_WIN_SCORING;
+  try {
+    assertEqual(extractSimultaneousWinners(log).join(','), 'Ai(1)-Alpha,Ai(2)-Beta', 'both winners, repeat folded');
+    delete process.env.SIMULTANEOUS_WIN_SCORING;
+    assertEqual(extractWinner(log), '', 'draw by default');
+    process.env.SIMULTANEOUS_WIN_SCORING = 'shared';
+    assertEqual(extractWinner(log), 'Ai(1)-Alpha', 'shared: the first winner');
+    process.env.SIMULTANEOUS_WIN_SCORING = 'coinflip';
+    let threw = false;
+    try {
+      getSimultaneousWinScoring();
+    } catch (err) {
+      threw = err instanceof Error && err.message.startsWith('Invalid SIMULTANEOUS_WIN_SCORING');
+    }
+    assertEqual(threw, true, 'unknown scoring rejected');
+  } finally {
+    if (previous === undefined) delete process.env.SIMULTANEOUS_WIN_SCORING;
+    else process.env.SIMULTANEOUS_WIN_SCORING = previous;
+  }
+  const apart = log.replace('Ai(2)-Beta wins the game.', 'Turn: Turn 4 (Ai(2)-Beta)\nAi(2)-Beta wins the game.');
+  assertEqual(extractSimultaneousWinners(apart).length, 0, 'wins in different turns are not simultaneous');
+});
+
+test('applyWinnerAlias: aliased winners name their deck when it is in the job', () => {
+  const aliases = { "Atraxa, Praetors' Voice": 'Four Color Counters', Krenko: 'Not In This Job' };
+  const decks = ['Four Color Counters', 'Goblins'];
+  assertEqual(applyWinnerAlias("Atraxa, Praetors' Voice", decks, aliases), 'Four Color Counters', 'alias');
+  assertEqual(applyWinnerAlias("Ai(1)-atraxa,  praetors' voice", decks, aliases), 'Four Color Counters', 'normalized, Ai prefix dropped');
+  assertEqual(applyWinnerAlias('Krenko', decks, aliases), 'Krenko', 'deck not in the job');
+  assertEqual(applyWinnerAlias('Ai(2)-Goblins', decks, aliases), 'Ai(2)-Goblins', 'no alias: unchanged');
+});
+
+test('getWinnerAliases: reads WINNER_ALIASES and rejects bad values', () => {
+  const previous = process.env.WINNER_ALIASES;
+  const errorOf = (source: string) => {
+    process.env.WINNER_ALIASES = source;
+    try { getWinnerAliases(); } catch (err) { return (err as Error).message; }
+    return '';
+  };
+  try {
+    delete process.env.WINNER_ALIASES;
+    assertEqual(Object.keys(getWinnerAliases()).length, 0, 'unset');
+    process.env.WINNER_ALIASES = '{"Krenko": "Goblins"}';
+    assertEqual(getWinnerAliases().Krenko, 'Goblins', 'parsed');
+    assertEqual(errorOf('{bad').startsWith('Invalid WINNER_ALIASES'), true, 'invalid JSON');
+    assertEqual(errorOf('["Krenko"]').includes('JSON object'), true, 'array');
+    assertEqual(errorOf('{"Krenko": 1}').includes('must be a string'), true, 'number value');
+  } finally {
+    if (previous === undefined) delete process.env.WINNER_ALIASES;
+    else process.env.WINNER_ALIASES = previous;
+  }
+});
+
+test('extractWinner: loss lines naming a winning opponent are skipped', () => {
+  const log = [
+    'Turn: Turn 1 (Ai(1)-Alpha)',
+    'Turn: Turn 2 (Ai(2)-Beta)',
+    'Game outcome: Ai(2)-Beta has lost because an opponent has won by spell (Thassa\'s Oracle)',
+    'Game outcome: Ai(1)-Alpha has won because all opponents have lost',
+  ].join('\n');
+  assertEqual(extractWinner(log), 'Ai(1)-Alpha', 'winner from the win line');
+});
+
+test('trimWinnerCapture: legacy mode keeps the old capture', () => {
+  assertEqual(trimWinnerCapture('Turn 7: Alice attacks, Alice', ['Alice'], 'legacy'), 'Turn 7: Alice attacks, Alice', 'legacy');
+  assertEqual(trimWinnerCapture('Game outcome: Alice', ['Alice'], 'legacy'), 'Alice', 'Game outcome still stripped');
+});
+
 // ---------------------------------------------------------------------------
 // extractWinningTurn
 // ---------------------------------------------------------------------------
@@ -145,6 +260,29 @@ test('extractWinningTurn: old format (bare "Turn N:") returns 0 (no player attri
   assertEqual(extractWinningTurn(log), 0, 'bare Turn N: with no player returns 0');
 });
 
+test('extractWinningTurn: tolerates indented and prefixed turn markers', () => {
+  const log = [
+    '  Turn: Turn 1 (Alice)', 'stuff',
+    '\tTurn: Turn 2 (Bob)', 'stuff',
+    '[12:00:03] Turn: Turn 3 (Alice)', 'stuff',
+    '0007: Turn: Turn 4 (Bob)', 'stuff',
+    '[12:00:05] Turn 5: Alice', 'stuff',
+    'Alice has won because all opponents have lost',
+  ].join('\n');
+  assertEqual(extractWinningTurn(log), 3, 'Alice took 3 turns');
+});
+
+test('extractWinningTurn: "Turn" mid-line is not a turn marker', () => {
+  const log = [
+    'Turn: Turn 1 (Alice)', 'stuff',
+    'Turn: Turn 2 (Bob)', 'stuff',
+    'Game outcome: Turn 20 (Alice)',
+    'Bob casts Time Walk on Turn 3: Bob',
+    'Bob has won because all opponents have lost',
+  ].join('\n');
+  assertEqual(extractWinningTurn(log), 1, 'only the two real markers count');
+});
+
 // ---------------------------------------------------------------------------
 // splitConcatenatedGames
 // ---------------------------------------------------------------------------
@@ -168,6 +306,35 @@ test('splitConcatenatedGames: two games split by Game Result line', () => {
   assertEqual(games.length, 2, 'two games');
 });
 
+test('splitConcatenatedGames: splits at a turn 1 after a win line', () => {
+  const log = [
+    'Turn: Turn 1 (Alice)',
+    'Turn: Turn 2 (Bob)',
+    'Game outcome: Bob has won because all opponents have lost',
+    'Turn: Turn 1 (Bob)',
+    'Turn: Turn 2 (Alice)',
+    'Game outcome: Alice has won because all opponents have lost',
+  ].join('\n');
+  const games = splitConcatenatedGames(log);
+  assertEqual(games.length, 2, 'two games');
+  assertEqual(extractWinner(games[0]), 'Bob', 'game 1 winner');
+  assertEqual(extractWinner(games[1]), 'Alice', 'game 2 winner');
+});
+
+test('splitConcatenatedGames: turn-reset strategy splits without a win line', () => {
+  const log = [
+    'Turn: Turn 1 (Alice)',
+    'Turn: Turn 2 (Bob)',
+    'Turn: Turn 1 (Bob)',
+    'Turn: Turn 2 (Alice)',
+    'Game outcome: Alice has won because all opponents have lost',
+  ].join('\n');
+  assertEqual(splitConcatenatedGames(log).length, 1, 'default strategy keeps one game');
+  const games = splitConcatenatedGames(log, { strategy: 'turn-reset' });
+  assertEqual(games.length, 2, 'two games');
+  assert(games[1].startsWith('Turn: Turn 1 (Bob)'), 'game 2 starts at its turn 1');
+});
+
 test('splitConcatenatedGames: empty string returns one empty entry', () => {
   const games = splitConcatenatedGames('');
   // Implementation returns [''] (one empty game) for empty input
@@ -197,6 +364,34 @@ test('matchesDeckName parity: precon with set suffix resolves winning turn', ()
   assertEqual(extractWinningTurn(log), 2, 'precon set suffix matches winner turns');
 });
 
+// ---------------------------------------------------------------------------
+// checkDeckCount
+// ---------------------------------------------------------------------------
+
+const threePlayerGame = [
+  'Turn: Turn 1 (Ai(1)-Alpha)', 'stuff',
+  'Turn: Turn 2 (Ai(2)-Beta)', 'stuff',
+  'Turn: Turn 3 (Ai(3)-Gamma)', 'stuff',
+  'Turn: Turn 4 (Ai(1)-Alpha)', 'stuff',
+  'Ai(1)-Alpha has won!',
+].join('\n');
+
+test('countPlayers: counts distinct players, 0 when none are named', () => {
+  assertEqual(countPlayers(threePlayerGame), 3, 'three players');
+  assertEqual(countPlayers('no turns here'), 0, 'no turn markers');
+});
+
+test('checkDeckCount: warns when job decks and log players disagree', () => {
+  const check = checkDeckCount(4, [threePlayerGame, threePlayerGame]);
+  assertEqual(check.observed, 3, 'observed players');
+  assertEqual(check.warning, 'Job has 4 decks but the logs show 3 players', 'warning');
+});
+
+test('checkDeckCount: no warning when counts agree or players are unknown', () => {
+  assertEqual(checkDeckCount(3, [threePlayerGame]).warning, undefined, 'matching counts');
+  assertEqual(checkDeckCount(4, ['Game Result: Game 1 ended']).warning, undefined, 'no players named');
+});
+
 // ---------------------------------------------------------------------------
 // Summary
 // ---------------------------------------------------------------------------
@@ -18,7 +18,7 @@ import {
 const IgnorePriorityPass = /player\s+passes\s+priority/i;
 const IgnoreUntapStep = /untap\s+step/i;
 const IgnoreDrawStep = /draw\s+step/i;
-const IgnoreBareTurn = /^Turn\s+\d+:\s*$/i;
+const IgnoreBareTurn = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn\s+\d+:\s*$/i;
 
 const IgnorePatterns = [
   IgnorePriorityPass,
@@ -39,16 +39,43 @@ const KeepCombat = /attacks?\s+with|declares?\s+attack|combat\s+damage|assigned\
 const KeepLandPlayed = /^Land:/i;
 
 // Extraction patterns
-const ExtractTurnMarkerNew = /^Turn:\s*Turn\s+(\d+)\s*\((.+)\)\s*$/i;
-const ExtractTurnMarkerOld = /^Turn\s+(\d+):\s*(.+?)\s*$/i;
+// Turn markers may be indented or prefixed with a [timestamp] or line number;
+// keep in sync with the turn marker prefix in api/lib/condenser/patterns.ts.
+const ExtractTurnMarkerNew = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn:\s*Turn\s+(\d+)\s*\((.+)\)\s*$/i;
+const ExtractTurnMarkerOld = /^[ \t]*(?:\[[^\]\n]*\][ \t]*|\d+[:.)]?[ \t]+)?Turn\s+(\d+):\s*(.+?)\s*$/i;
 const ExtractManaProduced = /(?:adds?|produces?|tap(s|ped)?\s+for)\s+[\w\s{}\d]*mana|(\d+)\s+mana\s+produced/i;
 const ExtractTapFor = /tap(s|ped)?\s+.*?\s+for/i;
 const ExtractDrawMultiple = /draws?\s+(\d+)\s+cards?/i;
 const ExtractDrawSingle = /draws?\s+(?:a\s+)?card(?!s)/i;
 const ExtractCMC = /\((?:CMC\s*)?(\d+)\)/i;
 const ExtractWinnerRegex = /(.+?)\s+(?:wins\s+the\s+game|has\s+won!?)/i;
+
+// Operator-supplied win line (first capture group = winner), tried before
+// ExtractWinnerRegex. Keep in sync with WIN_LINE_PATTERN in
+// api/lib/condenser/patterns.ts.
+const WIN_LINE_PATTERN_ENV = 'WIN_LINE_PATTERN';
+
+// Decoration before the winner in a win line ("Game Result: Game 1 ended in
+// 600 ms.", "Turn 7:"), stripped from ExtractWinnerRegex's capture. Keep in
+// sync with WIN_LINE_PREFIX and WINNER_CAPTURE in api/lib/condenser/patterns.ts.
+const WinLinePrefix =
+  /^(?:Game\s+outcome:\s*|Game\s+Result:.*?\bended\s+in\s+\d+\s*ms\.\s*|Turn:?\s+(?:Turn\s+)?\d+(?:\s*\([^)]*\))?\s*:?\s*)/i;
+const WINNER_CAPTURE_ENV = 'WINNER_CAPTURE';
+// How a game won by several players in the same turn is scored. Keep in sync
+// with SIMULTANEOUS_WIN_SCORING in api/lib/condenser/turns.ts.
+const SIMULTANEOUS_WIN_SCORING_ENV = 'SIMULTANEOUS_WIN_SCORING';
+// Winners reported by commander (or other alias) instead of deck name, as a
+// JSON object of alias -> deck name. Keep in sync with WINNER_ALIASES in
+// api/lib/condenser/deck-match.ts.
+const WINNER_ALIASES_ENV = 'WINNER_ALIASES';
 const GameResultPattern = /^Game Result: Game (\d+) ended/i;
 
+// Loss and concession lines, naming the player they take out of the game.
+// Keep in sync with EXTRACT_ELIMINATED_PLAYER and EXTRACT_CONCEDED_PLAYER in
+// api/lib/condenser/patterns.ts.
+const ExtractEliminatedPlayer = /^Game outcome:\s*(.{1,120}?)\s+has\s+lost\b|^(.{1,120}?)\s+loses\s+the\s+game\b/i;
+const ExtractConcededPlayer = /^Game outcome:\s*(.{1,120}?)\s+has\s+conceded\b/i;
+
 // ============================================================================
 // Turn Range Extraction (from condenser.go)
 // ============================================================================
@@ -168,9 +195,29 @@ function splitAndFilter(rawLog: string): string[] {
 }
 
 /**
- * Split a log that contains multiple concatenated games
+ * How splitConcatenatedGames finds game boundaries. Keep in sync with
+ * SplitStrategy in api/lib/condenser/patterns.ts.
+ */
+export type SplitStrategy = 'result-line' | 'turn-reset';
+
+export interface SplitOptions {
+  /** Boundary detection strategy (default 'result-line') */
+  strategy?: SplitStrategy;
+}
+
+/**
+ * Split a log that contains multiple concatenated games: after each "Game
+ * Result" line, then at a "Turn 1" line that follows a win line (a best-of-N
+ * match with no result lines). With the 'turn-reset' strategy, also wherever
+ * "Turn 1" follows a higher turn. Keep in sync with splitConcatenatedGames
+ * in api/lib/condenser/patterns.ts.
  */
-export function splitConcatenatedGames(rawLog: string): string[] {
+export function splitConcatenatedGames(rawLog: string, options: SplitOptions = {}): string[] {
+  const games = splitOnResultLines(rawLog).flatMap(splitOnWinLines);
+  return options.strategy === 'turn-reset' ? games.flatMap(splitOnTurnReset) : games;
+}
+
+function splitOnResultLines(rawLog: string): string[] {
   const lines = rawLog.replace(/\r\n/g, '\n').split('\n');
   const games: string[] = [];
   let currentGame: string[] = [];
@@ -202,6 +249,59 @@ export function splitConcatenatedGames(rawLog: string): string[] {
   return games;
 }
 
+function turnNumberOf(line: string): number | undefined {
+  const matches = ExtractTurnMarkerNew.exec(line) ?? ExtractTurnMarkerOld.exec(line);
+  return matches ? parseInt(matches[1], 10) : undefined;
+}
+
+// Splits one game at each "Turn 1" line that comes after a win line. The
+// built-in win phrases only; WIN_LINE_PATTERN doesn't move boundaries.
+function splitOnWinLines(game: string): string[] {
+  const lines = game.split('\n');
+  const games: string[] = [];
+  let start = 0;
+  let won = false;
+
+  lines.forEach((line, i) => {
+    const turn = turnNumberOf(line);
+    if (turn !== undefined) {
+      if (won && turn === 1) {
+        games.push(lines.slice(start, i).join('\n').trim());
+        start = i;
+      }
+      won = false;
+    } else if (ExtractWinnerRegex.test(line)) {
+      won = true;
+    }
+  });
+  if (start === 0) return [game];
+  games.push(lines.slice(start).join('\n').trim());
+
+  return games.filter((g) => g.length > 0);
+}
+
+// Splits one game wherever the turn counter resets to 1 after higher turns.
+function splitOnTurnReset(game: string): string[] {
+  const lines = game.split('\n');
+  const games: string[] = [];
+  let start = 0;
+  let highestTurn = 0;
+
+  lines.forEach((line, i) => {
+    const turn = turnNumberOf(line);
+    if (turn === undefined) return;
+    if (turn === 1 && highestTurn > 1) {
+      games.push(lines.slice(start, i).join('\n').trim());
+      start = i;
+    }
+    highestTurn = turn === 1 ? 1 : Math.max(highestTurn, turn);
+  });
+  if (start === 0) return [game];
+  games.push(lines.slice(start).join('\n').trim());
+
+  return games.filter((g) => g.length > 0);
+}
+
 // ============================================================================
 // Classification Functions (from classify.go)
 // ============================================================================
@@ -295,12 +395,13 @@ function createEvent(
   return event;
 }
 
-function classifyLines(lines: string[]): GameEvent[] {
+function classifyLines(lines: string[], onEvent?: (event: GameEvent) => void): GameEvent[] {
   const events: GameEvent[] = [];
   for (const line of lines) {
     const event = createEvent(line);
     if (event) {
       events.push(event);
+      onEvent?.(event);
     }
   }
   return events;
@@ -375,12 +476,185 @@ function calculateCardsDrawnPerTurn(
   return result;
 }
 
+/**
+ * Compiles and validates a WIN_LINE_PATTERN value. Throws if the regex is
+ * invalid or has no capture group for the winner.
+ */
+export function compileWinLinePattern(source: string): RegExp {
+  let pattern: RegExp;
+  try {
+    pattern = new RegExp(source, 'i');
+  } catch (err) {
+    throw new Error(`Invalid ${WIN_LINE_PATTERN_ENV}: ${err instanceof Error ? err.message : String(err)}`);
+  }
+  const groups = new RegExp(`${source}|`).exec('')!.length - 1;
+  if (groups < 1) {
+    throw new Error(`Invalid ${WIN_LINE_PATTERN_ENV}: needs a capture group for the winner`);
+  }
+  return pattern;
+}
+
+let winLineOverride: { source: string; pattern: RegExp } | undefined;
+
+/**
+ * Returns the compiled WIN_LINE_PATTERN override, or undefined when unset.
+ * Called at startup so a bad pattern stops the worker.
+ */
+export function getWinLinePattern(): RegExp | undefined {
+  const source = process.env[WIN_LINE_PATTERN_ENV]?.trim();
+  if (!source) return undefined;
+  if (winLineOverride?.source !== source) {
+    winLineOverride = { source, pattern: compileWinLinePattern(source) };
+  }
+  return winLineOverride.pattern;
+}
+
+export type WinnerCapture = 'strict' | 'legacy';
+
+/**
+ * Reads WINNER_CAPTURE ('strict' when unset). Called at startup so a bad
+ * value stops the worker.
+ */
+export function getWinnerCapture(): WinnerCapture {
+  const source = process.env[WINNER_CAPTURE_ENV]?.trim().toLowerCase();
+  if (!source) return 'strict';
+  if (source !== 'strict' && source !== 'legacy') {
+    throw new Error(`Invalid ${WINNER_CAPTURE_ENV}: unknown mode "${source}" (expected strict, legacy)`);
+  }
+  return source;
+}
+
+/**
+ * Trims ExtractWinnerRegex's capture to the winner's name: strict mode
+ * strips WinLinePrefix and cuts a capture ending in a known player to that
+ * player. Mirrors api/lib/condenser/turns.ts:trimWinnerCapture.
+ */
+export function trimWinnerCapture(captured: string, players: readonly string[], mode = getWinnerCapture()): string {
+  const legacy = captured.trim().replace(/^Game outcome:\s*/i, '');
+  if (mode === 'legacy') return legacy;
+  const stripped = captured.trim().replace(WinLinePrefix, '').trim();
+  if (!stripped) return legacy;
+  const player = players
+    .filter((p) => stripped === p || (stripped.endsWith(p) && /[\s,.;:]$/.test(stripped.slice(0, -p.length))))
+    .sort((a, b) => b.length - a.length)[0];
+  return player ?? stripped;
+}
+
+/** Distinct players named by the turn markers. */
+function turnPlayers(ranges: TurnRange[]): string[] {
+  return [...new Set(ranges.map((r) => r.player).filter((p) => p))];
+}
+
+/**
+ * Names the player a loss or concession line takes out of the game.
+ * Mirrors api/lib/condenser/turns.ts:eliminatedPlayerOf.
+ */
+function eliminatedPlayerOf(line: string): string | undefined {
+  const trimmed = line.trim();
+  const lost = ExtractEliminatedPlayer.exec(trimmed);
+  return (lost?.[1] ?? lost?.[2] ?? ExtractConcededPlayer.exec(trimmed)?.[1])?.trim() || undefined;
+}
+
+/**
+ * The winner named by each win line, in order: WIN_LINE_PATTERN matches when
+ * there are any, otherwise ExtractWinnerRegex. Loss lines are skipped ("X has
+ * lost because an opponent has won" names the loser). Mirrors
+ * api/lib/condenser/turns.ts:winLines.
+ */
+function winLines(lines: string[], players: readonly string[]): Array<{ index: number; winner: string }> {
+  const override = getWinLinePattern();
+  if (override) {
+    const found = lines.flatMap((line, index) => {
+      const winner = override.exec(line)?.[1]?.trim();
+      return winner ? [{ index, winner }] : [];
+    });
+    if (found.length > 0) return found;
+  }
+
+  return lines.flatMap((line, index) => {
+    if (eliminatedPlayerOf(line)) return [];
+    const match = ExtractWinnerRegex.exec(line);
+    return match ? [{ index, winner: trimWinnerCapture(match[1], players) }] : [];
+  });
+}
+
+/**
+ * The last player standing when eliminations leave exactly one, or '' when
+ * zero or several remain. A player is out once a loss or concession line
+ * names them. Mirrors api/lib/condenser/turns.ts:extractLastStanding.
+ */
+export function extractLastStanding(rawLog: string): string {
+  const players = turnPlayers(extractTurnRanges(rawLog));
+  if (players.length < 2) return '';
+
+  const eliminated: string[] = [];
+  for (const line of rawLog.replace(/\r\n/g, '\n').split('\n')) {
+    const name = eliminatedPlayerOf(line);
+    if (name) eliminated.push(name);
+  }
+
+  const standing = players.filter((p) => !eliminated.some((e) => matchesDeckName(p, e) || matchesDeckName(e, p)));
+  return standing.length === 1 ? standing[0] : '';
+}
+
+export type SimultaneousWinScoring = 'draw' | 'shared';
+
+/**
+ * Reads SIMULTANEOUS_WIN_SCORING ('draw' when unset). Called at startup so a
+ * bad value stops the worker.
+ */
+export function getSimultaneousWinScoring(): SimultaneousWinScoring {
+  const source = process.env[SIMULTANEOUS_WIN_SCORING_ENV]?.trim().toLowerCase();
+  if (!source) return 'draw';
+  if (source !== 'draw' && source !== 'shared') {
+    throw new Error(`Invalid ${SIMULTANEOUS_WIN_SCORING_ENV}: unknown scoring "${source}" (expected draw, shared)`);
+  }
+  return source;
+}
+
+/**
+ * True when two win lines name the same player, e.g. "Game outcome: X has
+ * won" followed by a Game Result line for X. Mirrors
+ * api/lib/condenser/turns.ts:sameWinner.
+ */
+function sameWinner(a: string, b: string): boolean {
+  return a === b || a.endsWith(` ${b}`) || b.endsWith(` ${a}`) || matchesDeckName(a, b) || matchesDeckName(b, a);
+}
+
+/**
+ * Players who won in the same turn: distinct winners whose win lines fall
+ * between the same pair of turn markers. Returns [] when the game didn't end
+ * that way. Mirrors api/lib/condenser/turns.ts:extractSimultaneousWinners.
+ */
+export function extractSimultaneousWinners(rawLog: string): string[] {
+  const lines = rawLog.replace(/\r\n/g, '\n').split('\n');
+  const ranges = extractTurnRanges(rawLog);
+
+  // segment: index of the turn marker before the win line (-1 before the first)
+  const winners: Array<{ winner: string; segment: number }> = [];
+  for (const { index, winner } of winLines(lines, turnPlayers(ranges))) {
+    if (winners.some((w) => sameWinner(w.winner, winner))) continue;
+    winners.push({ winner, segment: ranges.filter((r) => r.startIndex <= index).length - 1 });
+  }
+  if (winners.length < 2) return [];
+  const together = winners.filter((w) => w.segment === winners[0].segment);
+  return together.length > 1 ? together.map((w) => w.winner) : [];
+}
+
+/**
+ * The game's winner: the first win line's, else the last player standing.
+ * A simultaneous win is scored by SIMULTANEOUS_WIN_SCORING: 'draw' reports
+ * no winner, 'shared' the first of them. Returns '' when there's no winner.
+ * Mirrors the winner of api/lib/condenser/turns.ts:resolveWinner.
+ */
 export function extractWinner(rawLog: string): string {
-  const matches = ExtractWinnerRegex.exec(rawLog);
-  if (matches && matches.length > 1) {
-    return matches[1].trim().replace(/^Game outcome:\s*/i, '');
+  const simultaneous = extractSimultaneousWinners(rawLog);
+  if (simultaneous.length > 0) {
+    return getSimultaneousWinScoring() === 'shared' ? simultaneous[0] : '';
   }
-  return '';
+  const lines = rawLog.replace(/\r\n/g, '\n').split('\n');
+  const players = turnPlayers(extractTurnRanges(rawLog));
+  return winLines(lines, players)[0]?.winner ?? extractLastStanding(rawLog);
 }
 
 // Keep in sync with api/lib/condenser/deck-match.ts:matchesDeckName.
@@ -395,6 +669,72 @@ function matchesDeckName(fullName: string, shortName: string): boolean {
   return false;
 }
 
+// Keep in sync with DEFAULT_NORMALIZE_RULES and normalizeDeckName in
+// api/lib/condenser/deck-match.ts.
+const NormalizePrefixes = ['Precon:', 'Precon -', '[Precon]'];
+const NormalizeSuffixes = [/(?: - .+ Commander(?: Deck)?)$/i, /(?: ?\((?:precon|upgraded)\))$/i];
+
+function normalizeDeckName(name: string): string {
+  let key = name.trim().replace(/\s+/g, ' ');
+  for (const prefix of NormalizePrefixes) {
+    if (key.toLowerCase().startsWith(prefix.toLowerCase())) {
+      key = key.slice(prefix.length).trim();
+    }
+  }
+  for (const suffix of NormalizeSuffixes) {
+    key = key.replace(suffix, '').trim();
+  }
+  return key.toLowerCase();
+}
+
+export type WinnerAliases = Record<string, string>;
+
+let aliasesOverride: { source: string; aliases: WinnerAliases } | undefined;
+
+/**
+ * Reads WINNER_ALIASES (an empty table when unset), reparsing only when the
+ * value changes. Called at startup so a bad value stops the worker.
+ */
+export function getWinnerAliases(): WinnerAliases {
+  const source = process.env[WINNER_ALIASES_ENV]?.trim();
+  if (!source) return {};
+  if (aliasesOverride?.source !== source) {
+    let parsed: unknown;
+    try {
+      parsed = JSON.parse(source);
+    } catch (err) {
+      throw new Error(`Invalid ${WINNER_ALIASES_ENV}: ${err instanceof Error ? err.message : String(err)}`);
+    }
+    if (typeof parsed !== 'object' || parsed === null || Array.isArray(parsed)) {
+      throw new Error(`Invalid ${WINNER_ALIASES_ENV}: expected a JSON object of alias -> deck name`);
+    }
+    for (const [alias, deck] of Object.entries(parsed)) {
+      if (typeof deck !== 'string') {
+        throw new Error(`Invalid ${WINNER_ALIASES_ENV}: deck name for "${alias}" must be a string`);
+      }
+    }
+    aliasesOverride = { source, aliases: parsed as WinnerAliases };
+  }
+  return aliasesOverride.aliases;
+}
+
+/**
+ * Swaps an aliased winner (e.g. a commander name) for its deck, so winners[]
+ * names decks the frontend can match. Labels are compared normalized, and an
+ * alias only applies when its deck is one of `deckNames`; other winners are
+ * returned unchanged. Mirrors the alias step of
+ * api/lib/condenser/deck-match.ts:resolveWinnerName.
+ */
+export function applyWinnerAlias(winner: string, deckNames: string[], aliases = getWinnerAliases()): string {
+  const key = normalizeDeckName(winner.replace(/^Ai\(\d+\)-/, ''));
+  for (const [alias, deck] of Object.entries(aliases)) {
+    if (normalizeDeckName(alias) !== key) continue;
+    const target = normalizeDeckName(deck);
+    return deckNames.find((name) => normalizeDeckName(name) === target) ?? winner;
+  }
+  return winner;
+}
+
 // Shape mirrors api/lib/condenser/turns.ts so drift is obvious if the two diverge.
 function calculatePerDeckTurns(ranges: TurnRange[]): Record<string, { turnsTaken: number }> {
   const result: Record<string, { turnsTaken: number }> = {};
@@ -425,6 +765,45 @@ export function extractWinningTurn(rawLog: string): number {
   return getMaxRound(turnRanges, getNumPlayers(turnRanges));
 }
 
+// ============================================================================
+// Deck Count Validation
+// ============================================================================
+
+export interface DeckCountCheck {
+  /** Decks in the job */
+  expected: number;
+  /** Most distinct players seen in any game; 0 when no turn names a player */
+  observed: number;
+  /** Set when the logs show a different number of players than the job has decks */
+  warning?: string;
+}
+
+/**
+ * Count the distinct players named by turn markers (0 if none are named).
+ * Unlike getNumPlayers, there is no 4-player default.
+ */
+export function countPlayers(rawLog: string): number {
+  const players = new Set<string>();
+  for (const range of extractTurnRanges(rawLog)) {
+    if (range.player) players.add(range.player);
+  }
+  return players.size;
+}
+
+/**
+ * Compare the job's deck count with the player count inferred from its logs.
+ * A mismatch means the job and the simulator disagree about the game (e.g.
+ * 4 decks sent, 3-player game played) and the results can't be trusted.
+ */
+export function checkDeckCount(expectedDecks: number, games: string[]): DeckCountCheck {
+  const observed = games.reduce((max, game) => Math.max(max, countPlayers(game)), 0);
+  const check: DeckCountCheck = { expected: expectedDecks, observed };
+  if (observed > 0 && observed !== expectedDecks) {
+    check.warning = `Job has ${expectedDecks} decks but the logs show ${observed} players`;
+  }
+  return check;
+}
+
 // ============================================================================
 // Main Condenser Functions
 // ============================================================================
@@ -433,11 +812,19 @@ export function extractWinningTurn(rawLog: string): number {
  * Condense a single raw game log into a structured summary
  */
 export function condenseGame(rawLog: string): CondensedGame {
+  return condenseGameTo(rawLog);
+}
+
+/**
+ * Condense a single raw game log, passing each kept event to `onEvent` as
+ * soon as it is classified (used to stream a live timeline to the API)
+ */
+export function condenseGameTo(rawLog: string, onEvent?: (event: GameEvent) => void): CondensedGame {
   // Step 1: Filter
   const filteredLines = splitAndFilter(rawLog);
 
   // Step 2: Classify
-  const keptEvents = classifyLines(filteredLines);
+  const keptEvents = classifyLines(filteredLines, onEvent);
 
   // Step 3: Extract metrics
   const turnRanges = extractTurnRanges(rawLog);
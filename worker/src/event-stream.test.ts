@@ -0,0 +1,177 @@
+/**
+ * Unit tests for event-stream.ts (live event batching and the events POST).
+ * Run with: npx tsx src/event-stream.test.ts
+ */
+
+import * as http from 'http';
+import type { AddressInfo } from 'net';
+import {
+  EventBatcher,
+  postEvents,
+  resolveEventStreamOptions,
+  DEFAULT_EVENT_STREAM,
+  MAX_EVENTS_PER_POST,
+} from './event-stream.js';
+import { condenseGame, condenseGameTo } from './condenser.js';
+import type { GameEvent } from './types.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+/** Starts a server with the given handler and returns its base URL and a close function. */
+async function withServer(
+  handler: http.RequestListener,
+  fn: (apiUrl: string) => Promise<void>
+): Promise<void> {
+  const server = http.createServer(handler);
+  await new Promise<void>((resolve) => server.listen(0, '127.0.0.1', resolve));
+  const { port } = server.address() as AddressInfo;
+  try {
+    await fn(`http://127.0.0.1:${port}`);
+  } finally {
+    await new Promise<void>((resolve) => server.close(() => resolve()));
+  }
+}
+
+const HEADERS = { 'Content-Type': 'application/json', 'X-Worker-Secret': 'shh' };
+
+function event(n: number): GameEvent {
+  return { type: 'land_played', line: `Land: Ai(1)-Alpha played Forest (${n})` };
+}
+
+async function main() {
+  console.log('Running event stream tests...\n');
+
+  await test('postEvents sends the batch to the job events endpoint with worker headers', async () => {
+    let seen: { method?: string; url?: string; secret?: string; type?: string; body: string } = { body: '' };
+    await withServer((req, res) => {
+      let body = '';
+      req.on('data', (chunk) => { body += chunk; });
+      req.on('end', () => {
+        seen = {
+          method: req.method,
+          url: req.url,
+          secret: req.headers['x-worker-secret'] as string | undefined,
+          type: req.headers['content-type'],
+          body,
+        };
+        res.writeHead(202, { 'Content-Type': 'application/json' });
+        res.end('{}');
+      });
+    }, async (apiUrl) => {
+      await postEvents(apiUrl, HEADERS, 'job-1', [event(1), event(2)], 1000);
+    });
+    assertEqual(seen.method, 'POST', 'method');
+    assertEqual(seen.url, '/api/jobs/job-1/events', 'path');
+    assertEqual(seen.secret, 'shh', 'X-Worker-Secret header');
+    assertEqual(seen.type, 'application/json', 'Content-Type header');
+    assertEqual((JSON.parse(seen.body) as { events: GameEvent[] }).events.length, 2, 'events in body');
+  });
+
+  await test('postEvents throws on a non-2xx response', async () => {
+    await withServer((_req, res) => {
+      res.writeHead(503);
+      res.end();
+    }, async (apiUrl) => {
+      let message = '';
+      await postEvents(apiUrl, HEADERS, 'job-1', [event(1)], 1000).catch((err: Error) => { message = err.message; });
+      assertEqual(message, 'HTTP 503', 'error');
+    });
+  });
+
+  await test('EventBatcher sends full batches, then the remainder on close', async () => {
+    const sent: number[] = [];
+    const batcher = new EventBatcher(async (events) => { sent.push(events.length); }, { batchSize: 2, flushIntervalMs: 60_000 });
+    for (let i = 1; i <= 5; i++) batcher.add(event(i));
+    assertEqual(sent.join(','), '2,2', 'full batches sent as they fill');
+    await batcher.close();
+    assertEqual(sent.join(','), '2,2,1', 'remainder sent on close');
+  });
+
+  await test('EventBatcher sends a partial batch after the flush interval', async () => {
+    const sent: number[] = [];
+    const batcher = new EventBatcher(async (events) => { sent.push(events.length); }, { batchSize: 100, flushIntervalMs: 20 });
+    batcher.add(event(1));
+    batcher.add(event(2));
+    assertEqual(sent.length, 0, 'nothing sent yet');
+    await new Promise((resolve) => setTimeout(resolve, 60));
+    assertEqual(sent.join(','), '2', 'sent by the timer');
+    await batcher.close();
+    assertEqual(sent.join(','), '2', 'close with nothing pending sends nothing');
+  });
+
+  await test('EventBatcher failures are reported, not thrown', async () => {
+    const dropped: number[] = [];
+    const batcher = new EventBatcher(
+      async () => { throw new Error('down'); },
+      { batchSize: 2, flushIntervalMs: 60_000 },
+      (_err, count) => { dropped.push(count); }
+    );
+    for (let i = 1; i <= 3; i++) batcher.add(event(i));
+    await batcher.close();
+    assertEqual(dropped.join(','), '2,1', 'each failed batch reported with its size');
+  });
+
+  await test('resolveEventStreamOptions: off unless STREAM_EVENTS=true', async () => {
+    assertEqual(resolveEventStreamOptions({}), null, 'unset');
+    assertEqual(resolveEventStreamOptions({ STREAM_EVENTS: '1' }), null, 'not "true"');
+    const defaults = resolveEventStreamOptions({ STREAM_EVENTS: 'true', EVENT_BATCH_SIZE: 'x' });
+    assertEqual(defaults?.batchSize, DEFAULT_EVENT_STREAM.batchSize, 'invalid batch size falls back');
+    const custom = resolveEventStreamOptions({ STREAM_EVENTS: 'true', EVENT_BATCH_SIZE: '25', EVENT_FLUSH_MS: '500' });
+    assertEqual(custom?.batchSize, 25, 'batch size');
+    assertEqual(custom?.flushIntervalMs, 500, 'flush interval');
+  });
+
+  await test('resolveEventStreamOptions: batch size is capped at the per-post limit', async () => {
+    const big = resolveEventStreamOptions({ STREAM_EVENTS: 'true', EVENT_BATCH_SIZE: '2000' });
+    assertEqual(big?.batchSize, MAX_EVENTS_PER_POST, 'clamped');
+    const atCap = resolveEventStreamOptions({ STREAM_EVENTS: 'true', EVENT_BATCH_SIZE: String(MAX_EVENTS_PER_POST) });
+    assertEqual(atCap?.batchSize, MAX_EVENTS_PER_POST, 'cap itself allowed');
+  });
+
+  await test('condenseGameTo passes each kept event to the sink in order', async () => {
+    const log = [
+      'Turn: Turn 1 (Ai(1)-Alpha)',
+      'Land: Ai(1)-Alpha played Forest (1)',
+      'Ai(1)-Alpha cast Llanowar Elves (2)',
+      'Ai(1)-Alpha has won!',
+    ].join('\n');
+    const streamed: GameEvent[] = [];
+    const condensed = condenseGameTo(log, (e) => streamed.push(e));
+    assertEqual(streamed.length, condensed.keptEvents.length, 'one sink call per kept event');
+    assertEqual(JSON.stringify(condensed), JSON.stringify(condenseGame(log)), 'same output as condenseGame');
+  });
+
+  console.log('\n-------------------');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}`);
+  console.log(`Failed: ${failed}`);
+  if (failed > 0) process.exit(1);
+}
+
+main();
@@ -0,0 +1,120 @@
+/**
+ * Streams condensed events to the API's `/api/jobs/:id/events` endpoint
+ * while a simulation's log is being condensed, so the frontend can show a
+ * live timeline during long runs.
+ *
+ * Events are posted in batches: a batch is sent when it reaches
+ * `batchSize` events or `flushIntervalMs` after its first event, whichever
+ * comes first. Streaming is best-effort: a failed post is logged and its
+ * batch dropped, never failing the simulation.
+ */
+
+import type { GameEvent } from './types.js';
+
+export interface EventStreamOptions {
+  /** Events per POST */
+  batchSize: number;
+  /** Max time an event waits before its batch is sent */
+  flushIntervalMs: number;
+}
+
+export const DEFAULT_EVENT_STREAM: EventStreamOptions = { batchSize: 100, flushIntervalMs: 2000 };
+
+/**
+ * Most events the API accepts in one POST; larger batches get a 400. Keep in
+ * sync with MAX_LIVE_EVENTS_PER_POST in api/lib/live-events.ts.
+ */
+export const MAX_EVENTS_PER_POST = 1000;
+
+function positiveInt(value: string | undefined, fallback: number): number {
+  const parsed = parseInt(value ?? '', 10);
+  return Number.isFinite(parsed) && parsed > 0 ? parsed : fallback;
+}
+
+/**
+ * Reads streaming options from the environment. Returns null unless
+ * STREAM_EVENTS=true; EVENT_BATCH_SIZE and EVENT_FLUSH_MS override the
+ * defaults. EVENT_BATCH_SIZE is capped at MAX_EVENTS_PER_POST.
+ */
+export function resolveEventStreamOptions(env: NodeJS.ProcessEnv = process.env): EventStreamOptions | null {
+  if (env.STREAM_EVENTS !== 'true') return null;
+  return {
+    batchSize: Math.min(positiveInt(env.EVENT_BATCH_SIZE, DEFAULT_EVENT_STREAM.batchSize), MAX_EVENTS_PER_POST),
+    flushIntervalMs: positiveInt(env.EVENT_FLUSH_MS, DEFAULT_EVENT_STREAM.flushIntervalMs),
+  };
+}
+
+/**
+ * POSTs one batch of events for a job. Throws on network errors, timeouts
+ * and non-2xx responses.
+ */
+export async function postEvents(
+  apiUrl: string,
+  headers: Record<string, string>,
+  jobId: string,
+  events: GameEvent[],
+  timeoutMs: number
+): Promise<void> {
+  const res = await fetch(`${apiUrl}/api/jobs/${encodeURIComponent(jobId)}/events`, {
+    method: 'POST',
+    headers,
+    body: JSON.stringify({ events }),
+    signal: AbortSignal.timeout(timeoutMs),
+  });
+  if (!res.ok) {
+    throw new Error(`HTTP ${res.status}`);
+  }
+}
+
+/**
+ * Collects events and sends them in batches. Call `close()` when done to
+ * send what's left and wait for in-flight posts.
+ */
+export class EventBatcher {
+  private batch: GameEvent[] = [];
+  private timer: ReturnType<typeof setTimeout> | null = null;
+  private inFlight = new Set<Promise<void>>();
+  private readonly send: (events: GameEvent[]) => Promise<void>;
+  private readonly options: EventStreamOptions;
+  private readonly onError: (err: unknown, count: number) => void;
+
+  constructor(
+    send: (events: GameEvent[]) => Promise<void>,
+    options: EventStreamOptions = DEFAULT_EVENT_STREAM,
+    onError: (err: unknown, count: number) => void = () => {}
+  ) {
+    this.send = send;
+    this.options = options;
+    this.onError = onError;
+  }
+
+  add(event: GameEvent): void {
+    this.batch.push(event);
+    if (this.batch.length >= this.options.batchSize) {
+      this.flush();
+    } else if (!this.timer) {
+      this.timer = setTimeout(() => this.flush(), this.options.flushIntervalMs);
+    }
+  }
+
+  /** Sends the pending batch, if any, without waiting for it. */
+  flush(): void {
+    if (this.timer) {
+      clearTimeout(this.timer);
+      this.timer = null;
+    }
+    if (this.batch.length === 0) return;
+    const events = this.batch;
+    this.batch = [];
+    const post = this.send(events)
+      .catch((err) => this.onError(err, events.length))
+      .finally(() => this.inFlight.delete(post));
+    this.inFlight.add(post);
+  }
+
+  /** Sends the pending batch and waits for every post to settle. */
+  async close(): Promise<void> {
+    this.flush();
+    await Promise.all([...this.inFlight]);
+  }
+}
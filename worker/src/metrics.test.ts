@@ -0,0 +1,105 @@
+/**
+ * Unit tests for the worker's metrics, /metrics endpoint and METRICS_PORT.
+ * Run with: npx tsx src/metrics.test.ts
+ */
+
+import type { AddressInfo } from 'net';
+import {
+  metrics,
+  recordApiError,
+  recordCondense,
+  recordUpload,
+  registry,
+  resolveMetricsPort,
+  startMetricsServer,
+} from './metrics.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+function assertIncludes(text: string, line: string) {
+  if (!text.split('\n').includes(line)) {
+    throw new Error(`expected line ${JSON.stringify(line)} in:\n${text}`);
+  }
+}
+
+async function main() {
+  console.log('Running metrics tests...\n');
+
+  await test('METRICS_PORT must be a valid port', async () => {
+    assertEqual(resolveMetricsPort({}), undefined, 'unset');
+    assertEqual(resolveMetricsPort({ METRICS_PORT: ' ' }), undefined, 'empty');
+    assertEqual(resolveMetricsPort({ METRICS_PORT: '9464' }), 9464, 'valid');
+    for (const bad of ['abc', '0', '65536', '80x', '-1']) {
+      let threw = false;
+      try {
+        resolveMetricsPort({ METRICS_PORT: bad });
+      } catch (err) {
+        threw = err instanceof Error && err.message.startsWith('Invalid METRICS_PORT');
+      }
+      assertEqual(threw, true, `rejects ${JSON.stringify(bad)}`);
+    }
+  });
+
+  await test('GET /metrics serves the recorded pipeline metrics', async () => {
+    recordCondense(4, 20);
+    recordUpload(2048, 1500);
+    recordApiError('heartbeat');
+
+    const server = await startMetricsServer(0);
+    try {
+      const { port } = server.address() as AddressInfo;
+      const res = await fetch(`http://127.0.0.1:${port}/metrics`);
+      assertEqual(res.status, 200, 'status');
+      assertEqual(res.headers.get('content-type'), registry.contentType, 'content type');
+      const text = await res.text();
+      assertIncludes(text, 'worker_games_condensed_total 4');
+      assertIncludes(text, 'worker_artifacts_uploaded_total 1');
+      assertIncludes(text, 'worker_upload_bytes_total 2048');
+      assertIncludes(text, 'worker_api_errors_total{operation="heartbeat"} 1');
+      assertIncludes(text, 'worker_condense_duration_seconds_count 1');
+      assertIncludes(text, 'worker_upload_duration_seconds_bucket{le="1"} 0');
+      assertIncludes(text, 'worker_upload_duration_seconds_bucket{le="2.5"} 1');
+      const apiErrors = (await metrics.apiErrors.get()).values;
+      assertEqual(apiErrors.find((v) => v.labels.operation === 'heartbeat')?.value, 1, 'registry value');
+
+      const notFound = await fetch(`http://127.0.0.1:${port}/other`);
+      assertEqual(notFound.status, 404, 'unknown path');
+    } finally {
+      await new Promise((resolve) => server.close(resolve));
+    }
+  });
+
+  console.log('\n-------------------');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}`);
+  console.log(`Failed: ${failed}`);
+  if (failed > 0) process.exit(1);
+}
+
+main();
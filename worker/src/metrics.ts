@@ -0,0 +1,139 @@
+/**
+ * Prometheus operational metrics for the worker, built on prom-client.
+ *
+ * Served in the Prometheus text exposition format on GET /metrics when
+ * METRICS_PORT is set. The worker's metrics live in their own registry
+ * (not prom-client's global one) so only they are exposed.
+ *
+ * Metrics are updated from the real pipeline paths in worker.ts through the
+ * record* helpers below.
+ */
+
+import * as http from 'http';
+import { Counter, Histogram, Registry } from 'prom-client';
+
+/** Environment variable holding the metrics server port. */
+export const METRICS_PORT_ENV = 'METRICS_PORT';
+
+/** Default buckets in seconds, covering sub-millisecond condenses to slow uploads. */
+export const DEFAULT_BUCKETS = [0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30];
+
+export const registry = new Registry();
+
+// ---------------------------------------------------------------------------
+// Worker metrics
+// ---------------------------------------------------------------------------
+
+export const metrics = {
+  gamesCondensed: new Counter({
+    name: 'worker_games_condensed_total',
+    help: 'Games split and condensed from simulation logs',
+    registers: [registry],
+  }),
+  artifactsUploaded: new Counter({
+    name: 'worker_artifacts_uploaded_total',
+    help: 'Raw logs uploaded to the API',
+    registers: [registry],
+  }),
+  uploadBytes: new Counter({
+    name: 'worker_upload_bytes_total',
+    help: 'Bytes of raw log uploaded to the API',
+    registers: [registry],
+  }),
+  apiErrors: new Counter({
+    name: 'worker_api_errors_total',
+    help: 'Failed API requests, by operation',
+    labelNames: ['operation'] as const,
+    registers: [registry],
+  }),
+  deckCountMismatches: new Counter({
+    name: 'worker_deck_count_mismatch_total',
+    help: 'Simulations whose logs show a different player count than the job has decks',
+    registers: [registry],
+  }),
+  condenseDuration: new Histogram({
+    name: 'worker_condense_duration_seconds',
+    help: 'Time to split and condense a simulation log',
+    buckets: DEFAULT_BUCKETS,
+    registers: [registry],
+  }),
+  uploadDuration: new Histogram({
+    name: 'worker_upload_duration_seconds',
+    help: 'Time to upload a raw log to the API',
+    buckets: DEFAULT_BUCKETS,
+    registers: [registry],
+  }),
+};
+
+export function renderMetrics(): Promise<string> {
+  return registry.metrics();
+}
+
+export function recordCondense(games: number, durationMs: number): void {
+  metrics.gamesCondensed.inc(games);
+  metrics.condenseDuration.observe(durationMs / 1000);
+}
+
+export function recordUpload(bytes: number, durationMs: number): void {
+  metrics.artifactsUploaded.inc();
+  metrics.uploadBytes.inc(bytes);
+  metrics.uploadDuration.observe(durationMs / 1000);
+}
+
+export function recordApiError(operation: string): void {
+  metrics.apiErrors.inc({ operation });
+}
+
+export function recordDeckCountMismatch(): void {
+  metrics.deckCountMismatches.inc();
+}
+
+// ---------------------------------------------------------------------------
+// Server
+// ---------------------------------------------------------------------------
+
+/**
+ * Reads the metrics server port from the environment.
+ *
+ * @returns The port, or undefined when METRICS_PORT is unset or empty
+ * @throws If METRICS_PORT isn't an integer from 1 to 65535
+ */
+export function resolveMetricsPort(env: NodeJS.ProcessEnv = process.env): number | undefined {
+  const source = env[METRICS_PORT_ENV]?.trim();
+  if (!source) return undefined;
+  const port = /^\d+$/.test(source) ? parseInt(source, 10) : NaN;
+  if (!(port >= 1 && port <= 65535)) {
+    throw new Error(`Invalid ${METRICS_PORT_ENV}: "${source}" (expected a port from 1 to 65535)`);
+  }
+  return port;
+}
+
+/**
+ * Starts the metrics server. Port 0 picks a free port (for tests).
+ */
+export function startMetricsServer(port: number): Promise<http.Server> {
+  return new Promise((resolve, reject) => {
+    const server = http.createServer((req, res) => {
+      if (req.method === 'GET' && req.url === '/metrics') {
+        renderMetrics().then(
+          (body) => {
+            res.writeHead(200, {
+              'Content-Type': registry.contentType,
+              'Content-Length': Buffer.byteLength(body),
+            });
+            res.end(body);
+          },
+          () => {
+            res.writeHead(500);
+            res.end();
+          }
+        );
+        return;
+      }
+      res.writeHead(404);
+      res.end();
+    });
+    server.on('error', reject);
+    server.listen(port, '0.0.0.0', () => resolve(server));
+  });
+}
@@ -0,0 +1,145 @@
+/**
+ * Unit tests for per-job parallelism.
+ * Run with: npx tsx src/parallelism.test.ts
+ */
+
+import {
+  resolveJobParallelism,
+  JobConcurrencyLimiter,
+  MAX_PARALLELISM,
+} from './parallelism.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+/** Lets pending promise callbacks run. */
+function tick(): Promise<void> {
+  return new Promise((resolve) => setImmediate(resolve));
+}
+
+async function main() {
+  console.log('Running parallelism tests...\n');
+
+  await test('limit derives from the job parallelism', async () => {
+    assertEqual(resolveJobParallelism({ parallelism: 3 }, 8), 3, 'job value used');
+    assertEqual(resolveJobParallelism({ parallelism: 1 }, 8), 1, 'coverage job value');
+  });
+
+  await test('falls back when the job is missing or has no value', async () => {
+    assertEqual(resolveJobParallelism(null, 6), 6, 'no job');
+    assertEqual(resolveJobParallelism({ parallelism: 0 }, 6), 6, 'zero');
+    assertEqual(resolveJobParallelism({} as { parallelism: number }, 6), 6, 'absent');
+    assertEqual(resolveJobParallelism({ parallelism: NaN }, 6), 6, 'NaN');
+  });
+
+  await test('out-of-range values are clamped', async () => {
+    assertEqual(resolveJobParallelism({ parallelism: 500 }, 6), MAX_PARALLELISM, 'too high');
+    assertEqual(resolveJobParallelism({ parallelism: 2.7 }, 6), 2, 'fractional');
+  });
+
+  await test('limiter caps concurrent sims per job', async () => {
+    const limiter = new JobConcurrencyLimiter();
+    const limit = resolveJobParallelism({ parallelism: 2 }, 8);
+    const first = limiter.claim('job-1');
+    const second = limiter.claim('job-1');
+    const third = limiter.claim('job-1');
+    assertEqual(await first.acquire(limit), true, 'first sim runs');
+    assertEqual(await second.acquire(limit), true, 'second sim runs');
+
+    let thirdStarted = false;
+    const thirdAcquired = third.acquire(limit).then((acquired) => {
+      thirdStarted = true;
+      return acquired;
+    });
+    await tick();
+    assertEqual(thirdStarted, false, 'third sim waits');
+    assertEqual(limiter.active('job-1'), 2, 'two running');
+
+    // Other jobs are not affected
+    const other = limiter.claim('job-2');
+    assertEqual(await other.acquire(1), true, 'other job runs');
+    assertEqual(limiter.active('job-2'), 1, 'other job running');
+
+    first.release();
+    first.release(); // double release is a no-op
+    assertEqual(await thirdAcquired, true, 'third sim acquires');
+    assertEqual(thirdStarted, true, 'third sim starts after a release');
+    assertEqual(limiter.active('job-1'), 2, 'still two running');
+
+    second.release();
+    third.release();
+    other.release();
+    assertEqual(limiter.active('job-1'), 0, 'job-1 drained');
+    assertEqual(limiter.active('job-2'), 0, 'job-2 drained');
+  });
+
+  await test('jobs at their limit are reported as saturated', async () => {
+    let changes = 0;
+    const limiter = new JobConcurrencyLimiter(() => changes++);
+    const first = limiter.claim('job-1');
+    assertEqual(limiter.saturatedJobs().join(), 'job-1', 'limit unknown until fetched');
+    await first.acquire(2);
+    assertEqual(limiter.saturatedJobs().length, 0, 'room for a second sim');
+    assertEqual(changes, 1, 'learning the limit is a change');
+    const second = limiter.claim('job-1');
+    await second.acquire(2);
+    assertEqual(limiter.saturatedJobs().join(), 'job-1', 'two claimed of two');
+    second.release();
+    assertEqual(limiter.saturatedJobs().length, 0, 'room again after a release');
+    assertEqual(changes, 2, 'a release is a change');
+    first.release();
+    assertEqual(limiter.saturatedJobs().length, 0, 'drained jobs are forgotten');
+  });
+
+  await test('a waiting sim stops waiting when cancelled', async () => {
+    const limiter = new JobConcurrencyLimiter();
+    const running = limiter.claim('job-1');
+    const waiting = limiter.claim('job-1');
+    await running.acquire(1);
+    const controller = new AbortController();
+    const acquired = waiting.acquire(1, controller.signal);
+    await tick();
+    controller.abort();
+    assertEqual(await acquired, false, 'cancelled wait gives up');
+    waiting.release();
+    running.release();
+    assertEqual(limiter.active('job-1'), 0, 'cancelled sim never held a slot');
+
+    const late = limiter.claim('job-2');
+    assertEqual(await late.acquire(1, controller.signal), true, 'a free slot is taken even if already aborted');
+    late.release();
+  });
+
+  console.log('\n-------------------');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}`);
+  console.log(`Failed: ${failed}`);
+  if (failed > 0) process.exit(1);
+}
+
+main();
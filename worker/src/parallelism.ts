@@ -0,0 +1,151 @@
+/**
+ * Per-job simulation parallelism.
+ *
+ * Jobs carry a `parallelism` value set when they are created (1-16 in the
+ * API, 1 for coverage jobs). The worker's own semaphore caps how many sims
+ * run on this machine; this caps how many sims of ONE job run at once, so
+ * the value the job was created with actually takes effect.
+ *
+ * The polling loop asks claim-sim to skip jobs that are already at their
+ * limit on this worker (saturatedJobs), so sims over the limit are normally
+ * never claimed. A job's limit is only known once one of its sims has
+ * fetched it, so until then a job with a sim in flight is skipped too.
+ * A sim can still end up over the limit if the limit drops; it then waits
+ * for a slot, and the wait ends early if the sim is cancelled.
+ */
+
+import type { JobData } from './types.js';
+
+export const MIN_PARALLELISM = 1;
+export const MAX_PARALLELISM = 16;
+
+/**
+ * Resolves how many sims of a job may run at once.
+ *
+ * @param job - The fetched job, or null if it couldn't be fetched
+ * @param fallback - Limit to use when the job has no usable value
+ *                   (normally the worker's env/CPU-derived capacity)
+ * @returns The job's parallelism clamped to MIN..MAX_PARALLELISM, or the fallback
+ */
+export function resolveJobParallelism(
+  job: Pick<JobData, 'parallelism'> | null | undefined,
+  fallback: number
+): number {
+  const value = Math.floor(Number(job?.parallelism));
+  if (!Number.isFinite(value) || value <= 0) return fallback;
+  return Math.min(MAX_PARALLELISM, Math.max(MIN_PARALLELISM, value));
+}
+
+interface JobSlots {
+  /** Sims of the job claimed by this worker and not yet finished */
+  claimed: number;
+  /** Sims holding a slot */
+  active: number;
+  /** Latest known limit; undefined until one of the job's sims fetches it */
+  limit?: number;
+  waiting: (() => void)[];
+}
+
+/** One claimed sim's hold on its job's slots. */
+export interface JobSlot {
+  /**
+   * Waits for a slot in the job. The latest limit passed for a job wins, so
+   * a changed value applies to the next sim that starts.
+   *
+   * @returns true once the slot is held; false if signal aborted first
+   */
+  acquire(limit: number, signal?: AbortSignal): Promise<boolean>;
+  /** Gives up the slot (if held) and the claim. Safe to call twice. */
+  release(): void;
+}
+
+/**
+ * Counting limiter keyed by job ID. Entries are dropped once a job has no
+ * claimed sims.
+ */
+export class JobConcurrencyLimiter {
+  private readonly jobs = new Map<string, JobSlots>();
+  private readonly onChange: () => void;
+
+  /**
+   * @param onChange - Called when a job may have stopped being saturated
+   *                   (its limit became known or a claim was released)
+   */
+  constructor(onChange: () => void = () => {}) {
+    this.onChange = onChange;
+  }
+
+  /** Records a sim claimed for the job; call right after the claim. */
+  claim(jobId: string): JobSlot {
+    let slots = this.jobs.get(jobId);
+    if (!slots) {
+      slots = { claimed: 0, active: 0, waiting: [] };
+      this.jobs.set(jobId, slots);
+    }
+    const entry = slots;
+    entry.claimed++;
+
+    let holding = false;
+    let released = false;
+    return {
+      acquire: async (limit, signal) => {
+        if (released) return false;
+        const changed = entry.limit !== limit;
+        entry.limit = limit;
+        if (changed) this.onChange();
+        if (entry.active < limit) {
+          entry.active++;
+          holding = true;
+          return true;
+        }
+        if (signal?.aborted) return false;
+        holding = await new Promise<boolean>((resolve) => {
+          const wake = () => {
+            signal?.removeEventListener('abort', onAbort);
+            resolve(true);
+          };
+          const onAbort = () => {
+            entry.waiting.splice(entry.waiting.indexOf(wake), 1);
+            resolve(false);
+          };
+          entry.waiting.push(wake);
+          signal?.addEventListener('abort', onAbort, { once: true });
+        });
+        return holding;
+      },
+      release: () => {
+        if (released) return;
+        released = true;
+        entry.claimed--;
+        if (holding) {
+          entry.active--;
+          this.wake(entry);
+        }
+        if (entry.claimed === 0) this.jobs.delete(jobId);
+        this.onChange();
+      },
+    };
+  }
+
+  /** Number of sims of the job currently holding a slot. */
+  active(jobId: string): number {
+    return this.jobs.get(jobId)?.active ?? 0;
+  }
+
+  /**
+   * Jobs this worker shouldn't claim more sims of: those with as many sims
+   * claimed as their limit, or with a sim claimed and no limit known yet.
+   */
+  saturatedJobs(): string[] {
+    return [...this.jobs.entries()]
+      .filter(([, slots]) => slots.limit === undefined || slots.claimed >= slots.limit)
+      .map(([jobId]) => jobId);
+  }
+
+  private wake(slots: JobSlots): void {
+    while (slots.active < (slots.limit ?? 0) && slots.waiting.length > 0) {
+      slots.active++;
+      slots.waiting.shift()!();
+    }
+  }
+}
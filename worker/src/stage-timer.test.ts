@@ -0,0 +1,83 @@
+/**
+ * Unit tests for StageTimer / withStageDurations.
+ * Run with: npx tsx src/stage-timer.test.ts
+ */
+
+import { StageTimer, withStageDurations } from './stage-timer.js';
+
+interface TestResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+}
+
+const results: TestResult[] = [];
+
+async function test(name: string, fn: () => Promise<void>) {
+  try {
+    await fn();
+    results.push({ name, passed: true });
+    console.log(`✓ ${name}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    results.push({ name, passed: false, error: message });
+    console.log(`✗ ${name}`);
+    console.log(`  Error: ${message}`);
+  }
+}
+
+function assertEqual<T>(actual: T, expected: T, message: string) {
+  if (actual !== expected) {
+    throw new Error(`${message}: expected ${JSON.stringify(expected)}, got ${JSON.stringify(actual)}`);
+  }
+}
+
+async function main() {
+  console.log('Running stage timer tests...\n');
+
+  await test('no timed stages leaves the PATCH body unchanged', async () => {
+    const body = withStageDurations({ state: 'COMPLETED' }, new StageTimer());
+    assertEqual(JSON.stringify(body), '{"state":"COMPLETED"}', 'body');
+  });
+
+  await test('timed stages are included in the PATCH body', async () => {
+    const timer = new StageTimer();
+    const value = await timer.time('run', async () => {
+      await new Promise((resolve) => setTimeout(resolve, 20));
+      return 42;
+    });
+    await timer.time('condense', () => 'sync');
+    await timer.time('upload', async () => undefined);
+    assertEqual(value, 42, 'return value passed through');
+
+    const body = JSON.parse(JSON.stringify(withStageDurations({ state: 'COMPLETED', durationMs: 100 }, timer)));
+    assertEqual(body.state, 'COMPLETED', 'existing fields kept');
+    assertEqual(body.stageDurationsMs.run >= 15, true, 'run duration recorded');
+    assertEqual(typeof body.stageDurationsMs.condense, 'number', 'condense duration recorded');
+    assertEqual(typeof body.stageDurationsMs.upload, 'number', 'upload duration recorded');
+    assertEqual(body.stageDurationsMs.fetch, undefined, 'untimed stage omitted');
+  });
+
+  await test('a throwing stage still records its duration', async () => {
+    const timer = new StageTimer();
+    let threw = false;
+    try {
+      await timer.time('fetch', () => {
+        throw new Error('boom');
+      });
+    } catch {
+      threw = true;
+    }
+    assertEqual(threw, true, 'error propagated');
+    assertEqual(typeof timer.snapshot()?.fetch, 'number', 'fetch duration recorded');
+  });
+
+  console.log('\n-------------------');
+  const passed = results.filter((r) => r.passed).length;
+  const failed = results.filter((r) => !r.passed).length;
+  console.log(`Passed: ${passed}`);
+  console.log(`Failed: ${failed}`);
+  if (failed > 0) process.exit(1);
+}
+
+main();
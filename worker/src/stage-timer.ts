@@ -0,0 +1,45 @@
+/**
+ * Per-stage wall-clock timing for a simulation, reported to the API as
+ * `stageDurationsMs` on the simulation PATCH.
+ *
+ * Timing is best-effort: stages that never ran are simply omitted, and a
+ * stage that throws still records how long it took before the error.
+ */
+
+// The worker can't import shared/, so keep in sync with SIMULATION_STAGES in
+// shared/types/simulation.ts (and the stageDurationsMs schema in
+// api/lib/validation.ts).
+export type SimulationStage = 'fetch' | 'run' | 'condense' | 'upload';
+
+export type StageDurations = Partial<Record<SimulationStage, number>>;
+
+export class StageTimer {
+  private readonly durations: StageDurations = {};
+
+  /** Runs fn and records its duration under stage (overwriting any earlier run). */
+  async time<T>(stage: SimulationStage, fn: () => Promise<T> | T): Promise<T> {
+    const start = Date.now();
+    try {
+      return await fn();
+    } finally {
+      this.durations[stage] = Date.now() - start;
+    }
+  }
+
+  /** Recorded durations, or undefined if no stage has been timed. */
+  snapshot(): StageDurations | undefined {
+    return Object.keys(this.durations).length > 0 ? { ...this.durations } : undefined;
+  }
+}
+
+/**
+ * Adds `stageDurationsMs` to a simulation status update when any stage was
+ * timed. The update is returned unchanged otherwise.
+ */
+export function withStageDurations(
+  update: Record<string, unknown>,
+  timer: StageTimer
+): Record<string, unknown> {
+  const durations = timer.snapshot();
+  return durations ? { ...update, stageDurationsMs: durations } : update;
+}
@@ -45,6 +45,8 @@ export interface JobData {
   simulations: number;
   parallelism: number;
   status: string;
+  /** Times the API has reset the job for another attempt (0 on the first) */
+  retryCount?: number;
 }
 
 // A deck slot in a job
@@ -42,17 +42,36 @@ import {
   splitConcatenatedGames,
   extractWinner,
   extractWinningTurn,
+  getWinLinePattern,
+  getWinnerCapture,
+  getSimultaneousWinScoring,
+  getWinnerAliases,
+  applyWinnerAlias,
+  checkDeckCount,
+  condenseGameTo,
 } from './condenser.js';
 import { startWorkerApi, stopWorkerApi, HealthStatus } from './worker-api.js';
 import { createLogger } from './logger.js';
 import { captureWorkerException, addWorkerBreadcrumb, flushSentry } from './sentry.js';
 import { parseOverrideHeader } from './override.js';
+import { claimSim, type ClaimedSim } from './claim.js';
+import { StageTimer, withStageDurations } from './stage-timer.js';
+import { startMetricsServer, resolveMetricsPort, recordCondense, recordUpload, recordApiError, recordDeckCountMismatch } from './metrics.js';
+import { resolveJobParallelism, JobConcurrencyLimiter, type JobSlot } from './parallelism.js';
+import { resolveEventStreamOptions, postEvents, EventBatcher } from './event-stream.js';
+import { resolveJobMaxAttempts, isFinalAttempt, jobAttempt, partialResult } from './attempts.js';
 
 const log = createLogger('Worker');
 
 
 const SECRET_NAME = 'simulation-worker-config';
 const API_TIMEOUT_MS = parseInt(process.env.API_TIMEOUT_MS || '10000', 10);
+// Fail sims whose logs show a different player count than the job has decks
+const STRICT_DECK_COUNT = process.env.STRICT_DECK_COUNT === 'true';
+// Stream condensed events to the API while condensing (null = off)
+const EVENT_STREAM = resolveEventStreamOptions();
+// Job attempts before a failing sim keeps partial results instead of failing
+const JOB_MAX_ATTEMPTS = resolveJobMaxAttempts();
 
 // Module-scoped worker ID and name, set in main() after initialization
 let currentWorkerId = '';
@@ -68,6 +87,10 @@ const workerStartTime = Date.now();
 // Shared simulation concurrency semaphore, initialized in main()
 let simSemaphore: Semaphore | null = null;
 
+// Per-job cap on concurrent sims, from each job's parallelism. A job that
+// may have room again wakes the polling loop.
+const jobLimiter = new JobConcurrencyLimiter(() => notifyJobAvailable());
+
 // Abort controllers per job for push-based cancellation
 const activeAbortControllers = new Map<string, Set<AbortController>>();
 
@@ -238,11 +261,13 @@ async function fetchJob(jobId: string): Promise<JobData | null> {
     });
     if (!response.ok) {
       console.error(`Failed to fetch job ${jobId}: ${response.status}`);
+      recordApiError('fetch-job');
       return null;
     }
     return await response.json();
   } catch (error) {
     console.error(`Error fetching job ${jobId}:`, error);
+    recordApiError('fetch-job');
     return null;
   }
 }
@@ -269,9 +294,11 @@ async function reportSimulationStatus(
       const data = await res.json() as { updated?: boolean };
       return data.updated !== false;
     }
+    recordApiError('report-status');
     return false;
   } catch {
     // Non-fatal: simulation status update failing shouldn't crash the sim
+    recordApiError('report-status');
     return true; // Assume accepted on network failure to avoid skipping work
   }
 }
@@ -290,6 +317,7 @@ async function uploadSingleSimulationLog(
 ): Promise<void> {
   const filename = `raw/game_${String(simIndex + 1).padStart(3, '0')}.txt`;
   const url = `${getApiUrl()}/api/jobs/${jobId}/logs/simulation`;
+  const startedAt = Date.now();
   try {
     const res = await fetch(url, {
       method: 'POST',
@@ -299,15 +327,18 @@ async function uploadSingleSimulationLog(
     });
     if (!res.ok) {
       console.warn(`[sim_${String(simIndex).padStart(3, '0')}] Log upload failed: HTTP ${res.status}`);
+      recordApiError('log-upload');
       captureWorkerException(
         new Error(`Log upload failed: HTTP ${res.status}`),
         { component: 'log-upload', jobId, simIndex, workerId: currentWorkerId }
       );
     } else {
+      recordUpload(Buffer.byteLength(logText), Date.now() - startedAt);
       console.log(`[sim_${String(simIndex).padStart(3, '0')}] Log uploaded (${(logText.length / 1024).toFixed(1)}KB)`);
     }
   } catch (err) {
     console.warn(`[sim_${String(simIndex).padStart(3, '0')}] Log upload error:`, err instanceof Error ? err.message : err);
+    recordApiError('log-upload');
     captureWorkerException(err, {
       component: 'log-upload',
       jobId,
@@ -317,6 +348,26 @@ async function uploadSingleSimulationLog(
   }
 }
 
+/**
+ * Stream each game's condensed events to the API for the live timeline
+ * (non-fatal: failed batches are logged and dropped).
+ */
+async function streamGameEvents(jobId: string, simLabel: string, games: string[]): Promise<void> {
+  if (!EVENT_STREAM) return;
+  const batcher = new EventBatcher(
+    (events) => postEvents(getApiUrl(), getApiHeaders(), jobId, events, API_TIMEOUT_MS),
+    EVENT_STREAM,
+    (err, count) => {
+      console.warn(`${simLabel} Event stream batch of ${count} dropped:`, err instanceof Error ? err.message : err);
+      recordApiError('post-events');
+    }
+  );
+  for (const game of games) {
+    condenseGameTo(game, (event) => batcher.add(event));
+  }
+  await batcher.close();
+}
+
 // ============================================================================
 // Simulation Processing
 // ============================================================================
@@ -328,14 +379,15 @@ async function uploadSingleSimulationLog(
 async function processSimulation(
   jobId: string,
   simId: string,
-  simIndex: number
+  simIndex: number,
+  jobSlot: JobSlot
 ): Promise<void> {
   const simLabel = `[${simId}]`;
   console.log(`${simLabel} Processing simulation for job ${jobId}`);
   addWorkerBreadcrumb('processSimulation:start', { jobId, simId, simIndex });
 
   try {
-    await processSimulationInternal(jobId, simId, simIndex);
+    await processSimulationInternal(jobId, simId, simIndex, jobSlot);
   } catch (err) {
     // Any uncaught error from container orchestration, reporting, etc.
     // lands here. Capture with full context before re-throwing so the
@@ -354,12 +406,14 @@ async function processSimulation(
 async function processSimulationInternal(
   jobId: string,
   simId: string,
-  simIndex: number
+  simIndex: number,
+  jobSlot: JobSlot
 ): Promise<void> {
   const simLabel = `[${simId}]`;
+  const stages = new StageTimer();
 
   // Fetch job for deck data (coalesced — concurrent sims for the same job share one request)
-  const job = await fetchJobCached(jobId);
+  const job = await stages.time('fetch', () => fetchJobCached(jobId));
   if (!job) {
     console.error(`${simLabel} Job ${jobId} not found, reporting FAILED`);
     await reportSimulationStatus(jobId, simId, {
@@ -395,12 +449,15 @@ async function processSimulationInternal(
     job.decks[2].dck,
     job.decks[3].dck,
   ];
+  const deckNames = job.decks.map((deck) => deck.name);
 
   // claim-sim has already flipped this sim to RUNNING with our workerId;
   // no need to PATCH here. Just bump the in-flight counter.
   activeSimCount++;
 
-  // Register abort controller for push-based cancellation and capacity preemption
+  // Register abort controller for push-based cancellation and capacity
+  // preemption, before any wait for a job slot so a waiting sim can be
+  // cancelled too
   const abortController = new AbortController();
   if (!activeAbortControllers.has(jobId)) {
     activeAbortControllers.set(jobId, new Set());
@@ -409,47 +466,80 @@ async function processSimulationInternal(
   allActiveAbortControllers.push(abortController);
 
   try {
+    // Respect the job's parallelism; falls back to this worker's capacity
+    // when the job has no usable value. The polling loop doesn't claim sims
+    // of a job at its limit, so this only waits if the limit just dropped.
+    const acquired = await jobSlot.acquire(resolveJobParallelism(job, localCapacity), abortController.signal);
+    if (!acquired || abortController.signal.aborted) {
+      console.log(`${simLabel} Cancelled while waiting for a job slot`);
+      await reportSimulationStatus(jobId, simId, { state: 'CANCELLED' });
+      return;
+    }
+
     const MAX_RETRIES = 2;
     const RETRY_DELAY_MS = 30_000;
 
     for (let attempt = 0; attempt <= MAX_RETRIES; attempt++) {
       // Run the simulation container with cancellation signal
-      const result = await runSimulationContainer(jobId, simId, simIndex, deckContents, abortController.signal);
+      const result = await stages.time('run', () =>
+        runSimulationContainer(jobId, simId, simIndex, deckContents, abortController.signal)
+      );
 
       if (result.error === 'AlreadyRunning') {
         // Container is already running from a previous attempt — don't report status
         return;
       } else if (result.error === 'Cancelled') {
         console.log(`${simLabel} CANCELLED in ${formatDuration(result.durationMs)}`);
-        await reportSimulationStatus(jobId, simId, {
+        await reportSimulationStatus(jobId, simId, withStageDurations({
           state: 'CANCELLED',
           durationMs: result.durationMs,
-        });
+        }, stages));
         return;
       } else if (result.exitCode === 0) {
         // Split concatenated 4-game log and extract per-game winners/turns
-        const games = splitConcatenatedGames(result.logText);
-        const winners: string[] = [];
-        const winningTurns: number[] = [];
-        for (const game of games) {
-          const w = extractWinner(game);
-          if (w) winners.push(w);
-          const t = extractWinningTurn(game);
-          if (t > 0) winningTurns.push(t);
+        const { games, winners, winningTurns } = await stages.time('condense', () => {
+          const condenseStart = Date.now();
+          const games = splitConcatenatedGames(result.logText);
+          const winners: string[] = [];
+          const winningTurns: number[] = [];
+          for (const game of games) {
+            const w = extractWinner(game);
+            if (w) winners.push(applyWinnerAlias(w, deckNames));
+            const t = extractWinningTurn(game);
+            if (t > 0) winningTurns.push(t);
+          }
+          recordCondense(games.length, Date.now() - condenseStart);
+          return { games, winners, winningTurns };
+        });
+        await streamGameEvents(jobId, simLabel, games);
+        const deckCount = checkDeckCount(deckContents.length, games);
+        if (deckCount.warning) {
+          console.warn(`${simLabel} Deck count mismatch: ${deckCount.warning}`);
+          recordDeckCountMismatch();
+          if (STRICT_DECK_COUNT) {
+            await reportSimulationStatus(jobId, simId, withStageDurations({
+              state: 'FAILED',
+              durationMs: result.durationMs,
+              errorMessage: `Deck count mismatch: ${deckCount.warning}`,
+            }, stages));
+            return;
+          }
         }
+
         console.log(`${simLabel} COMPLETED in ${formatDuration(result.durationMs)}, logSize=${(result.logText.length / 1024).toFixed(1)}KB, games=${games.length}, winners=${winners.length}`);
 
-        await reportSimulationStatus(jobId, simId, {
+        // Upload log incrementally (non-fatal), before COMPLETED so its
+        // timing can be reported with the status
+        if (result.logText.trim()) {
+          await stages.time('upload', () => uploadSingleSimulationLog(jobId, simIndex, result.logText));
+        }
+
+        await reportSimulationStatus(jobId, simId, withStageDurations({
           state: 'COMPLETED',
           durationMs: result.durationMs,
           winners,
           winningTurns,
-        });
-
-        // Upload log incrementally (non-fatal)
-        if (result.logText.trim()) {
-          await uploadSingleSimulationLog(jobId, simIndex, result.logText);
-        }
+        }, stages));
         return;
       } else {
         // Container failed — retry locally before reporting FAILED
@@ -468,17 +558,33 @@ async function processSimulationInternal(
           continue; // Retry
         }
 
-        // All retries exhausted — report FAILED
+        // All retries exhausted. On the job's final attempt, keep any
+        // complete games rather than leaving the job with nothing.
+        const partial = isFinalAttempt(job, JOB_MAX_ATTEMPTS) ? partialResult(result.logText, errorMsg) : null;
+        if (partial) {
+          console.log(`${simLabel} PARTIAL on job attempt ${jobAttempt(job)}/${JOB_MAX_ATTEMPTS}: ${partial.note}`);
+          await stages.time('upload', () => uploadSingleSimulationLog(jobId, simIndex, partial.logText));
+          await reportSimulationStatus(jobId, simId, withStageDurations({
+            state: 'COMPLETED',
+            durationMs: result.durationMs,
+            winners: partial.winners.map((w) => applyWinnerAlias(w, deckNames)),
+            winningTurns: partial.winningTurns,
+            errorMessage: partial.note,
+          }, stages));
+          return;
+        }
+
+        // Report FAILED
         console.log(`${simLabel} FAILED in ${formatDuration(result.durationMs)} after ${attempt + 1} attempt(s): ${errorMsg}`);
         if (result.logText) {
           console.log(`${simLabel} Log preview (first 500 chars): ${result.logText.slice(0, 500)}`);
         }
 
-        await reportSimulationStatus(jobId, simId, {
+        await reportSimulationStatus(jobId, simId, withStageDurations({
           state: 'FAILED',
           durationMs: result.durationMs,
           errorMessage: errorMsg,
-        });
+        }, stages));
         return;
       }
     }
@@ -631,6 +737,7 @@ async function sendHeartbeat(status?: 'idle' | 'busy' | 'updating', timeoutMs?:
     });
     if (!res.ok) {
       log.warn('Heartbeat failed', { status: res.status, statusText: res.statusText });
+      recordApiError('heartbeat');
       return;
     }
 
@@ -647,6 +754,7 @@ async function sendHeartbeat(status?: 'idle' | 'busy' | 'updating', timeoutMs?:
     initialHeartbeatDone = true;
   } catch (err) {
     log.warn('Heartbeat error', { error: err instanceof Error ? err.message : err });
+    recordApiError('heartbeat');
   }
 }
 
@@ -858,9 +966,10 @@ async function requestCoverageJob(): Promise<boolean> {
  *
  * For each available semaphore slot, ask the API to atomically claim the
  * next PENDING simulation. On success, process it in the background (fire-
- * and-forget) so the loop can immediately try to fill the next slot. On
- * 204 (no work), request a coverage job and then sleep until the push-
- * notify or the idle timeout wakes us.
+ * and-forget) so the loop can immediately try to fill the next slot. Jobs
+ * already at their parallelism on this worker are excluded from the claim.
+ * On 204 (no work), request a coverage job and then sleep until the push-
+ * notify, a job slot freeing up or the idle timeout wakes us.
  */
 async function pollForSims(): Promise<void> {
   const POLL_INTERVAL_MS = parseInt(process.env.POLL_INTERVAL_MS || '3000', 10);
@@ -877,23 +986,19 @@ async function pollForSims(): Promise<void> {
     // can't run. Resize-down paths already preempt excess in-flight sims.
     await simSemaphore!.acquire();
 
-    let claimed: { jobId: string; simId: string; simIndex: number } | null = null;
+    let claimed: ClaimedSim | null = null;
+    const saturatedJobs = jobLimiter.saturatedJobs();
     try {
-      const claimUrl = new URL(`${getApiUrl()}/api/jobs/claim-sim`);
-      claimUrl.searchParams.set('workerId', currentWorkerId);
-      claimUrl.searchParams.set('workerName', currentWorkerName);
-      const res = await fetch(claimUrl.toString(), {
-        headers: getApiHeaders(),
-        signal: AbortSignal.timeout(API_TIMEOUT_MS),
-      });
+      const result = await claimSim(
+        getApiUrl(), getApiHeaders(), currentWorkerId, currentWorkerName, API_TIMEOUT_MS, saturatedJobs
+      );
       // Sync override from response header. This is the responsive fallback
       // when push /config is unavailable (worker behind NAT / WORKER_API_URL
       // unset): the override propagates within one poll interval.
-      applyOverrideFromHeader(res.headers.get('X-Max-Concurrent-Override'));
-      if (res.status === 200) {
-        claimed = (await res.json()) as { jobId: string; simId: string; simIndex: number };
-      } else if (res.status !== 204) {
-        console.warn(`claim-sim unexpected status ${res.status}`);
+      applyOverrideFromHeader(result.overrideHeader);
+      claimed = result.sim;
+      if (result.status !== 200 && result.status !== 204) {
+        console.warn(`claim-sim unexpected status ${result.status}`);
       }
     } catch (error) {
       if (error instanceof Error && error.name !== 'TimeoutError') {
@@ -904,16 +1009,23 @@ async function pollForSims(): Promise<void> {
 
     if (!claimed) {
       simSemaphore!.release();
-      const coverageCreated = await requestCoverageJob();
-      if (coverageCreated) continue; // Try to claim the coverage sim immediately.
+      // Skipped jobs still have work, so only look for coverage work when
+      // nothing was skipped. A skipped job gaining room wakes the loop.
+      if (saturatedJobs.length === 0) {
+        const coverageCreated = await requestCoverageJob();
+        if (coverageCreated) continue; // Try to claim the coverage sim immediately.
+      }
       await waitForNotifyOrTimeout(POLL_INTERVAL_MS);
       continue;
     }
 
     const { jobId, simId, simIndex } = claimed;
+    // Count the claim against its job before the next claim, so the loop
+    // skips the job once it's at its parallelism.
+    const jobSlot = jobLimiter.claim(jobId);
     // Fire-and-forget so the loop can claim the next sim in parallel.
-    // simSemaphore is released in the finally handler below.
-    processSimulation(jobId, simId, simIndex)
+    // simSemaphore and the job slot are released in the finally handler below.
+    processSimulation(jobId, simId, simIndex, jobSlot)
       .catch(async (error) => {
         console.error(`Error processing simulation ${simId} for job ${jobId}:`, error);
         await reportSimulationStatus(jobId, simId, {
@@ -921,7 +1033,10 @@ async function pollForSims(): Promise<void> {
           errorMessage: error instanceof Error ? error.message : 'Unknown error',
         });
       })
-      .finally(() => simSemaphore!.release());
+      .finally(() => {
+        jobSlot.release();
+        simSemaphore!.release();
+      });
   }
 }
 
@@ -983,6 +1098,15 @@ process.on('SIGINT', () => handleShutdown('SIGINT'));
 async function main(): Promise<void> {
   await loadConfigFromSecretManager();
 
+  // Fail fast on a bad WIN_LINE_PATTERN, WINNER_CAPTURE, SIMULTANEOUS_WIN_SCORING or
+  // WINNER_ALIASES rather than misreporting winners, and on a bad METRICS_PORT rather
+  // than exporting nothing
+  getWinLinePattern();
+  getWinnerCapture();
+  getSimultaneousWinScoring();
+  getWinnerAliases();
+  const metricsPort = resolveMetricsPort();
+
   currentWorkerName = getWorkerName();
   currentWorkerId = getWorkerId();
   log.info('Worker identity', { workerId: currentWorkerId, workerName: currentWorkerName });
@@ -1016,6 +1140,12 @@ async function main(): Promise<void> {
     getHealth: (): HealthStatus => ({ ok: true }),
   });
 
+  // Optional Prometheus metrics endpoint (disabled unless METRICS_PORT is set)
+  if (metricsPort !== undefined) {
+    await startMetricsServer(metricsPort);
+    log.info('Metrics server listening', { port: metricsPort, path: '/metrics' });
+  }
+
   // Initial heartbeat (await to apply override before polling starts)
   await sendHeartbeat();
   // 60s default: the heartbeat exists only so the frontend can show "worker